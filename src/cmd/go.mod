@@ -6,16 +6,16 @@ require (
 	github.com/google/pprof v0.0.0-20250630185457-6e76a2b096b5
 	golang.org/x/arch v0.22.1-0.20251016010524-fea4a9ec4938
 	golang.org/x/build v0.0.0-20250806225920-b7c66c047964
-	golang.org/x/mod v0.30.1-0.20251114215501-3f03020ad526
-	golang.org/x/sync v0.18.0
-	golang.org/x/sys v0.38.0
-	golang.org/x/telemetry v0.0.0-20251111182119-bc8e575c7b54
+	golang.org/x/mod v0.36.0
+	golang.org/x/sync v0.20.0
+	golang.org/x/sys v0.45.0
+	golang.org/x/telemetry v0.0.0-20260508192327-42602be52be6
 	golang.org/x/term v0.34.0
-	golang.org/x/tools v0.39.1-0.20251114194111-59ff18ce4883
+	golang.org/x/tools v0.44.0
 )
 
 require (
 	github.com/ianlancetaylor/demangle v0.0.0-20250417193237-f615e6bd150b // indirect
-	golang.org/x/text v0.30.0 // indirect
+	golang.org/x/text v0.37.0 // indirect
 	rsc.io/markdown v0.0.0-20240306144322-0bf8f97ee8ef // indirect
 )
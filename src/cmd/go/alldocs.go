@@ -175,6 +175,13 @@
 //		cannot be included due to a missing tool or ambiguous directory structure.
 //	-compiler name
 //		name of compiler to use, as in runtime.Compiler (gccgo or gc).
+//	-explain
+//		print why each package or binary that is rebuilt could not be
+//		reused from the build cache: which of its inputs, such as source
+//		files, flags, environment variables, dependencies, and the
+//		toolchain, differ from the last build of the same package or
+//		binary for the same GOOS and GOARCH that the cache recorded
+//		while using -explain.
 //	-gccgoflags '[pattern=]arg list'
 //		arguments to pass on each gccgo compiler/linker invocation.
 //	-gcflags '[pattern=]arg list'
@@ -551,7 +558,7 @@
 //
 // Usage:
 //
-//	go generate [-run regexp] [-n] [-v] [-x] [build flags] [file.go... | packages]
+//	go generate [-run regexp] [-check] [-n] [-v] [-x] [build flags] [file.go... | packages]
 //
 // Generate runs commands described by directives within existing
 // files. Those commands can run any process but the intent is to
@@ -640,8 +647,28 @@
 // specifies that the command "foo" represents the generator
 // "go tool foo".
 //
+// A directive may declare the files the generator reads and writes
+// with options before the command:
+//
+//	//go:generate -in=api.yaml -in=templates/*.tmpl -out=api_gen.go go run ./internal/gen
+//
+// Each -in=pattern option names input files and each -out=pattern option
+// names output files, using the syntax of path/filepath.Match on
+// slash-separated paths relative to the package directory.
+// A directive with at least one -out option is tracked: go generate
+// records the content of its outputs in the build cache and skips the
+// directive when its command line, the content of its inputs, and the
+// content of its outputs are all unchanged since it last ran.
+// The inputs should therefore include every file that affects the
+// outputs, such as the source files of a generator built by "go run".
+// A tracked directive must produce at least one file matching its
+// -out patterns. Directives without -out options always run.
+//
 // Generate processes packages in the order given on the command line,
-// one at a time. If the command line lists .go files from a single directory,
+// one at a time, except that packages in which every directive is
+// tracked are considered independent and may be processed in parallel
+// with each other, up to the limit set by the -p build flag.
+// If the command line lists .go files from a single directory,
 // they are treated as a single package. Within a package, generate processes the
 // source files in a package in file name order, one at a time. Within
 // a source file, generate runs generators in the order they appear
@@ -657,7 +684,7 @@
 //
 // The generator is run in the package's source directory.
 //
-// Go generate accepts two specific flags:
+// Go generate accepts three specific flags:
 //
 //	-run=""
 //		if non-empty, specifies a regular expression to select
@@ -672,6 +699,18 @@
 //		expression. If a directive matches both the -run and
 //		the -skip arguments, it is skipped.
 //
+//	-check
+//		reports tracked directives whose output files are stale.
+//		Directives whose outputs are recorded as up to date in the
+//		build cache are not run. Other tracked directives are run,
+//		their outputs compared with the previous files, and the
+//		previous files restored. Only files matching the -out
+//		patterns are restored, so any other files a generator
+//		changes remain changed.
+//		Go generate prints the stale files of each directive and
+//		exits with a non-zero status if any are stale.
+//		Directives without -out options are not run.
+//
 // It also accepts the standard build flags including -v, -n, and -x.
 // The -v flag prints the names of packages and files as they are
 // processed.
@@ -990,6 +1029,50 @@
 // With the -find flag, the -deps, -test and -export commands cannot be
 // used.
 //
+// The -graph flag causes list to print the import graph of the named
+// packages and their dependencies instead of the packages themselves.
+// By default it prints one import per line, as the paths of the importing
+// and the imported package, followed by notes in parentheses: "test" for
+// an import only by test files, "if" and a build constraint for an import
+// only by files with build constraints, and "ignored" for an import only
+// by files that build constraints exclude from the build. For example:
+//
+//	example.com/m fmt
+//	example.com/m golang.org/x/sys/windows (if windows, ignored)
+//	example.com/m testing (test)
+//
+// The -graph=dot flag prints the graph in the DOT language of Graphviz
+// instead, and the -graph=json flag as a JSON object corresponding to
+// this Go struct:
+//
+//	type Graph struct {
+//	    Packages []struct {
+//	        ImportPath string
+//	        Module     string        // path of the package's module, if any
+//	        Standard   bool          // package is in the standard library
+//	        Root       bool          // package was named on the command line
+//	        Ignored    bool          // package is imported only by ignored files, and was not loaded
+//	        Error      *PackageError // error loading the package
+//	    }
+//	    Imports []struct {
+//	        From, To   string
+//	        Test       bool   // only test files of From import To
+//	        Ignored    bool   // only files excluded from the build import To
+//	        Constraint string // build constraint of the files importing To, if all have one
+//	    }
+//	}
+//
+// The graph includes the imports of files excluded from the build by
+// build constraints (other than "ignore"), but not the dependencies of the
+// packages that only those files import. With -test, it includes the
+// imports of the test files of the named packages, and their dependencies.
+// The -to=pattern flag limits the graph to the import paths that lead
+// from the named packages to the packages matching the pattern, such as
+// all the paths from package A to package B ('go list -graph -to=B A'),
+// or the packages that pull in package X ('go list -graph -to=X all').
+// The -graph flag cannot be used with -f, -json, -find, -compiled,
+// or -export.
+//
 // The -test flag causes list to report not only the named packages
 // but also their test binaries (for packages with tests), to convey to
 // source code analysis tools exactly how test binaries are constructed.
@@ -1149,6 +1232,10 @@
 //	edit        edit go.mod from tools or scripts
 //	graph       print module requirement graph
 //	init        initialize new module in current directory
+//	licenses    report the licenses of dependencies
+//	outdated    report available upgrades of dependencies
+//	proxy       serve the module cache as a module proxy
+//	sbom        print a software bill of materials for main packages
 //	tidy        add missing and remove unused modules
 //	vendor      make vendored copy of dependencies
 //	verify      verify dependencies have expected content
@@ -1390,6 +1477,216 @@
 //
 // See https://golang.org/ref/mod#go-mod-init for more about 'go mod init'.
 //
+// # Report the licenses of dependencies
+//
+// Usage:
+//
+//	go mod licenses [-json] [-m] [-policy=file] [packages]
+//
+// Licenses reports the licenses of the packages that the named packages
+// depend on, from the license files of the modules that provide them.
+// With no arguments, licenses applies to the packages matched by
+// "go list all". Packages in the standard library and in the main module
+// are not reported.
+//
+// Licenses finds the license files of each module, such as LICENSE,
+// LICENSE.md, COPYING, and LICENSE-MIT, in the module zip file in the
+// module cache, downloading it if needed, or in the directory of a module
+// replaced by a local directory. A license file applies to the packages
+// in its directory and its subdirectories. Licenses identifies each
+// license by its SPDX identifier, either from SPDX-License-Identifier
+// lines in the license file or by recognizing the text of common
+// licenses. A license file with a license that is not recognized is
+// reported as NOASSERTION, and a package with no license file as NONE.
+//
+// For each package, licenses prints the package path followed by the
+// identifiers of its licenses. The -m flag causes licenses to treat the
+// arguments as modules instead, as for 'go list -m', and to report the
+// licenses of each module in the build list, with its version. With -m
+// and no arguments, licenses reports every module in the build list
+// ("all").
+//
+// The -json flag causes licenses to print a JSON object for each package
+// or module instead, corresponding to these Go structs:
+//
+//	type Report struct {
+//	    Path      string    // package import path or module path
+//	    Module    string    // module path, for a package
+//	    Version   string    // module version
+//	    Licenses  []License // license files that apply
+//	    Error     string    // error finding licenses
+//	    Violation string    // violation of license policy
+//	}
+//
+//	type License struct {
+//	    Path string   // license file path, relative to the module root
+//	    IDs  []string // SPDX license identifiers
+//	}
+//
+// A license policy makes licenses report an error for each package or
+// module whose licenses it does not allow. Policy lines in go.mod have
+// the form
+//
+//	//go:license allow Apache-2.0 BSD-3-Clause MIT
+//	//go:license deny AGPL-3.0
+//
+// An allow line lists licenses that are allowed. If there are any allow
+// lines, all other licenses are denied, and so is having no license file.
+// A deny line lists licenses that are denied. The licenses of every
+// license file that applies to a package must be allowed. When a license
+// file gives an SPDX license expression, such as "MIT OR Apache-2.0",
+// only one of the licenses joined by OR need be allowed, while all of
+// those joined by AND must be. The -policy flag names a policy file to
+// use in addition to go.mod, with the same lines without the
+// "//go:license" prefix. Lines in a policy file beginning with # are
+// comments.
+//
+// # Report available upgrades of dependencies
+//
+// Usage:
+//
+//	go mod outdated [-json] [-preview] [-x] [modules]
+//
+// Outdated reports the modules in the build list of the main module that
+// have newer versions available. The arguments are module patterns, as for
+// 'go list -m'; with no arguments, outdated applies to all modules in the
+// build list ("all").
+//
+// For each module, outdated prints the module path and selected version,
+// followed by "// indirect" if the module is not a direct dependency of the
+// main module, and then one line for each kind of upgrade available:
+//
+//	patch: the latest patch release with the same major and minor version
+//	minor: the latest version with the same module path, as reported by
+//	       'go list -m -u'
+//	major: the latest version of a later major version of the module,
+//	       which has a different module path, such as example.com/m/v2
+//
+// A module is not reported if none of these is newer than its selected
+// version. Modules replaced by local directories are never reported.
+//
+// The -preview flag causes outdated to also print, for each upgrade, the
+// changes to the build list that 'go get' would make to apply it: the
+// modules that would be upgraded or added, including the go and toolchain
+// versions, with their old and new versions. The preview edits the
+// requirements of the main module as 'go get' would, without writing them,
+// and compares the resulting module graph, as listed by 'go list -m all',
+// so it may include modules that are not needed to build the packages of
+// the main module.
+//
+// The -json flag causes outdated to print a JSON object for each module
+// instead, corresponding to these Go structs:
+//
+//	type Module struct {
+//	    Path     string   // module path
+//	    Version  string   // selected version
+//	    Indirect bool     // module is only indirectly needed by main module
+//	    Patch    *Upgrade // latest patch release, if newer
+//	    Minor    *Upgrade // latest version with the same path, if newer
+//	    Major    *Upgrade // latest version of a later major version, if any
+//	    Error    string   // error looking up upgrades
+//	}
+//
+//	type Upgrade struct {
+//	    Path    string     // module path
+//	    Version string     // module version
+//	    Time    *time.Time // time version was created
+//	    Changes []Change   // changes to the build list (with -preview)
+//	}
+//
+//	type Change struct {
+//	    Path string // module path
+//	    Old  string // selected version, or "none" if module is added
+//	    New  string // version after the upgrade
+//	}
+//
+// The -x flag causes outdated to print the commands it executes.
+//
+// Outdated does not modify go.mod or go.sum, and it cannot be used in
+// workspace mode.
+//
+// # Serve the module cache as a module proxy
+//
+// Proxy provides access to the module cache as a module proxy,
+// for use by other go commands through GOPROXY.
+//
+// Usage:
+//
+//	go mod proxy <command> [arguments]
+//
+// The commands are:
+//
+//	serve       serve the module cache over HTTP
+//
+// Use "go help mod proxy <command>" for more information about a command.
+//
+// # Serve the module cache over HTTP
+//
+// Usage:
+//
+//	go mod proxy serve [-addr=host:port]
+//
+// Serve serves the modules in the module cache ($GOMODCACHE/cache/download)
+// over HTTP using the module proxy protocol, so that go commands on other
+// machines, such as builders without network access, can download them
+// by setting GOPROXY to the server's URL. See 'go help goproxy' for
+// details of the protocol.
+//
+// Serve answers requests for the list of versions of a module, for the
+// .info, .mod, and .zip files of a version, and for the latest version of
+// a module, all from files already in the module cache. It never
+// downloads modules: to serve a module, first download it into the cache,
+// for example with 'go mod download'. The version list includes each
+// version whose go.mod file is cached, as the go command needs to load the
+// go.mod files of versions whose code it never downloads, but omits
+// pseudo-versions, as a proxy should. The latest version of a module is
+// its latest cached release, or if there is none, its latest cached
+// pre-release, or its latest cached pseudo-version.
+//
+// Serve also proxies the checksum databases that the go command has
+// consulted, as described in 'go help module-auth', serving the lookups
+// and tiles cached in $GOMODCACHE/cache/download/sumdb and the latest
+// signed tree heads the go command has seen. A go command using the
+// server as its proxy can then verify the modules it downloads without
+// access to the checksum database, provided the cache holds the lookups
+// it needs.
+//
+// The -addr flag sets the address to listen on. The default is
+// localhost:8080; to serve other machines, use an address such as
+// -addr=:8080.
+//
+// Serve runs until it is interrupted. The server itself is the modproxy
+// tool of the Go distribution, which serve runs with 'go tool modproxy',
+// as the go command does not contain an HTTP server.
+//
+// # Print a software bill of materials for main packages
+//
+// Usage:
+//
+//	go mod sbom [-format format] [build flags] [packages]
+//
+// SBOM prints a software bill of materials (SBOM) for each named main
+// package, describing the program that 'go build' would build from it
+// with the same build flags.
+//
+// The -format flag selects the format of the SBOM: spdx, the default,
+// for SPDX 2.3 JSON, or cyclonedx for CycloneDX 1.5 JSON. When more than
+// one package is named, the SBOMs are printed one after another.
+//
+// An SBOM lists the main package, the standard library of the Go toolchain,
+// and each module that provides packages for the program, with its
+// version, its package URL (purl), and its hash from go.sum. The go.sum
+// hash is recorded as an annotation (SPDX) or property (CycloneDX) in its
+// "h1:" form, since it is not a checksum of the module zip file.
+// Replaced modules are listed with the version of their replacement.
+// The SBOM also records the build settings of the program, including
+// version control information, as reported by 'go version -m'.
+//
+// To print an SBOM for a program that has already been built, use
+// 'go version -m -sbom'.
+//
+// For more about build flags, see 'go help build'.
+//
 // # Add missing and remove unused modules
 //
 // Usage:
@@ -1434,7 +1731,7 @@
 //
 // Usage:
 //
-//	go mod vendor [-e] [-v] [-o outdir]
+//	go mod vendor [-e] [-v] [-o outdir] [-tools]
 //
 // Vendor resets the main module's vendor directory to include all packages
 // needed to build and test all the main module's packages.
@@ -1451,6 +1748,17 @@
 // named "vendor" within the module root directory, so this flag is
 // primarily useful for other tools.
 //
+// The -tools flag causes vendor to also build the tools declared by
+// tool directives in go.mod, and to store their binaries in vendor/tools.
+// When run from a module with a vendor directory, 'go tool' runs a stored
+// binary instead of building the tool, provided that the binary was built
+// by the same toolchain, for the same platform and build configuration,
+// from the same sources, and that its content matches the SHA-256 hash
+// recorded for it in vendor/modules.txt. The stored binaries, and the tools that 'go tool'
+// builds in such a module, are built with -trimpath, so that they do not
+// depend on the location of the module. The -tools flag cannot be used
+// with -o.
+//
 // See https://golang.org/ref/mod#go-mod-vendor for more about 'go mod vendor'.
 //
 // # Verify dependencies have expected content
@@ -1737,7 +2045,7 @@
 //
 // Usage:
 //
-//	go work vendor [-e] [-v] [-o outdir]
+//	go work vendor [-e] [-v] [-o outdir] [-tools]
 //
 // Vendor resets the workspace's vendor directory to include all packages
 // needed to build and test all the workspace's packages.
@@ -1754,6 +2062,12 @@
 // named "vendor" within the module root directory, so this flag is
 // primarily useful for other tools.
 //
+// The -tools flag causes vendor to also build the tools declared by
+// tool directives in the workspace's modules, and to store their binaries
+// in vendor/tools for 'go tool' to run without building them.
+// See 'go help mod vendor' for details. The -tools flag cannot be used
+// with -o.
+//
 // # Compile and run Go program
 //
 // Usage:
@@ -1919,7 +2233,7 @@
 // test binary and the flags on the command line come entirely from a
 // restricted set of 'cacheable' test flags, defined as -benchtime,
 // -coverprofile, -cpu, -failfast, -fullpath, -list, -outputdir, -parallel,
-// -run, -short, -skip, -timeout and -v.
+// -run, -shard, -short, -skip, -testtimeout, -timeout and -v.
 // If a run of go test has any test or non-test flags outside this set,
 // the result is not cached. To disable test caching, use any test flag
 // or argument other than the cacheable flags. The idiomatic way to disable
@@ -1947,6 +2261,14 @@
 //	    Run the test binary using xprog. The behavior is the same as
 //	    in 'go run'. See 'go help run' for details.
 //
+//	-format format
+//	    Convert test output to a report of the tests in the given format:
+//	    junit for JUnit XML, or tap for TAP version 14. Like -json, this
+//	    implies -v. The JUnit report is written once all tests have run;
+//	    the TAP report is written one package at a time.
+//	    See 'go doc test2json' for the details of these formats.
+//	    Build output is not converted. Cannot be used with -json.
+//
 //	-json
 //	    Convert test output to JSON suitable for automated processing.
 //	    See 'go doc test2json' for the encoding details.
@@ -1958,6 +2280,11 @@
 //	    If file ends in a slash or names an existing directory,
 //	    the test is written to pkg.test in that directory.
 //
+//	-shardpackages
+//	    Make -shard split the packages to test between shards, rather than
+//	    the tests within each package. Each package is tested in full
+//	    in exactly one shard.
+//
 // The test binary also accepts flags that control execution of the test; these
 // flags are also accessible by 'go test'. See 'go help testflag' for details.
 //
@@ -1982,6 +2309,12 @@
 // The -n flag causes tool to print the command that would be
 // executed but not execute it.
 //
+// In a module with a vendor directory, 'go tool' runs the prebuilt tool
+// binaries stored by 'go mod vendor -tools' instead of building the tools,
+// as long as they match the current toolchain, platform, build
+// configuration, and sources, and the hashes recorded for them in
+// vendor/modules.txt. See 'go help mod vendor'.
+//
 // The -modfile=file.mod build flag causes tool to use an alternate file
 // instead of the go.mod in the module root directory.
 //
@@ -1995,7 +2328,7 @@
 //
 // Usage:
 //
-//	go version [-m] [-v] [-json] [file ...]
+//	go version [-m] [-v] [-json] [-sbom[=format]] [file ...]
 //
 // Version prints the build information for Go binary files.
 //
@@ -2017,6 +2350,12 @@
 // The -json flag is similar to -m but outputs the runtime/debug.BuildInfo in JSON format.
 // If flag -json is specified without -m, go version reports an error.
 //
+// The -sbom flag is similar to -json but outputs a software bill of materials
+// (SBOM) derived from the build information: SPDX 2.3 JSON by default or
+// with -sbom=spdx, and CycloneDX 1.5 JSON with -sbom=cyclonedx.
+// If flag -sbom is specified without -m, go version reports an error.
+// See 'go help mod sbom' for the contents of the SBOM.
+//
 // See also: go doc runtime/debug.BuildInfo.
 //
 // # Report likely mistakes in packages
@@ -2349,6 +2688,20 @@
 // Running 'go clean -fuzzcache' removes all cached fuzzing values.
 // This may make fuzzing less effective, temporarily.
 //
+// Setting the GOCACHEURL environment variable to the URL of a remote
+// cache server lets machines share build outputs. The go command looks
+// up outputs missing from GOCACHE on the server, and stores the outputs
+// it builds both in GOCACHE and on the server. The server must implement
+// the HTTP caching protocol used by Bazel (https://bazel.build/remote/caching):
+// an output whose SHA-256 hash is H is stored at $GOCACHEURL/cas/H,
+// and the cache entry for an action is stored at $GOCACHEURL/ac/ followed
+// by the action's ID. If GOCACHEURL begins with "readonly+", as in
+// GOCACHEURL=readonly+https://cache.example.com/go, the go command
+// only reads from the server. Requests to an HTTPS server use GOAUTH
+// credentials (see 'go help goauth'). If a request to the server fails,
+// the go command prints a warning and continues with only GOCACHE.
+// GOCACHEURL cannot be used together with GOCACHEPROG.
+//
 // The GODEBUG environment variable can enable printing of debugging
 // information about the state of the cache:
 //
@@ -2400,6 +2753,10 @@
 //		A command (with optional space-separated flags) that implements an
 //		external go command build cache.
 //		See 'go doc cmd/go/internal/cacheprog'.
+//	GOCACHEURL
+//		The URL of a remote build cache server that the go command
+//		shares build outputs through, in addition to GOCACHE.
+//		See 'go help cache'.
 //	GODEBUG
 //		Enable various debugging facilities for programs built with Go,
 //		including the go command. Cannot be set using 'go env -w'.
@@ -3343,19 +3700,23 @@
 //	    Show full file names in the error messages.
 //
 //	-fuzz regexp
-//	    Run the fuzz test matching the regular expression. When specified,
-//	    the command line argument must match exactly one package within the
-//	    main module, and regexp must match exactly one fuzz test within
-//	    that package. Fuzzing will occur after tests, benchmarks, seed corpora
-//	    of other fuzz tests, and examples have completed. See the Fuzzing
-//	    section of the testing package documentation for details.
+//	    Run the fuzz tests matching the regular expression. When specified,
+//	    the command line arguments must match only packages within the
+//	    main module. Fuzzing will occur after tests, benchmarks, seed corpora
+//	    of other fuzz tests, and examples have completed. If regexp matches
+//	    more than one fuzz test in a package, those fuzz tests are fuzzed
+//	    concurrently, sharing the worker processes allowed by -parallel.
+//	    Packages are fuzzed one at a time. See the Fuzzing section of the
+//	    testing package documentation for details.
 //
 //	-fuzztime t
 //	    Run enough iterations of the fuzz target during fuzzing to take t,
 //	    specified as a time.Duration (for example, -fuzztime 1h30s).
 //		The default is to run forever.
+//	    When more than one fuzz test is fuzzed, t is the total time to
+//	    spend fuzzing, split evenly between the matching fuzz tests.
 //	    The special syntax Nx means to run the fuzz target N times
-//	    (for example, -fuzztime 1000x).
+//	    (for example, -fuzztime 1000x). This applies to each fuzz test.
 //
 //	-fuzzminimizetime t
 //	    Run enough iterations of the fuzz target during each minimization
@@ -3365,6 +3726,19 @@
 //	    The special syntax Nx means to run the fuzz target N times
 //	    (for example, -fuzzminimizetime 100x).
 //
+//	-fuzzmerge list
+//	    Instead of fuzzing, add entries from other corpora to the seed
+//	    corpus of each fuzz test matched by -fuzz. The list is a
+//	    comma-separated list of directories that, like testdata/fuzz, hold
+//	    the corpus of each fuzz test in a subdirectory named after it.
+//	    The special name "cache" refers to the corpus generated by fuzzing
+//	    and stored in the build cache. Each entry is run once, and entries
+//	    are added, smallest first, only if they reach code not reached by
+//	    the seed corpus or by entries added before them. Added entries are
+//	    minimized as for -fuzzminimizetime, preserving the new coverage,
+//	    and written to testdata/fuzz. Existing seed corpus entries are not
+//	    changed.
+//
 //	-json
 //	    Log verbose output and test results in JSON. This presents the
 //	    same information as the -v flag in a machine-readable format.
@@ -3395,6 +3769,19 @@
 //	    in parallel as well, according to the setting of the -p flag
 //	    (see 'go help build').
 //
+//	-retry n
+//	    Run each failing test up to n more times, each time with a fresh
+//	    *testing.T, until it passes. A test that fails and then passes
+//	    when retried is reported as flaky rather than as passing or failing,
+//	    and does not cause the package's tests to fail. Only top-level
+//	    tests are retried, together with all of their subtests.
+//	    A test can also mark itself as known to be flaky by calling
+//	    t.Flaky, in which case it is retried at least once if it fails.
+//	    With -json, each failed attempt is reported by a "retry" event,
+//	    and the final result of a flaky test by a "flaky" event.
+//	    Examples, benchmarks, and fuzz tests are not retried.
+//	    The output of flaky tests is printed even without -v.
+//
 //	-run regexp
 //	    Run only those tests, examples, and fuzz tests matching the regular
 //	    expression. For tests, the regular expression is split by unbracketed
@@ -3406,6 +3793,16 @@
 //	    because it must run them to look for those sub-tests.
 //	    See also -skip.
 //
+//	-shard i/n
+//	    Split the top-level tests, examples, and fuzz tests of each package
+//	    into n shards, and run only those in shard i, for i from 1 to n.
+//	    Running every shard, for example on n different machines, runs
+//	    each test exactly once. A test's shard depends only on its name,
+//	    so it does not change as other tests are added or removed, and
+//	    -run and -skip select tests within the shard. Benchmarks and
+//	    fuzzing are not affected. See also -shardpackages.
+//	    With -json, each event records the shard.
+//
 //	-short
 //	    Tell long-running tests to shorten their run time.
 //	    It is off by default but set during all.bash so that installing
@@ -3427,11 +3824,23 @@
 //	    part of a test's identifier must match the corresponding element in
 //	    the sequence, if any.
 //
+//	-testtimeout d
+//	    If a top-level test runs longer than duration d, fail it,
+//	    print the stack traces of its goroutines, and cancel its context.
+//	    Unlike -timeout, the other tests continue to run. A test can set
+//	    its own timeout with t.SetTimeout. If d is 0, the default, there
+//	    is no per-test timeout.
+//
 //	-timeout d
 //	    If a test binary runs longer than duration d, panic.
 //	    If d is 0, the timeout is disabled.
 //	    The default is 10 minutes (10m).
 //
+//	-update-golden
+//	    Write the golden files of tests, instead of comparing them with
+//	    the output of the tests. See the Golden method of testing.T.
+//	    Test results are never cached with -update-golden.
+//
 //	-v
 //	    Verbose output: log all tests as they are run. Also print all
 //	    text from Log and Logf calls even if the test succeeds.
@@ -16,7 +16,7 @@ var Interrupted = make(chan struct{})
 // processSignals setups signal handler.
 func processSignals() {
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, signalsToIgnore...)
+	signal.Notify(sig, SignalsToIgnore...)
 	go func() {
 		<-sig
 		close(Interrupted)
@@ -10,7 +10,9 @@ import (
 	"os"
 )
 
-var signalsToIgnore = []os.Signal{os.Interrupt}
+// SignalsToIgnore are the signals that interrupt the go command.
+// Commands that run a long-lived child process pass them on to it.
+var SignalsToIgnore = []os.Signal{os.Interrupt}
 
 // SignalTrace is the signal to send to make a Go program
 // crash with a stack trace (no such signal in this case).
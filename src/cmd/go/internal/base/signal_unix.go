@@ -11,7 +11,9 @@ import (
 	"syscall"
 )
 
-var signalsToIgnore = []os.Signal{os.Interrupt, syscall.SIGQUIT}
+// SignalsToIgnore are the signals that interrupt the go command.
+// Commands that run a long-lived child process pass them on to it.
+var SignalsToIgnore = []os.Signal{os.Interrupt, syscall.SIGQUIT}
 
 // SignalTrace is the signal to send to make a Go program
 // crash with a stack trace.
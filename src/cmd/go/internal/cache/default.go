@@ -55,8 +55,14 @@ func initDefaultCache() Cache {
 	}
 
 	if cfg.GOCACHEPROG != "" {
+		if cfg.GOCACHEURL != "" {
+			base.Fatalf("GOCACHEPROG and GOCACHEURL cannot both be set")
+		}
 		return startCacheProg(cfg.GOCACHEPROG, diskCache)
 	}
+	if cfg.GOCACHEURL != "" {
+		return startHTTPCache(cfg.GOCACHEURL, diskCache)
+	}
 
 	return diskCache
 }
@@ -27,6 +27,7 @@ type Hash struct {
 	h    hash.Hash
 	name string        // for debugging
 	buf  *bytes.Buffer // for verify
+	rec  *bytes.Buffer // for Record
 }
 
 // hashSalt is a salt string added to the beginning of every hash
@@ -99,9 +100,29 @@ func (h *Hash) Write(b []byte) (int, error) {
 	if h.buf != nil {
 		h.buf.Write(b)
 	}
+	if h.rec != nil {
+		h.rec.Write(b)
+	}
 	return h.h.Write(b)
 }
 
+// Record causes h to keep a copy of the data written to it
+// from now on, which Recorded returns.
+func (h *Hash) Record() {
+	if h.rec == nil {
+		h.rec = new(bytes.Buffer)
+	}
+}
+
+// Recorded returns the data written to h since the call to Record,
+// or nil if Record has not been called.
+func (h *Hash) Recorded() []byte {
+	if h.rec == nil {
+		return nil
+	}
+	return h.rec.Bytes()
+}
+
 // Sum returns the hash of the data written previously.
 func (h *Hash) Sum() [HashSize]byte {
 	var out [HashSize]byte
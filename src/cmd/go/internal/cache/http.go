@@ -0,0 +1,415 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/web"
+)
+
+// HTTPCache implements Cache on top of a remote HTTP cache server,
+// as configured by GOCACHEURL, keeping a copy of every entry it gets
+// or puts in a local DiskCache.
+//
+// The server protocol is that of Bazel's HTTP remote cache
+// (https://bazel.build/remote/caching#http-caching):
+// the go command gets and puts an output with output ID o at
+// $GOCACHEURL/cas/<o in hex>, and the entry for action ID a at
+// $GOCACHEURL/ac/<a in hex>. An action cache entry is an encoded
+// build.bazel.remote.execution.v2.ActionResult message
+// listing the action's output as its single output file.
+//
+// If a request to the server fails, HTTPCache prints a warning
+// and continues with only the local cache.
+type HTTPCache struct {
+	local    *DiskCache
+	url      *url.URL
+	readOnly bool // do not put entries to the server
+
+	failed atomic.Bool    // a request to the server failed
+	sem    chan struct{}  // limits concurrent uploads
+	wg     sync.WaitGroup // outstanding uploads
+
+	mu   sync.Mutex
+	sent map[OutputID]bool // outputs already put to the server
+}
+
+// maxHTTPCacheUploads is the maximum number of concurrent uploads
+// by an HTTPCache.
+const maxHTTPCacheUploads = 8
+
+// maxActionResultSize is the maximum size of an action cache entry
+// that HTTPCache reads from the server.
+const maxActionResultSize = 64 << 10
+
+// NewHTTPCache returns an HTTPCache for the remote cache at the given
+// URL, storing entries locally in local. The URL is a GOCACHEURL setting:
+// if it has the prefix "readonly+", the cache only gets entries from the
+// server and never puts any.
+func NewHTTPCache(rawURL string, local *DiskCache) (*HTTPCache, error) {
+	u, readOnly := strings.CutPrefix(rawURL, "readonly+")
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported scheme %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return nil, errors.New("missing host")
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	parsed.RawPath = ""
+	return &HTTPCache{
+		local:    local,
+		url:      parsed,
+		readOnly: readOnly,
+		sem:      make(chan struct{}, maxHTTPCacheUploads),
+		sent:     make(map[OutputID]bool),
+	}, nil
+}
+
+// fail records that a request to the server failed with err,
+// warning about it the first time.
+func (c *HTTPCache) fail(err error) {
+	if c.failed.CompareAndSwap(false, true) {
+		fmt.Fprintf(os.Stderr, "go: GOCACHEURL: %v\ngo: using only the local build cache\n", err)
+	}
+}
+
+// endpoint returns the URL of the object with the given ID in the
+// given part ("ac" or "cas") of the remote cache.
+func (c *HTTPCache) endpoint(part string, id [HashSize]byte) *url.URL {
+	u := *c.url
+	u.Path += "/" + part + "/" + hex.EncodeToString(id[:])
+	return &u
+}
+
+func (c *HTTPCache) Get(id ActionID) (Entry, error) {
+	e, err := c.local.Get(id)
+	if err == nil || verify || c.failed.Load() {
+		return e, err
+	}
+	if err := c.fetch(id); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.fail(err)
+		}
+		return Entry{}, &entryNotFoundError{Err: err}
+	}
+	return c.local.Get(id)
+}
+
+// fetch gets the entry for id from the server and stores it in the local cache.
+func (c *HTTPCache) fetch(id ActionID) error {
+	u := c.endpoint("ac", id)
+	body, err := c.get(u)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxActionResultSize))
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", u.Redacted(), err)
+	}
+	if len(data) >= maxActionResultSize {
+		return fmt.Errorf("reading %s: response too large", u.Redacted())
+	}
+	out, size, err := decodeActionResult(data)
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", u.Redacted(), err)
+	}
+
+	if info, err := os.Stat(c.local.fileName(out, "d")); err != nil || info.Size() != size {
+		if err := c.fetchOutput(out, size); err != nil {
+			return err
+		}
+	}
+	return c.local.putIndexEntry(id, out, size, false)
+}
+
+// fetchOutput gets the output out, of the given size, from the server and
+// stores it in the local cache. The output is streamed through a temporary
+// file, since it may be too large to hold in memory.
+func (c *HTTPCache) fetchOutput(out OutputID, size int64) error {
+	u := c.endpoint("cas", out)
+	body, err := c.get(u)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp("", "go-cacheurl-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(body, size+1))
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", u.Redacted(), err)
+	}
+	var sum OutputID
+	h.Sum(sum[:0])
+	if n != size || sum != out {
+		return fmt.Errorf("reading %s: content does not match its digest", u.Redacted())
+	}
+	return c.local.copyFile(f, "", out, size, 0o666)
+}
+
+// get returns the body of the resource at u, which the caller must close.
+func (c *HTTPCache) get(u *url.URL) (io.ReadCloser, error) {
+	resp, err := web.Get(web.DefaultSecurity, u)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *HTTPCache) Put(id ActionID, file io.ReadSeeker) (_ OutputID, size int64, _ error) {
+	out, size, err := c.local.Put(id, file)
+	if err != nil || c.readOnly || c.failed.Load() {
+		return out, size, err
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.sem <- struct{}{}
+		defer func() { <-c.sem }()
+		if c.failed.Load() {
+			return
+		}
+		if err := c.upload(id, out, size); err != nil {
+			c.fail(err)
+		}
+	}()
+	return out, size, nil
+}
+
+// upload puts the output out and the entry for id to the server.
+func (c *HTTPCache) upload(id ActionID, out OutputID, size int64) error {
+	c.mu.Lock()
+	sent := c.sent[out]
+	c.mu.Unlock()
+	if !sent {
+		if err := c.uploadOutput(out, size); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.sent[out] = true
+		c.mu.Unlock()
+	}
+	return c.put(c.endpoint("ac", id), bytes.NewReader(encodeActionResult(out, size)))
+}
+
+// uploadOutput puts the output out, of the given size, to the server,
+// streaming it from the local cache.
+func (c *HTTPCache) uploadOutput(out OutputID, size int64) error {
+	f, err := os.Open(c.local.fileName(out, "d"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("cache entry %x changed size", out)
+	}
+	return c.put(c.endpoint("cas", out), f)
+}
+
+// put puts body to the server at u.
+func (c *HTTPCache) put(u *url.URL, body io.ReadSeeker) error {
+	resp, err := web.Do(web.DefaultSecurity, "PUT", u, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("writing %s: %v", u.Redacted(), resp.Status)
+	}
+	return nil
+}
+
+func (c *HTTPCache) Close() error {
+	c.wg.Wait()
+	return c.local.Close()
+}
+
+func (c *HTTPCache) OutputFile(o OutputID) string {
+	return c.local.OutputFile(o)
+}
+
+func (c *HTTPCache) FuzzDir() string {
+	return c.local.FuzzDir()
+}
+
+// startHTTPCache returns an HTTPCache for GOCACHEURL, or exits on error.
+func startHTTPCache(rawURL string, local *DiskCache) Cache {
+	c, err := NewHTTPCache(rawURL, local)
+	if err != nil {
+		base.Fatalf("invalid GOCACHEURL %q: %v", rawURL, err)
+	}
+	return c
+}
+
+// The action cache entries on the server are ActionResult protocol buffer
+// messages from the Bazel remote execution API:
+//
+//	message ActionResult {
+//		repeated OutputFile output_files = 2;
+//		...
+//	}
+//	message OutputFile {
+//		string path = 1;
+//		Digest digest = 2;
+//		...
+//	}
+//	message Digest {
+//		string hash = 1;       // lowercase hex SHA-256
+//		int64 size_bytes = 2;
+//	}
+//
+// See https://github.com/bazelbuild/remote-apis.
+
+// actionResultPath is the path of the single output file
+// in the action results written by HTTPCache.
+const actionResultPath = "output"
+
+// encodeActionResult returns the encoding of an ActionResult
+// whose only output file is out, with the given size.
+func encodeActionResult(out OutputID, size int64) []byte {
+	var digest []byte
+	digest = appendProtoBytes(digest, 1, []byte(hex.EncodeToString(out[:])))
+	digest = appendProtoVarint(digest, 2, uint64(size))
+
+	var file []byte
+	file = appendProtoBytes(file, 1, []byte(actionResultPath))
+	file = appendProtoBytes(file, 2, digest)
+
+	return appendProtoBytes(nil, 2, file)
+}
+
+// decodeActionResult returns the output ID and size of the
+// only output file of the encoded ActionResult data.
+func decodeActionResult(data []byte) (out OutputID, size int64, err error) {
+	errBad := errors.New("malformed action cache entry")
+	var files [][]byte
+	if err := walkProto(data, func(num int, v uint64, b []byte) {
+		if num == 2 && b != nil {
+			files = append(files, b)
+		}
+	}); err != nil {
+		return OutputID{}, 0, errBad
+	}
+	if len(files) != 1 {
+		return OutputID{}, 0, fmt.Errorf("action cache entry has %d output files, want 1", len(files))
+	}
+
+	var digest []byte
+	if err := walkProto(files[0], func(num int, v uint64, b []byte) {
+		if num == 2 && b != nil {
+			digest = b
+		}
+	}); err != nil || digest == nil {
+		return OutputID{}, 0, errBad
+	}
+	var hash []byte
+	if err := walkProto(digest, func(num int, v uint64, b []byte) {
+		switch {
+		case num == 1 && b != nil:
+			hash = b
+		case num == 2 && b == nil:
+			size = int64(v)
+		}
+	}); err != nil || size < 0 {
+		return OutputID{}, 0, errBad
+	}
+	if n, err := hex.Decode(out[:], hash); err != nil || n != HashSize || len(hash) != 2*HashSize {
+		return OutputID{}, 0, errBad
+	}
+	return out, size, nil
+}
+
+// appendProtoBytes appends the encoding of a length-delimited protocol
+// buffer field with the given number and value to b.
+func appendProtoBytes(b []byte, num int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendProtoVarint appends the encoding of a varint protocol buffer field
+// with the given number and value to b.
+func appendProtoVarint(b []byte, num int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+// walkProto calls f for each field in the encoded protocol buffer message
+// data, passing the field number and either its value, for a varint field,
+// or its contents, for a length-delimited field. It skips fixed-size fields.
+func walkProto(data []byte, f func(num int, v uint64, b []byte)) error {
+	errBad := errors.New("malformed protocol buffer")
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > 1<<29 {
+			return errBad
+		}
+		data = data[n:]
+		num := int(tag >> 3)
+		switch tag & 7 {
+		case 0: // varint
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return errBad
+			}
+			data = data[n:]
+			f(num, v, nil)
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return errBad
+			}
+			data = data[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return errBad
+			}
+			f(num, 0, data[n:n+int(l)])
+			data = data[n+int(l):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return errBad
+			}
+			data = data[4:]
+		default:
+			return errBad
+		}
+	}
+	return nil
+}
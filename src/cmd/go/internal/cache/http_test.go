@@ -0,0 +1,201 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A fakeRemoteCache is an in-memory HTTP cache server
+// implementing the Bazel HTTP remote cache protocol.
+type fakeRemoteCache struct {
+	mu   sync.Mutex
+	objs map[string][]byte
+	puts int
+}
+
+func (s *fakeRemoteCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.TrimPrefix(r.URL.Path, "/prefix")
+	if !strings.HasPrefix(key, "/ac/") && !strings.HasPrefix(key, "/cas/") {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		data, ok := s.objs[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case "PUT":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objs[key] = data
+		s.puts++
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func newHTTPCache(t *testing.T, url string) *HTTPCache {
+	t.Helper()
+	local, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewHTTPCache(url, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestHTTPCache(t *testing.T) {
+	remote := &fakeRemoteCache{objs: make(map[string][]byte)}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+
+	// Put an entry through one cache.
+	c1 := newHTTPCache(t, srv.URL+"/prefix/")
+	data := []byte("hello, world\n")
+	out, size, err := c1.Put(dummyID(1), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c1.failed.Load() {
+		t.Fatal("c1 failed")
+	}
+	if remote.puts != 2 {
+		t.Fatalf("server got %d puts, want 2", remote.puts)
+	}
+
+	// Get it through another cache with a different local directory.
+	c2 := newHTTPCache(t, "readonly+"+srv.URL+"/prefix")
+	got, entry, err := GetBytes(c2, dummyID(1))
+	if err != nil {
+		t.Fatalf("GetBytes from remote: %v", err)
+	}
+	if !bytes.Equal(got, data) || entry.OutputID != out || entry.Size != size {
+		t.Fatalf("GetBytes from remote = %q, %x, %d, want %q, %x, %d", got, entry.OutputID, entry.Size, data, out, size)
+	}
+	// The entry is now in the local cache.
+	if _, err := c2.local.Get(dummyID(1)); err != nil {
+		t.Fatalf("local Get after remote hit: %v", err)
+	}
+
+	// A read-only cache does not put entries to the server.
+	if err := PutBytes(c2, dummyID(2), []byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	c2.Close()
+	if remote.puts != 2 {
+		t.Fatalf("server got %d puts after read-only put, want 2", remote.puts)
+	}
+
+	// A miss on the server is a miss, not a failure.
+	if _, err := c2.Get(dummyID(3)); err == nil {
+		t.Fatal("Get of missing entry succeeded")
+	}
+	if c2.failed.Load() {
+		t.Fatal("miss marked cache as failed")
+	}
+
+	// Corrupt output data is rejected.
+	for k := range remote.objs {
+		if strings.HasPrefix(k, "/cas/") {
+			remote.objs[k] = []byte("goodbye, world\n")
+		}
+	}
+	c3 := newHTTPCache(t, srv.URL+"/prefix")
+	if _, err := c3.Get(dummyID(1)); err == nil {
+		t.Fatal("Get of corrupt entry succeeded")
+	}
+}
+
+func TestHTTPCacheUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newHTTPCache(t, srv.URL)
+	if _, err := c.Get(dummyID(1)); err == nil {
+		t.Fatal("Get succeeded")
+	}
+	if !c.failed.Load() {
+		t.Fatal("server error did not mark cache as failed")
+	}
+
+	// The local cache still works.
+	if err := PutBytes(c, dummyID(1), []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _, err := GetBytes(c, dummyID(1)); err != nil || string(data) != "data" {
+		t.Fatalf("GetBytes = %q, %v, want %q, nil", data, err, "data")
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestActionResult(t *testing.T) {
+	out := OutputID(dummyID(7))
+	for _, size := range []int64{0, 1, 1 << 40} {
+		gotOut, gotSize, err := decodeActionResult(encodeActionResult(out, size))
+		if err != nil || gotOut != out || gotSize != size {
+			t.Errorf("decodeActionResult(encodeActionResult(%x, %d)) = %x, %d, %v", out, size, gotOut, gotSize, err)
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"\x12",
+		"\x12\x05abc",
+		"\x12\x02\x0a\x00",
+	} {
+		if _, _, err := decodeActionResult([]byte(bad)); err == nil {
+			t.Errorf("decodeActionResult(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestNewHTTPCache(t *testing.T) {
+	for _, bad := range []string{
+		"cache.example.com",
+		"ftp://cache.example.com",
+		"https://",
+		"readonly+file:///cache",
+	} {
+		if _, err := NewHTTPCache(bad, nil); err == nil {
+			t.Errorf("NewHTTPCache(%q) succeeded", bad)
+		}
+	}
+	c, err := NewHTTPCache("readonly+https://cache.example.com/go/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.readOnly {
+		t.Error("readonly+ cache is not read-only")
+	}
+	if got, want := c.endpoint("ac", dummyID(1)).String(), "https://cache.example.com/go/ac/0100000000000000000000000000000000000000000000000000000000000000"; got != want {
+		t.Errorf("endpoint = %s, want %s", got, want)
+	}
+}
@@ -81,6 +81,7 @@ var (
 	BuildCover             bool                    // -cover flag
 	BuildCoverMode         string                  // -covermode flag
 	BuildCoverPkg          []string                // -coverpkg flag
+	BuildExplain           bool                    // -explain flag
 	BuildJSON              bool                    // -json flag
 	BuildN                 bool                    // -n flag
 	BuildO                 string                  // -o flag
@@ -462,6 +463,7 @@ var (
 
 	GOBIN                           = Getenv("GOBIN")
 	GOCACHEPROG, GOCACHEPROGChanged = EnvOrAndChanged("GOCACHEPROG", "")
+	GOCACHEURL, GOCACHEURLChanged   = EnvOrAndChanged("GOCACHEURL", "")
 	GOMODCACHE, GOMODCACHEChanged   = EnvOrAndChanged("GOMODCACHE", gopathDir("pkg/mod"))
 
 	// Used in envcmd.MkEnv and build ID computations.
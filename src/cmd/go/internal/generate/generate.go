@@ -21,6 +21,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/cfg"
@@ -33,7 +34,7 @@ import (
 
 var CmdGenerate = &base.Command{
 	Run:       runGenerate,
-	UsageLine: "go generate [-run regexp] [-n] [-v] [-x] [build flags] [file.go... | packages]",
+	UsageLine: "go generate [-run regexp] [-check] [-n] [-v] [-x] [build flags] [file.go... | packages]",
 	Short:     "generate Go files by processing source",
 	Long: `
 Generate runs commands described by directives within existing
@@ -123,8 +124,28 @@ For example,
 specifies that the command "foo" represents the generator
 "go tool foo".
 
+A directive may declare the files the generator reads and writes
+with options before the command:
+
+	//go:generate -in=api.yaml -in=templates/*.tmpl -out=api_gen.go go run ./internal/gen
+
+Each -in=pattern option names input files and each -out=pattern option
+names output files, using the syntax of path/filepath.Match on
+slash-separated paths relative to the package directory.
+A directive with at least one -out option is tracked: go generate
+records the content of its outputs in the build cache and skips the
+directive when its command line, the content of its inputs, and the
+content of its outputs are all unchanged since it last ran.
+The inputs should therefore include every file that affects the
+outputs, such as the source files of a generator built by "go run".
+A tracked directive must produce at least one file matching its
+-out patterns. Directives without -out options always run.
+
 Generate processes packages in the order given on the command line,
-one at a time. If the command line lists .go files from a single directory,
+one at a time, except that packages in which every directive is
+tracked are considered independent and may be processed in parallel
+with each other, up to the limit set by the -p build flag.
+If the command line lists .go files from a single directory,
 they are treated as a single package. Within a package, generate processes the
 source files in a package in file name order, one at a time. Within
 a source file, generate runs generators in the order they appear
@@ -140,7 +161,7 @@ all further processing for that package.
 
 The generator is run in the package's source directory.
 
-Go generate accepts two specific flags:
+Go generate accepts three specific flags:
 
 	-run=""
 		if non-empty, specifies a regular expression to select
@@ -155,6 +176,18 @@ Go generate accepts two specific flags:
 		expression. If a directive matches both the -run and
 		the -skip arguments, it is skipped.
 
+	-check
+		reports tracked directives whose output files are stale.
+		Directives whose outputs are recorded as up to date in the
+		build cache are not run. Other tracked directives are run,
+		their outputs compared with the previous files, and the
+		previous files restored. Only files matching the -out
+		patterns are restored, so any other files a generator
+		changes remain changed.
+		Go generate prints the stale files of each directive and
+		exits with a non-zero status if any are stale.
+		Directives without -out options are not run.
+
 It also accepts the standard build flags including -v, -n, and -x.
 The -v flag prints the names of packages and files as they are
 processed.
@@ -173,12 +206,15 @@ var (
 
 	generateSkipFlag string         // generate -skip flag
 	generateSkipRE   *regexp.Regexp // compiled expression for -skip
+
+	generateCheck bool // generate -check flag
 )
 
 func init() {
 	work.AddBuildFlags(CmdGenerate, work.OmitBuildOnlyFlags)
 	CmdGenerate.Flag.StringVar(&generateRunFlag, "run", "", "")
 	CmdGenerate.Flag.StringVar(&generateSkipFlag, "skip", "", "")
+	CmdGenerate.Flag.BoolVar(&generateCheck, "check", false, "")
 }
 
 func runGenerate(ctx context.Context, cmd *base.Command, args []string) {
@@ -202,6 +238,15 @@ func runGenerate(ctx context.Context, cmd *base.Command, args []string) {
 
 	cfg.BuildContext.BuildTags = append(cfg.BuildContext.BuildTags, "generate")
 
+	// Packages whose directives all declare their outputs run in
+	// parallel, buffering their output; any other package waits for
+	// all earlier packages and runs alone.
+	var (
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, cfg.BuildP)
+		outMu sync.Mutex // serializes writing buffered output
+	)
+
 	// Even if the arguments are .go files, this loop suffices.
 	printed := false
 	pkgOpts := load.PackageOpts{IgnoreImports: true}
@@ -221,23 +266,66 @@ func runGenerate(ctx context.Context, cmd *base.Command, args []string) {
 			base.Errorf("%v", pkg.Error)
 		}
 
-		for _, file := range pkg.InternalGoFiles() {
-			if !generate(file) {
-				break
-			}
+		if cfg.BuildP > 1 && independent(pkg) {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				var stdout, stderr bytes.Buffer
+				generatePackage(pkg, &stdout, &stderr)
+				outMu.Lock()
+				os.Stdout.Write(stdout.Bytes())
+				os.Stderr.Write(stderr.Bytes())
+				outMu.Unlock()
+			}()
+			continue
 		}
+		wg.Wait()
+		generatePackage(pkg, os.Stdout, os.Stderr)
+	}
+	wg.Wait()
 
-		for _, file := range pkg.InternalXGoFiles() {
-			if !generate(file) {
-				break
-			}
+	if c := generateCache(); c != nil {
+		if err := c.Close(); err != nil {
+			base.Errorf("%v", err)
 		}
 	}
 	base.ExitIfErrors()
 }
 
+// generatePackage runs the generation directives for the package pkg,
+// writing output to stdout and stderr.
+func generatePackage(pkg *load.Package, stdout, stderr io.Writer) {
+	for _, file := range pkg.InternalGoFiles() {
+		if !generate(pkg, file, stdout, stderr) {
+			break
+		}
+	}
+
+	for _, file := range pkg.InternalXGoFiles() {
+		if !generate(pkg, file, stdout, stderr) {
+			break
+		}
+	}
+}
+
+// independent reports whether every directive in pkg declares its
+// outputs, so that pkg can be processed in parallel with other packages.
+func independent(pkg *load.Package) bool {
+	for _, file := range pkg.InternalAllGoFiles() {
+		src, err := os.ReadFile(file)
+		if err != nil || !declaresOutputs(src) {
+			return false
+		}
+	}
+	return true
+}
+
 // generate runs the generation directives for a single file.
-func generate(absFile string) bool {
+func generate(pkg *load.Package, absFile string, stdout, stderr io.Writer) bool {
 	src, err := os.ReadFile(absFile)
 	if err != nil {
 		log.Fatalf("generate: %s", err)
@@ -251,10 +339,13 @@ func generate(absFile string) bool {
 	}
 
 	g := &Generator{
-		r:        bytes.NewReader(src),
-		path:     absFile,
-		pkg:      filePkg.Name.String(),
-		commands: make(map[string][]string),
+		r:          bytes.NewReader(src),
+		path:       absFile,
+		pkg:        filePkg.Name.String(),
+		importPath: pkg.ImportPath,
+		commands:   make(map[string][]string),
+		stdout:     stdout,
+		stderr:     stderr,
 	}
 	return g.run()
 }
@@ -270,6 +361,12 @@ type Generator struct {
 	commands map[string][]string
 	lineNum  int // current line number.
 	env      []string
+
+	importPath string    // import path of package.
+	inputs     []string  // -in patterns of current directive.
+	outputs    []string  // -out patterns of current directive.
+	stdout     io.Writer // standard output of generators.
+	stderr     io.Writer // standard error of generators and go generate.
 }
 
 // run runs the generators in the current file.
@@ -289,7 +386,7 @@ func (g *Generator) run() (ok bool) {
 	g.dir, g.file = filepath.Split(g.path)
 	g.dir = filepath.Clean(g.dir) // No final separator please.
 	if cfg.BuildV {
-		fmt.Fprintf(os.Stderr, "%s\n", base.ShortPath(g.path))
+		fmt.Fprintf(g.stderr, "%s\n", base.ShortPath(g.path))
 	}
 
 	// Scan for lines that start "//go:generate".
@@ -324,13 +421,7 @@ func (g *Generator) run() (ok bool) {
 			break
 		}
 
-		if !isGoGenerate(buf) {
-			continue
-		}
-		if generateRunFlag != "" && !generateRunRE.Match(bytes.TrimSpace(buf)) {
-			continue
-		}
-		if generateSkipFlag != "" && generateSkipRE.Match(bytes.TrimSpace(buf)) {
+		if !isGoGenerate(buf) || !selected(buf) {
 			continue
 		}
 
@@ -340,12 +431,29 @@ func (g *Generator) run() (ok bool) {
 			g.errorf("no arguments to directive")
 		}
 		if words[0] == "-command" {
+			if g.inputs != nil || g.outputs != nil {
+				g.errorf("-in and -out cannot be used with -command")
+			}
 			g.setShorthand(words)
 			continue
 		}
+		if g.outputs != nil {
+			g.runTracked(words)
+			continue
+		}
+		if g.inputs != nil {
+			g.errorf("-in requires -out")
+		}
+		if generateCheck {
+			// Only tracked directives can be checked.
+			if cfg.BuildV {
+				fmt.Fprintf(g.stderr, "%s:%d: not checking directive without -out\n", base.ShortPath(g.path), g.lineNum)
+			}
+			continue
+		}
 		// Run the command line.
 		if cfg.BuildN || cfg.BuildX {
-			fmt.Fprintf(os.Stderr, "%s\n", strings.Join(words, " "))
+			fmt.Fprintf(g.stderr, "%s\n", strings.Join(words, " "))
 		}
 		if cfg.BuildN {
 			continue
@@ -362,6 +470,18 @@ func isGoGenerate(buf []byte) bool {
 	return bytes.HasPrefix(buf, []byte("//go:generate ")) || bytes.HasPrefix(buf, []byte("//go:generate\t"))
 }
 
+// selected reports whether the directive line buf
+// is selected by the -run and -skip flags.
+func selected(buf []byte) bool {
+	if generateRunFlag != "" && !generateRunRE.Match(bytes.TrimSpace(buf)) {
+		return false
+	}
+	if generateSkipFlag != "" && generateSkipRE.Match(bytes.TrimSpace(buf)) {
+		return false
+	}
+	return true
+}
+
 // setEnv sets the extra environment variables used when executing a
 // single go:generate command.
 func (g *Generator) setEnv() {
@@ -381,10 +501,13 @@ func (g *Generator) setEnv() {
 
 // split breaks the line into words, evaluating quoted
 // strings and evaluating environment variables.
+// It removes any leading -in and -out options,
+// recording their patterns in g.inputs and g.outputs.
 // The initial //go:generate element is present in line.
 func (g *Generator) split(line string) []string {
 	// Parse line, obeying quoted strings.
 	var words []string
+	g.inputs, g.outputs = nil, nil
 	line = line[len("//go:generate ") : len(line)-1] // Drop preamble and final newline.
 	// There may still be a carriage return.
 	if len(line) > 0 && line[len(line)-1] == '\r' {
@@ -429,6 +552,17 @@ Words:
 		words = append(words, line[0:i])
 		line = line[i:]
 	}
+	// Remove input and output declarations.
+	for len(words) > 0 {
+		if pattern, ok := strings.CutPrefix(words[0], "-in="); ok {
+			g.inputs = append(g.inputs, os.Expand(pattern, g.expandVar))
+		} else if pattern, ok := strings.CutPrefix(words[0], "-out="); ok {
+			g.outputs = append(g.outputs, os.Expand(pattern, g.expandVar))
+		} else {
+			break
+		}
+		words = words[1:]
+	}
 	// Substitute command if required.
 	if len(words) > 0 && g.commands[words[0]] != nil {
 		// Replace 0th word by command substitution.
@@ -452,7 +586,7 @@ var stop = fmt.Errorf("error in generation")
 // It then exits the program (with exit status 1) because generation stops
 // at the first error.
 func (g *Generator) errorf(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "%s:%d: %s\n", base.ShortPath(g.path), g.lineNum,
+	fmt.Fprintf(g.stderr, "%s:%d: %s\n", base.ShortPath(g.path), g.lineNum,
 		fmt.Sprintf(format, args...))
 	panic(stop)
 }
@@ -500,8 +634,8 @@ func (g *Generator) exec(words []string) {
 	cmd.Args[0] = words[0] // Overwrite with the original in case it was rewritten above.
 
 	// Standard in and out of generator should be the usual.
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = g.stdout
+	cmd.Stderr = g.stderr
 	// Run the command in the package directory.
 	cmd.Dir = g.dir
 	cmd.Env = str.StringList(cfg.OrigEnv, g.env)
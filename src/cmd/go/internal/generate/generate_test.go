@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"testing"
 )
 
@@ -257,3 +258,53 @@ func TestGenerateCommandShortHand2(t *testing.T) {
 		}
 	}
 }
+
+var splitDeclTests = []struct {
+	in           string
+	out          []string
+	inputs, outs []string
+}{
+	{"x", []string{"x"}, nil, nil},
+	{"-out=x.go gen", []string{"gen"}, nil, []string{"x.go"}},
+	{"-in=a.txt -in=t/*.tmpl -out=${GOFILE}_gen.go gen -in=b", []string{"gen", "-in=b"}, []string{"a.txt", "t/*.tmpl"}, []string{"proc.go_gen.go"}},
+	{"-out=x.go yacc x.y", []string{"go", "tool", "yacc", "x.y"}, nil, []string{"x.go"}},
+	{"-in=a.txt", nil, []string{"a.txt"}, nil},
+}
+
+func TestGenerateDeclarations(t *testing.T) {
+	dir := filepath.Join(testenv.GOROOT(t), "src", "sys")
+	g := &Generator{
+		r:        nil, // Unused here.
+		path:     filepath.Join(dir, "proc.go"),
+		dir:      dir,
+		file:     "proc.go",
+		pkg:      "sys",
+		commands: make(map[string][]string),
+	}
+	g.setEnv()
+	g.setShorthand([]string{"-command", "yacc", "go", "tool", "yacc"})
+	for _, test := range splitDeclTests {
+		got := g.split("//go:generate " + test.in + "\n")
+		if !slices.Equal(got, test.out) || !slices.Equal(g.inputs, test.inputs) || !slices.Equal(g.outputs, test.outs) {
+			t.Errorf("split(%q): got %q, -in %q, -out %q; expected %q, -in %q, -out %q", test.in, got, g.inputs, g.outputs, test.out, test.inputs, test.outs)
+		}
+	}
+}
+
+func TestDeclaresOutputs(t *testing.T) {
+	for _, test := range []struct {
+		src  string
+		want bool
+	}{
+		{"package p\n", true},
+		{"package p\n//go:generate -out=x.go gen\n", true},
+		{"package p\n//go:generate -command gen go run ./gen\n//go:generate -in=x.in -out=x.go gen\n", true},
+		{"package p\n//go:generate -out=x.go gen\n//go:generate gen\n", false},
+		{"package p\n//go:generate -in=x.in gen\n", false},
+		{"package p\n//go:generate \"-out=x y.go\" gen\n", false},
+	} {
+		if got := declaresOutputs([]byte(test.src)); got != test.want {
+			t.Errorf("declaresOutputs(%q) = %v, want %v", test.src, got, test.want)
+		}
+	}
+}
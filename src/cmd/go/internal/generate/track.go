@@ -0,0 +1,257 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Incremental generation.
+//
+// A directive that declares its outputs with -out options is tracked.
+// Before running a tracked directive, go generate computes an action ID
+// from the package, the file, the expanded command line, the target
+// system, and the contents of the files matching the -in patterns.
+// After running it, go generate stores the names and content hashes of
+// the files matching the -out patterns in the build cache under that
+// action ID. A later run finding the same action ID in the cache, and
+// output files matching the recorded ones, skips the directive.
+
+package generate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cache"
+	"cmd/go/internal/cfg"
+)
+
+// generateCache returns the build cache,
+// or nil if the build cache is disabled.
+var generateCache = sync.OnceValue(func() cache.Cache {
+	if dir, _, _ := cache.DefaultDir(); dir == "off" {
+		return nil
+	}
+	return cache.Default()
+})
+
+// runTracked runs the tracked directive words,
+// unless its outputs are up to date.
+func (g *Generator) runTracked(words []string) {
+	c := generateCache()
+	var id cache.ActionID
+	if c != nil {
+		id = g.actionID(words)
+		if g.upToDate(c, id) {
+			if cfg.BuildV || cfg.BuildX {
+				fmt.Fprintf(g.stderr, "%s:%d: outputs up to date\n", base.ShortPath(g.path), g.lineNum)
+			}
+			return
+		}
+	}
+
+	if cfg.BuildN || cfg.BuildX {
+		fmt.Fprintf(g.stderr, "%s\n", strings.Join(words, " "))
+	}
+	if cfg.BuildN {
+		return
+	}
+	if generateCheck {
+		g.check(words, c, id)
+		return
+	}
+	g.exec(words)
+	g.record(words, c, id)
+}
+
+// check runs the tracked directive words, reports any of its output
+// files that the run changes, and then restores the output files
+// to their previous state.
+func (g *Generator) check(words []string, c cache.Cache, id cache.ActionID) {
+	before := g.matchFiles(g.outputs)
+	saved := make(map[string][]byte)
+	for _, name := range before {
+		data, err := os.ReadFile(filepath.Join(g.dir, name))
+		if err != nil {
+			g.errorf("%v", err)
+		}
+		saved[name] = data
+	}
+
+	var stale []string
+	func() {
+		defer g.restore(saved)
+		g.exec(words)
+		after := g.matchFiles(g.outputs)
+		for _, name := range after {
+			old, ok := saved[name]
+			data, err := os.ReadFile(filepath.Join(g.dir, name))
+			if !ok || err != nil || !bytes.Equal(data, old) {
+				stale = append(stale, name)
+			}
+		}
+		for _, name := range before {
+			if !slices.Contains(after, name) {
+				stale = append(stale, name)
+			}
+		}
+	}()
+
+	if len(stale) > 0 {
+		slices.Sort(stale)
+		fmt.Fprintf(g.stderr, "%s:%d: stale generated files: %s\n", base.ShortPath(g.path), g.lineNum, strings.Join(stale, ", "))
+		base.SetExitStatus(1)
+		return
+	}
+	g.record(words, c, id)
+}
+
+// restore restores the output files of the current directive
+// to the contents in saved, removing any other output files.
+func (g *Generator) restore(saved map[string][]byte) {
+	for _, name := range g.matchFiles(g.outputs) {
+		if _, ok := saved[name]; !ok {
+			if err := os.Remove(filepath.Join(g.dir, name)); err != nil {
+				fmt.Fprintf(g.stderr, "%s:%d: %v\n", base.ShortPath(g.path), g.lineNum, err)
+				base.SetExitStatus(1)
+			}
+		}
+	}
+	for name, data := range saved {
+		file := filepath.Join(g.dir, name)
+		if old, err := os.ReadFile(file); err == nil && bytes.Equal(old, data) {
+			continue
+		}
+		if err := os.WriteFile(file, data, 0o666); err != nil {
+			fmt.Fprintf(g.stderr, "%s:%d: %v\n", base.ShortPath(g.path), g.lineNum, err)
+			base.SetExitStatus(1)
+		}
+	}
+}
+
+// record records the output files of the tracked directive words,
+// which has just run, in the cache c under the action ID id.
+func (g *Generator) record(words []string, c cache.Cache, id cache.ActionID) {
+	outputs := g.matchFiles(g.outputs)
+	if len(outputs) == 0 {
+		g.errorf("running %q: no files match -out patterns %s", words[0], strings.Join(g.outputs, ", "))
+	}
+	if c == nil {
+		return
+	}
+	// Failing to record the outputs only means the directive
+	// runs again next time, so ignore any error.
+	cache.PutBytes(c, id, []byte(g.outputRecord(outputs)))
+}
+
+// upToDate reports whether the cache c records outputs for the action ID
+// id and the current output files match them.
+func (g *Generator) upToDate(c cache.Cache, id cache.ActionID) bool {
+	data, _, err := cache.GetBytes(c, id)
+	if err != nil {
+		return false
+	}
+	outputs := g.matchFiles(g.outputs)
+	return len(outputs) > 0 && string(data) == g.outputRecord(outputs)
+}
+
+// actionID returns the action ID of the tracked directive words.
+func (g *Generator) actionID(words []string) cache.ActionID {
+	h := cache.NewHash("generate")
+	fmt.Fprintf(h, "generate %s\n", g.importPath)
+	fmt.Fprintf(h, "file %s\n", g.file)
+	fmt.Fprintf(h, "GOOS=%s GOARCH=%s\n", cfg.BuildContext.GOOS, cfg.BuildContext.GOARCH)
+	fmt.Fprintf(h, "command %q\n", words)
+	for _, pattern := range g.outputs {
+		fmt.Fprintf(h, "out %q\n", pattern)
+	}
+	for _, pattern := range g.inputs {
+		fmt.Fprintf(h, "in %q\n", pattern)
+	}
+	for _, name := range g.matchFiles(g.inputs) {
+		fmt.Fprintf(h, "input %s %x\n", name, g.hashFile(name))
+	}
+	return h.Sum()
+}
+
+// outputRecord returns the cache entry recording the output files.
+func (g *Generator) outputRecord(outputs []string) string {
+	var b strings.Builder
+	for _, name := range outputs {
+		fmt.Fprintf(&b, "%s %x\n", name, g.hashFile(name))
+	}
+	return b.String()
+}
+
+// hashFile returns the SHA-256 hash of the named file
+// in the package directory.
+//
+// It does not use cache.FileHash, which remembers the hash of each file
+// for the lifetime of the process: generators change files.
+func (g *Generator) hashFile(name string) []byte {
+	f, err := os.Open(filepath.Join(g.dir, name))
+	if err != nil {
+		g.errorf("%v", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		g.errorf("%v", err)
+	}
+	return h.Sum(nil)
+}
+
+// matchFiles returns the sorted, slash-separated names, relative to the
+// package directory, of the regular files matching any of the patterns.
+func (g *Generator) matchFiles(patterns []string) []string {
+	var names []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(g.dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			g.errorf("invalid pattern %q: %v", pattern, err)
+		}
+		for _, file := range matches {
+			if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			rel, err := filepath.Rel(g.dir, file)
+			if err != nil {
+				g.errorf("%v", err)
+			}
+			names = append(names, filepath.ToSlash(rel))
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// declaresOutputs reports whether every directive selected by -run and
+// -skip in src declares its outputs, and so can run independently of
+// the directives in other packages. Directives whose options
+// it cannot parse without expanding them count as undeclared.
+func declaresOutputs(src []byte) bool {
+	for line := range bytes.Lines(src) {
+		if !isGoGenerate(line) || !selected(line) {
+			continue
+		}
+		out := false
+		fields := strings.Fields(string(line[len("//go:generate "):]))
+		for len(fields) > 0 {
+			if strings.HasPrefix(fields[0], "-out=") {
+				out = true
+			} else if !strings.HasPrefix(fields[0], "-in=") {
+				break
+			}
+			fields = fields[1:]
+		}
+		if !out && (len(fields) == 0 || fields[0] != "-command") {
+			return false
+		}
+	}
+	return true
+}
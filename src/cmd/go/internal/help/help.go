@@ -36,10 +36,14 @@ func Help(w io.Writer, args []string) {
 		PrintUsage(buf, base.Go)
 		usage := &base.Command{Long: buf.String()}
 		cmds := []*base.Command{usage}
-		for _, cmd := range base.Go.Commands {
-			cmds = append(cmds, cmd)
-			cmds = append(cmds, cmd.Commands...)
+		var add func([]*base.Command)
+		add = func(list []*base.Command) {
+			for _, cmd := range list {
+				cmds = append(cmds, cmd)
+				add(cmd.Commands)
+			}
 		}
+		add(base.Go.Commands)
 		tmpl(&commentWriter{W: w}, documentationTemplate, cmds)
 		fmt.Fprintln(w, "package main")
 		return
@@ -530,6 +530,10 @@ General-purpose environment variables:
 		A command (with optional space-separated flags) that implements an
 		external go command build cache.
 		See 'go doc cmd/go/internal/cacheprog'.
+	GOCACHEURL
+		The URL of a remote build cache server that the go command
+		shares build outputs through, in addition to GOCACHE.
+		See 'go help cache'.
 	GODEBUG
 		Enable various debugging facilities for programs built with Go,
 		including the go command. Cannot be set using 'go env -w'.
@@ -864,6 +868,20 @@ testing, but they're stored in a subdirectory of the build cache.
 Running 'go clean -fuzzcache' removes all cached fuzzing values.
 This may make fuzzing less effective, temporarily.
 
+Setting the GOCACHEURL environment variable to the URL of a remote
+cache server lets machines share build outputs. The go command looks
+up outputs missing from GOCACHE on the server, and stores the outputs
+it builds both in GOCACHE and on the server. The server must implement
+the HTTP caching protocol used by Bazel (https://bazel.build/remote/caching):
+an output whose SHA-256 hash is H is stored at $GOCACHEURL/cas/H,
+and the cache entry for an action is stored at $GOCACHEURL/ac/ followed
+by the action's ID. If GOCACHEURL begins with "readonly+", as in
+GOCACHEURL=readonly+https://cache.example.com/go, the go command
+only reads from the server. Requests to an HTTPS server use GOAUTH
+credentials (see 'go help goauth'). If a request to the server fails,
+the go command prints a warning and continues with only GOCACHE.
+GOCACHEURL cannot be used together with GOCACHEPROG.
+
 The GODEBUG environment variable can enable printing of debugging
 information about the state of the cache:
 
@@ -307,3 +307,57 @@ func MatchFile(name string, tags map[string]bool) bool {
 	}
 	return true
 }
+
+// FileConstraint returns the build constraint on the file with the given
+// name and content, combining its //go:build line (or, lacking one, its
+// // +build lines) with the $GOOS and $GOARCH suffixes of its name,
+// as described in MatchFile. It returns nil if the file is unconstrained.
+func FileConstraint(name string, content []byte) (constraint.Expr, error) {
+	content, goBuild, _, err := parseFileHeader(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var x constraint.Expr
+	and := func(y constraint.Expr) {
+		if x == nil {
+			x = y
+		} else {
+			x = &constraint.AndExpr{X: x, Y: y}
+		}
+	}
+	if goBuild != nil {
+		y, err := constraint.Parse(string(goBuild))
+		if err != nil {
+			return nil, err
+		}
+		and(y)
+	} else {
+		for line := range bytes.Lines(content) {
+			line = bytes.TrimSpace(line)
+			if text := string(line); constraint.IsPlusBuild(text) {
+				if y, err := constraint.Parse(text); err == nil {
+					and(y)
+				}
+			}
+		}
+	}
+
+	// See MatchFile.
+	if dot := strings.Index(name, "."); dot != -1 {
+		name = name[:dot]
+	}
+	if i := strings.Index(name, "_"); i >= 0 {
+		l := strings.Split(name[i:], "_")
+		if n := len(l); n > 0 && l[n-1] == "test" {
+			l = l[:n-1]
+		}
+		n := len(l)
+		if n >= 2 && syslist.KnownOS[l[n-2]] && syslist.KnownArch[l[n-1]] {
+			and(&constraint.AndExpr{X: &constraint.TagExpr{Tag: l[n-2]}, Y: &constraint.TagExpr{Tag: l[n-1]}})
+		} else if n >= 1 && (syslist.KnownOS[l[n-1]] || syslist.KnownArch[l[n-1]]) {
+			and(&constraint.TagExpr{Tag: l[n-1]})
+		}
+	}
+	return x, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import "testing"
+
+var fileConstraintTests = []struct {
+	name    string
+	content string
+	want    string // "" for no constraint
+}{
+	{"x.go", "package x\n", ""},
+	{"linux.go", "package x\n", ""},
+	{"x_linux.go", "package x\n", "linux"},
+	{"x_windows_amd64_test.go", "package x\n", "windows && amd64"},
+	{"x_arm64.go", "//go:build !purego\n\npackage x\n", "!purego && arm64"},
+	{"x.go", "//go:build unix || js\n\npackage x\n", "unix || js"},
+	{"x.go", "// +build linux darwin\n// +build cgo\n\npackage x\n", "(linux || darwin) && cgo"},
+}
+
+func TestFileConstraint(t *testing.T) {
+	for _, tt := range fileConstraintTests {
+		x, err := FileConstraint(tt.name, []byte(tt.content))
+		if err != nil {
+			t.Errorf("FileConstraint(%q, %q): %v", tt.name, tt.content, err)
+			continue
+		}
+		got := ""
+		if x != nil {
+			got = x.String()
+		}
+		if got != tt.want {
+			t.Errorf("FileConstraint(%q, %q) = %q, want %q", tt.name, tt.content, got, tt.want)
+		}
+	}
+	if _, err := FileConstraint("x.go", []byte("//go:build a\n//go:build b\n\npackage x\n")); err == nil {
+		t.Errorf("FileConstraint with two //go:build lines succeeded, want error")
+	}
+}
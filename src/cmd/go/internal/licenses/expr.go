@@ -0,0 +1,185 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// An Expr is an SPDX license expression, such as
+// "MIT OR (Apache-2.0 AND BSD-3-Clause)".
+// An expression is either a single license or
+// a conjunction or disjunction of expressions.
+type Expr struct {
+	Op   string  // "AND", "OR", or "" for a single license
+	ID   string  // license identifier, if Op is ""
+	Args []*Expr // operands, if Op is "AND" or "OR"
+}
+
+// License returns the expression for the single license id.
+func License(id string) *Expr {
+	return &Expr{ID: id}
+}
+
+// And returns the expression requiring all of the expressions xs,
+// or nil if xs is empty.
+func And(xs ...*Expr) *Expr {
+	switch len(xs) {
+	case 0:
+		return nil
+	case 1:
+		return xs[0]
+	}
+	return &Expr{Op: "AND", Args: xs}
+}
+
+// IDs returns the license identifiers in e, in order and
+// without duplicates.
+func (e *Expr) IDs() []string {
+	var ids []string
+	var walk func(*Expr)
+	walk = func(e *Expr) {
+		if e.Op == "" {
+			if !slices.Contains(ids, e.ID) {
+				ids = append(ids, e.ID)
+			}
+			return
+		}
+		for _, x := range e.Args {
+			walk(x)
+		}
+	}
+	if e != nil {
+		walk(e)
+	}
+	return ids
+}
+
+// String returns e in SPDX syntax.
+func (e *Expr) String() string {
+	if e.Op == "" {
+		return e.ID
+	}
+	var b strings.Builder
+	for i, x := range e.Args {
+		if i > 0 {
+			b.WriteString(" " + e.Op + " ")
+		}
+		if x.Op != "" {
+			b.WriteString("(" + x.String() + ")")
+		} else {
+			b.WriteString(x.ID)
+		}
+	}
+	return b.String()
+}
+
+// ParseExpr parses an SPDX license expression.
+// AND binds more tightly than OR. An exception given with WITH,
+// and a "+" suffix on an identifier, are accepted and dropped:
+// the policy applies to the license itself.
+func ParseExpr(s string) (*Expr, error) {
+	p := &exprParser{s: s}
+	p.next()
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "" {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", s, p.tok)
+	}
+	return e, nil
+}
+
+type exprParser struct {
+	s   string // remaining input
+	tok string // current token, or "" at the end of the input
+}
+
+// next advances to the next token.
+func (p *exprParser) next() {
+	p.s = strings.TrimLeft(p.s, " \t\r\n")
+	if p.s == "" {
+		p.tok = ""
+		return
+	}
+	if p.s[0] == '(' || p.s[0] == ')' {
+		p.tok, p.s = p.s[:1], p.s[1:]
+		return
+	}
+	i := strings.IndexAny(p.s, " \t\r\n()")
+	if i < 0 {
+		i = len(p.s)
+	}
+	p.tok, p.s = p.s[:i], p.s[i:]
+}
+
+// op reports whether the current token is the operator op,
+// which may also be written in lower case.
+func (p *exprParser) op(op string) bool {
+	return p.tok == op || p.tok == strings.ToLower(op)
+}
+
+func (p *exprParser) or() (*Expr, error) {
+	return p.binary("OR", p.and)
+}
+
+func (p *exprParser) and() (*Expr, error) {
+	return p.binary("AND", p.term)
+}
+
+// binary parses a sequence of operands, as parsed by operand,
+// separated by op.
+func (p *exprParser) binary(op string, operand func() (*Expr, error)) (*Expr, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.op(op) {
+		return x, nil
+	}
+	e := &Expr{Op: op, Args: []*Expr{x}}
+	for p.op(op) {
+		p.next()
+		x, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		e.Args = append(e.Args, x)
+	}
+	return e, nil
+}
+
+func (p *exprParser) term() (*Expr, error) {
+	switch {
+	case p.tok == "":
+		return nil, fmt.Errorf("invalid license expression: unexpected end")
+	case p.tok == "(":
+		p.next()
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			return nil, fmt.Errorf("invalid license expression: missing )")
+		}
+		p.next()
+		return e, nil
+	case p.tok == ")" || p.op("AND") || p.op("OR") || p.op("WITH"):
+		return nil, fmt.Errorf("invalid license expression: unexpected %q", p.tok)
+	}
+	e := License(strings.TrimSuffix(p.tok, "+"))
+	p.next()
+	if p.op("WITH") {
+		p.next()
+		if p.tok == "" || p.tok == "(" || p.tok == ")" {
+			return nil, fmt.Errorf("invalid license expression: missing exception after WITH")
+		}
+		p.next()
+	}
+	return e, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// A File is a license file in a module.
+type File struct {
+	Path string   // path relative to the module root, with slashes
+	IDs  []string // SPDX identifiers of its licenses
+	Expr *Expr    `json:"-"` // license expression, naming the IDs
+}
+
+// maxFileSize is the most of a license file that is read.
+const maxFileSize = 1 << 20
+
+// newFile returns the File for the license file at path
+// with the given contents.
+func newFile(path string, data []byte) File {
+	e := Classify(data)
+	return File{Path: path, IDs: e.IDs(), Expr: e}
+}
+
+// AppliesTo reports whether the license file applies to the package in
+// the directory dir, relative to the module root and with slashes.
+// A license file applies to the packages in its directory and below.
+func (f File) AppliesTo(dir string) bool {
+	d := path.Dir(f.Path)
+	return d == "." || dir == d || strings.HasPrefix(dir, d+"/")
+}
+
+// FromZip returns the license files in the module zip file zipfile,
+// in which the files of the module have the given prefix,
+// which has the form "path@version/".
+func FromZip(zipfile, prefix string) ([]File, error) {
+	z, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	var files []File
+	for _, zf := range z.File {
+		name, ok := strings.CutPrefix(zf.Name, prefix)
+		if !ok || !IsLicenseFile(path.Base(name)) || ignored(path.Dir(name)) {
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(r, maxFileSize))
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, newFile(name, data))
+	}
+	slices.SortFunc(files, func(x, y File) int { return strings.Compare(x.Path, y.Path) })
+	return files, nil
+}
+
+// FromDir returns the license files of the module in the directory dir,
+// excluding those of nested modules.
+func FromDir(dir string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			if ignored(rel) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(name, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !IsLicenseFile(d.Name()) {
+			return nil
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxFileSize))
+		f.Close()
+		if err != nil {
+			return err
+		}
+		files = append(files, newFile(rel, data))
+		return nil
+	})
+	return files, err
+}
+
+// ignored reports whether files in the directory dir of a module,
+// relative to the module root and with slashes, are ignored:
+// those in testdata and vendor directories, and in directories
+// whose names begin with "." or "_", which the go command also ignores.
+func ignored(dir string) bool {
+	if dir == "." {
+		return false
+	}
+	for elem := range strings.SplitSeq(dir, "/") {
+		if elem == "testdata" || elem == "vendor" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package licenses finds and classifies the license files of modules
+// and checks them against a license policy.
+package licenses
+
+import (
+	"bytes"
+	"strings"
+)
+
+// NoAssertion is the SPDX identifier reported for a license file
+// whose license is not recognized.
+const NoAssertion = "NOASSERTION"
+
+// IsLicenseFile reports whether a file with the given base name
+// is conventionally a license file, such as LICENSE, COPYING.txt,
+// or LICENSE-MIT.
+func IsLicenseFile(name string) bool {
+	name = strings.ToUpper(name)
+	if strings.HasSuffix(name, ".GO") {
+		return false
+	}
+	for _, ext := range []string{".MD", ".MARKDOWN", ".TXT", ".RST"} {
+		if n, ok := strings.CutSuffix(name, ext); ok {
+			name = n
+			break
+		}
+	}
+	switch name {
+	case "LICENSE", "LICENCE", "COPYING", "UNLICENSE", "UNLICENCE", "MIT-LICENSE", "MIT_LICENSE":
+		return true
+	}
+	for _, prefix := range []string{"LICENSE-", "LICENCE-", "LICENSE.", "LICENCE.", "COPYING-", "COPYING."} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// A rule recognizes a license from phrases in its text.
+// The phrases are normalized as by normalize.
+type rule struct {
+	id  string
+	all []string // phrases that must all appear
+}
+
+const bsdPrefix = "redistribution and use in source and binary forms with or without modification are permitted provided that"
+
+// rules are the rules for recognizing licenses, in order:
+// a license whose text includes the phrases of another license
+// must come before it.
+var rules = []rule{
+	{"AGPL-3.0", []string{"gnu affero general public license"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2 1"}},
+	{"LGPL-2.0", []string{"gnu library general public license"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license version 2 0"}},
+	{"MPL-2.0", []string{"mozilla public license v 2 0"}},
+	{"EPL-2.0", []string{"eclipse public license version 2 0"}},
+	{"EPL-2.0", []string{"eclipse public license v 2 0"}},
+	{"EPL-1.0", []string{"eclipse public license version 1 0"}},
+	{"EPL-1.0", []string{"eclipse public license v 1 0"}},
+	{"Apache-2.0", []string{"apache license", "version 2 0"}},
+	{"BSL-1.0", []string{"boost software license version 1 0"}},
+	{"CC0-1.0", []string{"cc0 1 0 universal"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"Zlib", []string{"altered source versions must be plainly marked as such", "this notice may not be removed or altered from any source distribution"}},
+	{"BSD-4-Clause", []string{bsdPrefix, "all advertising materials mentioning features or use of this software"}},
+	{"BSD-3-Clause", []string{bsdPrefix, "endorse or promote products derived from this software"}},
+	{"BSD-2-Clause", []string{bsdPrefix}},
+	{"MIT", []string{"permission is hereby granted free of charge to any person obtaining a copy of this software"}},
+	{"ISC", []string{"permission to use copy modify and or distribute this software for any purpose with or without fee is hereby granted provided that the above copyright notice and this permission notice appear in all copies"}},
+	{"0BSD", []string{"permission to use copy modify and or distribute this software for any purpose with or without fee is hereby granted"}},
+}
+
+// Classify returns the license expression of the text of a license file.
+// If the text has SPDX-License-Identifier lines, Classify returns the
+// expressions they give, all of which apply. Otherwise it recognizes the
+// text of a few common licenses, returning a single license, which is
+// [NoAssertion] if the license is not recognized.
+func Classify(text []byte) *Expr {
+	if e := spdxExpr(text); e != nil {
+		return e
+	}
+	norm := normalize(text)
+Rules:
+	for _, r := range rules {
+		for _, phrase := range r.all {
+			if !strings.Contains(norm, phrase) {
+				continue Rules
+			}
+		}
+		return License(r.id)
+	}
+	return License(NoAssertion)
+}
+
+// spdxExpr returns the conjunction of the license expressions in the
+// SPDX-License-Identifier lines of text, or nil if there are none.
+// A line whose expression is not valid contributes [NoAssertion].
+func spdxExpr(text []byte) *Expr {
+	const tag = "SPDX-License-Identifier:"
+	var exprs []*Expr
+	for line := range bytes.Lines(text) {
+		_, b, ok := bytes.Cut(line, []byte(tag))
+		if !ok {
+			continue
+		}
+		s := strings.TrimSpace(string(b))
+		for _, end := range []string{"*/", "-->"} {
+			s = strings.TrimSuffix(s, end) // end of a comment
+		}
+		e, err := ParseExpr(s)
+		if err != nil {
+			e = License(NoAssertion)
+		}
+		exprs = append(exprs, e)
+	}
+	return And(exprs...)
+}
+
+// normalize returns text in lower case, with each run of characters other
+// than letters and digits replaced by a single space, so that phrases of
+// license texts can be found regardless of formatting and punctuation.
+func normalize(text []byte) string {
+	var b strings.Builder
+	space := true
+	for _, c := range bytes.ToLower(text) {
+		if 'a' <= c && c <= 'z' || '0' <= c && c <= '9' {
+			b.WriteByte(c)
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return b.String()
+}
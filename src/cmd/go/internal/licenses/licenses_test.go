@@ -0,0 +1,189 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"internal/testenv"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var isLicenseFileTests = []struct {
+	name string
+	want bool
+}{
+	{"LICENSE", true},
+	{"license.md", true},
+	{"LICENCE.txt", true},
+	{"COPYING", true},
+	{"LICENSE-MIT", true},
+	{"LICENSE.APACHE", true},
+	{"MIT-LICENSE.txt", true},
+	{"UNLICENSE", true},
+	{"LICENSES", false},
+	{"license.go", false},
+	{"README.md", false},
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	for _, tt := range isLicenseFileTests {
+		if got := IsLicenseFile(tt.name); got != tt.want {
+			t.Errorf("IsLicenseFile(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+var classifyTests = []struct {
+	text string
+	want string
+}{
+	{"SPDX-License-Identifier: MIT OR Apache-2.0\n", "MIT OR Apache-2.0"},
+	{"/* SPDX-License-Identifier: (GPL-2.0-only WITH Linux-syscall-note) AND BSD-2-Clause */\n", "GPL-2.0-only AND BSD-2-Clause"},
+	{"// SPDX-License-Identifier: MIT\n// SPDX-License-Identifier: ISC OR 0BSD\n", "MIT AND (ISC OR 0BSD)"},
+	{"<!-- SPDX-License-Identifier: MIT AND -->\n", NoAssertion},
+	{"Apache License\nVersion 2.0, January 2004\nhttp://www.apache.org/licenses/\n", "Apache-2.0"},
+	{"Mozilla Public License Version 2.0\n==================================\n", "MPL-2.0"},
+	{"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n", "LGPL-3.0"},
+	{"GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991\n", "GPL-2.0"},
+	{"This is free and unencumbered software released into the public domain.\n", "Unlicense"},
+	{"Permission to use, copy, modify, and/or distribute this software for any\npurpose with or without fee is hereby granted, provided that the above\ncopyright notice and this permission notice appear in all copies.\n", "ISC"},
+	{"Permission to use, copy, modify, and/or distribute this software for any\npurpose with or without fee is hereby granted.\n", "0BSD"},
+	{"Redistribution and use in source and binary forms, with or without\nmodification, are permitted provided that the following conditions are met:\n", "BSD-2-Clause"},
+	{"All rights reserved.\n", NoAssertion},
+}
+
+func TestClassify(t *testing.T) {
+	for _, tt := range classifyTests {
+		if got := Classify([]byte(tt.text)).String(); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyGoLicense(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testenv.GOROOT(t), "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Classify(data).String(), "BSD-3-Clause"; got != want {
+		t.Errorf("Classify($GOROOT/LICENSE) = %q, want %q", got, want)
+	}
+}
+
+var parseExprTests = []struct {
+	in   string
+	want string // String of the result, or "" for an error
+	ids  []string
+}{
+	{"MIT", "MIT", []string{"MIT"}},
+	{"MIT OR Apache-2.0", "MIT OR Apache-2.0", []string{"MIT", "Apache-2.0"}},
+	{"MIT AND BSD-3-Clause OR Apache-2.0", "(MIT AND BSD-3-Clause) OR Apache-2.0", []string{"MIT", "BSD-3-Clause", "Apache-2.0"}},
+	{"MIT and (BSD-3-Clause or MIT)", "MIT AND (BSD-3-Clause OR MIT)", []string{"MIT", "BSD-3-Clause"}},
+	{"((GPL-2.0+ WITH Classpath-exception-2.0))", "GPL-2.0", []string{"GPL-2.0"}},
+	{"", "", nil},
+	{"MIT OR", "", nil},
+	{"(MIT", "", nil},
+	{"MIT)", "", nil},
+	{"MIT Apache-2.0", "", nil},
+	{"MIT WITH", "", nil},
+	{"AND MIT", "", nil},
+}
+
+func TestParseExpr(t *testing.T) {
+	for _, tt := range parseExprTests {
+		e, err := ParseExpr(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseExpr(%q) = %q, want error", tt.in, e)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseExpr(%q): %v", tt.in, err)
+			continue
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("ParseExpr(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := e.IDs(); !slices.Equal(got, tt.ids) {
+			t.Errorf("ParseExpr(%q).IDs() = %q, want %q", tt.in, got, tt.ids)
+		}
+	}
+}
+
+func TestAppliesTo(t *testing.T) {
+	root := File{Path: "LICENSE"}
+	sub := File{Path: "a/b/LICENSE"}
+	for _, dir := range []string{".", "a", "a/b", "a/bc"} {
+		if !root.AppliesTo(dir) {
+			t.Errorf("%s does not apply to %s", root.Path, dir)
+		}
+	}
+	for dir, want := range map[string]bool{".": false, "a": false, "a/b": true, "a/b/c": true, "a/bc": false} {
+		if got := sub.AppliesTo(dir); got != want {
+			t.Errorf("%s applies to %s: %v, want %v", sub.Path, dir, got, want)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	var p Policy
+	if err := p.Parse("policy", []byte("# comment\nallow MIT BSD-3-Clause\n\ndeny GPL-3.0\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		expr string
+		ok   bool
+	}{
+		{"MIT", true},
+		{"MIT AND BSD-3-Clause", true},
+		{"MIT AND Apache-2.0", false},
+		{"GPL-3.0", false},
+		{"", false},
+
+		// Of the licenses joined by OR, one must be allowed.
+		{"MIT OR Apache-2.0", true},
+		{"Apache-2.0 OR GPL-3.0 OR BSD-3-Clause", true},
+		{"Apache-2.0 OR GPL-3.0", false},
+		{"(MIT AND Apache-2.0) OR BSD-3-Clause", true},
+		{"(MIT AND Apache-2.0) OR (BSD-3-Clause AND GPL-3.0)", false},
+
+		// Of the licenses joined by AND, all must be allowed.
+		{"MIT AND (Apache-2.0 OR BSD-3-Clause)", true},
+		{"MIT AND (Apache-2.0 OR GPL-3.0)", false},
+		{"(MIT OR GPL-3.0) AND (BSD-3-Clause OR Apache-2.0)", true},
+	} {
+		var e *Expr
+		if tt.expr != "" {
+			var err error
+			if e, err = ParseExpr(tt.expr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := p.Check(e); (err == nil) != tt.ok {
+			t.Errorf("Check(%q) = %v, want ok=%v", tt.expr, err, tt.ok)
+		}
+	}
+
+	p = Policy{Deny: []string{"GPL-3.0"}}
+	if err := p.Check(nil); err != nil {
+		t.Errorf("Check(nil) with only a deny list = %v, want nil", err)
+	}
+	for expr, ok := range map[string]bool{"GPL-3.0 OR MIT": true, "GPL-3.0 AND MIT": false} {
+		e, err := ParseExpr(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Check(e); (err == nil) != ok {
+			t.Errorf("Check(%q) with only a deny list = %v, want ok=%v", expr, err, ok)
+		}
+	}
+
+	if err := p.Parse("bad", []byte("allow\n")); err == nil {
+		t.Errorf("Parse(%q) succeeded, want error", "allow")
+	}
+}
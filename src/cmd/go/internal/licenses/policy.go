@@ -0,0 +1,99 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GoModPrefix is the prefix of the comment lines in a go.mod file
+// that give its license policy, such as
+//
+//	//go:license allow Apache-2.0 BSD-3-Clause MIT
+const GoModPrefix = "//go:license "
+
+// A Policy says which licenses modules may have.
+type Policy struct {
+	Allow []string // if non-empty, the only licenses allowed
+	Deny  []string // licenses not allowed
+}
+
+// IsEmpty reports whether p allows all licenses.
+func (p *Policy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// ParseLine adds to p the policy on a single line, which has the form
+// "allow ID..." or "deny ID...", where each ID is an SPDX license
+// identifier.
+func (p *Policy) ParseLine(line string) error {
+	f := strings.Fields(line)
+	if len(f) < 2 {
+		return fmt.Errorf("malformed license policy %q: want \"allow\" or \"deny\" followed by licenses", line)
+	}
+	switch f[0] {
+	case "allow":
+		p.Allow = append(p.Allow, f[1:]...)
+	case "deny":
+		p.Deny = append(p.Deny, f[1:]...)
+	default:
+		return fmt.Errorf("unknown license policy %q: want \"allow\" or \"deny\"", f[0])
+	}
+	return nil
+}
+
+// Parse adds to p the policy in data, the contents of a policy file.
+// Each line of a policy file is a policy line, as parsed by ParseLine.
+// Blank lines and lines beginning with # are ignored.
+func (p *Policy) Parse(file string, data []byte) error {
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := p.ParseLine(line); err != nil {
+			return fmt.Errorf("%s:%d: %v", file, i+1, err)
+		}
+	}
+	return nil
+}
+
+// Check returns an error if the license expression e, which applies
+// to a single package or module, violates p. Of the operands of an OR,
+// at least one must be allowed; of the operands of an AND, all must be.
+// A nil e means that no license was found.
+func (p *Policy) Check(e *Expr) error {
+	if e == nil {
+		if len(p.Allow) > 0 {
+			return fmt.Errorf("no license found")
+		}
+		return nil
+	}
+	switch e.Op {
+	case "AND":
+		for _, x := range e.Args {
+			if err := p.Check(x); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "OR":
+		for _, x := range e.Args {
+			if p.Check(x) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("no choice of license in %s is allowed by policy", e)
+	}
+	if slices.Contains(p.Deny, e.ID) {
+		return fmt.Errorf("license %s is denied by policy", e.ID)
+	}
+	if len(p.Allow) > 0 && !slices.Contains(p.Allow, e.ID) {
+		return fmt.Errorf("license %s is not allowed by policy", e.ID)
+	}
+	return nil
+}
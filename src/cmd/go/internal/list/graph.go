@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package list
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modload"
+	"cmd/internal/pkgpattern"
+)
+
+// listImportGraph prints the import graph of pkgs to w
+// in the format selected by the -graph flag.
+func listImportGraph(loaderstate *modload.State, ctx context.Context, opts load.PackageOpts, pkgs []*load.Package, w io.Writer) {
+	g := load.PackageGraph(loaderstate, ctx, opts, pkgs, *listTest)
+	if !*listE {
+		for _, n := range g.Packages {
+			if n.Error != nil {
+				base.Errorf("%v", n.Error)
+			}
+		}
+		base.ExitIfErrors()
+	}
+	if *listTo != "" {
+		var ok bool
+		g, ok = g.To(pkgpattern.MatchPattern(*listTo))
+		if !ok {
+			base.Fatalf("go: no package matching %s in import graph", *listTo)
+		}
+	}
+
+	switch listGraph {
+	case "json":
+		b, err := json.MarshalIndent(g, "", "\t")
+		if err != nil {
+			base.Fatalf("%s", err)
+		}
+		w.Write(b)
+		w.Write(nl)
+
+	case "dot":
+		fmt.Fprintf(w, "digraph imports {\n")
+		for _, n := range g.Packages {
+			var attrs []string
+			if n.Root {
+				attrs = append(attrs, "shape=box")
+			}
+			if n.Ignored {
+				attrs = append(attrs, "color=gray")
+			}
+			if n.Error != nil {
+				attrs = append(attrs, "color=red")
+			}
+			fmt.Fprintf(w, "\t%s%s;\n", strconv.Quote(n.ImportPath), dotAttrs(attrs))
+		}
+		for _, e := range g.Imports {
+			var attrs []string
+			if e.Test {
+				attrs = append(attrs, "style=dashed")
+			}
+			if e.Ignored {
+				attrs = append(attrs, "color=gray")
+			}
+			if e.Constraint != "" {
+				attrs = append(attrs, "label="+strconv.Quote(e.Constraint))
+			}
+			fmt.Fprintf(w, "\t%s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), dotAttrs(attrs))
+		}
+		fmt.Fprintf(w, "}\n")
+
+	default:
+		for _, e := range g.Imports {
+			var notes []string
+			if e.Test {
+				notes = append(notes, "test")
+			}
+			if e.Constraint != "" {
+				notes = append(notes, "if "+e.Constraint)
+			}
+			if e.Ignored {
+				notes = append(notes, "ignored")
+			}
+			if len(notes) > 0 {
+				fmt.Fprintf(w, "%s %s (%s)\n", e.From, e.To, strings.Join(notes, ", "))
+			} else {
+				fmt.Fprintf(w, "%s %s\n", e.From, e.To)
+			}
+		}
+	}
+}
+
+// dotAttrs formats attrs as a DOT attribute list.
+func dotAttrs(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
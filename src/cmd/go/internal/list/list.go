@@ -201,6 +201,50 @@ resolve their dependencies: the Imports and Deps lists will be empty.
 With the -find flag, the -deps, -test and -export commands cannot be
 used.
 
+The -graph flag causes list to print the import graph of the named
+packages and their dependencies instead of the packages themselves.
+By default it prints one import per line, as the paths of the importing
+and the imported package, followed by notes in parentheses: "test" for
+an import only by test files, "if" and a build constraint for an import
+only by files with build constraints, and "ignored" for an import only
+by files that build constraints exclude from the build. For example:
+
+    example.com/m fmt
+    example.com/m golang.org/x/sys/windows (if windows, ignored)
+    example.com/m testing (test)
+
+The -graph=dot flag prints the graph in the DOT language of Graphviz
+instead, and the -graph=json flag as a JSON object corresponding to
+this Go struct:
+
+    type Graph struct {
+        Packages []struct {
+            ImportPath string
+            Module     string        // path of the package's module, if any
+            Standard   bool          // package is in the standard library
+            Root       bool          // package was named on the command line
+            Ignored    bool          // package is imported only by ignored files, and was not loaded
+            Error      *PackageError // error loading the package
+        }
+        Imports []struct {
+            From, To   string
+            Test       bool   // only test files of From import To
+            Ignored    bool   // only files excluded from the build import To
+            Constraint string // build constraint of the files importing To, if all have one
+        }
+    }
+
+The graph includes the imports of files excluded from the build by
+build constraints (other than "ignore"), but not the dependencies of the
+packages that only those files import. With -test, it includes the
+imports of the test files of the named packages, and their dependencies.
+The -to=pattern flag limits the graph to the import paths that lead
+from the named packages to the packages matching the pattern, such as
+all the paths from package A to package B ('go list -graph -to=B A'),
+or the packages that pull in package X ('go list -graph -to=X all').
+The -graph flag cannot be used with -f, -json, -find, -compiled,
+or -export.
+
 The -test flag causes list to report not only the named packages
 but also their test binaries (for packages with tests), to convey to
 source code analysis tools exactly how test binaries are constructed.
@@ -349,6 +393,7 @@ func init() {
 	work.AddBuildFlags(CmdList, work.OmitJSONFlag)
 	work.AddCoverFlags(CmdList, nil)
 	CmdList.Flag.Var(&listJsonFields, "json", "")
+	CmdList.Flag.Var(&listGraph, "graph", "")
 }
 
 var (
@@ -358,12 +403,14 @@ var (
 	listExport     = CmdList.Flag.Bool("export", false, "")
 	listFmt        = CmdList.Flag.String("f", "", "")
 	listFind       = CmdList.Flag.Bool("find", false, "")
+	listGraph      graphFlag
 	listJson       bool
 	listJsonFields jsonFlag // If not empty, only output these fields.
 	listM          = CmdList.Flag.Bool("m", false, "")
 	listRetracted  = CmdList.Flag.Bool("retracted", false, "")
 	listReuse      = CmdList.Flag.String("reuse", "", "")
 	listTest       = CmdList.Flag.Bool("test", false, "")
+	listTo         = CmdList.Flag.String("to", "", "")
 	listU          = CmdList.Flag.Bool("u", false, "")
 	listVersions   = CmdList.Flag.Bool("versions", false, "")
 )
@@ -416,6 +463,35 @@ func (v *jsonFlag) needAny(fields ...string) bool {
 	return false
 }
 
+// A graphFlag is the -graph flag, which may be given alone
+// or with the name of an output format.
+type graphFlag string
+
+func (v *graphFlag) Set(s string) error {
+	if b, err := strconv.ParseBool(s); err == nil {
+		if b {
+			*v = "text"
+		} else {
+			*v = ""
+		}
+		return nil
+	}
+	switch s {
+	case "text", "dot", "json":
+		*v = graphFlag(s)
+		return nil
+	}
+	return fmt.Errorf("unknown graph format %q (want text, dot, or json)", s)
+}
+
+func (v *graphFlag) String() string {
+	return string(*v)
+}
+
+func (v *graphFlag) IsBoolFlag() bool {
+	return true
+}
+
 var nl = []byte{'\n'}
 
 func runList(ctx context.Context, cmd *base.Command, args []string) {
@@ -431,6 +507,22 @@ func runList(ctx context.Context, cmd *base.Command, args []string) {
 	if *listReuse != "" && moduleLoaderState.HasModRoot() {
 		base.Fatalf("go list -reuse cannot be used inside a module")
 	}
+	if listGraph != "" {
+		switch {
+		case *listFmt != "":
+			base.Fatalf("go list -graph cannot be used with -f")
+		case listJson:
+			base.Fatalf("go list -graph cannot be used with -json")
+		case *listFind:
+			base.Fatalf("go list -graph cannot be used with -find")
+		case *listCompiled:
+			base.Fatalf("go list -graph cannot be used with -compiled")
+		case *listExport:
+			base.Fatalf("go list -graph cannot be used with -export")
+		}
+	} else if *listTo != "" {
+		base.Fatalf("go list -to can only be used with -graph")
+	}
 
 	work.BuildInit(moduleLoaderState)
 	out := newTrackingWriter(os.Stdout)
@@ -525,6 +617,9 @@ func runList(ctx context.Context, cmd *base.Command, args []string) {
 		if *listTest {
 			base.Fatalf("go list -test cannot be used with -m")
 		}
+		if listGraph != "" {
+			base.Fatalf("go list -graph cannot be used with -m")
+		}
 
 		if modload.Init(moduleLoaderState); !moduleLoaderState.Enabled() {
 			base.Fatalf("go: list -m cannot be used with GO111MODULE=off")
@@ -629,6 +724,11 @@ func runList(ctx context.Context, cmd *base.Command, args []string) {
 		base.ExitIfErrors()
 	}
 
+	if listGraph != "" {
+		listImportGraph(moduleLoaderState, ctx, pkgOpts, pkgs, out)
+		return
+	}
+
 	if *listTest {
 		c := cache.Default()
 		// Add test binaries to packages to be listed.
@@ -0,0 +1,343 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package load
+
+import (
+	"cmp"
+	"context"
+	"go/build/constraint"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cmd/go/internal/fsys"
+	"cmd/go/internal/imports"
+	"cmd/go/internal/modload"
+	"cmd/go/internal/str"
+)
+
+// A Graph is the import graph of a set of packages and their dependencies.
+type Graph struct {
+	Packages []*GraphNode // sorted by import path
+	Imports  []*GraphEdge // sorted by From, then To
+}
+
+// A GraphNode is a package in a Graph.
+type GraphNode struct {
+	ImportPath string
+	Module     string        `json:",omitempty"` // path of the package's module, if any
+	Standard   bool          `json:",omitempty"` // package is in the standard library
+	Root       bool          `json:",omitempty"` // package was named on the command line
+	Ignored    bool          `json:",omitempty"` // package is imported only by ignored files, and was not loaded
+	Error      *PackageError `json:",omitempty"` // error loading the package
+}
+
+// A GraphEdge is an import of one package by another in a Graph.
+type GraphEdge struct {
+	From, To   string
+	Test       bool   `json:",omitempty"` // only test files of From import To
+	Ignored    bool   `json:",omitempty"` // only files excluded from the build import To
+	Constraint string `json:",omitempty"` // build constraint of the files importing To, if all have one
+}
+
+// PackageGraph returns the import graph of the packages in the dag
+// rooted at roots. If tests is true, the graph also includes the imports
+// of the test files of the roots, and their dependencies.
+//
+// Besides the imports in the build, the graph includes the imports of
+// files that are excluded from the build by build constraints, except
+// for files tagged "ignore", but not the dependencies of the packages
+// that only those files import. Each import records the build
+// constraints of the files that import it.
+func PackageGraph(loaderstate *modload.State, ctx context.Context, opts PackageOpts, roots []*Package, tests bool) *Graph {
+	var pkgs []*Package
+	if tests {
+		pkgs = TestPackageList(loaderstate, ctx, opts, roots)
+	} else {
+		pkgs = PackageList(roots)
+	}
+
+	g := new(Graph)
+	nodes := make(map[string]*GraphNode)
+	for _, p := range pkgs {
+		if nodes[p.ImportPath] != nil {
+			continue
+		}
+		n := &GraphNode{ImportPath: p.ImportPath, Standard: p.Standard, Error: p.Error}
+		if p.Module != nil {
+			n.Module = p.Module.Path
+		}
+		nodes[p.ImportPath] = n
+		g.Packages = append(g.Packages, n)
+	}
+	for _, p := range roots {
+		nodes[p.ImportPath].Root = true
+	}
+	for _, p := range pkgs {
+		g.Imports = append(g.Imports, packageImports(loaderstate, p, tests && nodes[p.ImportPath].Root)...)
+	}
+	for _, e := range g.Imports {
+		if nodes[e.To] == nil {
+			n := &GraphNode{ImportPath: e.To, Ignored: true}
+			nodes[e.To] = n
+			g.Packages = append(g.Packages, n)
+		}
+	}
+
+	slices.SortFunc(g.Packages, func(x, y *GraphNode) int {
+		return strings.Compare(x.ImportPath, y.ImportPath)
+	})
+	slices.SortFunc(g.Imports, func(x, y *GraphEdge) int {
+		return cmp.Or(strings.Compare(x.From, y.From), strings.Compare(x.To, y.To))
+	})
+	return g
+}
+
+// packageImports returns the imports of p, including its test imports
+// if tests is true.
+func packageImports(loaderstate *modload.State, p *Package, tests bool) []*GraphEdge {
+	type use struct {
+		test    bool              // only test files import the package
+		ignored bool              // only ignored files import the package
+		always  bool              // some file imports the package unconditionally
+		xs      []constraint.Expr // constraints of the files importing the package
+	}
+	uses := make(map[string]*use)
+	scan := func(files []string, ignored bool) {
+		if p.Dir == "" {
+			return
+		}
+		for _, file := range files {
+			test := strings.HasSuffix(file, "_test.go")
+			if test && !tests {
+				continue
+			}
+			list, x, ok := fileImports(filepath.Join(p.Dir, file), ignored)
+			if !ok {
+				continue
+			}
+			for _, path := range list {
+				if path == "C" {
+					continue
+				}
+				path = ResolveImportPath(loaderstate, p, path)
+				if path == p.ImportPath {
+					// The external test package imports the package itself.
+					continue
+				}
+				u := uses[path]
+				if u == nil {
+					u = &use{test: true, ignored: true}
+					uses[path] = u
+				}
+				u.test = u.test && test
+				u.ignored = u.ignored && ignored
+				if x == nil {
+					u.always = true
+				} else {
+					u.xs = append(u.xs, x)
+				}
+			}
+		}
+	}
+	scan(str.StringList(p.GoFiles, p.CgoFiles, p.TestGoFiles, p.XTestGoFiles), false)
+	scan(p.IgnoredGoFiles, true)
+
+	// The go command adds some imports that appear in no file,
+	// such as runtime/cgo for cgo and the coverage runtime.
+	for _, path := range p.Imports {
+		u := uses[path]
+		if u == nil {
+			u = &use{always: true}
+			uses[path] = u
+		}
+		u.test = false
+		u.ignored = false
+	}
+
+	var edges []*GraphEdge
+	for path, u := range uses {
+		e := &GraphEdge{From: p.ImportPath, To: path, Test: u.test, Ignored: u.ignored}
+		if !u.always {
+			if x := anyOf(u.xs); x != nil {
+				e.Constraint = x.String()
+			}
+		}
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+// anyOf returns the disjunction of xs, simplified by removing duplicate
+// terms and terms implied by others, or nil if it is always true.
+func anyOf(xs []constraint.Expr) constraint.Expr {
+	type term struct {
+		key string
+		xs  []constraint.Expr // conjuncts
+	}
+	var flatten func(x constraint.Expr, op string, list []constraint.Expr) []constraint.Expr
+	flatten = func(x constraint.Expr, op string, list []constraint.Expr) []constraint.Expr {
+		switch y := x.(type) {
+		case *constraint.OrExpr:
+			if op == "||" {
+				return flatten(y.Y, op, flatten(y.X, op, list))
+			}
+		case *constraint.AndExpr:
+			if op == "&&" {
+				return flatten(y.Y, op, flatten(y.X, op, list))
+			}
+		}
+		return append(list, x)
+	}
+
+	var terms []*term
+	for _, x := range xs {
+		for _, d := range flatten(x, "||", nil) {
+			var cs []constraint.Expr
+			var keys []string
+			for _, c := range flatten(d, "&&", nil) {
+				if k := c.String(); !slices.Contains(keys, k) {
+					cs = append(cs, c)
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			terms = append(terms, &term{strings.Join(keys, " && "), cs})
+		}
+	}
+
+	// x || !x is always true.
+	single := make(map[string]bool)
+	for _, t := range terms {
+		if len(t.xs) == 1 {
+			single[t.key] = true
+		}
+	}
+	for k := range single {
+		if single["!"+k] {
+			return nil
+		}
+	}
+
+	// x || (x && y) is x.
+	implied := func(t, by *term) bool {
+		for _, c := range by.xs {
+			if !slices.ContainsFunc(t.xs, func(x constraint.Expr) bool { return x.String() == c.String() }) {
+				return false
+			}
+		}
+		return true
+	}
+	var x constraint.Expr
+	for i, t := range terms {
+		redundant := false
+		for j, u := range terms {
+			if i != j && implied(t, u) && (len(u.xs) < len(t.xs) || j < i) {
+				redundant = true
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		var y constraint.Expr
+		for _, c := range t.xs {
+			if y == nil {
+				y = c
+			} else {
+				y = &constraint.AndExpr{X: y, Y: c}
+			}
+		}
+		if x == nil {
+			x = y
+		} else {
+			x = &constraint.OrExpr{X: x, Y: y}
+		}
+	}
+	return x
+}
+
+// fileImports returns the import paths in the named Go source file and
+// the build constraint on the file, or nil if it has none.
+// If ignored is true, the file is excluded from the build, and
+// fileImports reports ok=false if no build could include it.
+func fileImports(name string, ignored bool) (list []string, x constraint.Expr, ok bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, false
+	}
+	var quoted []string
+	data, err := imports.ReadImports(f, false, &quoted)
+	f.Close()
+	if err != nil {
+		return nil, nil, false
+	}
+	if ignored && !imports.ShouldBuild(data, imports.AnyTags()) {
+		return nil, nil, false
+	}
+	x, err = imports.FileConstraint(filepath.Base(name), data)
+	if err != nil {
+		return nil, nil, false
+	}
+	for _, q := range quoted {
+		path, err := strconv.Unquote(q)
+		if err != nil {
+			continue
+		}
+		if path == "C" {
+			// Files that import "C" are built only with cgo.
+			if x == nil {
+				x = &constraint.TagExpr{Tag: "cgo"}
+			} else {
+				x = &constraint.AndExpr{X: x, Y: &constraint.TagExpr{Tag: "cgo"}}
+			}
+		}
+		list = append(list, path)
+	}
+	return list, x, true
+}
+
+// To returns the subgraph of g made up of the import paths that lead from
+// the root packages to the packages whose import paths match,
+// and reports whether any package matched.
+func (g *Graph) To(match func(string) bool) (*Graph, bool) {
+	importers := make(map[string][]*GraphEdge)
+	for _, e := range g.Imports {
+		importers[e.To] = append(importers[e.To], e)
+	}
+	keep := make(map[string]bool)
+	var mark func(string)
+	mark = func(path string) {
+		if keep[path] {
+			return
+		}
+		keep[path] = true
+		for _, e := range importers[path] {
+			mark(e.From)
+		}
+	}
+	for _, n := range g.Packages {
+		if match(n.ImportPath) {
+			mark(n.ImportPath)
+		}
+	}
+	if len(keep) == 0 {
+		return &Graph{}, false
+	}
+
+	sub := new(Graph)
+	for _, n := range g.Packages {
+		if keep[n.ImportPath] {
+			sub.Packages = append(sub.Packages, n)
+		}
+	}
+	for _, e := range g.Imports {
+		if keep[e.From] && keep[e.To] {
+			sub.Imports = append(sub.Imports, e)
+		}
+	}
+	return sub, true
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package load
+
+import (
+	"go/build/constraint"
+	"testing"
+)
+
+var anyOfTests = []struct {
+	in   []string
+	want string // "" for always true
+}{
+	{[]string{"linux"}, "linux"},
+	{[]string{"linux", "linux"}, "linux"},
+	{[]string{"linux", "windows && windows"}, "linux || windows"},
+	{[]string{"windows && amd64", "windows"}, "windows"},
+	{[]string{"unix || windows", "openbsd && mips64"}, "unix || windows || (openbsd && mips64)"},
+	{[]string{"!asan", "asan"}, ""},
+	{[]string{"(linux || darwin) && cgo"}, "(linux || darwin) && cgo"},
+}
+
+func TestAnyOf(t *testing.T) {
+	for _, tt := range anyOfTests {
+		var xs []constraint.Expr
+		for _, s := range tt.in {
+			x, err := constraint.Parse("//go:build " + s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			xs = append(xs, x)
+		}
+		got := ""
+		if x := anyOf(xs); x != nil {
+			got = x.String()
+		}
+		if got != tt.want {
+			t.Errorf("anyOf(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
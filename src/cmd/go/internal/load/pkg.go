@@ -235,6 +235,7 @@ type PackageInternal struct {
 	GobinSubdir       bool                // install target would be subdir of GOBIN
 	BuildInfo         *debug.BuildInfo    // add this info to package main
 	TestmainGo        *[]byte             // content for _testmain.go
+	FuzzTargets       []string            // names of fuzz tests run by _testmain.go
 	Embed             map[string][]string // //go:embed comment mapping
 	OrigImportPath    string              // original import path before adding '_test' suffix
 	PGOProfile        string              // path to PGO profile
@@ -404,6 +404,9 @@ func TestPackagesAndErrors(loaderstate *modload.State, ctx context.Context, done
 		// Set TestmainGo even if it is empty: the presence of a TestmainGo
 		// indicates that this package is, in fact, a test main.
 		pmain.Internal.TestmainGo = &data
+		for _, f := range t.FuzzTargets {
+			pmain.Internal.FuzzTargets = append(pmain.Internal.FuzzTargets, f.Name)
+		}
 	}
 
 	if done != nil {
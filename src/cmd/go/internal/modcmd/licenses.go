@@ -0,0 +1,312 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go mod licenses
+
+package modcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/imports"
+	"cmd/go/internal/licenses"
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/modinfo"
+	"cmd/go/internal/modload"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+var cmdLicenses = &base.Command{
+	UsageLine: "go mod licenses [-json] [-m] [-policy=file] [packages]",
+	Short:     "report the licenses of dependencies",
+	Long: `
+Licenses reports the licenses of the packages that the named packages
+depend on, from the license files of the modules that provide them.
+With no arguments, licenses applies to the packages matched by
+"go list all". Packages in the standard library and in the main module
+are not reported.
+
+Licenses finds the license files of each module, such as LICENSE,
+LICENSE.md, COPYING, and LICENSE-MIT, in the module zip file in the
+module cache, downloading it if needed, or in the directory of a module
+replaced by a local directory. A license file applies to the packages
+in its directory and its subdirectories. Licenses identifies each
+license by its SPDX identifier, either from SPDX-License-Identifier
+lines in the license file or by recognizing the text of common
+licenses. A license file with a license that is not recognized is
+reported as NOASSERTION, and a package with no license file as NONE.
+
+For each package, licenses prints the package path followed by the
+identifiers of its licenses. The -m flag causes licenses to treat the
+arguments as modules instead, as for 'go list -m', and to report the
+licenses of each module in the build list, with its version. With -m
+and no arguments, licenses reports every module in the build list
+("all").
+
+The -json flag causes licenses to print a JSON object for each package
+or module instead, corresponding to these Go structs:
+
+    type Report struct {
+        Path      string    // package import path or module path
+        Module    string    // module path, for a package
+        Version   string    // module version
+        Licenses  []License // license files that apply
+        Error     string    // error finding licenses
+        Violation string    // violation of license policy
+    }
+
+    type License struct {
+        Path string   // license file path, relative to the module root
+        IDs  []string // SPDX license identifiers
+    }
+
+A license policy makes licenses report an error for each package or
+module whose licenses it does not allow. Policy lines in go.mod have
+the form
+
+	//go:license allow Apache-2.0 BSD-3-Clause MIT
+	//go:license deny AGPL-3.0
+
+An allow line lists licenses that are allowed. If there are any allow
+lines, all other licenses are denied, and so is having no license file.
+A deny line lists licenses that are denied. The licenses of every
+license file that applies to a package must be allowed. When a license
+file gives an SPDX license expression, such as "MIT OR Apache-2.0",
+only one of the licenses joined by OR need be allowed, while all of
+those joined by AND must be. The -policy flag names a policy file to
+use in addition to go.mod, with the same lines without the
+"//go:license" prefix. Lines in a policy file beginning with # are
+comments.
+	`,
+}
+
+var (
+	licensesJSON   = cmdLicenses.Flag.Bool("json", false, "")
+	licensesM      = cmdLicenses.Flag.Bool("m", false, "")
+	licensesPolicy = cmdLicenses.Flag.String("policy", "", "")
+)
+
+func init() {
+	cmdLicenses.Run = runLicenses // break init cycle
+	base.AddChdirFlag(&cmdLicenses.Flag)
+	base.AddModCommonFlags(&cmdLicenses.Flag)
+}
+
+// A licenseReport reports the licenses of a package or module,
+// as printed by 'go mod licenses -json'.
+type licenseReport struct {
+	Path      string
+	Module    string          `json:",omitempty"`
+	Version   string          `json:",omitempty"`
+	Licenses  []licenses.File `json:",omitempty"`
+	Error     string          `json:",omitempty"`
+	Violation string          `json:",omitempty"`
+}
+
+func runLicenses(ctx context.Context, cmd *base.Command, args []string) {
+	moduleLoaderState := modload.NewState()
+	moduleLoaderState.InitWorkfile()
+	moduleLoaderState.ForceUseModules = true
+	moduleLoaderState.RootMode = modload.NeedRoot
+	modload.ExplicitWriteGoMod = true // don't write go.mod in ListModules
+
+	var reports []*licenseReport
+	files := make(map[module.Version][]licenses.File)
+	moduleFiles := func(m *modinfo.ModulePublic) ([]licenses.File, error) {
+		mod := module.Version{Path: m.Path, Version: m.Version}
+		if f, ok := files[mod]; ok {
+			return f, nil
+		}
+		f, err := moduleLicenses(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		files[mod] = f
+		return f, nil
+	}
+
+	if len(args) == 0 {
+		args = []string{"all"}
+	}
+	if *licensesM {
+		mods, err := modload.ListModules(moduleLoaderState, ctx, args, 0, "")
+		if err != nil {
+			base.Fatal(err)
+		}
+		for _, m := range mods {
+			if m.Main {
+				continue
+			}
+			r := &licenseReport{Path: m.Path, Version: m.Version}
+			if m.Error != nil {
+				r.Error = m.Error.Err
+			} else if f, err := moduleFiles(m); err != nil {
+				r.Error = err.Error()
+			} else {
+				r.Licenses = f
+			}
+			reports = append(reports, r)
+		}
+	} else {
+		loadOpts := modload.PackageOpts{
+			Tags:                     imports.AnyTags(),
+			VendorModulesInGOROOTSrc: true,
+		}
+		_, pkgs := modload.LoadPackages(moduleLoaderState, ctx, loadOpts, args...)
+		slices.Sort(pkgs)
+		for _, path := range pkgs {
+			m := modload.PackageModuleInfo(moduleLoaderState, ctx, path)
+			if m == nil || m.Main {
+				continue
+			}
+			r := &licenseReport{Path: path, Module: m.Path, Version: m.Version}
+			f, err := moduleFiles(m)
+			if err != nil {
+				r.Error = err.Error()
+			}
+			dir := strings.TrimPrefix(strings.TrimPrefix(path, m.Path), "/")
+			if dir == "" {
+				dir = "."
+			}
+			for _, lf := range f {
+				if lf.AppliesTo(dir) {
+					r.Licenses = append(r.Licenses, lf)
+				}
+			}
+			reports = append(reports, r)
+		}
+	}
+
+	var policy licenses.Policy
+	for _, mm := range moduleLoaderState.MainModules.Versions() {
+		if f := moduleLoaderState.MainModules.ModFile(mm); f != nil {
+			if err := goModPolicy(&policy, f); err != nil {
+				base.Fatal(err)
+			}
+		}
+	}
+	if *licensesPolicy != "" {
+		data, err := os.ReadFile(*licensesPolicy)
+		if err != nil {
+			base.Fatal(err)
+		}
+		if err := policy.Parse(*licensesPolicy, data); err != nil {
+			base.Fatal(err)
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for _, r := range reports {
+		name := r.Path
+		if r.Module == "" && r.Version != "" {
+			name += "@" + r.Version
+		}
+		if r.Error != "" {
+			base.Errorf("go: %s: %s", name, r.Error)
+		} else if err := policy.Check(licenseExpr(r.Licenses)); err != nil {
+			r.Violation = err.Error()
+			base.Errorf("go: %s: %s", name, r.Violation)
+		}
+
+		if *licensesJSON {
+			b, err := json.MarshalIndent(r, "", "\t")
+			if err != nil {
+				base.Fatal(err)
+			}
+			w.Write(append(b, '\n'))
+			continue
+		}
+		if r.Error != "" {
+			continue
+		}
+		ids := licenseIDs(r.Licenses)
+		if len(ids) == 0 {
+			ids = []string{"NONE"}
+		}
+		fmt.Fprintf(w, "%s", r.Path)
+		if r.Module == "" && r.Version != "" {
+			fmt.Fprintf(w, " %s", r.Version)
+		}
+		fmt.Fprintf(w, " %s\n", strings.Join(ids, ", "))
+	}
+}
+
+// moduleLicenses returns the license files of the module m,
+// or of its replacement.
+func moduleLicenses(ctx context.Context, m *modinfo.ModulePublic) ([]licenses.File, error) {
+	mod := module.Version{Path: m.Path, Version: m.Version}
+	if r := m.Replace; r != nil {
+		if r.Version == "" {
+			return licenses.FromDir(r.Dir)
+		}
+		mod = module.Version{Path: r.Path, Version: r.Version}
+	}
+	zipfile, err := modfetch.DownloadZip(ctx, mod)
+	if err != nil {
+		return nil, err
+	}
+	return licenses.FromZip(zipfile, mod.Path+"@"+mod.Version+"/")
+}
+
+// licenseIDs returns the distinct license identifiers of files, in order.
+func licenseIDs(files []licenses.File) []string {
+	var ids []string
+	for _, f := range files {
+		for _, id := range f.IDs {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// licenseExpr returns the license expression requiring the licenses
+// of all of files, or nil if there are none.
+func licenseExpr(files []licenses.File) *licenses.Expr {
+	var exprs []*licenses.Expr
+	for _, f := range files {
+		exprs = append(exprs, f.Expr)
+	}
+	return licenses.And(exprs...)
+}
+
+// goModPolicy adds to policy the license policy in the comments of the
+// go.mod file f.
+func goModPolicy(policy *licenses.Policy, f *modfile.File) error {
+	var comments []modfile.Comment
+	add := func(c *modfile.Comments) {
+		comments = append(comments, c.Before...)
+		comments = append(comments, c.Suffix...)
+		comments = append(comments, c.After...)
+	}
+	add(&f.Syntax.Comments)
+	for _, stmt := range f.Syntax.Stmt {
+		add(stmt.Comment())
+		if b, ok := stmt.(*modfile.LineBlock); ok {
+			for _, line := range b.Line {
+				add(line.Comment())
+			}
+			add(b.RParen.Comment())
+		}
+	}
+	for _, c := range comments {
+		if line, ok := strings.CutPrefix(c.Token, licenses.GoModPrefix); ok {
+			if err := policy.ParseLine(line); err != nil {
+				return fmt.Errorf("%s:%d: %v", base.ShortPath(f.Syntax.Name), c.Start.Line, err)
+			}
+		}
+	}
+	return nil
+}
@@ -25,6 +25,10 @@ See 'go help modules' for an overview of module functionality.
 		cmdEdit,
 		cmdGraph,
 		cmdInit,
+		cmdLicenses,
+		cmdOutdated,
+		cmdProxy,
+		cmdSBOM,
 		cmdTidy,
 		cmdVendor,
 		cmdVerify,
@@ -0,0 +1,362 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go mod outdated
+
+package modcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/gover"
+	"cmd/go/internal/modinfo"
+	"cmd/go/internal/modload"
+
+	"golang.org/x/mod/module"
+)
+
+var cmdOutdated = &base.Command{
+	UsageLine: "go mod outdated [-json] [-preview] [-x] [modules]",
+	Short:     "report available upgrades of dependencies",
+	Long: `
+Outdated reports the modules in the build list of the main module that
+have newer versions available. The arguments are module patterns, as for
+'go list -m'; with no arguments, outdated applies to all modules in the
+build list ("all").
+
+For each module, outdated prints the module path and selected version,
+followed by "// indirect" if the module is not a direct dependency of the
+main module, and then one line for each kind of upgrade available:
+
+	patch: the latest patch release with the same major and minor version
+	minor: the latest version with the same module path, as reported by
+	       'go list -m -u'
+	major: the latest version of a later major version of the module,
+	       which has a different module path, such as example.com/m/v2
+
+A module is not reported if none of these is newer than its selected
+version. Modules replaced by local directories are never reported.
+
+The -preview flag causes outdated to also print, for each upgrade, the
+changes to the build list that 'go get' would make to apply it: the
+modules that would be upgraded or added, including the go and toolchain
+versions, with their old and new versions. The preview edits the
+requirements of the main module as 'go get' would, without writing them,
+and compares the resulting module graph, as listed by 'go list -m all',
+so it may include modules that are not needed to build the packages of
+the main module.
+
+The -json flag causes outdated to print a JSON object for each module
+instead, corresponding to these Go structs:
+
+    type Module struct {
+        Path     string   // module path
+        Version  string   // selected version
+        Indirect bool     // module is only indirectly needed by main module
+        Patch    *Upgrade // latest patch release, if newer
+        Minor    *Upgrade // latest version with the same path, if newer
+        Major    *Upgrade // latest version of a later major version, if any
+        Error    string   // error looking up upgrades
+    }
+
+    type Upgrade struct {
+        Path    string     // module path
+        Version string     // module version
+        Time    *time.Time // time version was created
+        Changes []Change   // changes to the build list (with -preview)
+    }
+
+    type Change struct {
+        Path string // module path
+        Old  string // selected version, or "none" if module is added
+        New  string // version after the upgrade
+    }
+
+The -x flag causes outdated to print the commands it executes.
+
+Outdated does not modify go.mod or go.sum, and it cannot be used in
+workspace mode.
+	`,
+}
+
+var (
+	outdatedJSON    = cmdOutdated.Flag.Bool("json", false, "")
+	outdatedPreview = cmdOutdated.Flag.Bool("preview", false, "")
+)
+
+func init() {
+	cmdOutdated.Run = runOutdated // break init cycle
+	cmdOutdated.Flag.BoolVar(&cfg.BuildX, "x", false, "")
+	base.AddChdirFlag(&cmdOutdated.Flag)
+	base.AddModCommonFlags(&cmdOutdated.Flag)
+}
+
+// An outdatedModule is a module with available upgrades,
+// as printed by 'go mod outdated -json'.
+type outdatedModule struct {
+	Path     string
+	Version  string
+	Indirect bool           `json:",omitempty"`
+	Patch    *moduleUpgrade `json:",omitempty"`
+	Minor    *moduleUpgrade `json:",omitempty"`
+	Major    *moduleUpgrade `json:",omitempty"`
+	Error    string         `json:",omitempty"`
+}
+
+// A moduleUpgrade is a version that a module could be upgraded to.
+type moduleUpgrade struct {
+	Path    string
+	Version string
+	Time    *time.Time        `json:",omitempty"`
+	Changes []buildListChange `json:",omitempty"`
+}
+
+// A buildListChange is a change to the version of a module
+// in the build list.
+type buildListChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+func runOutdated(ctx context.Context, cmd *base.Command, args []string) {
+	moduleLoaderState := modload.NewState()
+	moduleLoaderState.InitWorkfile()
+	if modload.WorkFilePath(moduleLoaderState) != "" {
+		base.Fatalf("go: 'go mod outdated' cannot be run in workspace mode; set 'GOWORK=off' to exit workspace mode.")
+	}
+	moduleLoaderState.ForceUseModules = true
+	moduleLoaderState.RootMode = modload.NeedRoot
+
+	if len(args) == 0 {
+		args = []string{"all"}
+	}
+	mods, err := modload.ListModules(moduleLoaderState, ctx, args, modload.ListU, "")
+	if err != nil {
+		base.Fatal(err)
+	}
+
+	// Look up the upgrades of the modules in parallel,
+	// and report them in the order of the build list.
+	type token struct{}
+	sem := make(chan token, runtime.GOMAXPROCS(0))
+	outdated := make([]*outdatedModule, len(mods))
+	for i, m := range mods {
+		if m.Main || m.Replace != nil && m.Replace.Version == "" {
+			continue
+		}
+		if m.Version == "" {
+			// The module is not in the build list.
+			if m.Error != nil {
+				outdated[i] = &outdatedModule{Path: m.Path, Error: m.Error.Err}
+			}
+			continue
+		}
+		sem <- token{}
+		go func() {
+			defer func() { <-sem }()
+			outdated[i] = findUpgrades(moduleLoaderState, ctx, m)
+		}()
+	}
+	for n := cap(sem); n > 0; n-- {
+		sem <- token{}
+	}
+
+	if *outdatedPreview {
+		// Editing the requirements loads module graphs through the shared
+		// loader state, so the previews are computed one at a time.
+		buildList, err := modload.PreviewBuildList(moduleLoaderState, ctx)
+		if err != nil {
+			base.Fatal(err)
+		}
+		for _, m := range outdated {
+			if m != nil {
+				previewUpgrades(moduleLoaderState, ctx, m, buildList)
+			}
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for _, m := range outdated {
+		if m == nil || m.Patch == nil && m.Minor == nil && m.Major == nil && m.Error == "" {
+			continue
+		}
+		if m.Error != "" {
+			base.Errorf("go: %s", m.Error)
+		}
+		if *outdatedJSON {
+			b, err := json.MarshalIndent(m, "", "\t")
+			if err != nil {
+				base.Fatal(err)
+			}
+			w.Write(append(b, '\n'))
+			continue
+		}
+		if m.Patch == nil && m.Minor == nil && m.Major == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s %s", m.Path, m.Version)
+		if m.Indirect {
+			fmt.Fprintf(w, " // indirect")
+		}
+		fmt.Fprintf(w, "\n")
+		printUpgrade(w, "patch", m, m.Patch)
+		printUpgrade(w, "minor", m, m.Minor)
+		printUpgrade(w, "major", m, m.Major)
+	}
+}
+
+// findUpgrades returns the upgrades available for m, the module
+// information for a module in the build list with m.Update set.
+func findUpgrades(loaderstate *modload.State, ctx context.Context, m *modinfo.ModulePublic) *outdatedModule {
+	om := &outdatedModule{
+		Path:     m.Path,
+		Version:  m.Version,
+		Indirect: m.Indirect,
+	}
+	var errs []error
+	if m.Error != nil {
+		errs = append(errs, errors.New(m.Error.Err))
+	}
+
+	query := func(path, query, current string) *moduleUpgrade {
+		info, err := modload.Query(loaderstate, ctx, path, query, current, loaderstate.CheckAllowed)
+		if err != nil {
+			if !isNoUpgradeError(err) {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		if path == m.Path && gover.ModCompare(path, info.Version, m.Version) <= 0 {
+			return nil
+		}
+		return &moduleUpgrade{Path: path, Version: info.Version, Time: &info.Time}
+	}
+
+	om.Patch = query(m.Path, "patch", m.Version)
+	if m.Update != nil {
+		om.Minor = &moduleUpgrade{Path: m.Update.Path, Version: m.Update.Version, Time: m.Update.Time}
+	}
+	for _, path := range laterMajorPaths(m.Path) {
+		u := query(path, "latest", "")
+		if u == nil {
+			break
+		}
+		om.Major = u
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		om.Error = err.Error()
+	}
+	return om
+}
+
+// previewUpgrades sets the Changes of each upgrade of m to the changes
+// that 'go get' would make to buildList, the current build list, to apply
+// the upgrade.
+func previewUpgrades(loaderstate *modload.State, ctx context.Context, m *outdatedModule, buildList []module.Version) {
+	for _, u := range []*moduleUpgrade{m.Patch, m.Minor, m.Major} {
+		if u == nil {
+			continue
+		}
+		list, err := modload.PreviewBuildList(loaderstate, ctx, module.Version{Path: u.Path, Version: u.Version})
+		if err != nil {
+			if m.Error != "" {
+				m.Error += "\n"
+			}
+			m.Error += err.Error()
+			continue
+		}
+		u.Changes = buildListChanges(buildList, list)
+	}
+}
+
+// isNoUpgradeError reports whether err, from modload.Query, means only that
+// there is no version matching the query, in which case there is no upgrade
+// to report. See the similar logic in modload.addUpdate.
+func isNoUpgradeError(err error) bool {
+	_, ok := errors.AsType[*modload.NoMatchingVersionError](err)
+	return ok || errors.Is(err, fs.ErrNotExist) || errors.Is(err, modload.ErrDisallowed)
+}
+
+// laterMajorPaths returns the module paths for the major versions of the
+// module with the given path after its own, in order, up to some limit.
+// The caller stops at the first one that does not exist.
+func laterMajorPaths(path string) []string {
+	prefix, pathMajor, ok := module.SplitPathVersion(path)
+	if !ok {
+		return nil
+	}
+	sep := "/"
+	if strings.HasPrefix(path, "gopkg.in/") {
+		sep = "."
+	}
+	major := 1
+	if pathMajor != "" {
+		n, err := strconv.Atoi(pathMajor[len(".v"):])
+		if err != nil {
+			return nil
+		}
+		major = max(n, 1)
+	}
+
+	// Modules rarely have more than a few major versions,
+	// and each one costs a lookup, so stop after ten.
+	var paths []string
+	for v := major + 1; v <= major+10; v++ {
+		paths = append(paths, prefix+sep+"v"+strconv.Itoa(v))
+	}
+	return paths
+}
+
+// buildListChanges returns the changes from the build list old to new,
+// sorted by module path.
+func buildListChanges(old, new []module.Version) []buildListChange {
+	oldVersion := make(map[string]string, len(old))
+	for _, m := range old {
+		oldVersion[m.Path] = m.Version
+	}
+	var changes []buildListChange
+	for _, m := range new {
+		v, ok := oldVersion[m.Path]
+		if !ok {
+			v = "none"
+		}
+		if v != m.Version {
+			changes = append(changes, buildListChange{Path: m.Path, Old: v, New: m.Version})
+		}
+	}
+	slices.SortFunc(changes, func(x, y buildListChange) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return changes
+}
+
+func printUpgrade(w *bufio.Writer, kind string, m *outdatedModule, u *moduleUpgrade) {
+	if u == nil {
+		return
+	}
+	fmt.Fprintf(w, "\t%s ", kind)
+	if u.Path != m.Path {
+		fmt.Fprintf(w, "%s ", u.Path)
+	}
+	fmt.Fprintf(w, "%s\n", u.Version)
+	for _, c := range u.Changes {
+		fmt.Fprintf(w, "\t\t%s %s => %s\n", c.Path, c.Old, c.New)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go mod proxy
+
+package modcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync/atomic"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/modfetch"
+)
+
+var cmdProxy = &base.Command{
+	UsageLine: "go mod proxy",
+	Short:     "serve the module cache as a module proxy",
+	Long: `
+Proxy provides access to the module cache as a module proxy,
+for use by other go commands through GOPROXY.
+`,
+	Commands: []*base.Command{
+		cmdProxyServe,
+	},
+}
+
+var cmdProxyServe = &base.Command{
+	UsageLine: "go mod proxy serve [-addr=host:port]",
+	Short:     "serve the module cache over HTTP",
+	Long: `
+Serve serves the modules in the module cache ($GOMODCACHE/cache/download)
+over HTTP using the module proxy protocol, so that go commands on other
+machines, such as builders without network access, can download them
+by setting GOPROXY to the server's URL. See 'go help goproxy' for
+details of the protocol.
+
+Serve answers requests for the list of versions of a module, for the
+.info, .mod, and .zip files of a version, and for the latest version of
+a module, all from files already in the module cache. It never
+downloads modules: to serve a module, first download it into the cache,
+for example with 'go mod download'. The version list includes each
+version whose go.mod file is cached, as the go command needs to load the
+go.mod files of versions whose code it never downloads, but omits
+pseudo-versions, as a proxy should. The latest version of a module is
+its latest cached release, or if there is none, its latest cached
+pre-release, or its latest cached pseudo-version.
+
+Serve also proxies the checksum databases that the go command has
+consulted, as described in 'go help module-auth', serving the lookups
+and tiles cached in $GOMODCACHE/cache/download/sumdb and the latest
+signed tree heads the go command has seen. A go command using the
+server as its proxy can then verify the modules it downloads without
+access to the checksum database, provided the cache holds the lookups
+it needs.
+
+The -addr flag sets the address to listen on. The default is
+localhost:8080; to serve other machines, use an address such as
+-addr=:8080.
+
+Serve runs until it is interrupted. The server itself is the modproxy
+tool of the Go distribution, which serve runs with 'go tool modproxy',
+as the go command does not contain an HTTP server.
+`,
+}
+
+var proxyServeAddr = cmdProxyServe.Flag.String("addr", "localhost:8080", "")
+
+func init() {
+	cmdProxyServe.Run = runProxyServe // break init cycle
+
+	base.AddChdirFlag(&cmdProxyServe.Flag)
+	base.AddModCommonFlags(&cmdProxyServe.Flag)
+}
+
+func runProxyServe(ctx context.Context, cmd *base.Command, args []string) {
+	if len(args) != 0 {
+		base.Fatalf("go: 'go mod proxy serve' accepts no arguments")
+	}
+	dir, err := modfetch.DownloadCacheDir(ctx)
+	if err != nil {
+		base.Fatal(err)
+	}
+	gocmd, err := os.Executable()
+	if err != nil {
+		base.Fatal(err)
+	}
+	tool := exec.Command(gocmd, "tool", "modproxy", "-addr="+*proxyServeAddr, dir, cfg.SumdbDir)
+	tool.Stdout = os.Stdout
+	tool.Stderr = os.Stderr
+	if err := tool.Start(); err != nil {
+		base.Fatal(err)
+	}
+	// Pass interrupts on to the server, so that it stops when
+	// 'go mod proxy serve' is interrupted.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, base.SignalsToIgnore...)
+	var forwarded atomic.Bool
+	go func() {
+		for sig := range c {
+			forwarded.Store(true)
+			tool.Process.Signal(sig)
+		}
+	}()
+	err = tool.Wait()
+	signal.Stop(c)
+	interrupted := forwarded.Load() || len(c) > 0
+	close(c)
+	var e *exec.ExitError
+	switch {
+	case errors.As(err, &e) && e.Exited():
+		// The server has reported its error.
+		base.SetExitStatus(e.ExitCode())
+	case e != nil && interrupted:
+		// The server was stopped by an interrupt passed on to it,
+		// which is how it is meant to shut down.
+	case err != nil:
+		base.Fatal(err)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modcmd
+
+import (
+	"context"
+	"os"
+	"runtime"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/gover"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modload"
+	"cmd/go/internal/sbom"
+	"cmd/go/internal/work"
+)
+
+var cmdSBOM = &base.Command{
+	UsageLine: "go mod sbom [-format format] [build flags] [packages]",
+	Short:     "print a software bill of materials for main packages",
+	Long: `
+SBOM prints a software bill of materials (SBOM) for each named main
+package, describing the program that 'go build' would build from it
+with the same build flags.
+
+The -format flag selects the format of the SBOM: spdx, the default,
+for SPDX 2.3 JSON, or cyclonedx for CycloneDX 1.5 JSON. When more than
+one package is named, the SBOMs are printed one after another.
+
+An SBOM lists the main package, the standard library of the Go toolchain,
+and each module that provides packages for the program, with its
+version, its package URL (purl), and its hash from go.sum. The go.sum
+hash is recorded as an annotation (SPDX) or property (CycloneDX) in its
+"h1:" form, since it is not a checksum of the module zip file.
+Replaced modules are listed with the version of their replacement.
+The SBOM also records the build settings of the program, including
+version control information, as reported by 'go version -m'.
+
+To print an SBOM for a program that has already been built, use
+'go version -m -sbom'.
+
+For more about build flags, see 'go help build'.
+	`,
+}
+
+var sbomFormat = cmdSBOM.Flag.String("format", sbom.SPDX, "")
+
+func init() {
+	cmdSBOM.Run = runSBOM // break init cycle
+	work.AddBuildFlags(cmdSBOM, work.OmitJSONFlag)
+}
+
+func runSBOM(ctx context.Context, cmd *base.Command, args []string) {
+	if err := sbom.CheckFormat(*sbomFormat); err != nil {
+		base.Fatalf("go: %v", err)
+	}
+
+	moduleLoaderState := modload.NewState()
+	moduleLoaderState.InitWorkfile()
+	moduleLoaderState.ForceUseModules = true
+	moduleLoaderState.RootMode = modload.NeedRoot
+	work.BuildInit(moduleLoaderState)
+
+	pkgs := load.PackagesAndErrors(moduleLoaderState, ctx, load.PackageOpts{AutoVCS: true}, args)
+	load.CheckPackageErrors(pkgs)
+
+	tool := runtime.Version()
+	if gover.TestVersion != "" {
+		tool = gover.TestVersion
+	}
+	for _, p := range pkgs {
+		if p.Name != "main" || p.Internal.BuildInfo == nil {
+			base.Errorf("go: %s is not a main package", p.ImportPath)
+			continue
+		}
+		// The Go version is recorded by the linker.
+		bi := *p.Internal.BuildInfo
+		bi.GoVersion = tool
+		if err := sbom.Write(os.Stdout, *sbomFormat, tool, &bi); err != nil {
+			base.Fatal(err)
+		}
+	}
+	base.ExitIfErrors()
+}
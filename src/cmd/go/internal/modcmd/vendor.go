@@ -12,9 +12,11 @@ import (
 	"go/build"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -26,12 +28,13 @@ import (
 	"cmd/go/internal/load"
 	"cmd/go/internal/modload"
 	"cmd/go/internal/str"
+	"cmd/go/internal/work"
 
 	"golang.org/x/mod/module"
 )
 
 var cmdVendor = &base.Command{
-	UsageLine: "go mod vendor [-e] [-v] [-o outdir]",
+	UsageLine: "go mod vendor [-e] [-v] [-o outdir] [-tools]",
 	Short:     "make vendored copy of dependencies",
 	Long: `
 Vendor resets the main module's vendor directory to include all packages
@@ -49,18 +52,31 @@ path instead of "vendor". The go command can only use a vendor directory
 named "vendor" within the module root directory, so this flag is
 primarily useful for other tools.
 
+The -tools flag causes vendor to also build the tools declared by
+tool directives in go.mod, and to store their binaries in vendor/tools.
+When run from a module with a vendor directory, 'go tool' runs a stored
+binary instead of building the tool, provided that the binary was built
+by the same toolchain, for the same platform and build configuration,
+from the same sources, and that its content matches the SHA-256 hash
+recorded for it in vendor/modules.txt. The stored binaries, and the tools that 'go tool'
+builds in such a module, are built with -trimpath, so that they do not
+depend on the location of the module. The -tools flag cannot be used
+with -o.
+
 See https://golang.org/ref/mod#go-mod-vendor for more about 'go mod vendor'.
 	`,
 	Run: runVendor,
 }
 
-var vendorE bool   // if true, report errors but proceed anyway
-var vendorO string // if set, overrides the default output directory
+var vendorE bool     // if true, report errors but proceed anyway
+var vendorO string   // if set, overrides the default output directory
+var vendorTools bool // if true, store prebuilt tool binaries
 
 func init() {
 	cmdVendor.Flag.BoolVar(&cfg.BuildV, "v", false, "")
 	cmdVendor.Flag.BoolVar(&vendorE, "e", false, "")
 	cmdVendor.Flag.StringVar(&vendorO, "o", "", "")
+	cmdVendor.Flag.BoolVar(&vendorTools, "tools", false, "")
 	base.AddChdirFlag(&cmdVendor.Flag)
 	base.AddModCommonFlags(&cmdVendor.Flag)
 }
@@ -71,13 +87,16 @@ func runVendor(ctx context.Context, cmd *base.Command, args []string) {
 	if modload.WorkFilePath(moduleLoaderState) != "" {
 		base.Fatalf("go: 'go mod vendor' cannot be run in workspace mode. Run 'go work vendor' to vendor the workspace or set 'GOWORK=off' to exit workspace mode.")
 	}
-	RunVendor(moduleLoaderState, ctx, vendorE, vendorO, args)
+	RunVendor(moduleLoaderState, ctx, vendorE, vendorO, vendorTools, args)
 }
 
-func RunVendor(loaderstate *modload.State, ctx context.Context, vendorE bool, vendorO string, args []string) {
+func RunVendor(loaderstate *modload.State, ctx context.Context, vendorE bool, vendorO string, vendorTools bool, args []string) {
 	if len(args) != 0 {
 		base.Fatalf("go: 'go mod vendor' accepts no arguments")
 	}
+	if vendorTools && vendorO != "" {
+		base.Fatalf("go: -tools cannot be used with -o")
+	}
 	loaderstate.ForceUseModules = true
 	loaderstate.RootMode = modload.NeedRoot
 
@@ -244,6 +263,43 @@ func RunVendor(loaderstate *modload.State, ctx context.Context, vendorE bool, ve
 	if err := os.WriteFile(filepath.Join(vdir, "modules.txt"), buf.Bytes(), 0666); err != nil {
 		base.Fatal(err)
 	}
+
+	if vendorTools {
+		vendorToolBinaries(ctx, vdir)
+	}
+}
+
+// vendorToolBinaries builds the tools of the main modules from the
+// vendor directory vdir, which has just been written, and stores their
+// binaries in vdir for 'go tool' to run, recording their hashes at the
+// end of vdir/modules.txt.
+func vendorToolBinaries(ctx context.Context, vdir string) {
+	// Build the tools exactly as 'go tool' will build them: from the
+	// vendor directory, with -trimpath. That requires loading the main
+	// modules afresh, now in vendor mode.
+	cfg.BuildMod = "vendor"
+	cfg.BuildModExplicit = true
+	cfg.BuildTrimpath = true
+	loaderstate := modload.NewState()
+	loaderstate.InitWorkfile()
+	loaderstate.ForceUseModules = true
+	loaderstate.RootMode = modload.NeedRoot
+	modload.LoadModFile(loaderstate, ctx)
+
+	tools := slices.Sorted(maps.Keys(loaderstate.MainModules.Tools()))
+	lines := work.BuildToolStore(loaderstate, ctx, vdir, tools)
+	if len(lines) == 0 {
+		return
+	}
+	file := filepath.Join(vdir, "modules.txt")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		base.Fatal(err)
+	}
+	data = append(data, strings.Join(lines, "\n")+"\n"...)
+	if err := os.WriteFile(file, data, 0666); err != nil {
+		base.Fatal(err)
+	}
 }
 
 func moduleLine(m, r module.Version) string {
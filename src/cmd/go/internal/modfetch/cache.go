@@ -33,14 +33,25 @@ import (
 )
 
 func cacheDir(ctx context.Context, path string) (string, error) {
-	if err := checkCacheDir(ctx); err != nil {
+	dir, err := DownloadCacheDir(ctx)
+	if err != nil {
 		return "", err
 	}
 	enc, err := module.EscapePath(path)
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(cfg.GOMODCACHE, "cache/download", enc, "/@v"), nil
+	return filepath.Join(dir, enc, "/@v"), nil
+}
+
+// DownloadCacheDir returns the directory holding the downloaded module
+// files, laid out as a module proxy, and the cached checksum database
+// lookups and tiles: $GOMODCACHE/cache/download.
+func DownloadCacheDir(ctx context.Context) (string, error) {
+	if err := checkCacheDir(ctx); err != nil {
+		return "", err
+	}
+	return filepath.Join(cfg.GOMODCACHE, "cache/download"), nil
 }
 
 func CachePath(ctx context.Context, m module.Version, suffix string) (string, error) {
@@ -69,6 +69,9 @@ func newGitRepo(ctx context.Context, remote string, local bool) (Repo, error) {
 		}
 		return nil, fmt.Errorf("git remote (%s) must not be local directory", remote)
 	}
+	if useGitProto(remote) {
+		return newGitProtoRepo(ctx, remote)
+	}
 	var err error
 	r.dir, r.mu.Path, err = WorkDir(ctx, gitWorkDirType, r.remote)
 	if err != nil {
@@ -139,6 +142,10 @@ type gitRepo struct {
 	// Repo uses the SHA256 for hashes, so expect the hashes to be 256/4 == 64-bytes in hex.
 	sha256Hashes bool
 
+	// proto, if non-nil, is the in-process Git client used in place of
+	// the git command, and dir holds its object store instead of a git repo.
+	proto *gitProto
+
 	mu lockedfile.Mutex // protects fetchLevel and git repo state
 
 	fetchLevel int
@@ -165,6 +172,10 @@ const (
 // loadLocalTags loads tag references from the local git cache
 // into the map r.localTags.
 func (r *gitRepo) loadLocalTags(ctx context.Context) {
+	if r.proto != nil {
+		// There are no local tags: statLocal uses the remote tags.
+		return
+	}
 	// The git protocol sends all known refs and ls-remote filters them on the client side,
 	// so we might as well record both heads and tags in one shot.
 	// Most of the time we only care about tags but sometimes we care about heads too.
@@ -240,6 +251,11 @@ func (r *gitRepo) loadRefs(ctx context.Context) (map[string]string, error) {
 		return nil, nil
 	}
 	r.refsOnce.Do(func() {
+		if r.proto != nil {
+			r.refs, r.refsErr = r.proto.lsRefs(ctx)
+			return
+		}
+
 		// The git protocol sends all known refs and ls-remote filters them on the client side,
 		// so we might as well record both heads and tags in one shot.
 		// Most of the time we only care about tags but sometimes we care about heads too.
@@ -558,16 +574,21 @@ func (r *gitRepo) stat(ctx context.Context, rev string) (info *RevInfo, err erro
 			refspec = ref + ":" + ref
 		}
 
-		release, err := base.AcquireNet()
-		if err != nil {
-			return nil, err
+		if r.proto != nil {
+			err = r.proto.fetch(ctx, []string{hash}, 1)
+		} else {
+			var release func()
+			release, err = base.AcquireNet()
+			if err != nil {
+				return nil, err
+			}
+			// We explicitly set protocol.version=2 for this command to work around
+			// an apparent Git bug introduced in Git 2.21 (commit 61c771),
+			// which causes the handler for protocol version 1 to sometimes miss
+			// tags that point to the requested commit (see https://go.dev/issue/56881).
+			_, err = r.runGit(ctx, "git", "-c", "protocol.version=2", "fetch", "-f", "--depth=1", r.remote, refspec)
+			release()
 		}
-		// We explicitly set protocol.version=2 for this command to work around
-		// an apparent Git bug introduced in Git 2.21 (commit 61c771),
-		// which causes the handler for protocol version 1 to sometimes miss
-		// tags that point to the requested commit (see https://go.dev/issue/56881).
-		_, err = r.runGit(ctx, "git", "-c", "protocol.version=2", "fetch", "-f", "--depth=1", r.remote, refspec)
-		release()
 
 		if err == nil {
 			return r.statLocal(ctx, rev, ref)
@@ -599,6 +620,24 @@ func (r *gitRepo) fetchRefsLocked(ctx context.Context) error {
 	if r.local {
 		panic("go: fetchRefsLocked called in local only mode.")
 	}
+	if r.proto != nil {
+		if r.fetchLevel < fetchAll {
+			refs, err := r.loadRefs(ctx)
+			if err != nil {
+				return err
+			}
+			var hashes []string
+			for _, hash := range refs {
+				hashes = append(hashes, hash)
+			}
+			slices.Sort(hashes)
+			if err := r.proto.fetch(ctx, slices.Compact(hashes), 0); err != nil {
+				return err
+			}
+			r.fetchLevel = fetchAll
+		}
+		return nil
+	}
 	if r.fetchLevel < fetchAll {
 		// NOTE: To work around a bug affecting Git clients up to at least 2.23.0
 		// (2019-08-16), we must first expand the set of local refs, and only then
@@ -630,7 +669,13 @@ func (r *gitRepo) fetchRefsLocked(ctx context.Context) error {
 // statLocal returns a new RevInfo describing rev in the local git repository.
 // It uses version as info.Version.
 func (r *gitRepo) statLocal(ctx context.Context, version, rev string) (*RevInfo, error) {
-	out, err := r.runGit(ctx, "git", "-c", "log.showsignature=false", "log", "--no-decorate", "-n1", "--format=format:%H %ct %D", rev, "--")
+	var out []byte
+	var err error
+	if r.proto != nil {
+		out, err = r.protoLog(ctx, rev)
+	} else {
+		out, err = r.runGit(ctx, "git", "-c", "log.showsignature=false", "log", "--no-decorate", "-n1", "--format=format:%H %ct %D", rev, "--")
+	}
 	if err != nil {
 		// Return info with Origin.RepoSum if possible to allow caching of negative lookup.
 		var info *RevInfo
@@ -720,6 +765,9 @@ func (r *gitRepo) ReadFile(ctx context.Context, rev, file string, maxSize int64)
 	if err != nil {
 		return nil, err
 	}
+	if r.proto != nil {
+		return r.proto.readFile(info.Name, file, maxSize)
+	}
 	out, err := r.runGit(ctx, "git", "cat-file", "blob", info.Name+":"+file)
 	if err != nil {
 		return nil, fs.ErrNotExist
@@ -738,7 +786,11 @@ func (r *gitRepo) RecentTag(ctx context.Context, rev, prefix string, allowed fun
 	// result is definitive.
 	describe := func() (definitive bool) {
 		var out []byte
-		out, err = r.runGit(ctx, "git", "for-each-ref", "--format", "%(refname)", "refs/tags", "--merged", rev)
+		if r.proto != nil {
+			out, err = r.protoMergedTags(ctx, rev)
+		} else {
+			out, err = r.runGit(ctx, "git", "for-each-ref", "--format", "%(refname)", "refs/tags", "--merged", rev)
+		}
 		if err != nil {
 			return true
 		}
@@ -822,20 +874,10 @@ func (r *gitRepo) RecentTag(ctx context.Context, rev, prefix string, allowed fun
 }
 
 func (r *gitRepo) DescendsFrom(ctx context.Context, rev, tag string) (bool, error) {
-	// The "--is-ancestor" flag was added to "git merge-base" in version 1.8.0, so
-	// this won't work with Git 1.7.1. According to golang.org/issue/28550, cmd/go
-	// already doesn't work with Git 1.7.1, so at least it's not a regression.
-	//
-	// git merge-base --is-ancestor exits with status 0 if rev is an ancestor, or
-	// 1 if not.
-	_, err := r.runGit(ctx, "git", "merge-base", "--is-ancestor", "--", tag, rev)
-
-	// Git reports "is an ancestor" with exit code 0 and "not an ancestor" with
-	// exit code 1.
-	// Unfortunately, if we've already fetched rev with a shallow history, git
-	// merge-base has been observed to report a false-negative, so don't stop yet
-	// even if the exit code is 1!
-	if err == nil {
+	// Unfortunately, if we've already fetched rev with a shallow history,
+	// isAncestor has been observed to report a false-negative, so don't stop yet
+	// even if it reports false!
+	if ok, _ := r.isAncestor(ctx, tag, rev); ok {
 		return true, nil
 	}
 
@@ -876,7 +918,35 @@ func (r *gitRepo) DescendsFrom(ctx context.Context, rev, tag string) (bool, erro
 		}
 	}
 
-	_, err = r.runGit(ctx, "git", "merge-base", "--is-ancestor", "--", tag, rev)
+	return r.isAncestor(ctx, tag, rev)
+}
+
+// isAncestor reports whether the commit for tag is rev or an ancestor of rev
+// in the local repository.
+func (r *gitRepo) isAncestor(ctx context.Context, tag, rev string) (bool, error) {
+	if r.proto != nil {
+		refs, err := r.loadRefs(ctx)
+		if err != nil {
+			return false, err
+		}
+		tagHash, err := r.proto.resolve(tag, refs)
+		if err != nil {
+			return false, err
+		}
+		revHash, err := r.proto.resolve(rev, refs)
+		if err != nil {
+			return false, err
+		}
+		return r.proto.ancestors(revHash)[tagHash], nil
+	}
+
+	// The "--is-ancestor" flag was added to "git merge-base" in version 1.8.0, so
+	// this won't work with Git 1.7.1. According to golang.org/issue/28550, cmd/go
+	// already doesn't work with Git 1.7.1, so at least it's not a regression.
+	//
+	// Git reports "is an ancestor" with exit code 0 and "not an ancestor" with
+	// exit code 1.
+	_, err := r.runGit(ctx, "git", "merge-base", "--is-ancestor", "--", tag, rev)
 	if err == nil {
 		return true, nil
 	}
@@ -896,6 +966,27 @@ func (r *gitRepo) ReadZip(ctx context.Context, rev, subdir string, maxSize int64
 	if err != nil {
 		return nil, err
 	}
+	if r.proto != nil {
+		f, err := os.CreateTemp("", "go-readzip-*.zip")
+		if err != nil {
+			return nil, err
+		}
+		lw := &limitedWriter{
+			W:               f,
+			N:               maxSize,
+			ErrLimitReached: errors.New("ReadZip: encoded file exceeds allowed size"),
+		}
+		err = r.proto.archive(lw, info.Name, subdir)
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return nil, err
+		}
+		return &deleteCloser{f}, nil
+	}
 
 	unlock, err := r.mu.Lock()
 	if err != nil {
@@ -963,6 +1054,46 @@ func ensureGitAttributes(repoDir string) (err error) {
 	return nil
 }
 
+// protoLog returns the hash and commit time of rev in the local object store
+// of r.proto, formatted like the output of statLocal's git log command.
+func (r *gitRepo) protoLog(ctx context.Context, rev string) ([]byte, error) {
+	refs, err := r.loadRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := r.proto.resolve(rev, refs)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.proto.readCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "%s %d", hash, c.time.Unix()), nil
+}
+
+// protoMergedTags returns the tags whose commits are rev or its ancestors
+// in the local object store of r.proto, formatted like the output of
+// RecentTag's git for-each-ref command.
+func (r *gitRepo) protoMergedTags(ctx context.Context, rev string) ([]byte, error) {
+	refs, err := r.loadRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := r.proto.resolve(rev, refs)
+	if err != nil {
+		return nil, err
+	}
+	ancestors := r.proto.ancestors(hash)
+	var out []byte
+	for ref, h := range refs {
+		if strings.HasPrefix(ref, "refs/tags/") && ancestors[h] {
+			out = fmt.Appendf(out, "%s\n", ref)
+		}
+	}
+	return out, nil
+}
+
 func (r *gitRepo) runGit(ctx context.Context, cmdline ...any) ([]byte, error) {
 	args := RunArgs{cmdline: cmdline, dir: r.dir, local: r.local}
 	if !r.local {
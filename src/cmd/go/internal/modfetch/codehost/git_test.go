@@ -42,6 +42,7 @@ var gitrepo1, gitsha256repo, hgrepo1, vgotest1 string
 var altRepos = func() []string {
 	return []string{
 		"localGitRepo",
+		protoGitRepo,
 		hgrepo1,
 	}
 }
@@ -94,6 +95,10 @@ var (
 	localGitURLErr  error
 )
 
+// protoGitRepo is gitrepo1 accessed using the in-process Git client
+// instead of the git command. (The vcweb server still needs git.)
+const protoGitRepo = "protoGitRepo"
+
 func testMain(m *testing.M) (err error) {
 	cfg.BuildX = testing.Verbose()
 
@@ -180,6 +185,10 @@ func testRepo(ctx context.Context, t *testing.T, remote string) (Repo, error) {
 	if remote == "localGitRepo" {
 		return NewRepo(ctx, "git", localGitURL(t), false)
 	}
+	if remote == protoGitRepo {
+		testenv.MustHaveExecPath(t, "git")
+		return newGitProtoRepo(ctx, gitrepo1)
+	}
 	vcsName := "git"
 	for _, k := range []string{"hg"} {
 		if strings.Contains(remote, "/"+k+"/") {
@@ -402,6 +411,14 @@ func TestLatest(t *testing.T) {
 			info.Origin = &o
 			o.URL = localGitURL(t)
 			t.Run(path.Base(tt.repo), runTest(tt))
+
+			tt.repo = protoGitRepo
+			protoInfo := info
+			tt.info = &protoInfo
+			protoOrigin := o
+			protoInfo.Origin = &protoOrigin
+			protoOrigin.URL = gitrepo1
+			t.Run(path.Base(tt.repo), runTest(tt))
 		}
 	}
 }
@@ -781,8 +798,9 @@ func TestReadZip(t *testing.T) {
 	} {
 		t.Run(path.Base(tt.repo)+"/"+tt.rev+"/"+tt.subdir, runTest(tt))
 		if tt.repo == gitrepo1 {
-			tt.repo = "localGitRepo"
-			t.Run(path.Base(tt.repo)+"/"+tt.rev+"/"+tt.subdir, runTest(tt))
+			for _, tt.repo = range []string{"localGitRepo", protoGitRepo} {
+				t.Run(path.Base(tt.repo)+"/"+tt.rev+"/"+tt.subdir, runTest(tt))
+			}
 		}
 	}
 }
@@ -0,0 +1,1065 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codehost
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cmd/go/internal/web"
+	"cmd/internal/pathcache"
+)
+
+// This file implements a client for version 2 of Git's smart HTTP protocol,
+// which gitRepo uses in place of the git command for remote repositories
+// when git is not installed. See https://git-scm.com/docs/http-protocol,
+// https://git-scm.com/docs/protocol-v2, and https://git-scm.com/docs/pack-format.
+//
+// Like the git command in gitRepo.stat, the client makes shallow fetches of
+// the individual commits it needs, falling back to fetching the complete
+// history of every branch and tag only when it has to search that history.
+// It stores the objects it fetches as loose objects (in the same format as
+// the git command) in the repo's work directory, so that they can be read
+// again without using the network.
+
+const gitProtoWorkDirType = "gitproto1"
+
+// gitInstalled reports whether the git command is installed.
+var gitInstalled = sync.OnceValue(func() bool {
+	_, err := pathcache.LookPath("git")
+	return err == nil
+})
+
+// useGitProto reports whether newGitRepo should use the in-process Git
+// client for the remote repository: it does if the git command is not
+// installed and the remote is an HTTP or HTTPS URL.
+func useGitProto(remote string) bool {
+	if gitInstalled() {
+		return false
+	}
+	u, err := url.Parse(remote)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// newGitProtoRepo returns a gitRepo for the remote repository at the given
+// HTTP or HTTPS URL that uses the in-process Git client instead of the git
+// command.
+func newGitProtoRepo(ctx context.Context, remote string) (*gitRepo, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil, err
+	}
+	r := &gitRepo{remote: remote, remoteURL: remote}
+	r.dir, r.mu.Path, err = WorkDir(ctx, gitProtoWorkDirType, remote)
+	if err != nil {
+		return nil, err
+	}
+	r.proto = &gitProto{url: u, dir: r.dir}
+	// An error here is reported again by loadRefs.
+	if caps, err := r.proto.capabilities(ctx); err == nil {
+		r.sha256Hashes = caps["object-format"] == "sha256"
+	}
+	return r, nil
+}
+
+// A gitProto is a client for a Git repository served by Git's smart HTTP
+// protocol, version 2.
+type gitProto struct {
+	url *url.URL
+	dir string // directory holding the loose object store
+
+	capsOnce sync.Once
+	caps     map[string]string // capability name → value
+	capsErr  error
+
+	// tips maps the commit hashes of annotated tags, as recorded in
+	// gitRepo.refs, to the hashes of the tag objects themselves,
+	// which are what servers allow clients to request.
+	tips map[string]string
+}
+
+// Packet types returned by readPkt.
+const (
+	pktData        = iota // a data packet
+	pktFlush              // "0000"
+	pktDelim              // "0001"
+	pktResponseEnd        // "0002"
+)
+
+// writePkt appends to b a pkt-line containing the formatted text.
+func writePkt(b *bytes.Buffer, format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	fmt.Fprintf(b, "%04x%s", len(line)+4, line)
+}
+
+// readPkt reads a single pkt-line from r.
+// It returns the packet's type and, for a data packet, its payload.
+// If the payload begins with "ERR ", readPkt returns an error
+// with the message sent by the server.
+func readPkt(r *bufio.Reader) (typ int, data []byte, err error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	n, err := strconv.ParseUint(string(hdr[:]), 16, 16)
+	if err != nil {
+		return 0, nil, fmt.Errorf("malformed pkt-line length %q", hdr[:])
+	}
+	switch n {
+	case 0:
+		return pktFlush, nil, nil
+	case 1:
+		return pktDelim, nil, nil
+	case 2:
+		return pktResponseEnd, nil, nil
+	case 3:
+		return 0, nil, fmt.Errorf("malformed pkt-line length %q", hdr[:])
+	}
+	data = make([]byte, n-4)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	if msg, ok := bytes.CutPrefix(data, []byte("ERR ")); ok {
+		return 0, nil, fmt.Errorf("remote error: %s", bytes.TrimSpace(msg))
+	}
+	return pktData, data, nil
+}
+
+// readPktLine is like readPkt but returns the text of a data packet,
+// without its trailing newline.
+func readPktLine(r *bufio.Reader) (typ int, line string, err error) {
+	typ, data, err := readPkt(r)
+	return typ, strings.TrimSuffix(string(data), "\n"), err
+}
+
+// capabilities returns the capabilities that the server advertises,
+// mapping each capability name to its value, or to "" if it has none.
+func (p *gitProto) capabilities(ctx context.Context) (map[string]string, error) {
+	p.capsOnce.Do(func() {
+		p.caps, p.capsErr = p.loadCapabilities(ctx)
+	})
+	return p.caps, p.capsErr
+}
+
+func (p *gitProto) loadCapabilities(ctx context.Context) (map[string]string, error) {
+	u := web.Join(p.url, "info/refs")
+	u.RawQuery = "service=git-upload-pack"
+	resp, err := web.Do(web.DefaultSecurity, "GET", u, map[string][]string{"Git-Protocol": {"version=2"}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	var ct string
+	if v := resp.Header["Content-Type"]; len(v) > 0 {
+		ct, _, _ = mime.ParseMediaType(v[0])
+	}
+	if ct != "application/x-git-upload-pack-advertisement" {
+		return nil, fmt.Errorf("%s: not a Git smart HTTP server (Content-Type %q)", resp.URL, ct)
+	}
+
+	br := bufio.NewReader(resp.Body)
+	typ, line, err := readPktLine(br)
+	if err == nil && typ == pktData && strings.HasPrefix(line, "# service=") {
+		// Servers may precede the advertisement with the service name
+		// and a flush packet, as in earlier versions of the protocol.
+		if typ, _, err = readPktLine(br); err == nil && typ == pktFlush {
+			typ, line, err = readPktLine(br)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading capabilities: %v", resp.URL, err)
+	}
+	if typ != pktData || line != "version 2" {
+		return nil, fmt.Errorf("%s: server does not support Git protocol version 2", resp.URL)
+	}
+	caps := make(map[string]string)
+	for {
+		typ, line, err := readPktLine(br)
+		if err != nil {
+			return nil, fmt.Errorf("%s: reading capabilities: %v", resp.URL, err)
+		}
+		if typ == pktFlush {
+			break
+		}
+		k, v, _ := strings.Cut(line, "=")
+		caps[k] = v
+	}
+	if _, ok := caps["ls-refs"]; !ok {
+		return nil, fmt.Errorf("%s: server does not support ls-refs", resp.URL)
+	}
+	if _, ok := caps["fetch"]; !ok {
+		return nil, fmt.Errorf("%s: server does not support fetch", resp.URL)
+	}
+	switch f := caps["object-format"]; f {
+	case "", "sha1", "sha256":
+	default:
+		return nil, fmt.Errorf("%s: unsupported object format %q", resp.URL, f)
+	}
+	return caps, nil
+}
+
+// command runs the named protocol command with the given arguments
+// and returns a reader for the server's response.
+// The caller must close the response.
+func (p *gitProto) command(ctx context.Context, name string, args []string) (*bufio.Reader, io.Closer, error) {
+	caps, err := p.capabilities(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var req bytes.Buffer
+	writePkt(&req, "command=%s\n", name)
+	if f := caps["object-format"]; f != "" {
+		writePkt(&req, "object-format=%s\n", f)
+	}
+	req.WriteString("0001")
+	for _, arg := range args {
+		writePkt(&req, "%s\n", arg)
+	}
+	req.WriteString("0000")
+
+	header := map[string][]string{
+		"Content-Type": {"application/x-git-upload-pack-request"},
+		"Accept":       {"application/x-git-upload-pack-result"},
+		"Git-Protocol": {"version=2"},
+	}
+	resp, err := web.Do(web.DefaultSecurity, "POST", web.Join(p.url, "git-upload-pack"), header, bytes.NewReader(req.Bytes()))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := resp.Err(); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
+	}
+	return bufio.NewReader(resp.Body), resp.Body, nil
+}
+
+// lsRefs returns the server's HEAD, branches, and tags, mapping each ref
+// to the hash of the commit it identifies.
+func (p *gitProto) lsRefs(ctx context.Context) (map[string]string, error) {
+	br, body, err := p.command(ctx, "ls-refs", []string{
+		"peel",
+		"ref-prefix HEAD",
+		"ref-prefix refs/heads/",
+		"ref-prefix refs/tags/",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	refs := make(map[string]string)
+	p.tips = make(map[string]string)
+	for {
+		typ, line, err := readPktLine(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading refs: %v", err)
+		}
+		if typ == pktFlush {
+			break
+		}
+		f := strings.Fields(line)
+		if len(f) < 2 {
+			return nil, fmt.Errorf("reading refs: malformed line %q", line)
+		}
+		hash, ref := f[0], f[1]
+		if ref != "HEAD" && !strings.HasPrefix(ref, "refs/heads/") && !strings.HasPrefix(ref, "refs/tags/") {
+			continue
+		}
+		for _, attr := range f[2:] {
+			if peeled, ok := strings.CutPrefix(attr, "peeled:"); ok {
+				p.tips[peeled] = hash
+				hash = peeled
+			}
+		}
+		refs[ref] = hash
+	}
+	return refs, nil
+}
+
+// fetch fetches the commits with the given hashes, along with their trees
+// and, if depth is 0, their complete history, or else depth commits of it.
+func (p *gitProto) fetch(ctx context.Context, hashes []string, depth int) error {
+	caps, err := p.capabilities(ctx)
+	if err != nil {
+		return err
+	}
+	args := []string{"no-progress", "ofs-delta"}
+	if depth > 0 && slices.Contains(strings.Fields(caps["fetch"]), "shallow") {
+		args = append(args, fmt.Sprintf("deepen %d", depth))
+	}
+	for _, h := range hashes {
+		if tip := p.tips[h]; tip != "" {
+			h = tip
+		}
+		args = append(args, "want "+h)
+	}
+	args = append(args, "done")
+
+	br, body, err := p.command(ctx, "fetch", args)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// The response is a sequence of sections, each beginning with its name
+	// and ending with a delimiter packet, except the last, which ends with a
+	// flush packet. We need only the packfile, which is always last.
+	for {
+		typ, section, err := readPktLine(br)
+		if err != nil {
+			return fmt.Errorf("fetching commits: %v", err)
+		}
+		if typ != pktData {
+			return fmt.Errorf("fetching commits: server sent no packfile")
+		}
+		if section == "packfile" {
+			break
+		}
+		for typ == pktData {
+			if typ, _, err = readPktLine(br); err != nil {
+				return fmt.Errorf("fetching commits: %v", err)
+			}
+		}
+		if typ != pktDelim {
+			return fmt.Errorf("fetching commits: server sent no packfile")
+		}
+	}
+	if err := p.readPack(&sidebandReader{r: br}); err != nil {
+		return fmt.Errorf("fetching commits: %v", err)
+	}
+	return nil
+}
+
+// A sidebandReader reads the data sent in band 1 of a side-band-64k stream
+// of pkt-lines, up to the terminating flush packet.
+type sidebandReader struct {
+	r    *bufio.Reader
+	data []byte
+	err  error
+}
+
+func (s *sidebandReader) Read(b []byte) (int, error) {
+	for len(s.data) == 0 && s.err == nil {
+		var typ int
+		var data []byte
+		typ, data, s.err = readPkt(s.r)
+		switch {
+		case s.err != nil:
+		case typ == pktFlush:
+			s.err = io.EOF
+		case typ != pktData || len(data) == 0:
+			s.err = fmt.Errorf("unexpected packet in packfile")
+		case data[0] == 1:
+			s.data = data[1:]
+		case data[0] == 2:
+			// Progress message.
+		case data[0] == 3:
+			s.err = fmt.Errorf("remote error: %s", bytes.TrimSpace(data[1:]))
+		default:
+			s.err = fmt.Errorf("unexpected side band %d in packfile", data[0])
+		}
+	}
+	if len(s.data) > 0 {
+		n := copy(b, s.data)
+		s.data = s.data[n:]
+		return n, nil
+	}
+	return 0, s.err
+}
+
+// Object types in a packfile.
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var objTypeNames = [...]string{
+	objCommit: "commit",
+	objTree:   "tree",
+	objBlob:   "blob",
+	objTag:    "tag",
+}
+
+// A packObject is an object read from a packfile.
+type packObject struct {
+	typ  string // "commit", "tree", "blob", or "tag"
+	data []byte
+}
+
+// A packReader reads a packfile, keeping track of the offset
+// and the checksum of the data it has read.
+type packReader struct {
+	r   *bufio.Reader
+	off int64
+	h   hash.Hash
+	b   [1]byte
+}
+
+func (r *packReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.off += int64(n)
+	r.h.Write(b[:n])
+	return n, err
+}
+
+func (r *packReader) ReadByte() (byte, error) {
+	c, err := r.r.ReadByte()
+	if err == nil {
+		r.off++
+		r.b[0] = c
+		r.h.Write(r.b[:])
+	}
+	return c, err
+}
+
+// readPack reads the packfile in rd and adds its objects to the
+// loose object store.
+func (p *gitProto) readPack(rd io.Reader) error {
+	r := &packReader{r: bufio.NewReader(rd), h: p.newHash()}
+	var hdr [12]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return err
+	}
+	if string(hdr[:4]) != "PACK" {
+		return fmt.Errorf("malformed packfile header")
+	}
+	if v := be32(hdr[4:]); v != 2 && v != 3 {
+		return fmt.Errorf("unsupported packfile version %d", v)
+	}
+	count := be32(hdr[8:])
+
+	// Deltas are relative to objects earlier in the packfile,
+	// or, for ref-deltas, possibly later or already in the store.
+	byOffset := make(map[int64]*packObject)
+	byHash := make(map[string]*packObject)
+	type refDelta struct {
+		base  string
+		delta []byte
+	}
+	var pending []refDelta
+	add := func(off int64, obj *packObject) error {
+		h, err := p.writeObject(obj.typ, obj.data)
+		if err != nil {
+			return err
+		}
+		byOffset[off] = obj
+		byHash[h] = obj
+		return nil
+	}
+	base := func(hash string) (*packObject, error) {
+		if obj := byHash[hash]; obj != nil {
+			return obj, nil
+		}
+		typ, data, err := p.readObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		return &packObject{typ, data}, nil
+	}
+
+	for range count {
+		off := r.off
+		c, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		typ := int(c>>4) & 7
+		size := uint64(c & 0x0f)
+		for shift := 4; c&0x80 != 0; shift += 7 {
+			if c, err = r.ReadByte(); err != nil {
+				return err
+			}
+			if shift > 57 {
+				return fmt.Errorf("malformed packfile object size")
+			}
+			size |= uint64(c&0x7f) << shift
+		}
+
+		var baseOff int64
+		var baseHash string
+		switch typ {
+		case objCommit, objTree, objBlob, objTag:
+		case objOfsDelta:
+			c, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			rel := int64(c & 0x7f)
+			for c&0x80 != 0 {
+				if c, err = r.ReadByte(); err != nil {
+					return err
+				}
+				if rel >= 1<<55 {
+					return fmt.Errorf("malformed packfile delta offset")
+				}
+				rel = (rel+1)<<7 | int64(c&0x7f)
+			}
+			baseOff = off - rel
+		case objRefDelta:
+			b := make([]byte, p.newHash().Size())
+			if _, err := io.ReadFull(r, b); err != nil {
+				return err
+			}
+			baseHash = hex.EncodeToString(b)
+		default:
+			return fmt.Errorf("unknown object type %d in packfile", typ)
+		}
+
+		data, err := inflate(r, size)
+		if err != nil {
+			return err
+		}
+
+		switch typ {
+		default:
+			err = add(off, &packObject{objTypeNames[typ], data})
+		case objOfsDelta:
+			b := byOffset[baseOff]
+			if b == nil {
+				return fmt.Errorf("missing delta base at offset %d in packfile", baseOff)
+			}
+			if data, err = applyDelta(b.data, data); err == nil {
+				err = add(off, &packObject{b.typ, data})
+			}
+		case objRefDelta:
+			b, berr := base(baseHash)
+			if berr != nil {
+				pending = append(pending, refDelta{baseHash, data})
+				continue
+			}
+			if data, err = applyDelta(b.data, data); err == nil {
+				err = add(off, &packObject{b.typ, data})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Resolve any ref-deltas whose bases appeared later in the packfile.
+	for len(pending) > 0 {
+		var next []refDelta
+		for _, d := range pending {
+			b, err := base(d.base)
+			if err != nil {
+				next = append(next, d)
+				continue
+			}
+			data, err := applyDelta(b.data, d.delta)
+			if err != nil {
+				return err
+			}
+			h, err := p.writeObject(b.typ, data)
+			if err != nil {
+				return err
+			}
+			byHash[h] = &packObject{b.typ, data}
+		}
+		if len(next) == len(pending) {
+			return fmt.Errorf("missing delta base %s in packfile", next[0].base)
+		}
+		pending = next
+	}
+
+	sum := r.h.Sum(nil)
+	trailer := make([]byte, len(sum))
+	if _, err := io.ReadFull(r.r, trailer); err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, trailer) {
+		return fmt.Errorf("packfile checksum mismatch")
+	}
+	if _, err := r.r.ReadByte(); err != io.EOF {
+		return fmt.Errorf("unexpected data after packfile")
+	}
+	return nil
+}
+
+func be32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+// inflate reads a zlib stream from r, which must contain exactly size bytes.
+// Because r is an io.ByteReader, inflate reads no further than the end of
+// the stream.
+func inflate(r *packReader, size uint64) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	if size > MaxZipFile {
+		return nil, fmt.Errorf("packfile object too large")
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, fmt.Errorf("reading packfile: %v", err)
+	}
+	// Read to the end of the stream, which checks its checksum.
+	if n, err := zr.Read(make([]byte, 1)); n > 0 || err != io.EOF {
+		return nil, fmt.Errorf("reading packfile: object larger than its size")
+	}
+	return data, nil
+}
+
+// applyDelta returns the result of applying the Git delta to base.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errMalformed := errors.New("malformed delta in packfile")
+	varint := func() (uint64, bool) {
+		var x uint64
+		for shift := 0; len(delta) > 0 && shift < 64; shift += 7 {
+			c := delta[0]
+			delta = delta[1:]
+			x |= uint64(c&0x7f) << shift
+			if c&0x80 == 0 {
+				return x, true
+			}
+		}
+		return 0, false
+	}
+	baseSize, ok1 := varint()
+	size, ok2 := varint()
+	if !ok1 || !ok2 || baseSize != uint64(len(base)) || size > MaxZipFile {
+		return nil, errMalformed
+	}
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch {
+		case op&0x80 != 0:
+			// Copy from base. The low 4 bits say which bytes of the
+			// offset follow, and the next 3 bits which bytes of the size.
+			var off, n uint64
+			for i := range 7 {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, errMalformed
+				}
+				if i < 4 {
+					off |= uint64(delta[0]) << (8 * i)
+				} else {
+					n |= uint64(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if off+n > uint64(len(base)) {
+				return nil, errMalformed
+			}
+			out = append(out, base[off:off+n]...)
+		case op != 0:
+			// Insert the next op bytes.
+			if int(op) > len(delta) {
+				return nil, errMalformed
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errMalformed
+		}
+	}
+	if uint64(len(out)) != size {
+		return nil, errMalformed
+	}
+	return out, nil
+}
+
+func (p *gitProto) newHash() hash.Hash {
+	if p.caps["object-format"] == "sha256" {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+func (p *gitProto) objectPath(hash string) string {
+	return filepath.Join(p.dir, "objects", hash[:2], hash[2:])
+}
+
+// writeObject adds the object with the given type and content to the loose
+// object store and returns its hash.
+func (p *gitProto) writeObject(typ string, data []byte) (string, error) {
+	hdr := fmt.Sprintf("%s %d\x00", typ, len(data))
+	h := p.newHash()
+	io.WriteString(h, hdr)
+	h.Write(data)
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	file := p.objectPath(hash)
+	if _, err := os.Stat(file); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), "tmp-")
+	if err != nil {
+		return "", err
+	}
+	zw := zlib.NewWriter(f)
+	io.WriteString(zw, hdr)
+	zw.Write(data)
+	err = zw.Close()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+		if _, serr := os.Stat(file); err != nil && serr == nil {
+			// Another process wrote the same object first.
+			err = nil
+		}
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return hash, nil
+}
+
+// readObject returns the type and content of the object with the given hash
+// in the loose object store.
+func (p *gitProto) readObject(hash string) (typ string, data []byte, err error) {
+	if len(hash) != 2*p.newHash().Size() || !AllHex(hash) {
+		return "", nil, fmt.Errorf("invalid object hash %q", hash)
+	}
+	f, err := os.Open(p.objectPath(hash))
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading object %s: %v", hash, err)
+	}
+	br := bufio.NewReader(zr)
+	hdr, err := br.ReadString(0)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading object %s: %v", hash, err)
+	}
+	typ, sizeStr, _ := strings.Cut(strings.TrimSuffix(hdr, "\x00"), " ")
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil || size < 0 || size > MaxZipFile {
+		return "", nil, fmt.Errorf("reading object %s: malformed header", hash)
+	}
+	data = make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return "", nil, fmt.Errorf("reading object %s: %v", hash, err)
+	}
+	return typ, data, nil
+}
+
+// findObject returns the hash of the single object in the loose object
+// store whose hash begins with prefix.
+func (p *gitProto) findObject(prefix string) (string, error) {
+	prefix = strings.ToLower(prefix)
+	if len(prefix) < 2 || !AllHex(prefix) {
+		return "", fs.ErrNotExist
+	}
+	if len(prefix) == 2*p.newHash().Size() {
+		if _, err := os.Stat(p.objectPath(prefix)); err != nil {
+			return "", err
+		}
+		return prefix, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(p.dir, "objects", prefix[:2]))
+	if err != nil {
+		return "", err
+	}
+	var hash string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), prefix[2:]) && AllHex(e.Name()) {
+			if hash != "" {
+				return "", fmt.Errorf("ambiguous revision %s", prefix)
+			}
+			hash = prefix[:2] + e.Name()
+		}
+	}
+	if hash == "" {
+		return "", fs.ErrNotExist
+	}
+	return hash, nil
+}
+
+// A gitCommit is a parsed commit object.
+type gitCommit struct {
+	tree    string
+	parents []string
+	time    time.Time // committer time
+}
+
+// readCommit returns the commit with the given hash, following any tags.
+func (p *gitProto) readCommit(hash string) (*gitCommit, error) {
+	typ, data, err := p.readObject(hash)
+	for i := 0; err == nil && typ == "tag" && i < 10; i++ {
+		obj, _, _ := strings.Cut(string(data), "\n")
+		target, ok := strings.CutPrefix(obj, "object ")
+		if !ok {
+			return nil, fmt.Errorf("malformed tag %s", hash)
+		}
+		typ, data, err = p.readObject(target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if typ != "commit" {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", hash, typ)
+	}
+
+	c := new(gitCommit)
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line == "" {
+			break // end of headers
+		}
+		k, v, _ := strings.Cut(line, " ")
+		switch k {
+		case "tree":
+			c.tree = v
+		case "parent":
+			c.parents = append(c.parents, v)
+		case "committer":
+			// committer Name <email> 1523994202 +0000
+			f := strings.Fields(v[strings.LastIndex(v, ">")+1:])
+			if len(f) > 0 {
+				if t, err := strconv.ParseInt(f[0], 10, 64); err == nil {
+					c.time = time.Unix(t, 0).UTC()
+				}
+			}
+		}
+	}
+	if c.tree == "" || c.time.IsZero() {
+		return nil, fmt.Errorf("malformed commit %s", hash)
+	}
+	return c, nil
+}
+
+// A gitTreeEntry is an entry in a tree object.
+type gitTreeEntry struct {
+	mode string // "40000" (tree), "100644", "100755", "120000" (symlink), or "160000" (submodule)
+	name string
+	hash string
+}
+
+// readTree returns the entries of the tree with the given hash.
+func (p *gitProto) readTree(hash string) ([]gitTreeEntry, error) {
+	typ, data, err := p.readObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	if typ != "tree" {
+		return nil, fmt.Errorf("object %s is a %s, not a tree", hash, typ)
+	}
+	hashSize := p.newHash().Size()
+	var entries []gitTreeEntry
+	for len(data) > 0 {
+		mode, rest, ok1 := bytes.Cut(data, []byte(" "))
+		name, rest, ok2 := bytes.Cut(rest, []byte("\x00"))
+		if !ok1 || !ok2 || len(rest) < hashSize {
+			return nil, fmt.Errorf("malformed tree %s", hash)
+		}
+		entries = append(entries, gitTreeEntry{string(mode), string(name), hex.EncodeToString(rest[:hashSize])})
+		data = rest[hashSize:]
+	}
+	return entries, nil
+}
+
+// resolve returns the hash of the commit identified by rev, which is
+// either a possibly abbreviated hash of an object in the loose object store
+// or the name of one of the refs, which are as returned by lsRefs.
+func (p *gitProto) resolve(rev string, refs map[string]string) (string, error) {
+	hash := ""
+	if len(rev) >= minHashDigits && AllHex(rev) {
+		h, err := p.findObject(rev)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		hash = h
+	}
+	for _, ref := range []string{rev, "refs/tags/" + rev, "refs/heads/" + rev} {
+		if hash == "" {
+			hash = refs[ref]
+		}
+	}
+	if hash == "" {
+		return "", fs.ErrNotExist
+	}
+	if _, err := p.readCommit(hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// ancestors returns the set of commits in the loose object store
+// reachable from the commit with the given hash, including itself.
+// The set stops at commits whose parents have not been fetched.
+func (p *gitProto) ancestors(hash string) map[string]bool {
+	seen := make(map[string]bool)
+	work := []string{hash}
+	for len(work) > 0 {
+		h := work[len(work)-1]
+		work = work[:len(work)-1]
+		if seen[h] {
+			continue
+		}
+		c, err := p.readCommit(h)
+		if err != nil {
+			continue
+		}
+		seen[h] = true
+		work = append(work, c.parents...)
+	}
+	return seen
+}
+
+// readFile returns the content of the named file in the commit with the
+// given hash.
+func (p *gitProto) readFile(hash, file string, maxSize int64) ([]byte, error) {
+	c, err := p.readCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+	mode, obj := "40000", c.tree
+	for elem := range strings.SplitSeq(path.Clean(file), "/") {
+		if mode != "40000" {
+			return nil, fs.ErrNotExist
+		}
+		entries, err := p.readTree(obj)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(entries, func(e gitTreeEntry) bool { return e.name == elem })
+		if i < 0 {
+			return nil, fs.ErrNotExist
+		}
+		mode, obj = entries[i].mode, entries[i].hash
+	}
+	if mode == "40000" || mode == "160000" {
+		return nil, fs.ErrNotExist
+	}
+	_, data, err := p.readObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file %s too large (%d bytes > %d)", file, len(data), maxSize)
+	}
+	return data, nil
+}
+
+// archive writes to w a zip file of the files in the subdir subdirectory of
+// the commit with the given hash, all in the top-level directory "prefix/",
+// in the same form as "git archive --format=zip --prefix=prefix/".
+func (p *gitProto) archive(w io.Writer, hash, subdir string) error {
+	c, err := p.readCommit(hash)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	create := func(name string, mode fs.FileMode) (io.Writer, error) {
+		fh := &zip.FileHeader{Name: "prefix/" + name, Method: zip.Deflate, Modified: c.time}
+		if mode.IsDir() {
+			fh.Method = zip.Store
+		}
+		fh.SetMode(mode)
+		return zw.CreateHeader(fh)
+	}
+	if _, err := create("", fs.ModeDir|0775); err != nil {
+		return err
+	}
+
+	found := false
+	var walk func(dir, tree string) error
+	walk = func(dir, tree string) error {
+		entries, err := p.readTree(tree)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			name := dir + e.name
+			inSubdir := subdir == "" || name == subdir || strings.HasPrefix(name, subdir+"/")
+			if e.mode == "40000" {
+				if !inSubdir && !strings.HasPrefix(subdir, name+"/") {
+					continue
+				}
+				if _, err := create(name+"/", fs.ModeDir|0775); err != nil {
+					return err
+				}
+				if err := walk(name+"/", e.hash); err != nil {
+					return err
+				}
+				continue
+			}
+			if !inSubdir || e.mode == "160000" {
+				continue
+			}
+			mode := fs.FileMode(0664)
+			switch e.mode {
+			case "100755":
+				mode = 0775
+			case "120000":
+				mode = fs.ModeSymlink | 0777
+			}
+			_, data, err := p.readObject(e.hash)
+			if err != nil {
+				return err
+			}
+			w, err := create(name, mode)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+			found = true
+		}
+		return nil
+	}
+	if err := walk("", c.tree); err != nil {
+		return err
+	}
+	if !found {
+		return fs.ErrNotExist
+	}
+	return zw.Close()
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codehost
+
+import (
+	"bufio"
+	"bytes"
+	"internal/testenv"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/semver"
+)
+
+func TestPkt(t *testing.T) {
+	var b bytes.Buffer
+	writePkt(&b, "command=%s\n", "ls-refs")
+	b.WriteString("0001")
+	writePkt(&b, "peel\n")
+	b.WriteString("0000")
+	if want := "0014command=ls-refs\n00010009peel\n0000"; b.String() != want {
+		t.Fatalf("writePkt: have %q, want %q", b.String(), want)
+	}
+
+	r := bufio.NewReader(&b)
+	for _, want := range []struct {
+		typ  int
+		line string
+	}{
+		{pktData, "command=ls-refs"},
+		{pktDelim, ""},
+		{pktData, "peel"},
+		{pktFlush, ""},
+	} {
+		typ, line, err := readPktLine(r)
+		if err != nil || typ != want.typ || line != want.line {
+			t.Fatalf("readPktLine() = %d, %q, %v, want %d, %q, nil", typ, line, err, want.typ, want.line)
+		}
+	}
+	if _, _, err := readPktLine(r); err == nil {
+		t.Fatalf("readPktLine at EOF succeeded")
+	}
+
+	r = bufio.NewReader(strings.NewReader("0012ERR no access\n"))
+	if _, _, err := readPktLine(r); err == nil || err.Error() != "remote error: no access" {
+		t.Fatalf("readPktLine(ERR) = %v, want remote error", err)
+	}
+}
+
+var applyDeltaTests = []struct {
+	base, delta string
+	out         string // "" for error
+}{
+	// Copy "hello, " and insert "gophers\n".
+	{"hello, world\n", "\x0d\x0f\x90\x07\x08gophers\n", "hello, gophers\n"},
+	// Copy "world" from offset 7.
+	{"hello, world\n", "\x0d\x05\x91\x07\x05", "world"},
+	// Wrong base size.
+	{"hello\n", "\x0d\x05\x91\x07\x05", ""},
+	// Copy past the end of base.
+	{"hello, world\n", "\x0d\x05\x91\x0a\x05", ""},
+	// Wrong result size.
+	{"hello, world\n", "\x0d\x06\x91\x07\x05", ""},
+	// Reserved opcode 0.
+	{"hello, world\n", "\x0d\x05\x00", ""},
+}
+
+func TestApplyDelta(t *testing.T) {
+	for _, tt := range applyDeltaTests {
+		out, err := applyDelta([]byte(tt.base), []byte(tt.delta))
+		if tt.out == "" {
+			if err == nil {
+				t.Errorf("applyDelta(%q, %q) = %q, want error", tt.base, tt.delta, out)
+			}
+			continue
+		}
+		if err != nil || string(out) != tt.out {
+			t.Errorf("applyDelta(%q, %q) = %q, %v, want %q", tt.base, tt.delta, out, err, tt.out)
+		}
+	}
+}
+
+func TestGitProtoSHA256(t *testing.T) {
+	testenv.MustHaveExecPath(t, "git")
+	if v := gitVersion(t); semver.Compare(v, minGitSHA256Vers) < 0 {
+		t.Skipf("git version is too old (%+v); skipping git sha256 test", v)
+	}
+	ctx := testContext(t)
+
+	r, err := newGitProtoRepo(ctx, gitsha256repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := r.Stat(ctx, "v2.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1401e4e1fdb4169b51d44a1ff62af63ccc708bf5c12d15051268b51bbb6cbd82"; info.Name != want {
+		t.Errorf("Stat(v2.0.2).Name = %s, want %s", info.Name, want)
+	}
+	data, err := r.ReadFile(ctx, "v2", "another.txt", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "another\n" {
+		t.Errorf("ReadFile(v2, another.txt) = %q, want %q", data, "another\n")
+	}
+	ok, err := r.DescendsFrom(ctx, "v2.0.2", "v1.2.3")
+	if err != nil || !ok {
+		t.Errorf("DescendsFrom(v2.0.2, v1.2.3) = %v, %v, want true, nil", ok, err)
+	}
+}
@@ -583,6 +583,30 @@ func LoadModGraph(loaderstate *State, ctx context.Context, goVersion string) (*M
 	return mg, nil
 }
 
+// PreviewBuildList returns the build list of the main module that would
+// result from editing its requirements, as 'go get' does using
+// EditBuildList, so that every module in mustSelect is selected at the given
+// version. It does not change the requirements of the main module or the
+// loader state. With no modules in mustSelect, PreviewBuildList returns the
+// current build list, for comparison.
+//
+// PreviewBuildList must not be called in workspace mode.
+func PreviewBuildList(loaderstate *State, ctx context.Context, mustSelect ...module.Version) ([]module.Version, error) {
+	rs := LoadModFile(loaderstate, ctx)
+	if len(mustSelect) > 0 {
+		var err error
+		rs, _, err = editRequirements(loaderstate, ctx, rs, nil, mustSelect)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mg, err := rs.Graph(loaderstate, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mg.BuildList(), nil
+}
+
 // expandGraph loads the complete module graph from rs.
 //
 // If the complete graph reveals that some root of rs is not actually the
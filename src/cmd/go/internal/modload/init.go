@@ -1100,7 +1100,7 @@ func loadModFile(loaderstate *State, ctx context.Context, opts *PackageOpts) (*R
 	if loaderstate.MainModules.Index(mainModule).goVersion == "" && rs.pruning != workspace {
 		// TODO(#45551): Do something more principled instead of checking
 		// cfg.CmdName directly here.
-		if cfg.BuildMod == "mod" && cfg.CmdName != "mod graph" && cfg.CmdName != "mod why" {
+		if cfg.BuildMod == "mod" && cfg.CmdName != "mod graph" && cfg.CmdName != "mod licenses" && cfg.CmdName != "mod outdated" && cfg.CmdName != "mod why" {
 			// go line is missing from go.mod; add one there and add to derived requirements.
 			v := gover.Local()
 			if opts != nil && opts.TidyGoVersion != "" {
@@ -1518,7 +1518,7 @@ func setDefaultBuildMod(loaderstate *State) {
 	if cfg.BuildModExplicit {
 		if loaderstate.inWorkspaceMode() && cfg.BuildMod != "readonly" && cfg.BuildMod != "vendor" {
 			switch cfg.CmdName {
-			case "work sync", "mod graph", "mod verify", "mod why":
+			case "work sync", "mod graph", "mod licenses", "mod outdated", "mod verify", "mod why":
 				// These commands run with BuildMod set to mod, but they don't take the
 				// -mod flag, so we should never get here.
 				panic("in workspace mode and -mod was set explicitly, but command doesn't support setting -mod")
@@ -1540,7 +1540,7 @@ func setDefaultBuildMod(loaderstate *State) {
 		// These commands are intended to update go.mod and go.sum.
 		cfg.BuildMod = "mod"
 		return
-	case "mod graph", "mod verify", "mod why":
+	case "mod graph", "mod licenses", "mod outdated", "mod verify", "mod why":
 		// These commands should not update go.mod or go.sum, but they should be
 		// able to fetch modules not in go.sum and should not report errors if
 		// go.mod is inconsistent. They're useful for debugging, and they need
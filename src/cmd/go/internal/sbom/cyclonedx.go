@@ -0,0 +1,112 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"strconv"
+	"time"
+)
+
+// These types follow the CycloneDX 1.5 JSON schema:
+// https://cyclonedx.org/docs/1.5/json/.
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// cycloneDX returns the CycloneDX BOM for p.
+func (p *program) cycloneDX(tool string, created time.Time) *cdxBOM {
+	bom := &cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + p.uuid(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: created.Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{{Type: "application", Name: "go", Version: tool}},
+			},
+		},
+		Components: []cdxComponent{},
+	}
+
+	refs := make(map[string]bool)
+	comp := func(typ string, c component) cdxComponent {
+		ref := c.purl
+		if ref == "" {
+			ref = c.name
+		}
+		for i := 2; refs[ref]; i++ {
+			ref = c.name + "#" + strconv.Itoa(i)
+		}
+		refs[ref] = true
+		cc := cdxComponent{
+			Type:    typ,
+			BOMRef:  ref,
+			Name:    c.name,
+			Version: c.version,
+			PURL:    c.purl,
+		}
+		if c.sum != "" {
+			// The go.sum hash is not a hash of the module zip file,
+			// so it is recorded as a property rather than in hashes.
+			cc.Properties = append(cc.Properties, cdxProperty{"go:sum", c.sum})
+		}
+		if c.replace != "" {
+			cc.Properties = append(cc.Properties, cdxProperty{"go:replace", c.replace})
+		}
+		return cc
+	}
+
+	main := comp("application", p.main)
+	for _, s := range p.settings {
+		main.Properties = append(main.Properties, cdxProperty{"go:build:" + s.Key, s.Value})
+	}
+	bom.Metadata.Component = main
+
+	dep := cdxDependency{Ref: main.BOMRef}
+	for _, c := range append([]component{p.stdlib}, p.deps...) {
+		cc := comp("library", c)
+		bom.Components = append(bom.Components, cc)
+		dep.DependsOn = append(dep.DependsOn, cc.BOMRef)
+	}
+	bom.Dependencies = []cdxDependency{dep}
+	return bom
+}
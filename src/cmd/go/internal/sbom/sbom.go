@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sbom writes software bills of materials (SBOMs) for Go programs,
+// derived from their build information.
+package sbom
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"cmd/go/internal/gover"
+)
+
+// The supported SBOM formats.
+const (
+	SPDX      = "spdx"      // SPDX 2.3 JSON
+	CycloneDX = "cyclonedx" // CycloneDX 1.5 JSON
+)
+
+// CheckFormat returns an error if format is not a supported SBOM format.
+func CheckFormat(format string) error {
+	switch format {
+	case SPDX, CycloneDX:
+		return nil
+	}
+	return fmt.Errorf("unknown SBOM format %q: must be %q or %q", format, SPDX, CycloneDX)
+}
+
+// now returns the creation time of SBOMs. It is replaced in tests.
+var now = time.Now
+
+// Write writes an SBOM in the given format, for the program with the
+// build information bi, to w. The tool is the version of the go command
+// writing the SBOM, such as "go1.26.0".
+func Write(w io.Writer, format, tool string, bi *debug.BuildInfo) error {
+	if err := CheckFormat(format); err != nil {
+		return err
+	}
+	// Development versions of Go have a version such as
+	// "devel go1.26-abcdef Mon Jan 2 15:04:05 2026 +0000"; keep only the first word.
+	tool, _, _ = strings.Cut(strings.TrimPrefix(tool, "devel "), " ")
+	p := newProgram(bi)
+	created := now().UTC().Truncate(time.Second)
+	var doc any
+	switch format {
+	case SPDX:
+		doc = p.spdx(tool, created)
+	case CycloneDX:
+		doc = p.cycloneDX(tool, created)
+	}
+	js, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", js)
+	return err
+}
+
+// A program is the information about a program reported in an SBOM,
+// independent of the format.
+type program struct {
+	bi       *debug.BuildInfo
+	main     component
+	stdlib   component
+	deps     []component
+	settings []debug.BuildSetting
+}
+
+// A component is a module or the standard library,
+// as reported in an SBOM.
+type component struct {
+	name    string // module or package path
+	version string // version, or "" if unknown
+	purl    string // package URL, or "" if none
+	sum     string // hash from go.sum, such as "h1:...", or ""
+	replace string // for a replaced module, the replacement path and version
+}
+
+func newProgram(bi *debug.BuildInfo) *program {
+	p := &program{bi: bi, settings: bi.Settings}
+
+	p.main = component{name: bi.Path, version: moduleVersion(bi.Main.Version)}
+	if bi.Main.Path != "" {
+		p.main.purl = purl(bi.Main.Path, p.main.version)
+		if sub, ok := strings.CutPrefix(bi.Path, bi.Main.Path+"/"); ok {
+			p.main.purl += "#" + sub
+		}
+	}
+
+	// Package URLs of the standard library use semantic versions.
+	// Development and prerelease versions of Go have none.
+	p.stdlib = component{name: "stdlib", version: bi.GoVersion, purl: purl("stdlib", "")}
+	if v := gover.FromToolchain(bi.GoVersion); v != "" && !gover.IsPrerelease(v) && !gover.IsLang(v) {
+		p.stdlib.purl = purl("stdlib", "v"+v)
+	}
+
+	for _, d := range bi.Deps {
+		c := component{name: d.Path, version: moduleVersion(d.Version), sum: d.Sum}
+		if r := d.Replace; r != nil {
+			c.version = moduleVersion(r.Version)
+			c.sum = r.Sum
+			c.replace = r.Path
+			if r.Version != "" && r.Version != "(devel)" {
+				c.replace += "@" + r.Version
+			}
+			c.purl = purl(r.Path, c.version)
+		} else {
+			c.purl = purl(d.Path, c.version)
+		}
+		p.deps = append(p.deps, c)
+	}
+	return p
+}
+
+// moduleVersion returns the version of a module,
+// or "" if it has none, such as for a module in a local directory.
+func moduleVersion(v string) string {
+	if v == "(devel)" {
+		return ""
+	}
+	return v
+}
+
+// purl returns the package URL of the Go module with the given path and
+// version. The version is omitted if it is "".
+func purl(path, version string) string {
+	elems := strings.Split(path, "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	p := "pkg:golang/" + strings.Join(elems, "/")
+	if version != "" {
+		p += "@" + url.PathEscape(version)
+	}
+	return p
+}
+
+// setting returns the value of the named build setting, or "".
+func (p *program) setting(key string) string {
+	for _, s := range p.settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// uuid returns a UUID identifying the SBOM of p. It is a name-based
+// (version 5) UUID, derived from the build information, so that the
+// same program always gets the same UUID.
+func (p *program) uuid() string {
+	h := sha1.New()
+	// The namespace for URLs, from RFC 9562.
+	h.Write([]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8})
+	h.Write([]byte(p.bi.String()))
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
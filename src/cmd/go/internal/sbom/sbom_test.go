@@ -0,0 +1,123 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+var purlTests = []struct {
+	path, version, want string
+}{
+	{"rsc.io/quote", "v1.5.2", "pkg:golang/rsc.io/quote@v1.5.2"},
+	{"example.com/m", "", "pkg:golang/example.com/m"},
+	{"example.com/a b", "v1.0.0+incompatible", "pkg:golang/example.com/a%20b@v1.0.0+incompatible"},
+}
+
+func TestPURL(t *testing.T) {
+	for _, tt := range purlTests {
+		if got := purl(tt.path, tt.version); got != tt.want {
+			t.Errorf("purl(%q, %q) = %q, want %q", tt.path, tt.version, got, tt.want)
+		}
+	}
+}
+
+var testBuildInfo = &debug.BuildInfo{
+	GoVersion: "go1.26.1",
+	Path:      "example.com/m/cmd/hello",
+	Main:      debug.Module{Path: "example.com/m", Version: "(devel)"},
+	Deps: []*debug.Module{
+		{Path: "rsc.io/quote", Version: "v1.5.2", Sum: "h1:w5fcysjrx7yqtD/aO+QwRjYZOKnaM9Uh2b40tElTs3Y="},
+		{Path: "rsc.io/sampler", Version: "v1.3.0", Replace: &debug.Module{Path: "../sampler", Version: ""}},
+	},
+	Settings: []debug.BuildSetting{
+		{Key: "vcs", Value: "git"},
+		{Key: "vcs.revision", Value: "0123456789abcdef"},
+		{Key: "vcs.modified", Value: "true"},
+	},
+}
+
+func write(t *testing.T, format string, v any) {
+	t.Helper()
+	defer func(old func() time.Time) { now = old }(now)
+	now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	var buf bytes.Buffer
+	if err := Write(&buf, format, "go1.26.1", testBuildInfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), v); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.Bytes())
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	var doc spdxDocument
+	write(t, SPDX, &doc)
+
+	if doc.CreationInfo.Created != "2025-01-02T03:04:05Z" {
+		t.Errorf("created = %q, want 2025-01-02T03:04:05Z", doc.CreationInfo.Created)
+	}
+	if len(doc.Packages) != 4 {
+		t.Fatalf("got %d packages, want 4", len(doc.Packages))
+	}
+	main, stdlib, quote, sampler := doc.Packages[0], doc.Packages[1], doc.Packages[2], doc.Packages[3]
+	if want := "built from git revision 0123456789abcdef with uncommitted changes"; main.SourceInfo != want {
+		t.Errorf("main sourceInfo = %q, want %q", main.SourceInfo, want)
+	}
+	if want := "pkg:golang/example.com/m#cmd/hello"; len(main.ExternalRefs) != 1 || main.ExternalRefs[0].ReferenceLocator != want {
+		t.Errorf("main externalRefs = %v, want purl %s", main.ExternalRefs, want)
+	}
+	if want := "pkg:golang/stdlib@v1.26.1"; len(stdlib.ExternalRefs) != 1 || stdlib.ExternalRefs[0].ReferenceLocator != want {
+		t.Errorf("stdlib externalRefs = %v, want purl %s", stdlib.ExternalRefs, want)
+	}
+	if len(quote.Annotations) != 1 || !strings.HasPrefix(quote.Annotations[0].Comment, "go.sum hash h1:") {
+		t.Errorf("rsc.io/quote annotations = %v, want go.sum hash", quote.Annotations)
+	}
+	if sampler.VersionInfo != "" || sampler.Comment != "replaced by ../sampler" {
+		t.Errorf("rsc.io/sampler versionInfo, comment = %q, %q, want \"\", \"replaced by ../sampler\"", sampler.VersionInfo, sampler.Comment)
+	}
+	if len(doc.Relationships) != 4 {
+		t.Errorf("got %d relationships, want 4", len(doc.Relationships))
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	var bom cdxBOM
+	write(t, CycloneDX, &bom)
+
+	if len(bom.Components) != 3 {
+		t.Fatalf("got %d components, want 3", len(bom.Components))
+	}
+	if len(bom.Dependencies) != 1 || len(bom.Dependencies[0].DependsOn) != 3 {
+		t.Fatalf("dependencies = %v, want main depending on 3 components", bom.Dependencies)
+	}
+	if got, want := bom.Dependencies[0].Ref, bom.Metadata.Component.BOMRef; got != want {
+		t.Errorf("dependencies ref = %q, want %q", got, want)
+	}
+	refs := make(map[string]bool)
+	for _, c := range bom.Components {
+		if refs[c.BOMRef] {
+			t.Errorf("duplicate bom-ref %q", c.BOMRef)
+		}
+		refs[c.BOMRef] = true
+	}
+	if quote := bom.Components[1]; quote.Name != "rsc.io/quote" || len(quote.Properties) != 1 ||
+		quote.Properties[0].Name != "go:sum" || !strings.HasPrefix(quote.Properties[0].Value, "h1:") {
+		t.Errorf("rsc.io/quote component = %+v, want go:sum property", quote)
+	}
+
+	// The serial number is the same each time.
+	var bom2 cdxBOM
+	write(t, CycloneDX, &bom2)
+	if bom.SerialNumber != bom2.SerialNumber {
+		t.Errorf("serial numbers differ: %q, %q", bom.SerialNumber, bom2.SerialNumber)
+	}
+}
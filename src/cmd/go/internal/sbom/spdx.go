@@ -0,0 +1,149 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These types follow the SPDX 2.3 JSON schema:
+// https://spdx.github.io/spdx-spec/v2.3/.
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Annotations           []spdxAnnotation  `json:"annotations,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdx returns the SPDX document for p.
+func (p *program) spdx(tool string, created time.Time) *spdxDocument {
+	date := created.Format(time.RFC3339)
+	creator := "Tool: go-" + strings.TrimPrefix(tool, "go")
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              p.main.name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + pathID(p.main.name) + "-" + p.uuid(),
+		CreationInfo: spdxCreationInfo{
+			Created:  date,
+			Creators: []string{creator},
+		},
+	}
+
+	ids := make(map[string]bool)
+	annotate := func(sp *spdxPackage, comment string) {
+		sp.Annotations = append(sp.Annotations, spdxAnnotation{
+			AnnotationDate: date,
+			AnnotationType: "OTHER",
+			Annotator:      creator,
+			Comment:        comment,
+		})
+	}
+	add := func(c component, purpose string) spdxPackage {
+		id := "SPDXRef-Package-" + pathID(c.name)
+		for i := 2; ids[id]; i++ {
+			id = "SPDXRef-Package-" + pathID(c.name) + "-" + strconv.Itoa(i)
+		}
+		ids[id] = true
+		sp := spdxPackage{
+			SPDXID:                id,
+			Name:                  c.name,
+			VersionInfo:           c.version,
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: purpose,
+		}
+		if c.replace != "" {
+			sp.Comment = "replaced by " + c.replace
+		}
+		if c.sum != "" {
+			// The go.sum hash is not a checksum of any file that SPDX
+			// could describe, so it is recorded as an annotation.
+			annotate(&sp, "go.sum hash "+c.sum)
+		}
+		if c.purl != "" {
+			sp.ExternalRefs = []spdxExternalRef{{"PACKAGE-MANAGER", "purl", c.purl}}
+		}
+		return sp
+	}
+	relate := func(from, typ, to string) {
+		doc.Relationships = append(doc.Relationships, spdxRelationship{from, typ, to})
+	}
+
+	main := add(p.main, "APPLICATION")
+	if rev := p.setting("vcs.revision"); rev != "" {
+		main.SourceInfo = "built from " + p.setting("vcs") + " revision " + rev
+		if p.setting("vcs.modified") == "true" {
+			main.SourceInfo += " with uncommitted changes"
+		}
+	}
+	for _, s := range p.settings {
+		annotate(&main, "build setting "+s.Key+"="+s.Value)
+	}
+	doc.Packages = append(doc.Packages, main)
+	relate("SPDXRef-DOCUMENT", "DESCRIBES", main.SPDXID)
+
+	for _, c := range append([]component{p.stdlib}, p.deps...) {
+		dep := add(c, "LIBRARY")
+		doc.Packages = append(doc.Packages, dep)
+		relate(main.SPDXID, "DEPENDS_ON", dep.SPDXID)
+	}
+	return doc
+}
+
+// pathID returns a form of path that can be used in an SPDX identifier,
+// which may contain only letters, numbers, '.' and '-'.
+func pathID(path string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, path)
+}
@@ -22,6 +22,7 @@ var passFlagToTest = map[string]bool{
 	"failfast":             true,
 	"fullpath":             true,
 	"fuzz":                 true,
+	"fuzzmerge":            true,
 	"fuzzminimizetime":     true,
 	"fuzztime":             true,
 	"list":                 true,
@@ -31,12 +32,16 @@ var passFlagToTest = map[string]bool{
 	"mutexprofilefraction": true,
 	"outputdir":            true,
 	"parallel":             true,
+	"retry":                true,
 	"run":                  true,
+	"shard":                true,
 	"short":                true,
 	"shuffle":              true,
 	"skip":                 true,
+	"testtimeout":          true,
 	"timeout":              true,
 	"trace":                true,
+	"update-golden":        true,
 	"v":                    true,
 }
 
@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"internal/coverage"
 	"internal/platform"
 	"io"
@@ -128,7 +129,7 @@ The rule for a match in the cache is that the run involves the same
 test binary and the flags on the command line come entirely from a
 restricted set of 'cacheable' test flags, defined as -benchtime,
 -coverprofile, -cpu, -failfast, -fullpath, -list, -outputdir, -parallel,
--run, -short, -skip, -timeout and -v.
+-run, -shard, -short, -skip, -testtimeout, -timeout and -v.
 If a run of go test has any test or non-test flags outside this set,
 the result is not cached. To disable test caching, use any test flag
 or argument other than the cacheable flags. The idiomatic way to disable
@@ -156,6 +157,14 @@ In addition to the build flags, the flags handled by 'go test' itself are:
 	    Run the test binary using xprog. The behavior is the same as
 	    in 'go run'. See 'go help run' for details.
 
+	-format format
+	    Convert test output to a report of the tests in the given format:
+	    junit for JUnit XML, or tap for TAP version 14. Like -json, this
+	    implies -v. The JUnit report is written once all tests have run;
+	    the TAP report is written one package at a time.
+	    See 'go doc test2json' for the details of these formats.
+	    Build output is not converted. Cannot be used with -json.
+
 	-json
 	    Convert test output to JSON suitable for automated processing.
 	    See 'go doc test2json' for the encoding details.
@@ -167,6 +176,11 @@ In addition to the build flags, the flags handled by 'go test' itself are:
 	    If file ends in a slash or names an existing directory,
 	    the test is written to pkg.test in that directory.
 
+	-shardpackages
+	    Make -shard split the packages to test between shards, rather than
+	    the tests within each package. Each package is tested in full
+	    in exactly one shard.
+
 The test binary also accepts flags that control execution of the test; these
 flags are also accessible by 'go test'. See 'go help testflag' for details.
 
@@ -258,19 +272,23 @@ control the execution of any test:
 	    Show full file names in the error messages.
 
 	-fuzz regexp
-	    Run the fuzz test matching the regular expression. When specified,
-	    the command line argument must match exactly one package within the
-	    main module, and regexp must match exactly one fuzz test within
-	    that package. Fuzzing will occur after tests, benchmarks, seed corpora
-	    of other fuzz tests, and examples have completed. See the Fuzzing
-	    section of the testing package documentation for details.
+	    Run the fuzz tests matching the regular expression. When specified,
+	    the command line arguments must match only packages within the
+	    main module. Fuzzing will occur after tests, benchmarks, seed corpora
+	    of other fuzz tests, and examples have completed. If regexp matches
+	    more than one fuzz test in a package, those fuzz tests are fuzzed
+	    concurrently, sharing the worker processes allowed by -parallel.
+	    Packages are fuzzed one at a time. See the Fuzzing section of the
+	    testing package documentation for details.
 
 	-fuzztime t
 	    Run enough iterations of the fuzz target during fuzzing to take t,
 	    specified as a time.Duration (for example, -fuzztime 1h30s).
 		The default is to run forever.
+	    When more than one fuzz test is fuzzed, t is the total time to
+	    spend fuzzing, split evenly between the matching fuzz tests.
 	    The special syntax Nx means to run the fuzz target N times
-	    (for example, -fuzztime 1000x).
+	    (for example, -fuzztime 1000x). This applies to each fuzz test.
 
 	-fuzzminimizetime t
 	    Run enough iterations of the fuzz target during each minimization
@@ -280,6 +298,19 @@ control the execution of any test:
 	    The special syntax Nx means to run the fuzz target N times
 	    (for example, -fuzzminimizetime 100x).
 
+	-fuzzmerge list
+	    Instead of fuzzing, add entries from other corpora to the seed
+	    corpus of each fuzz test matched by -fuzz. The list is a
+	    comma-separated list of directories that, like testdata/fuzz, hold
+	    the corpus of each fuzz test in a subdirectory named after it.
+	    The special name "cache" refers to the corpus generated by fuzzing
+	    and stored in the build cache. Each entry is run once, and entries
+	    are added, smallest first, only if they reach code not reached by
+	    the seed corpus or by entries added before them. Added entries are
+	    minimized as for -fuzzminimizetime, preserving the new coverage,
+	    and written to testdata/fuzz. Existing seed corpus entries are not
+	    changed.
+
 	-json
 	    Log verbose output and test results in JSON. This presents the
 	    same information as the -v flag in a machine-readable format.
@@ -310,6 +341,19 @@ control the execution of any test:
 	    in parallel as well, according to the setting of the -p flag
 	    (see 'go help build').
 
+	-retry n
+	    Run each failing test up to n more times, each time with a fresh
+	    *testing.T, until it passes. A test that fails and then passes
+	    when retried is reported as flaky rather than as passing or failing,
+	    and does not cause the package's tests to fail. Only top-level
+	    tests are retried, together with all of their subtests.
+	    A test can also mark itself as known to be flaky by calling
+	    t.Flaky, in which case it is retried at least once if it fails.
+	    With -json, each failed attempt is reported by a "retry" event,
+	    and the final result of a flaky test by a "flaky" event.
+	    Examples, benchmarks, and fuzz tests are not retried.
+	    The output of flaky tests is printed even without -v.
+
 	-run regexp
 	    Run only those tests, examples, and fuzz tests matching the regular
 	    expression. For tests, the regular expression is split by unbracketed
@@ -321,6 +365,16 @@ control the execution of any test:
 	    because it must run them to look for those sub-tests.
 	    See also -skip.
 
+	-shard i/n
+	    Split the top-level tests, examples, and fuzz tests of each package
+	    into n shards, and run only those in shard i, for i from 1 to n.
+	    Running every shard, for example on n different machines, runs
+	    each test exactly once. A test's shard depends only on its name,
+	    so it does not change as other tests are added or removed, and
+	    -run and -skip select tests within the shard. Benchmarks and
+	    fuzzing are not affected. See also -shardpackages.
+	    With -json, each event records the shard.
+
 	-short
 	    Tell long-running tests to shorten their run time.
 	    It is off by default but set during all.bash so that installing
@@ -342,11 +396,23 @@ control the execution of any test:
 	    part of a test's identifier must match the corresponding element in
 	    the sequence, if any.
 
+	-testtimeout d
+	    If a top-level test runs longer than duration d, fail it,
+	    print the stack traces of its goroutines, and cancel its context.
+	    Unlike -timeout, the other tests continue to run. A test can set
+	    its own timeout with t.SetTimeout. If d is 0, the default, there
+	    is no per-test timeout.
+
 	-timeout d
 	    If a test binary runs longer than duration d, panic.
 	    If d is 0, the timeout is disabled.
 	    The default is 10 minutes (10m).
 
+	-update-golden
+	    Write the golden files of tests, instead of comparing them with
+	    the output of the tests. See the Golden method of testing.T.
+	    Test results are never cached with -update-golden.
+
 	-v
 	    Verbose output: log all tests as they are run. Also print all
 	    text from Log and Logf calls even if the test succeeds.
@@ -550,11 +616,15 @@ var (
 	testCoverPkgs    []*load.Package                   // -coverpkg flag
 	testCoverProfile string                            // -coverprofile flag
 	testFailFast     bool                              // -failfast flag
+	testFormat       formatFlag                        // -format flag
 	testFuzz         string                            // -fuzz flag
+	testFuzzMerge    fuzzMergeFlag                     // -fuzzmerge flag
 	testJSON         bool                              // -json flag
 	testList         string                            // -list flag
 	testO            string                            // -o flag
 	testOutputDir    outputdirFlag                     // -outputdir flag
+	testShard        string                            // -shard flag
+	testShardPkgs    bool                              // -shardpackages flag
 	testShuffle      shuffleFlag                       // -shuffle flag
 	testTimeout      time.Duration                     // -timeout flag
 	testV            testVFlag                         // -v flag
@@ -717,14 +787,27 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 	if len(pkgs) == 0 {
 		base.Fatalf("no packages to test")
 	}
+	if testShardPkgs {
+		if testShard == "" {
+			base.Fatalf("cannot use -shardpackages flag without -shard flag")
+		}
+		pkgs = shardPackages(pkgs)
+		if len(pkgs) == 0 {
+			return
+		}
+		// The packages in this shard are tested in full.
+		testArgs = slices.DeleteFunc(testArgs, func(arg string) bool {
+			return strings.HasPrefix(arg, "-test.shard=")
+		})
+	}
 
+	if len(testFuzzMerge.dirs) > 0 && testFuzz == "" {
+		base.Fatalf("cannot use -fuzzmerge flag without -fuzz flag")
+	}
 	if testFuzz != "" {
 		if !platform.FuzzSupported(cfg.Goos, cfg.Goarch) {
 			base.Fatalf("-fuzz flag is not supported on %s/%s", cfg.Goos, cfg.Goarch)
 		}
-		if len(pkgs) != 1 {
-			base.Fatalf("cannot use -fuzz flag with multiple packages")
-		}
 		if testCoverProfile != "" {
 			base.Fatalf("cannot use -coverprofile flag with -fuzz flag")
 		}
@@ -732,34 +815,36 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 			base.Fatalf("cannot use %s flag with -fuzz flag", profileFlag)
 		}
 
-		// Reject the '-fuzz' flag if the package is outside the main module.
+		// Reject the '-fuzz' flag if any package is outside the main module.
 		// Otherwise, if fuzzing identifies a failure it could corrupt checksums in
 		// the module cache (or permanently alter the behavior of std tests for all
 		// users) by writing the failing input to the package's testdata directory.
 		// (See https://golang.org/issue/48495 and test_fuzz_modcache.txt.)
 		mainMods := moduleLoaderState.MainModules
-		if m := pkgs[0].Module; m != nil && m.Path != "" {
-			if !mainMods.Contains(m.Path) {
-				base.Fatalf("cannot use -fuzz flag on package outside the main module")
-			}
-		} else if pkgs[0].Standard && moduleLoaderState.Enabled() {
-			// Because packages in 'std' and 'cmd' are part of the standard library,
-			// they are only treated as part of a module in 'go mod' subcommands and
-			// 'go get'. However, we still don't want to accidentally corrupt their
-			// testdata during fuzzing, nor do we want to fail with surprising errors
-			// if GOROOT isn't writable (as is often the case for Go toolchains
-			// installed through package managers).
-			//
-			// If the user is requesting to fuzz a standard-library package, ensure
-			// that they are in the same module as that package (just like when
-			// fuzzing any other package).
-			if strings.HasPrefix(pkgs[0].ImportPath, "cmd/") {
-				if !mainMods.Contains("cmd") || !mainMods.InGorootSrc(module.Version{Path: "cmd"}) {
+		for _, p := range pkgs {
+			if m := p.Module; m != nil && m.Path != "" {
+				if !mainMods.Contains(m.Path) {
 					base.Fatalf("cannot use -fuzz flag on package outside the main module")
 				}
-			} else {
-				if !mainMods.Contains("std") || !mainMods.InGorootSrc(module.Version{Path: "std"}) {
-					base.Fatalf("cannot use -fuzz flag on package outside the main module")
+			} else if p.Standard && moduleLoaderState.Enabled() {
+				// Because packages in 'std' and 'cmd' are part of the standard library,
+				// they are only treated as part of a module in 'go mod' subcommands and
+				// 'go get'. However, we still don't want to accidentally corrupt their
+				// testdata during fuzzing, nor do we want to fail with surprising errors
+				// if GOROOT isn't writable (as is often the case for Go toolchains
+				// installed through package managers).
+				//
+				// If the user is requesting to fuzz a standard-library package, ensure
+				// that they are in the same module as that package (just like when
+				// fuzzing any other package).
+				if strings.HasPrefix(p.ImportPath, "cmd/") {
+					if !mainMods.Contains("cmd") || !mainMods.InGorootSrc(module.Version{Path: "cmd"}) {
+						base.Fatalf("cannot use -fuzz flag on package outside the main module")
+					}
+				} else {
+					if !mainMods.Contains("std") || !mainMods.InGorootSrc(module.Version{Path: "std"}) {
+						base.Fatalf("cannot use -fuzz flag on package outside the main module")
+					}
 				}
 			}
 		}
@@ -1007,6 +1092,19 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 		}
 	}
 
+	if testFormat != "" {
+		f, err := test2json.NewFormatter(os.Stdout, string(testFormat))
+		if err != nil {
+			base.Fatal(err)
+		}
+		testFormatter = f
+		defer func() {
+			if err := f.Close(); err != nil {
+				base.Errorf("go: writing -format report: %v", err)
+			}
+		}()
+	}
+
 	// Prepare build + run + print actions for all packages being tested.
 	for _, p := range pkgs {
 		reportErr := func(perr *load.Package, err error) {
@@ -1020,7 +1118,7 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 		reportSetupFailed := func(perr *load.Package, err error) {
 			var stdout io.Writer = os.Stdout
 			if testJSON {
-				json := test2json.NewConverter(stdout, p.ImportPath, test2json.Timestamp)
+				json := test2json.NewShardConverter(lockedStdout{}, p.ImportPath, testShard, test2json.Timestamp)
 				defer func() {
 					json.Exited(err)
 					json.Close()
@@ -1096,6 +1194,10 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 		}
 	}
 
+	if testFuzz != "" {
+		splitFuzzTime(runs)
+	}
+
 	// Ultimately the goal is to print the output.
 	root := &work.Action{Mode: "go test", Actor: work.ActorFunc(printExitStatus), Deps: prints}
 
@@ -1107,8 +1209,8 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 		}
 	}
 
-	// Force benchmarks to run in serial.
-	if !testC && (testBench != "") {
+	// Force benchmarks and fuzzing to run in serial.
+	if !testC && (testBench != "" || testFuzz != "") {
 		// The first run must wait for all builds.
 		// Later runs must wait for the previous run's print.
 		for i, run := range runs {
@@ -1302,6 +1404,7 @@ func builderTest(loaderstate *modload.State, b *work.Builder, ctx context.Contex
 		// run test
 		rta := &runTestActor{
 			writeCoverMetaAct: writeCoverMetaAct,
+			fuzzTargets:       pmain.Internal.FuzzTargets,
 		}
 		runAction = &work.Action{
 			Mode:       "test run",
@@ -1384,7 +1487,7 @@ func addTestVet(loaderstate *modload.State, b *work.Builder, p *load.Package, ru
 
 var noTestsToRun = []byte("\ntesting: warning: no tests to run\n")
 var noFuzzTestsToFuzz = []byte("\ntesting: warning: no fuzz tests to fuzz\n")
-var tooManyFuzzTestsToFuzz = []byte("\ntesting: warning: -fuzz matches more than one fuzz test, won't fuzz\n")
+var flakyTest = []byte("\n--- FLAKY: ")
 
 // runTestActor is the actor for running a test.
 type runTestActor struct {
@@ -1399,6 +1502,12 @@ type runTestActor struct {
 	// sequencing of json start messages, to preserve test order
 	prev <-chan struct{} // wait to start until prev is closed
 	next chan<- struct{} // close next once the next test can start.
+
+	// fuzzTargets lists the fuzz tests in the package, and fuzzTime, if
+	// non-empty, overrides -fuzztime for this package's share of the time
+	// budget when fuzzing several packages.
+	fuzzTargets []string
+	fuzzTime    string
 }
 
 // runCache is the cache for running a single test.
@@ -1410,6 +1519,74 @@ type runCache struct {
 	id2 cache.ActionID
 }
 
+// shardPackages returns the packages in pkgs that belong to the shard
+// selected by the -shard flag. As in the testing package, which shards
+// tests by name, a package's shard is determined by a hash of its import
+// path.
+func shardPackages(pkgs []*load.Package) []*load.Package {
+	is, ns, _ := strings.Cut(testShard, "/")
+	i, err1 := strconv.Atoi(is)
+	n, err2 := strconv.Atoi(ns)
+	if err1 != nil || err2 != nil || i < 1 || i > n {
+		base.Fatalf("invalid -shard %q: must be of the form i/n, with 1 <= i <= n", testShard)
+	}
+	var shard []*load.Package
+	for _, p := range pkgs {
+		h := fnv.New32a()
+		h.Write([]byte(p.ImportPath))
+		if int(h.Sum32()%uint32(n)) == i-1 {
+			shard = append(shard, p)
+		}
+	}
+	return shard
+}
+
+// splitFuzzTime divides the -fuzztime duration between the packages in runs
+// in proportion to the number of their fuzz tests matching -fuzz, so that
+// fuzzing all of them, one package after another, takes about as long as
+// requested. A -fuzztime count applies to each fuzz test and is not split.
+func splitFuzzTime(runs []*work.Action) {
+	var d time.Duration
+	for _, arg := range testArgs {
+		if v, ok := strings.CutPrefix(arg, "-test.fuzztime="); ok {
+			d, _ = time.ParseDuration(v)
+		}
+	}
+	if d <= 0 {
+		return
+	}
+
+	// Fuzz tests have no subtests, so only the first element of the
+	// pattern can match. If the pattern is invalid, the test binary
+	// reports the error.
+	match := func(string) bool { return true }
+	first, _, _ := strings.Cut(testFuzz, "/")
+	if re, err := regexp.Compile(first); err == nil {
+		match = re.MatchString
+	}
+
+	counts := make(map[*runTestActor]int)
+	total := 0
+	for _, a := range runs {
+		r, ok := a.Actor.(*runTestActor)
+		if !ok {
+			continue
+		}
+		for _, name := range r.fuzzTargets {
+			if match(name) {
+				counts[r]++
+				total++
+			}
+		}
+	}
+	if len(counts) < 2 {
+		return
+	}
+	for r, n := range counts {
+		r.fuzzTime = (d * time.Duration(n) / time.Duration(total)).String()
+	}
+}
+
 func coverProfTempFile(a *work.Action) string {
 	if a.Objdir == "" {
 		panic("internal error: objdir not set in coverProfTempFile")
@@ -1422,6 +1599,7 @@ func coverProfTempFile(a *work.Action) string {
 // goroutines, so that we can have multiple JSON streams writing
 // to a lockedStdout simultaneously and know that events will
 // still be intelligible.
+// With -format, the JSON events are written to testFormatter instead.
 var stdoutMu sync.Mutex
 
 type lockedStdout struct{}
@@ -1429,9 +1607,15 @@ type lockedStdout struct{}
 func (lockedStdout) Write(b []byte) (int, error) {
 	stdoutMu.Lock()
 	defer stdoutMu.Unlock()
+	if testFormatter != nil {
+		return testFormatter.Write(b)
+	}
 	return os.Stdout.Write(b)
 }
 
+// testFormatter converts the JSON test events to the report for -format.
+var testFormatter *test2json.Formatter
+
 func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action) error {
 	sh := b.Shell(a)
 	barrierAction := a.Deps[0]
@@ -1478,7 +1662,7 @@ func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action)
 	var err error
 	var json *test2json.Converter
 	if testJSON {
-		json = test2json.NewConverter(lockedStdout{}, a.Package.ImportPath, test2json.Timestamp)
+		json = test2json.NewShardConverter(lockedStdout{}, a.Package.ImportPath, testShard, test2json.Timestamp)
 		defer func() {
 			json.Exited(err)
 			json.Close()
@@ -1619,6 +1803,14 @@ func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action)
 	}
 	args := str.StringList(execCmd, buildAction.BuiltTarget(), testlogArg, panicArg, fuzzArg, coverdirArg, testArgs)
 
+	if r.fuzzTime != "" {
+		for i, arg := range args {
+			if strings.HasPrefix(arg, "-test.fuzztime=") {
+				args[i] = "-test.fuzztime=" + r.fuzzTime
+			}
+		}
+	}
+
 	if testCoverProfile != "" {
 		// Write coverage to temporary profile, for merging later.
 		for i, arg := range args {
@@ -1710,7 +1902,9 @@ func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action)
 
 	if err == nil {
 		norun := ""
-		if !testShowPass() && !testJSON {
+		// Show the output of flaky tests, so that they don't go unnoticed.
+		flaky := bytes.HasPrefix(out, flakyTest[1:]) || bytes.Contains(out, flakyTest)
+		if !testShowPass() && !testJSON && !flaky {
 			buf.Reset()
 		}
 		if bytes.HasPrefix(out, noTestsToRun[1:]) || bytes.Contains(out, noTestsToRun) {
@@ -1719,9 +1913,6 @@ func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action)
 		if bytes.HasPrefix(out, noFuzzTestsToFuzz[1:]) || bytes.Contains(out, noFuzzTestsToFuzz) {
 			norun = " [no fuzz tests to fuzz]"
 		}
-		if bytes.HasPrefix(out, tooManyFuzzTestsToFuzz[1:]) || bytes.Contains(out, tooManyFuzzTestsToFuzz) {
-			norun = "[-fuzz matches more than one fuzz test, won't fuzz]"
-		}
 		if len(out) > 0 && !bytes.HasSuffix(out, []byte("\n")) {
 			// Ensure that the output ends with a newline before the "ok"
 			// line we're about to print (https://golang.org/issue/49317).
@@ -1816,8 +2007,10 @@ func (c *runCache) tryCacheWithID(b *work.Builder, a *work.Action, id string) bo
 			"-test.list",
 			"-test.parallel",
 			"-test.run",
+			"-test.shard",
 			"-test.short",
 			"-test.skip",
+			"-test.testtimeout",
 			"-test.timeout",
 			"-test.failfast",
 			"-test.v",
@@ -2216,13 +2409,8 @@ func builderPrintTest(b *work.Builder, ctx context.Context, a *work.Action) erro
 // we assume that the test output is being parsed by a tool
 // anyway, so the failure will not be missed and would be
 // awkward to try to wedge into the JSON stream.
-//
-// In fuzz mode, we only allow a single package for now
-// (see CL 350156 and https://golang.org/issue/46312),
-// so there is no possibility of scrolling off and no need
-// to print the final status.
 func printExitStatus(b *work.Builder, ctx context.Context, a *work.Action) error {
-	if !testJSON && testFuzz == "" && len(pkgArgs) != 0 {
+	if !testJSON && len(pkgArgs) != 0 {
 		if base.GetExitStatus() != 0 {
 			fmt.Println("FAIL")
 			return nil
@@ -38,6 +38,8 @@ func init() {
 	work.AddCoverFlags(CmdTest, &testCoverProfile)
 	cf.Var((*base.StringsFlag)(&work.ExecCmd), "exec", "")
 	cf.BoolVar(&testJSON, "json", false, "")
+	cf.Var(&testFormat, "format", "")
+	cf.BoolVar(&testShardPkgs, "shardpackages", false, "")
 	cf.Var(&testVet, "vet", "")
 
 	// Register flags to be forwarded to the test binary. We retain variables for
@@ -63,13 +65,18 @@ func init() {
 	cf.String("mutexprofilefraction", "", "")
 	cf.Var(&testOutputDir, "outputdir", "")
 	cf.Int("parallel", 0, "")
+	cf.Int("retry", 0, "")
 	cf.String("run", "", "")
+	cf.StringVar(&testShard, "shard", "", "")
 	cf.Bool("short", false, "")
 	cf.String("skip", "", "")
 	cf.DurationVar(&testTimeout, "timeout", 10*time.Minute, "") // known to cmd/dist
+	cf.Duration("testtimeout", 0, "")
 	cf.String("fuzztime", "", "")
 	cf.String("fuzzminimizetime", "", "")
+	cf.Var(&testFuzzMerge, "fuzzmerge", "")
 	cf.StringVar(&testTrace, "trace", "", "")
+	cf.Bool("update-golden", false, "")
 	cf.Var(&testV, "v", "")
 	cf.Var(&testShuffle, "shuffle", "")
 
@@ -106,6 +113,41 @@ func (f *outputdirFlag) getAbs() string {
 	return f.abs
 }
 
+// fuzzMergeFlag implements the -fuzzmerge flag: a comma-separated list of
+// corpus directories, with the distinguished value "cache". Directories are
+// made absolute, since the test binary runs in the package directory.
+type fuzzMergeFlag struct {
+	dirs []string
+}
+
+func (f *fuzzMergeFlag) String() string {
+	return strings.Join(f.dirs, ",")
+}
+
+func (f *fuzzMergeFlag) Set(value string) error {
+	if value == "" {
+		f.dirs = nil
+		return nil
+	}
+	var dirs []string
+	for dir := range strings.SplitSeq(value, ",") {
+		switch dir {
+		case "":
+			return fmt.Errorf("-fuzzmerge argument contains empty list element")
+		case "cache":
+		default:
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return err
+			}
+			dir = abs
+		}
+		dirs = append(dirs, dir)
+	}
+	f.dirs = dirs
+	return nil
+}
+
 // vetFlag implements the special parsing logic for the -vet flag:
 // a comma-separated list, with distinguished values "all" and
 // "off", plus a boolean tracking whether it was set explicitly.
@@ -181,6 +223,22 @@ func (f *vetFlag) Set(value string) error {
 	return nil
 }
 
+// formatFlag is the -format flag: the format of the report of the tests
+// converted from their JSON output.
+type formatFlag string
+
+func (f *formatFlag) String() string { return string(*f) }
+
+func (f *formatFlag) Set(value string) error {
+	switch value {
+	case "junit", "tap":
+	default:
+		return fmt.Errorf(`-format argument must be "junit" or "tap"`)
+	}
+	*f = formatFlag(value)
+	return nil
+}
+
 type shuffleFlag struct {
 	on   bool
 	seed *int64
@@ -348,6 +406,16 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 		exitWithUsage()
 	}
 
+	if testFormat != "" {
+		if testJSON {
+			fmt.Fprintf(os.Stderr, "go: cannot use -format flag with -json flag\n")
+			exitWithUsage()
+		}
+		// The report is converted from the JSON test output,
+		// but the build output is left alone.
+		testJSON = true
+	}
+
 	var injectedFlags []string
 	if testJSON {
 		// If converting to JSON, we need the full output in order to pipe it to test2json.
@@ -358,7 +426,9 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 		delete(addFromGOFLAGS, "v")
 		delete(addFromGOFLAGS, "test.v")
 
-		if gotestjsonbuildtext.Value() == "1" {
+		if testFormat != "" {
+			// Leave the build output as text.
+		} else if gotestjsonbuildtext.Value() == "1" {
 			gotestjsonbuildtext.IncNonDefault()
 		} else {
 			cfg.BuildJSON = true
@@ -47,6 +47,12 @@ With no arguments it prints the list of known tools.
 The -n flag causes tool to print the command that would be
 executed but not execute it.
 
+In a module with a vendor directory, 'go tool' runs the prebuilt tool
+binaries stored by 'go mod vendor -tools' instead of building the tools,
+as long as they match the current toolchain, platform, build
+configuration, and sources, and the hashes recorded for them in
+vendor/modules.txt. See 'go help mod vendor'.
+
 The -modfile=file.mod build flag causes tool to use an alternate file
 instead of the go.mod in the module root directory.
 
@@ -311,20 +317,19 @@ func buildAndRunBuiltinTool(loaderstate *modload.State, ctx context.Context, too
 	// user happens to be in.
 	loaderstate.RootMode = modload.NoRoot
 
-	runFunc := func(b *work.Builder, ctx context.Context, a *work.Action) error {
-		cmdline := str.StringList(builtTool(a), a.Args)
-		return runBuiltTool(toolName, nil, cmdline)
+	run := func(exe string, args []string) error {
+		return runBuiltTool(toolName, nil, str.StringList(exe, args))
 	}
 
-	buildAndRunTool(loaderstate, ctx, tool, args, runFunc)
+	buildAndRunTool(loaderstate, ctx, tool, args, "", run)
 }
 
 func buildAndRunModtool(loaderstate *modload.State, ctx context.Context, toolName, tool string, args []string) {
-	runFunc := func(b *work.Builder, ctx context.Context, a *work.Action) error {
+	run := func(exe string, args []string) error {
 		// Use the ExecCmd to run the binary, as go run does. ExecCmd allows users
 		// to provide a runner to run the binary, for example a simulator for binaries
 		// that are cross-compiled to a different platform.
-		cmdline := str.StringList(work.FindExecCmd(), builtTool(a), a.Args)
+		cmdline := str.StringList(work.FindExecCmd(), exe, args)
 		// Use same environment go run uses to start the executable:
 		// the original environment with cfg.GOROOTbin added to the path.
 		env := slices.Clip(cfg.OrigEnv)
@@ -333,10 +338,28 @@ func buildAndRunModtool(loaderstate *modload.State, ctx context.Context, toolNam
 		return runBuiltTool(toolName, env, cmdline)
 	}
 
-	buildAndRunTool(loaderstate, ctx, tool, args, runFunc)
+	// 'go mod vendor -tools' stores prebuilt tool binaries in the vendor directory.
+	vendorDir := ""
+	if cfg.BuildMod == "vendor" {
+		dir := modload.VendorDir(loaderstate)
+		if _, err := os.Stat(work.ToolStoreDir(dir)); err == nil {
+			vendorDir = dir
+			// The stored binaries are built with -trimpath so that
+			// they do not depend on the location of the module.
+			// Build the tool the same way if it is not in the store.
+			cfg.BuildTrimpath = true
+		}
+	}
+
+	buildAndRunTool(loaderstate, ctx, tool, args, vendorDir, run)
 }
 
-func buildAndRunTool(loaderstate *modload.State, ctx context.Context, tool string, args []string, runTool work.ActorFunc) {
+// buildAndRunTool builds the tool and runs it with args using run.
+// If vendorDir is not empty, it is a vendor directory with a store of
+// prebuilt tool binaries: if the store holds a binary of the tool matching
+// the current sources and build configuration, and the hash recorded for
+// it in vendor/modules.txt, buildAndRunTool runs that instead of building.
+func buildAndRunTool(loaderstate *modload.State, ctx context.Context, tool string, args []string, vendorDir string, run func(exe string, args []string) error) {
 	work.BuildInit(loaderstate)
 	b := work.NewBuilder("", loaderstate.VendorDirOrEmpty)
 	defer func() {
@@ -347,12 +370,21 @@ func buildAndRunTool(loaderstate *modload.State, ctx context.Context, tool strin
 
 	pkgOpts := load.PackageOpts{MainOnly: true}
 	p := load.PackagesAndErrors(loaderstate, ctx, pkgOpts, []string{tool})[0]
-	p.Internal.OmitDebug = true
-	p.Internal.ExeName = p.DefaultExecName()
+	work.PrepareTool(p)
+
+	if vendorDir != "" && p.Error == nil && len(p.DepsErrors) == 0 {
+		if exe := b.StoredTool(loaderstate, vendorDir, p); exe != "" {
+			run(exe, args)
+			return
+		}
+	}
 
 	a1 := b.LinkAction(loaderstate, work.ModeBuild, work.ModeBuild, p)
 	a1.CacheExecutable = true
-	a := &work.Action{Mode: "go tool", Actor: runTool, Args: args, Deps: []*work.Action{a1}}
+	runTool := func(b *work.Builder, ctx context.Context, a *work.Action) error {
+		return run(builtTool(a), a.Args)
+	}
+	a := &work.Action{Mode: "go tool", Actor: work.ActorFunc(runTool), Args: args, Deps: []*work.Action{a1}}
 	b.Do(ctx, a)
 }
 
@@ -15,14 +15,16 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/gover"
+	"cmd/go/internal/sbom"
 )
 
 var CmdVersion = &base.Command{
-	UsageLine: "go version [-m] [-v] [-json] [file ...]",
+	UsageLine: "go version [-m] [-v] [-json] [-sbom[=format]] [file ...]",
 	Short:     "print Go version",
 	Long: `Version prints the build information for Go binary files.
 
@@ -44,6 +46,12 @@ indented by a leading tab character.
 The -json flag is similar to -m but outputs the runtime/debug.BuildInfo in JSON format.
 If flag -json is specified without -m, go version reports an error.
 
+The -sbom flag is similar to -json but outputs a software bill of materials
+(SBOM) derived from the build information: SPDX 2.3 JSON by default or
+with -sbom=spdx, and CycloneDX 1.5 JSON with -sbom=cyclonedx.
+If flag -sbom is specified without -m, go version reports an error.
+See 'go help mod sbom' for the contents of the SBOM.
+
 See also: go doc runtime/debug.BuildInfo.
 `,
 }
@@ -57,8 +65,36 @@ var (
 	versionM    = CmdVersion.Flag.Bool("m", false, "")
 	versionV    = CmdVersion.Flag.Bool("v", false, "")
 	versionJson = CmdVersion.Flag.Bool("json", false, "")
+	versionSBOM sbomFlag
 )
 
+func init() {
+	CmdVersion.Flag.Var(&versionSBOM, "sbom", "")
+}
+
+// sbomFlag is the -sbom flag, which may be given a format or used alone
+// for the default format.
+type sbomFlag string
+
+func (f *sbomFlag) Set(s string) error {
+	if v, err := strconv.ParseBool(s); err == nil {
+		*f = ""
+		if v {
+			*f = sbom.SPDX
+		}
+		return nil
+	}
+	if err := sbom.CheckFormat(s); err != nil {
+		return err
+	}
+	*f = sbomFlag(s)
+	return nil
+}
+
+func (f *sbomFlag) String() string { return string(*f) }
+
+func (f *sbomFlag) IsBoolFlag() bool { return true }
+
 func runVersion(ctx context.Context, cmd *base.Command, args []string) {
 	if len(args) == 0 {
 		// If any of this command's flags were passed explicitly, error
@@ -78,6 +114,8 @@ func runVersion(ctx context.Context, cmd *base.Command, args []string) {
 			// it reports 'no arguments' issue only because that error will be reported
 			// once the 'no arguments' issue is fixed by users.
 			argOnlyFlag = "-json"
+		} else if !base.InGOFLAGS("-sbom") && versionSBOM != "" {
+			argOnlyFlag = "-sbom"
 		}
 		if argOnlyFlag != "" {
 			fmt.Fprintf(os.Stderr, "go: 'go version' only accepts %s flag with arguments\n", argOnlyFlag)
@@ -97,6 +135,16 @@ func runVersion(ctx context.Context, cmd *base.Command, args []string) {
 		base.SetExitStatus(2)
 		return
 	}
+	if !*versionM && versionSBOM != "" {
+		fmt.Fprintf(os.Stderr, "go: 'go version' with -sbom flag requires -m flag\n")
+		base.SetExitStatus(2)
+		return
+	}
+	if *versionJson && versionSBOM != "" {
+		fmt.Fprintf(os.Stderr, "go: 'go version' cannot use -json and -sbom flags together\n")
+		base.SetExitStatus(2)
+		return
+	}
 
 	for _, arg := range args {
 		info, err := os.Stat(arg)
@@ -183,6 +231,17 @@ func scanFile(file string, info fs.FileInfo, mustPrint bool) bool {
 		return false
 	}
 
+	if *versionM && versionSBOM != "" {
+		tool := runtime.Version()
+		if gover.TestVersion != "" {
+			tool = gover.TestVersion
+		}
+		if err := sbom.Write(os.Stdout, string(versionSBOM), tool, bi); err != nil {
+			base.Fatal(err)
+		}
+		return true
+	}
+
 	if *versionM && *versionJson {
 		bs, err := json.MarshalIndent(bi, "", "\t")
 		if err != nil {
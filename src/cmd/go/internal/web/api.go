@@ -183,7 +183,17 @@ func (r *Response) formatErrorDetail() string {
 // Get returns a non-nil error only if the request did not receive a response
 // under any applicable scheme. (A non-2xx response does not cause an error.)
 func Get(security SecurityMode, u *url.URL) (*Response, error) {
-	return get(security, u)
+	return do(security, "GET", u, nil, nil)
+}
+
+// Do is like Get, but it sends a request with the given method, header,
+// and body, as needed by protocols such as Git's smart HTTP protocol.
+// The header and body may be nil. The body is read from its start,
+// possibly more than once if the request is retried with credentials.
+// Do does not follow redirects for methods other than GET and HEAD
+// (see [net/http.Client.Do]).
+func Do(security SecurityMode, method string, u *url.URL, header map[string][]string, body io.ReadSeeker) (*Response, error) {
+	return do(security, method, u, header, body)
 }
 
 // OpenBrowser attempts to open the requested URL in a web browser.
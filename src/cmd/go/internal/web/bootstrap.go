@@ -13,10 +13,11 @@ package web
 
 import (
 	"errors"
+	"io"
 	urlpkg "net/url"
 )
 
-func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
+func do(security SecurityMode, method string, url *urlpkg.URL, header map[string][]string, body io.ReadSeeker) (*Response, error) {
 	return nil, errors.New("no http in bootstrap go command")
 }
 
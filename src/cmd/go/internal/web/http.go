@@ -73,10 +73,14 @@ func checkRedirect(req *http.Request, via []*http.Request) error {
 	return nil
 }
 
-func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
+func do(security SecurityMode, method string, url *urlpkg.URL, header map[string][]string, body io.ReadSeeker) (*Response, error) {
 	start := time.Now()
+	verb := strings.ToLower(method) // for -x logging
 
 	if url.Scheme == "file" {
+		if method != "GET" {
+			return nil, fmt.Errorf("unsupported method %s for %s", method, url.Redacted())
+		}
 		return getFile(url)
 	}
 
@@ -92,7 +96,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 					Body:       http.NoBody,
 				}
 				if cfg.BuildX {
-					fmt.Fprintf(os.Stderr, "# get %s: %v (%.3fs)\n", url.Redacted(), res.Status, time.Since(start).Seconds())
+					fmt.Fprintf(os.Stderr, "# %s %s: %v (%.3fs)\n", verb, url.Redacted(), res.Status, time.Since(start).Seconds())
 				}
 				return res, nil
 			}
@@ -122,10 +126,10 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 		// We print extra logging in -x mode instead, which traces what
 		// commands are executed.
 		if cfg.BuildX {
-			fmt.Fprintf(os.Stderr, "# get %s\n", url.Redacted())
+			fmt.Fprintf(os.Stderr, "# %s %s\n", verb, url.Redacted())
 		}
 
-		req, err := http.NewRequest("GET", url.String(), nil)
+		req, err := newRequest(method, url, header, body)
 		if err != nil {
 			return nil, err
 		}
@@ -166,7 +170,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 			// Close the body of the previous response since we
 			// are discarding it and creating a new one.
 			res.Body.Close()
-			req, err = http.NewRequest("GET", url.String(), nil)
+			req, err = newRequest(method, url, header, body)
 			if err != nil {
 				return nil, err
 			}
@@ -208,7 +212,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 			fetched = secure
 		} else {
 			if cfg.BuildX {
-				fmt.Fprintf(os.Stderr, "# get %s: %v\n", secure.Redacted(), err)
+				fmt.Fprintf(os.Stderr, "# %s %s: %v\n", verb, secure.Redacted(), err)
 			}
 			if security != Insecure || url.Scheme == "https" {
 				// HTTPS failed, and we can't fall back to plain HTTP.
@@ -223,7 +227,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 		case "http":
 			if security == SecureOnly {
 				if cfg.BuildX {
-					fmt.Fprintf(os.Stderr, "# get %s: insecure\n", url.Redacted())
+					fmt.Fprintf(os.Stderr, "# %s %s: insecure\n", verb, url.Redacted())
 				}
 				return nil, fmt.Errorf("insecure URL: %s", url.Redacted())
 			}
@@ -233,7 +237,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 			}
 		default:
 			if cfg.BuildX {
-				fmt.Fprintf(os.Stderr, "# get %s: unsupported\n", url.Redacted())
+				fmt.Fprintf(os.Stderr, "# %s %s: unsupported\n", verb, url.Redacted())
 			}
 			return nil, fmt.Errorf("unsupported scheme: %s", url.Redacted())
 		}
@@ -243,7 +247,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 		insecure.Scheme = "http"
 		if insecure.User != nil && security != Insecure {
 			if cfg.BuildX {
-				fmt.Fprintf(os.Stderr, "# get %s: insecure credentials\n", insecure.Redacted())
+				fmt.Fprintf(os.Stderr, "# %s %s: insecure credentials\n", verb, insecure.Redacted())
 			}
 			return nil, fmt.Errorf("refusing to pass credentials to insecure URL: %s", insecure.Redacted())
 		}
@@ -253,7 +257,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 			fetched = insecure
 		} else {
 			if cfg.BuildX {
-				fmt.Fprintf(os.Stderr, "# get %s: %v\n", insecure.Redacted(), err)
+				fmt.Fprintf(os.Stderr, "# %s %s: %v\n", verb, insecure.Redacted(), err)
 			}
 			// HTTP failed, and we already tried HTTPS if applicable.
 			// Report the error from the HTTP attempt.
@@ -264,7 +268,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 	// Note: accepting a non-200 OK here, so people can serve a
 	// meta import in their http 404 page.
 	if cfg.BuildX {
-		fmt.Fprintf(os.Stderr, "# get %s: %v (%.3fs)\n", fetched.Redacted(), res.Status, time.Since(start).Seconds())
+		fmt.Fprintf(os.Stderr, "# %s %s: %v (%.3fs)\n", verb, fetched.Redacted(), res.Status, time.Since(start).Seconds())
 	}
 
 	r := &Response{
@@ -291,6 +295,37 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 	return r, nil
 }
 
+// newRequest returns a new request with the given method, header, and body.
+func newRequest(method string, url *urlpkg.URL, header map[string][]string, body io.ReadSeeker) (*http.Request, error) {
+	var r io.Reader
+	var size int64
+	if body != nil {
+		var err error
+		size, err = body.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = body.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			return nil, err
+		}
+		r = body
+		if size == 0 {
+			r = http.NoBody
+		}
+	}
+	req, err := http.NewRequest(method, url.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, v := range header {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	return req, nil
+}
+
 func getFile(u *urlpkg.URL) (*Response, error) {
 	path, err := urlToFilePath(u)
 	if err != nil {
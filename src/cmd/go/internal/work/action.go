@@ -64,6 +64,8 @@ type Builder struct {
 	toolIDCache    par.Cache[string, string] // tool name -> tool ID
 	gccToolIDCache map[string]string         // tool name -> tool ID
 	buildIDCache   map[string]string         // file name -> build ID
+
+	actionInputs sync.Map // cache.ActionID -> string input to action ID hash, for -explain
 }
 
 // NOTE: Much of Action would not need to be exported if not for test.
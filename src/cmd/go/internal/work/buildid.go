@@ -471,6 +471,9 @@ func (b *Builder) useCache(a *Action, actionHash cache.ActionID, target string,
 		// cfg.BuildA above because we don't even look at the cache in that case.
 		if ok {
 			counterCacheHit.Inc()
+			if cfg.BuildExplain {
+				b.saveActionInput(a)
+			}
 		} else {
 			if a.Package != nil && a.Package.Standard {
 				stdlibRecompiledIncOnce()
@@ -600,6 +603,9 @@ func (b *Builder) useCache(a *Action, actionHash cache.ActionID, target string,
 	}
 
 	// If we've reached this point, we can't use the cache for the action.
+	if cfg.BuildExplain {
+		b.explainCacheMiss(a)
+	}
 	if p := a.Package; p != nil && !p.Stale {
 		p.Stale = true
 		p.StaleReason = "build ID mismatch"
@@ -670,6 +676,10 @@ func (b *Builder) updateBuildID(a *Action, target string) error {
 
 	c := cache.Default()
 
+	if cfg.BuildExplain {
+		b.saveActionInput(a)
+	}
+
 	// Cache output from compile/link, even if we don't do the rest.
 	switch a.Mode {
 	case "build":
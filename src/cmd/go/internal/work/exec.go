@@ -256,6 +256,9 @@ func (b *Builder) Do(ctx context.Context, root *Action) {
 func (b *Builder) buildActionID(a *Action) cache.ActionID {
 	p := a.Package
 	h := cache.NewHash("build " + p.ImportPath)
+	if cfg.BuildExplain {
+		h.Record()
+	}
 
 	// Configuration independent of compiler toolchain.
 	// Note: buildmode has already been accounted for in buildGcflags
@@ -430,7 +433,7 @@ func (b *Builder) buildActionID(a *Action) cache.ActionID {
 		}
 	}
 
-	return h.Sum()
+	return b.actionIDSum(h)
 }
 
 // needCgoHdr reports whether the actions triggered by this one
@@ -1457,6 +1460,9 @@ func copyToStdout(r io.Reader) error {
 func (b *Builder) linkActionID(a *Action) cache.ActionID {
 	p := a.Package
 	h := cache.NewHash("link " + p.ImportPath)
+	if cfg.BuildExplain {
+		h.Record()
+	}
 
 	// Toolchain-independent configuration.
 	fmt.Fprintf(h, "link\n")
@@ -1493,7 +1499,7 @@ func (b *Builder) linkActionID(a *Action) cache.ActionID {
 		}
 	}
 
-	return h.Sum()
+	return b.actionIDSum(h)
 }
 
 // printLinkerConfig prints the linker config into the hash h,
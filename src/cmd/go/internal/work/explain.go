@@ -0,0 +1,263 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Explaining build cache misses (the -explain flag).
+//
+// With -explain, the go command records the input to the hash that
+// computes the action ID of each compile and link action, and saves it
+// in the build cache under a key that depends only on the action's mode,
+// package, and target platform. When an action misses in the cache,
+// the go command compares the input to its action ID with the input
+// saved for the last build of the same action and reports how they
+// differ: which source files, dependencies, flags, environment
+// variables, or tools changed.
+
+package work
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"cmd/go/internal/cache"
+	"cmd/go/internal/cfg"
+)
+
+// actionIDSum returns the action ID computed by h,
+// remembering its input for -explain.
+func (b *Builder) actionIDSum(h *cache.Hash) cache.ActionID {
+	id := h.Sum()
+	if input := h.Recorded(); input != nil {
+		b.actionInputs.Store(cache.ActionID(id), "toolchain "+runtime.Version()+"\n"+string(input))
+	}
+	return id
+}
+
+// actionInput returns the input to the hash that computed the action ID
+// of a, or "" if it was not recorded.
+func (b *Builder) actionInput(a *Action) string {
+	if a.Package == nil {
+		return ""
+	}
+	input, _ := b.actionInputs.Load(a.actionID)
+	s, _ := input.(string)
+	return s
+}
+
+// explainKey returns the build cache key under which the input to the
+// action ID of a is saved. It must not depend on the inputs themselves,
+// nor on the go command's version.
+func explainKey(a *Action) cache.ActionID {
+	return sha256.Sum256(fmt.Appendf(nil, "explain %s %s %s/%s", a.Mode, a.Package.ImportPath, cfg.Goos, cfg.Goarch))
+}
+
+// saveActionInput saves the input to the action ID of a in the build cache,
+// for comparison by later builds using -explain.
+func (b *Builder) saveActionInput(a *Action) {
+	input := b.actionInput(a)
+	if input == "" || cfg.BuildN {
+		return
+	}
+	c := cache.Default()
+	key := explainKey(a)
+	if old, _, err := cache.GetBytes(c, key); err == nil && string(old) == input {
+		return
+	}
+	cache.PutBytes(c, key, []byte(input))
+}
+
+// explainCacheMiss prints why the output of a was not found in the build
+// cache, by comparing the input to its action ID with the one saved for the
+// last build of the same action.
+func (b *Builder) explainCacheMiss(a *Action) {
+	input := b.actionInput(a)
+	if input == "" {
+		return
+	}
+	sh := b.Shell(a)
+	name := a.Mode + " " + a.Package.ImportPath
+	old, _, err := cache.GetBytes(cache.Default(), explainKey(a))
+	if err != nil {
+		sh.Printf("explain: %s: no previous build recorded\n", name)
+		return
+	}
+	reasons := explainInputs(string(old), input)
+	if len(reasons) == 0 {
+		sh.Printf("explain: %s: inputs unchanged since last build, but output not in cache\n", name)
+		return
+	}
+	for _, r := range reasons {
+		sh.Printf("explain: %s: %s\n", name, r)
+	}
+}
+
+// explainInputs describes the differences between the old and new inputs
+// to an action ID.
+func explainInputs(old, new string) []string {
+	oldKeys, oldLines := inputLines(old)
+	newKeys, newLines := inputLines(new)
+	var reasons []string
+	for _, k := range newKeys {
+		o, ok := oldLines[k]
+		switch {
+		case !ok:
+			reasons = append(reasons, describeInput(k, "", newLines[k]))
+		case o != newLines[k]:
+			reasons = append(reasons, describeInput(k, o, newLines[k]))
+		}
+	}
+	for _, k := range oldKeys {
+		if _, ok := newLines[k]; !ok {
+			reasons = append(reasons, describeInput(k, oldLines[k], ""))
+		}
+	}
+	return reasons
+}
+
+// inputLines splits the input to an action ID into lines,
+// returning the keys identifying the lines in order,
+// and a map from each key to its line.
+func inputLines(input string) (keys []string, lines map[string]string) {
+	lines = make(map[string]string)
+	for line := range strings.Lines(input) {
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			continue
+		}
+		k := inputKey(line)
+		if _, ok := lines[k]; ok {
+			// Keep repeated keys distinct.
+			k = line
+		}
+		keys = append(keys, k)
+		lines[k] = line
+	}
+	return keys, lines
+}
+
+// inputKey returns the key identifying a line in the input to an action ID
+// as written by buildActionID or linkActionID: the part of the line that
+// names the input, without its value.
+func inputKey(line string) string {
+	f := strings.Fields(line)
+	switch f[0] {
+	case "file", "import":
+		if len(f) == 3 {
+			return f[0] + " " + f[1]
+		}
+	case "packagefile", "packageshlib":
+		k, _, _ := strings.Cut(line, "=")
+		return k
+	}
+	if k, _, ok := strings.Cut(line, "="); ok && (isEnvName(k) || strings.HasPrefix(k, "magic ")) {
+		return k
+	}
+	if len(f) > 1 {
+		return f[0] + " "
+	}
+	return line
+}
+
+// isEnvName reports whether k looks like the name of an environment
+// variable, or the name of a C compiler setting such as "CC ID".
+func isEnvName(k string) bool {
+	if k == "" {
+		return false
+	}
+	for _, c := range k {
+		if !('A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == ' ') {
+			return false
+		}
+	}
+	return true
+}
+
+// describeInput describes the change of the input with key k from the
+// line old to the line new. Either may be "", for an added or removed input.
+func describeInput(k, old, new string) string {
+	change := "changed"
+	switch {
+	case old == "":
+		change = "added"
+	case new == "":
+		change = "removed"
+	}
+	// values returns the old and new values of the input, if both exist.
+	values := func() string {
+		if old == "" || new == "" {
+			return ""
+		}
+		o := strings.TrimSpace(strings.TrimPrefix(old, k))
+		n := strings.TrimSpace(strings.TrimPrefix(new, k))
+		o = strings.TrimPrefix(o, "=")
+		n = strings.TrimPrefix(n, "=")
+		return fmt.Sprintf(": %s => %s", o, n)
+	}
+
+	name, _, _ := strings.Cut(k, " ")
+	arg := strings.TrimSpace(strings.TrimPrefix(k, name))
+	switch name {
+	case "toolchain":
+		return "Go toolchain " + change + values()
+	case "file":
+		return "source file " + arg + " " + change
+	case "import", "packagefile", "packageshlib":
+		if arg != "" {
+			return "dependency " + arg + " " + change
+		}
+	case "packagemain":
+		return "main package " + change
+	case "pgofile":
+		return "PGO profile " + change
+	case "compile", "asm", "link":
+		// The line is the tool name, its ID, and then its flags.
+		tool := map[string]string{"compile": "compiler", "asm": "assembler", "link": "linker"}[name]
+		if old == "" || new == "" || len(old) == len(name) || len(new) == len(name) {
+			break
+		}
+		oldID, oldFlags, _ := strings.Cut(old[len(name)+1:], " ")
+		newID, newFlags, _ := strings.Cut(new[len(name)+1:], " ")
+		if oldID != newID {
+			return tool + " " + change
+		}
+		return fmt.Sprintf("%s flags changed: %s => %s", tool, oldFlags, newFlags)
+	case "linkflags":
+		return "linker flags (-ldflags) " + change + values()
+	case "cgo":
+		return "cgo tool " + change
+	case "dir":
+		return "package directory " + change + values()
+	case "module":
+		return "module version " + change + values()
+	case "go":
+		return "Go version in go.mod " + change + values()
+	case "goos", "buildmode":
+		return "build mode or target platform " + change + values()
+	case "modinfo":
+		return "module build information " + change
+	case "defaultgodebug":
+		return "default GODEBUG settings " + change + values()
+	case "cover":
+		return "coverage settings " + change + values()
+	case "fuzz":
+		return "fuzzing instrumentation " + change + values()
+	case "trimpath":
+		return "-trimpath flag " + change
+	case "omitdebug":
+		return "package settings " + change + values()
+	case "magic":
+		return "environment variable " + arg + " " + change + values()
+	}
+	if tool, ok := strings.CutSuffix(k, " ID"); ok && isEnvName(tool) {
+		return "version of $" + tool + " " + change
+	}
+	if isEnvName(k) {
+		return "environment variable " + k + " " + change + values()
+	}
+	if old == "" || new == "" {
+		return fmt.Sprintf("input %q %s", old+new, change)
+	}
+	return fmt.Sprintf("input changed: %q => %q", old, new)
+}
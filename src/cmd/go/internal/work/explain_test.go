@@ -0,0 +1,79 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package work
+
+import (
+	"slices"
+	"testing"
+)
+
+var explainInputsTests = []struct {
+	old, new string
+	want     []string
+}{
+	{
+		old:  "compile\nfile a.go abc\n",
+		new:  "compile\nfile a.go abc\n",
+		want: nil,
+	},
+	{
+		old: "compile\nfile a.go abc\nfile b.go def\n",
+		new: "compile\nfile a.go xyz\nfile c.go ghi\n",
+		want: []string{
+			"source file a.go changed",
+			"source file c.go added",
+			"source file b.go removed",
+		},
+	},
+	{
+		old: "import \"x\"\nimport y abc\nimport z def\n",
+		new: "import \"x\"\nimport y xyz\nimport z def\n",
+		want: []string{
+			"dependency y changed",
+		},
+	},
+	{
+		old: "compile tool1 [] []\nGOAMD64=v1\n",
+		new: "compile tool1 [] [\"-N\"]\nGOAMD64=v3\n",
+		want: []string{
+			`compiler flags changed: [] [] => [] ["-N"]`,
+			"environment variable GOAMD64 changed: v1 => v3",
+		},
+	},
+	{
+		old: "compile tool1 [] []\nCC ID=\"gcc 1\"\nmagic GODEBUG=x\n",
+		new: "compile tool2 [] []\nCC ID=\"gcc 2\"\n",
+		want: []string{
+			"compiler changed",
+			"version of $CC changed",
+			"environment variable GODEBUG removed",
+		},
+	},
+	{
+		old: "link\npackagefile a=abc\npackagemain def\nlinkflags []\n",
+		new: "link\npackagefile a=xyz\npackagemain ghi\nlinkflags [\"-s\"]\n",
+		want: []string{
+			"dependency a changed",
+			"main package changed",
+			`linker flags (-ldflags) changed: [] => ["-s"]`,
+		},
+	},
+	{
+		old: "compile\nsomething new\n",
+		new: "compile\n",
+		want: []string{
+			`input "something new" removed`,
+		},
+	},
+}
+
+func TestExplainInputs(t *testing.T) {
+	for _, tt := range explainInputsTests {
+		got := explainInputs(tt.old, tt.new)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("explainInputs(%q, %q):\nhave %q\nwant %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Prebuilt tool binaries.
+//
+// 'go mod vendor -tools' stores prebuilt binaries of the tools declared
+// by the main modules in the tools subdirectory of the vendor directory,
+// so that 'go tool' can run them without building them.
+// Each binary is stored as tools/<id>/<name>, where name is the tool's
+// executable name and id is the hex source action ID of the tool's link
+// action (see SourceActionID). Because the ID covers the toolchain, the
+// build configuration, and the sources of every package in the tool,
+// 'go tool' runs a stored binary only if building the tool would produce
+// the same binary.
+//
+// Because a binary's path says nothing about its content, the SHA-256
+// hash of each stored binary is recorded in vendor/modules.txt, on a line
+//
+//	# tools/<id>/<name> sha256:<hash in hex>
+//
+// which older go commands ignore. 'go tool' runs a stored binary only if
+// its content matches the recorded hash, so a binary cannot be replaced
+// without a visible change to modules.txt.
+
+package work
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cache"
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modload"
+	"cmd/internal/buildid"
+)
+
+// ToolStoreDir returns the directory holding the prebuilt tool binaries
+// for the vendor directory vendorDir.
+func ToolStoreDir(vendorDir string) string {
+	return filepath.Join(vendorDir, "tools")
+}
+
+// SourceActionID returns an action ID for the link action a computed
+// like the one the build uses, except that each package linked into the
+// binary is identified by its own action ID rather than by the content
+// ID of its compiled archive. The result therefore depends only on the
+// toolchain, the build configuration, and the source files, and can be
+// computed without compiling anything.
+//
+// SourceActionID does not modify the graph rooted at a: it computes the
+// IDs in a copy of the graph.
+func (b *Builder) SourceActionID(a *Action) cache.ActionID {
+	copies := make(map[*Action]*Action)
+	var clone func(*Action) *Action
+	clone = func(a *Action) *Action {
+		if c := copies[a]; c != nil {
+			return c
+		}
+		c := new(Action)
+		*c = *a
+		copies[a] = c
+		c.Deps = make([]*Action, len(a.Deps))
+		for i, a1 := range a.Deps {
+			c.Deps[i] = clone(a1)
+		}
+		if c.Mode == "build" && c.Package != nil {
+			c.actionID = b.buildActionID(c)
+			id := buildid.HashToString(c.actionID)
+			c.buildID = id + buildIDSeparator + id
+		}
+		return c
+	}
+	return b.linkActionID(clone(a))
+}
+
+// storedToolPath returns the slash-separated path, relative to the vendor
+// directory, of the binary for the tool p with source action ID id.
+func storedToolPath(id cache.ActionID, p *load.Package) string {
+	return path.Join("tools", fmt.Sprintf("%x", id), p.Internal.ExeName+cfg.ExeSuffix)
+}
+
+// storedToolLine returns the line of vendor/modules.txt recording
+// the hash sum of the stored binary at path rel.
+func storedToolLine(rel string, sum []byte) string {
+	return "# " + rel + " sha256:" + hex.EncodeToString(sum)
+}
+
+// fileSHA256 returns the SHA-256 hash of the content of the named file.
+func fileSHA256(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// StoredTool returns the path of the prebuilt binary for the tool p in the
+// tool store of the vendor directory vendorDir, or "" if the store has no
+// binary matching the current sources and build configuration, or if the
+// binary's content does not match the hash recorded in vendor/modules.txt.
+//
+// The package p must have been loaded, and its Internal.ExeName and
+// Internal.OmitDebug fields set, exactly as for BuildToolStore.
+func (b *Builder) StoredTool(loaderstate *modload.State, vendorDir string, p *load.Package) string {
+	rel := storedToolPath(b.SourceActionID(b.LinkAction(loaderstate, ModeBuild, ModeBuild, p)), p)
+	file := filepath.Join(vendorDir, filepath.FromSlash(rel))
+	if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	sum, err := fileSHA256(file)
+	if err != nil || !storedToolRecorded(vendorDir, storedToolLine(rel, sum)) {
+		return ""
+	}
+	return file
+}
+
+// storedToolRecorded reports whether vendor/modules.txt in vendorDir
+// has the given line.
+func storedToolRecorded(vendorDir, line string) bool {
+	f, err := os.Open(filepath.Join(vendorDir, "modules.txt"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if strings.TrimSuffix(s.Text(), "\r") == line {
+			return true
+		}
+	}
+	return false
+}
+
+// PrepareTool sets up the loaded tool package p to be built
+// as 'go tool' builds it.
+func PrepareTool(p *load.Package) {
+	p.Internal.OmitDebug = true
+	p.Internal.ExeName = p.DefaultExecName()
+}
+
+// BuildToolStore builds the tools with the given import paths and stores
+// their binaries in the tool store of the vendor directory vendorDir,
+// replacing its previous contents. It returns the lines recording the
+// hashes of the binaries, which the caller must add to vendor/modules.txt.
+// The caller must set cfg.BuildTrimpath before loading any packages,
+// as 'go tool' does when running tools from a store, so that the
+// binaries do not depend on the location of the module.
+func BuildToolStore(loaderstate *modload.State, ctx context.Context, vendorDir string, tools []string) []string {
+	if err := os.RemoveAll(ToolStoreDir(vendorDir)); err != nil {
+		base.Fatal(err)
+	}
+	if len(tools) == 0 {
+		return nil
+	}
+
+	BuildInit(loaderstate)
+	pkgs := load.PackagesAndErrors(loaderstate, ctx, load.PackageOpts{MainOnly: true}, tools)
+	load.CheckPackageErrors(pkgs)
+
+	b := NewBuilder("", loaderstate.VendorDirOrEmpty)
+	defer func() {
+		if err := b.Close(); err != nil {
+			base.Fatal(err)
+		}
+	}()
+	root := &Action{Mode: "go mod vendor -tools"}
+	var rels []string
+	for _, p := range pkgs {
+		PrepareTool(p)
+		a := b.LinkAction(loaderstate, ModeBuild, ModeBuild, p)
+		rels = append(rels, storedToolPath(b.SourceActionID(a), p))
+		root.Deps = append(root.Deps, a)
+	}
+	b.Do(ctx, root)
+	base.ExitIfErrors()
+
+	sh := b.BackgroundShell()
+	var lines []string
+	for i, p := range pkgs {
+		file := filepath.Join(vendorDir, filepath.FromSlash(rels[i]))
+		if cfg.BuildV {
+			fmt.Fprintf(os.Stderr, "# %s\n%s\n", p.ImportPath, file)
+		}
+		if err := sh.Mkdir(filepath.Dir(file)); err != nil {
+			base.Fatal(err)
+		}
+		if err := sh.CopyFile(file, root.Deps[i].BuiltTarget(), 0o777, false); err != nil {
+			base.Fatal(err)
+		}
+		sum, err := fileSHA256(file)
+		if err != nil {
+			base.Fatal(err)
+		}
+		lines = append(lines, storedToolLine(rels[i], sum))
+	}
+	return lines
+}
@@ -13,7 +13,7 @@ import (
 )
 
 var cmdVendor = &base.Command{
-	UsageLine: "go work vendor [-e] [-v] [-o outdir]",
+	UsageLine: "go work vendor [-e] [-v] [-o outdir] [-tools]",
 	Short:     "make vendored copy of dependencies",
 	Long: `
 Vendor resets the workspace's vendor directory to include all packages
@@ -29,18 +29,26 @@ encountered while loading packages.
 The -o flag causes vendor to create the vendor directory at the given
 path instead of "vendor". The go command can only use a vendor directory
 named "vendor" within the module root directory, so this flag is
-primarily useful for other tools.`,
+primarily useful for other tools.
+
+The -tools flag causes vendor to also build the tools declared by
+tool directives in the workspace's modules, and to store their binaries
+in vendor/tools for 'go tool' to run without building them.
+See 'go help mod vendor' for details. The -tools flag cannot be used
+with -o.`,
 
 	Run: runVendor,
 }
 
-var vendorE bool   // if true, report errors but proceed anyway
-var vendorO string // if set, overrides the default output directory
+var vendorE bool     // if true, report errors but proceed anyway
+var vendorO string   // if set, overrides the default output directory
+var vendorTools bool // if true, store prebuilt tool binaries
 
 func init() {
 	cmdVendor.Flag.BoolVar(&cfg.BuildV, "v", false, "")
 	cmdVendor.Flag.BoolVar(&vendorE, "e", false, "")
 	cmdVendor.Flag.StringVar(&vendorO, "o", "", "")
+	cmdVendor.Flag.BoolVar(&vendorTools, "tools", false, "")
 	base.AddChdirFlag(&cmdVendor.Flag)
 	base.AddModCommonFlags(&cmdVendor.Flag)
 }
@@ -52,5 +60,5 @@ func runVendor(ctx context.Context, cmd *base.Command, args []string) {
 		base.Fatalf("go: no go.work file found\n\t(run 'go work init' first or specify path using GOWORK environment variable)")
 	}
 
-	modcmd.RunVendor(moduleLoaderState, ctx, vendorE, vendorO, args)
+	modcmd.RunVendor(moduleLoaderState, ctx, vendorE, vendorO, vendorTools, args)
 }
@@ -36,12 +36,14 @@ import (
 var (
 	proxyAddr = flag.String("proxy", "", "run proxy on this network address instead of running any tests")
 	proxyURL  string
+	cacheURL  string
 )
 
 var proxyOnce sync.Once
 
 // StartProxy starts the Go module proxy running on *proxyAddr (like "localhost:1234")
 // and sets proxyURL to the GOPROXY setting to use to access the proxy.
+// The same server runs a remote build cache at cacheURL.
 // Subsequent calls are no-ops.
 //
 // The proxy serves from testdata/mod. See testdata/mod/README.
@@ -58,6 +60,7 @@ func StartProxy() {
 		}
 		*proxyAddr = l.Addr().String()
 		proxyURL = "http://" + *proxyAddr + "/mod"
+		cacheURL = "http://" + *proxyAddr + "/cache"
 		fmt.Fprintf(os.Stderr, "go test proxy running at GOPROXY=%s\n", proxyURL)
 		go func() {
 			log.Fatalf("go proxy: http.Serve: %v", http.Serve(l, http.HandlerFunc(proxyHandler)))
@@ -105,6 +108,33 @@ func readModList() {
 	}
 }
 
+var cacheObjs sync.Map // URL path → []byte
+
+// cacheHandler serves an in-memory remote build cache, for use as
+// GOCACHEURL, implementing the Bazel HTTP remote cache protocol.
+// Each test should use its own path below cacheURL.
+func cacheHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		data, ok := cacheObjs.Load(r.URL.Path)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data.([]byte))
+	case "PUT":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cacheObjs.Store(r.URL.Path, data)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 var zipCache par.ErrCache[*txtar.Archive, []byte]
 
 const (
@@ -124,6 +154,10 @@ var (
 // proxyHandler serves the Go module proxy protocol.
 // See the proxy section of https://research.swtch.com/vgo-module.
 func proxyHandler(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/cache/") {
+		cacheHandler(w, r)
+		return
+	}
 	if !strings.HasPrefix(r.URL.Path, "/mod/") {
 		http.NotFound(w, r)
 		return
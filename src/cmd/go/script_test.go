@@ -234,6 +234,7 @@ func scriptEnv(srv *vcstest.Server, srvCertFile string) ([]string, error) {
 		"GOOS=" + runtime.GOOS,
 		"TESTGO_GOHOSTOS=" + goHostOS,
 		"GOPROXY=" + proxyURL,
+		"TESTGO_CACHEURL=" + cacheURL,
 		"GOPRIVATE=",
 		"GOROOT=" + testGOROOT,
 		"GOTRACEBACK=system",
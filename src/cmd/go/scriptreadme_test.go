@@ -121,6 +121,7 @@ Scripts also have access to other environment variables, including:
 	GOPROXY=<local module proxy serving from cmd/go/testdata/mod>
 	GOROOT=<actual GOROOT>
 	TESTGO_GOROOT=<GOROOT used to build cmd/go, for use in tests that may change GOROOT>
+	TESTGO_CACHEURL=<local remote build cache server; append a unique path to use as GOCACHEURL>
 	HOME=/no-home
 	PATH=<actual PATH>
 	TMPDIR=$WORK/tmp
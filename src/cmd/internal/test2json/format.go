@@ -0,0 +1,310 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test2json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Formatter converts the JSON test events written by Converters
+// into a report of the tests in another format.
+// It implements io.WriteCloser; the caller writes JSON events in,
+// for any number of packages, and the formatter writes the report to w.
+// Writes may come from multiple goroutines, provided each write holds
+// whole lines, as the writes of a Converter do.
+//
+// The supported formats are "junit", for JUnit XML, and "tap", for
+// TAP version 14. A JUnit report is written when the Formatter is closed;
+// a TAP report is written one package at a time, as each package finishes.
+type Formatter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string
+	input  []byte            // partial line of input
+	suites map[string]*suite // packages by name
+	order  []*suite          // packages in the order they started
+	done   int               // number of packages written, for TAP
+	header bool              // whether the TAP header has been written
+}
+
+// NewFormatter returns a Formatter writing a report in the given format to w.
+func NewFormatter(w io.Writer, format string) (*Formatter, error) {
+	switch format {
+	case "junit", "tap":
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return &Formatter{w: w, format: format, suites: make(map[string]*suite)}, nil
+}
+
+// inEvent is a JSON test event, as read by a Formatter.
+type inEvent struct {
+	Time        time.Time
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     *float64
+	Output      string
+	FailedBuild string
+	Shard       string
+	Key         string
+	Value       string
+	Path        string
+}
+
+// A suite holds the results of the tests of a single package.
+type suite struct {
+	name        string
+	start       time.Time
+	elapsed     *float64
+	result      string // final action for the package, or "" if not finished
+	failedBuild string
+	shard       string
+	output      strings.Builder     // output not attributed to any test
+	tests       map[string]*testRun // all tests by name
+	top         []*testRun          // top-level tests in the order they started
+}
+
+// A testRun holds the result of a single test, benchmark, or example.
+type testRun struct {
+	name      string
+	result    string // "pass", "fail", "skip", "bench", "retry", or "flaky", or "" if not finished
+	elapsed   *float64
+	output    strings.Builder
+	attrs     [][2]string // keys and values set by T.Attr
+	artifacts string      // directory set by T.ArtifactDir
+	retries   []string    // output of earlier failed attempts
+	sub       []*testRun
+}
+
+func (f *Formatter) Write(b []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.input = append(f.input, b...)
+	for {
+		i := bytes.IndexByte(f.input, '\n')
+		if i < 0 {
+			break
+		}
+		f.handleLine(f.input[:i])
+		f.input = f.input[i+1:]
+	}
+	return len(b), nil
+}
+
+// Close writes any part of the report that has not been written yet.
+func (f *Formatter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.input) > 0 {
+		f.handleLine(f.input)
+		f.input = nil
+	}
+	if f.format == "junit" {
+		return f.writeJUnit()
+	}
+	for _, s := range f.order {
+		if s.result == "" {
+			f.writeTAPSuite(s)
+		}
+	}
+	f.writeTAPHeader()
+	_, err := fmt.Fprintf(f.w, "1..%d\n", f.done)
+	return err
+}
+
+// handleLine handles a single JSON event.
+// Lines that are not test events, such as build events, are ignored.
+func (f *Formatter) handleLine(line []byte) {
+	var e inEvent
+	if err := json.Unmarshal(line, &e); err != nil {
+		return
+	}
+	switch e.Action {
+	case "start", "run", "pause", "cont", "pass", "fail", "skip", "bench",
+		"output", "retry", "flaky", "attr", "artifacts":
+	default:
+		return
+	}
+
+	s := f.suites[e.Package]
+	if s == nil {
+		s = &suite{name: e.Package, tests: make(map[string]*testRun)}
+		f.suites[e.Package] = s
+		f.order = append(f.order, s)
+	}
+
+	if e.Test == "" {
+		switch e.Action {
+		case "start":
+			s.start = e.Time
+		case "output":
+			s.output.WriteString(e.Output)
+		case "pass", "fail", "skip":
+			s.result = e.Action
+			s.elapsed = e.Elapsed
+			s.failedBuild = e.FailedBuild
+			s.shard = e.Shard
+			if f.format == "tap" {
+				f.writeTAPSuite(s)
+			}
+		}
+		return
+	}
+
+	t := s.tests[e.Test]
+	if t == nil {
+		t = &testRun{name: e.Test}
+		s.tests[e.Test] = t
+		if parent := s.parent(e.Test); parent != nil {
+			parent.sub = append(parent.sub, t)
+		} else {
+			s.top = append(s.top, t)
+		}
+	}
+	switch e.Action {
+	case "run":
+		if t.result == "retry" {
+			// A new attempt at running a retried test.
+			// Its subtests are run again, too.
+			t.retries = append(t.retries, t.allOutput())
+			t.result = ""
+			t.elapsed = nil
+			t.output.Reset()
+			t.attrs = nil
+			t.artifacts = ""
+			s.forget(t.sub)
+			t.sub = nil
+		}
+	case "output":
+		t.output.WriteString(e.Output)
+	case "attr":
+		t.attrs = append(t.attrs, [2]string{e.Key, e.Value})
+	case "artifacts":
+		t.artifacts = e.Path
+	case "pass", "fail", "skip", "bench", "retry", "flaky":
+		t.result = e.Action
+		t.elapsed = e.Elapsed
+	}
+}
+
+// parent returns the closest enclosing test of the named test that has
+// been seen, or nil if there is none.
+func (s *suite) parent(name string) *testRun {
+	for {
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+		if t := s.tests[name]; t != nil {
+			return t
+		}
+	}
+}
+
+// forget removes the tests and their subtests from s.tests.
+func (s *suite) forget(tests []*testRun) {
+	for _, t := range tests {
+		delete(s.tests, t.name)
+		s.forget(t.sub)
+	}
+}
+
+// allOutput returns the output of t and its subtests.
+func (t *testRun) allOutput() string {
+	var b strings.Builder
+	b.WriteString(cleanOutput(t.output.String()))
+	for _, sub := range t.sub {
+		b.WriteString(sub.allOutput())
+	}
+	return b.String()
+}
+
+// failed reports whether t failed.
+// A test that never finished, for example because of a timeout, failed.
+func (t *testRun) failed() bool {
+	return t.result == "fail" || t.result == "retry" || t.result == ""
+}
+
+// failed reports whether the tests in s failed, or s failed outside of any test.
+func (s *suite) failed() bool {
+	return s.result == "fail" || s.result == ""
+}
+
+// cleanOutput removes the test framing lines, such as "=== RUN" lines and
+// "--- PASS" lines, from the output of a test, and removes the indentation
+// common to the remaining lines.
+func cleanOutput(out string) string {
+	var lines []string
+	indent := -1
+	for line := range strings.Lines(out) {
+		trim := strings.TrimLeft(line, " ")
+		if isFraming(trim) {
+			continue
+		}
+		if strings.TrimSpace(trim) != "" {
+			if n := len(line) - len(trim); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		lines = append(lines, line)
+	}
+	prefix := strings.Repeat(" ", max(indent, 0))
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimPrefix(line, prefix))
+	}
+	return b.String()
+}
+
+// isFraming reports whether line, without its indentation, is a test
+// framing line.
+func isFraming(line string) bool {
+	for _, magic := range updates {
+		if strings.HasPrefix(line, string(magic)) {
+			return true
+		}
+	}
+	for _, magic := range reports {
+		if strings.HasPrefix(line, string(magic)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanSuiteOutput removes the lines summarizing the result of a package,
+// such as "PASS" and "ok" lines, from the output of a package.
+func cleanSuiteOutput(out string) string {
+	var b strings.Builder
+	for line := range strings.Lines(out) {
+		trim := strings.TrimSuffix(line, "\n")
+		if trim == "PASS" || trim == "FAIL" || isFraming(trim) ||
+			strings.HasPrefix(trim, "ok  \t") || strings.HasPrefix(trim, "FAIL\t") || strings.HasPrefix(trim, "?   \t") {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// skipReason returns the reason a test was skipped, given its output:
+// the last line it logged.
+func skipReason(out string) string {
+	out = strings.TrimSpace(out)
+	if i := strings.LastIndex(out, "\n"); i >= 0 {
+		out = strings.TrimSpace(out[i+1:])
+	}
+	return out
+}
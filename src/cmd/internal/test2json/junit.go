@@ -0,0 +1,196 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test2json
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// The JUnit XML format has no formal definition. These types follow the
+// format written by Maven Surefire and read by most CI systems, with two
+// extensions: subtests are testcase elements nested in the testcase of
+// their parent test, and attachments, for artifact directories, use the
+// [[ATTACHMENT|path]] syntax in system-out understood by Jenkins and GitLab.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr,omitempty"`
+	Timestamp  string           `xml:"timestamp,attr,omitempty"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	TestCases  []junitTestCase  `xml:"testcase"`
+	SystemOut  *junitText       `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name          string           `xml:"name,attr"`
+	Classname     string           `xml:"classname,attr"`
+	Time          string           `xml:"time,attr,omitempty"`
+	Properties    *junitProperties `xml:"properties,omitempty"`
+	Skipped       *junitResult     `xml:"skipped,omitempty"`
+	Failure       *junitResult     `xml:"failure,omitempty"`
+	Error         *junitResult     `xml:"error,omitempty"`
+	FlakyFailures []junitRerun     `xml:"flakyFailure,omitempty"`
+	RerunFailures []junitRerun     `xml:"rerunFailure,omitempty"`
+	SystemOut     *junitText       `xml:"system-out,omitempty"`
+	TestCases     []junitTestCase  `xml:"testcase,omitempty"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// A junitRerun is a failed attempt at running a test that was retried.
+type junitRerun struct {
+	Message   string     `xml:"message,attr"`
+	SystemOut *junitText `xml:"system-out,omitempty"`
+}
+
+// A junitText is test output, which is easier to read as CDATA.
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+// newJUnitText returns the junitText for out, or nil if out is empty.
+func newJUnitText(out string) *junitText {
+	if out == "" {
+		return nil
+	}
+	return &junitText{out}
+}
+
+// addProperty adds a property to the properties *p, allocating them if needed.
+func addProperty(p **junitProperties, name, value string) {
+	if *p == nil {
+		*p = new(junitProperties)
+	}
+	(*p).Properties = append((*p).Properties, junitProperty{name, value})
+}
+
+// writeJUnit writes the JUnit XML report for all packages.
+func (f *Formatter) writeJUnit() error {
+	var all junitTestSuites
+	for _, s := range f.order {
+		out := cleanSuiteOutput(s.output.String())
+		js := junitTestSuite{
+			Name: s.name,
+			Time: junitTime(s.elapsed),
+		}
+		if !s.start.IsZero() {
+			js.Timestamp = s.start.Format(time.RFC3339)
+		}
+		if s.shard != "" {
+			addProperty(&js.Properties, "shard", s.shard)
+		}
+		for _, t := range s.top {
+			js.TestCases = append(js.TestCases, js.testCase(s, t))
+		}
+		if s.failed() && js.Failures == 0 {
+			// The package failed, but none of its tests did:
+			// report the failure of the package itself as an error.
+			name, msg := "[package failed]", "package failed"
+			if s.failedBuild != "" {
+				name, msg = "[build failed]", "build failed: "+s.failedBuild
+			}
+			js.Tests++
+			js.Errors++
+			js.TestCases = append(js.TestCases, junitTestCase{
+				Name:      name,
+				Classname: s.name,
+				Error:     &junitResult{Message: msg, Text: out},
+			})
+			out = ""
+		}
+		js.SystemOut = newJUnitText(out)
+		all.Tests += js.Tests
+		all.Failures += js.Failures
+		all.Errors += js.Errors
+		all.Skipped += js.Skipped
+		all.Suites = append(all.Suites, js)
+	}
+
+	out, err := xml.MarshalIndent(all, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f.w, "%s%s\n", xml.Header, out)
+	return err
+}
+
+// testCase returns the testcase for t, counting it and its subtests in js.
+func (js *junitTestSuite) testCase(s *suite, t *testRun) junitTestCase {
+	tc := junitTestCase{
+		Name:      t.name,
+		Classname: s.name,
+		Time:      junitTime(t.elapsed),
+	}
+	js.Tests++
+	for _, kv := range t.attrs {
+		addProperty(&tc.Properties, kv[0], kv[1])
+	}
+	out := cleanOutput(t.output.String())
+	var reruns []junitRerun
+	for _, r := range t.retries {
+		reruns = append(reruns, junitRerun{Message: "Failed", SystemOut: newJUnitText(r)})
+	}
+	switch {
+	case t.result == "skip":
+		js.Skipped++
+		tc.Skipped = &junitResult{Message: skipReason(out)}
+		out = ""
+	case t.result == "flaky":
+		if len(reruns) == 0 {
+			// A test known to be flaky failed, and was not retried.
+			reruns = append(reruns, junitRerun{Message: "Failed", SystemOut: newJUnitText(out)})
+			out = ""
+		}
+		tc.FlakyFailures = reruns
+	case t.failed():
+		js.Failures++
+		tc.Failure = &junitResult{Message: "Failed", Text: out}
+		tc.RerunFailures = reruns
+		out = ""
+	}
+	if t.artifacts != "" {
+		out += "[[ATTACHMENT|" + t.artifacts + "]]\n"
+	}
+	tc.SystemOut = newJUnitText(out)
+	for _, sub := range t.sub {
+		tc.TestCases = append(tc.TestCases, js.testCase(s, sub))
+	}
+	return tc
+}
+
+// junitTime formats an elapsed time in seconds, if known.
+func junitTime(elapsed *float64) string {
+	if elapsed == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", *elapsed)
+}
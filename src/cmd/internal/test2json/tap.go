@@ -0,0 +1,169 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test2json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeTAPHeader writes the TAP version line, if it has not been written yet.
+func (f *Formatter) writeTAPHeader() {
+	if !f.header {
+		f.header = true
+		fmt.Fprintf(f.w, "TAP version 14\n")
+	}
+}
+
+// writeTAPSuite writes the TAP report for the tests of s, as a subtest
+// of the report for all packages.
+func (f *Formatter) writeTAPSuite(s *suite) {
+	f.writeTAPHeader()
+	f.done++
+
+	// Without -p, test2json reports no package name.
+	subtest, desc := "# Subtest", ""
+	if s.name != "" {
+		subtest += ": " + tapEscape(s.name)
+		desc = " - " + tapEscape(s.name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", subtest)
+	for i, t := range s.top {
+		writeTAPTest(&b, "    ", i+1, t)
+	}
+	fmt.Fprintf(&b, "    1..%d\n", len(s.top))
+
+	ok, directive := "ok", ""
+	if s.failed() {
+		ok = "not ok"
+	} else if s.result == "skip" {
+		directive = " # SKIP no test files"
+	}
+	fmt.Fprintf(&b, "%s %d%s%s\n", ok, f.done, desc, directive)
+
+	var y strings.Builder
+	if s.elapsed != nil {
+		fmt.Fprintf(&y, "duration_ms: %.3f\n", *s.elapsed*1000)
+	}
+	if s.shard != "" {
+		fmt.Fprintf(&y, "shard: %s\n", yamlString(s.shard))
+	}
+	if s.failedBuild != "" {
+		fmt.Fprintf(&y, "failed_build: %s\n", yamlString(s.failedBuild))
+	}
+	if out := cleanSuiteOutput(s.output.String()); s.failed() && out != "" {
+		yamlBlock(&y, "output", out)
+	}
+	writeTAPDiag(&b, "", y.String())
+
+	io.WriteString(f.w, b.String())
+}
+
+// writeTAPTest writes the TAP test point numbered n for t to b,
+// preceded by a subtest for the subtests of t.
+func writeTAPTest(b *strings.Builder, indent string, n int, t *testRun) {
+	if len(t.sub) > 0 {
+		fmt.Fprintf(b, "%s# Subtest: %s\n", indent, tapEscape(t.name))
+		for i, sub := range t.sub {
+			writeTAPTest(b, indent+"    ", i+1, sub)
+		}
+		fmt.Fprintf(b, "%s    1..%d\n", indent, len(t.sub))
+	}
+
+	out := cleanOutput(t.output.String())
+	ok, directive := "ok", ""
+	if t.result == "skip" {
+		directive = " # SKIP"
+		if reason := skipReason(out); reason != "" {
+			directive += " " + tapEscape(reason)
+		}
+		out = ""
+	} else if t.failed() {
+		ok = "not ok"
+	}
+	fmt.Fprintf(b, "%s%s %d - %s%s\n", indent, ok, n, tapEscape(t.name), directive)
+
+	var y strings.Builder
+	if t.elapsed != nil {
+		fmt.Fprintf(&y, "duration_ms: %.3f\n", *t.elapsed*1000)
+	}
+	if t.result == "flaky" {
+		fmt.Fprintf(&y, "flaky: true\n")
+	}
+	if len(t.attrs) > 0 {
+		fmt.Fprintf(&y, "attrs:\n")
+		for _, kv := range t.attrs {
+			fmt.Fprintf(&y, "  %s: %s\n", yamlString(kv[0]), yamlString(kv[1]))
+		}
+	}
+	if t.artifacts != "" {
+		fmt.Fprintf(&y, "artifacts: %s\n", yamlString(t.artifacts))
+	}
+	if len(t.retries) > 0 {
+		fmt.Fprintf(&y, "retries:\n")
+		for _, r := range t.retries {
+			fmt.Fprintf(&y, "  - %s\n", yamlString(r))
+		}
+	}
+	if out != "" {
+		yamlBlock(&y, "output", out)
+	}
+	writeTAPDiag(b, indent, y.String())
+}
+
+// writeTAPDiag writes the YAML diagnostic block y for a test point
+// at the given indentation, if y is not empty.
+func writeTAPDiag(b *strings.Builder, indent, y string) {
+	if y == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s  ---\n", indent)
+	for line := range strings.Lines(y) {
+		fmt.Fprintf(b, "%s  %s", indent, line)
+	}
+	fmt.Fprintf(b, "%s  ...\n", indent)
+}
+
+// yamlBlock writes text as a YAML literal block scalar with the given key.
+func yamlBlock(y *strings.Builder, key, text string) {
+	// If the first line is indented, the indentation of the block
+	// must be given explicitly.
+	indicator := ""
+	if strings.HasPrefix(text, " ") {
+		indicator = "2"
+	}
+	fmt.Fprintf(y, "%s: |%s\n", key, indicator)
+	for line := range strings.Lines(text) {
+		if strings.TrimSpace(line) == "" {
+			y.WriteString("\n")
+			continue
+		}
+		y.WriteString("  " + line)
+	}
+	if !strings.HasSuffix(text, "\n") {
+		y.WriteString("\n")
+	}
+}
+
+// yamlString returns s as a double-quoted YAML string.
+// Any JSON string is a valid YAML string.
+func yamlString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// tapEscape escapes s for use in the description of a TAP test point.
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "#", `\#`)
+}
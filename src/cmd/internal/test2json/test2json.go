@@ -36,6 +36,7 @@ type event struct {
 	Elapsed     *float64   `json:",omitempty"`
 	Output      *textBytes `json:",omitempty"`
 	FailedBuild string     `json:",omitempty"`
+	Shard       string     `json:",omitempty"`
 	Key         string     `json:",omitempty"`
 	Value       string     `json:",omitempty"`
 	Path        string     `json:",omitempty"`
@@ -67,6 +68,9 @@ type Converter struct {
 	// failedBuild is set to the package ID of the cause of a build failure,
 	// if that's what caused this test to fail.
 	failedBuild string
+
+	// shard is the shard of the tests being run, if they are sharded.
+	shard string
 }
 
 // inBuffer and outBuffer are the input and output buffer sizes.
@@ -110,10 +114,17 @@ var (
 // The pkg string, if present, specifies the import path to
 // report in the JSON stream.
 func NewConverter(w io.Writer, pkg string, mode Mode) *Converter {
+	return NewShardConverter(w, pkg, "", mode)
+}
+
+// NewShardConverter is like NewConverter, but for tests run as one shard
+// of a package's tests, such as "1/3", which is reported in every event.
+func NewShardConverter(w io.Writer, pkg, shard string, mode Mode) *Converter {
 	c := new(Converter)
 	*c = Converter{
 		w:     w,
 		pkg:   pkg,
+		shard: shard,
 		mode:  mode,
 		start: time.Now(),
 		input: lineBuffer{
@@ -189,6 +200,8 @@ var (
 		[]byte("--- FAIL: "),
 		[]byte("--- SKIP: "),
 		[]byte("--- BENCH: "),
+		[]byte("--- RETRY: "),
+		[]byte("--- FLAKY: "),
 	}
 
 	fourSpace = []byte("    ")
@@ -267,6 +280,8 @@ func (c *Converter) handleInputLine(line []byte) {
 		// "--- FAIL: "
 		// "--- SKIP: "
 		// "--- BENCH: "
+		// "--- RETRY: "
+		// "--- FLAKY: "
 		// but possibly indented.
 		for bytes.HasPrefix(line, fourSpace) {
 			line = line[4:]
@@ -407,6 +422,7 @@ func (c *Converter) writeOutputEvent(out []byte) {
 // It adds the package, time (if requested), and test name (if needed).
 func (c *Converter) writeEvent(e *event) {
 	e.Package = c.pkg
+	e.Shard = c.shard
 	if c.mode&Timestamp != 0 {
 		t := time.Now()
 		e.Time = &t
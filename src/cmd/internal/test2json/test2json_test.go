@@ -285,3 +285,59 @@ func TestTrimUTF8(t *testing.T) {
 		}
 	}
 }
+
+func TestFormat(t *testing.T) {
+	in, err := os.ReadFile("testdata/format.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"junit", "tap"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			f, err := NewFormatter(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Report the same tests for two packages, and interleave
+			// their events as when "go test" runs them in parallel.
+			var ja, jb bytes.Buffer
+			ca := NewConverter(&ja, "example.com/a", 0)
+			cb := NewConverter(&jb, "example.com/b", 0)
+			ca.Write(in)
+			cb.Write(in)
+			ca.Close()
+			cb.Close()
+			la := bytes.SplitAfter(ja.Bytes(), []byte("\n"))
+			lb := bytes.SplitAfter(jb.Bytes(), []byte("\n"))
+			for i := range max(len(la), len(lb)) {
+				if i < len(la) {
+					f.Write(la[i])
+				}
+				if i < len(lb) {
+					f.Write(lb[i])
+				}
+			}
+			f.Close()
+
+			file := "testdata/format." + format
+			if *update {
+				t.Logf("rewriting %s", file)
+				if err := os.WriteFile(file, buf.Bytes(), 0666); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("wrong output:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewFormatter(io.Discard, "xml"); err == nil {
+		t.Errorf("NewFormatter with unknown format succeeded")
+	}
+}
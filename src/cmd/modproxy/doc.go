@@ -0,0 +1,24 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Modproxy serves a module download cache over HTTP using the module
+proxy protocol described in 'go help goproxy'.
+
+Usage:
+
+	go tool modproxy [-addr host:port] cachedir [sumdbdir]
+
+Cachedir is a download cache directory laid out like
+$GOMODCACHE/cache/download. Sumdbdir, if given, holds the latest
+checksum database tree heads, laid out like $GOPATH/pkg/sumdb;
+without it, modproxy does not proxy checksum databases.
+
+This tool is only intended for use by 'go mod proxy serve',
+which documents what it serves. It is a separate tool, rather than
+part of the go command, because the go command does not link in the
+net/http server; being part of the Go distribution, it is still
+available wherever the go command is.
+*/
+package main
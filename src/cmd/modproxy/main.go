@@ -0,0 +1,64 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"cmd/internal/telemetry/counter"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: go tool modproxy [-addr host:port] cachedir [sumdbdir]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+var addr = flag.String("addr", "localhost:8080", "listen on `address`")
+
+func main() {
+	log.SetPrefix("modproxy: ")
+	log.SetFlags(0)
+	counter.Open()
+	flag.Usage = usage
+	flag.Parse()
+	counter.Inc("modproxy/invocations")
+	counter.CountFlags("modproxy/flag:", *flag.CommandLine)
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		usage()
+	}
+
+	s := &Server{Dir: flag.Arg(0), SumdbDir: flag.Arg(1)}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("serving module cache %s at http://%s", s.Dir, l.Addr())
+
+	// Stop serving when interrupted, which is how
+	// 'go mod proxy serve' is meant to stop.
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(l); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/tlog"
+)
+
+// A Server is an http.Handler that serves the modules in a download cache,
+// along with the checksum database lookups and tiles cached there.
+// It serves only what is already in the cache: it never downloads anything.
+//
+// The go command writes the .info, .mod, and .zip files of the cache
+// atomically, but rewrites the version lists and the latest checksum
+// database tree heads in place, holding a file lock that the server
+// does not take. A request racing with such a write may see a partial
+// file: a truncated version list, or a tree head that fails verification
+// by the go command fetching it. Either is only as bad as a failed
+// request, which the client can repeat.
+type Server struct {
+	// Dir is the download cache directory, laid out like
+	// $GOMODCACHE/cache/download.
+	Dir string
+
+	// SumdbDir is the directory holding the latest checksum database
+	// tree heads, laid out like $GOPATH/pkg/sumdb.
+	// If SumdbDir is empty, the server does not proxy checksum databases.
+	SumdbDir string
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := strings.TrimPrefix(r.URL.Path, "/")
+
+	if rest, ok := strings.CutPrefix(p, "sumdb/"); ok {
+		s.serveSumdb(w, r, rest)
+		return
+	}
+
+	// $GOPROXY/<module>/@latest
+	if enc, ok := strings.CutSuffix(p, "/@latest"); ok {
+		if _, err := module.UnescapePath(enc); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		latest := s.latest(enc)
+		if latest == "" {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveFile(w, r, filepath.Join(s.Dir, filepath.FromSlash(enc), "@v", latest+".info"), "application/json")
+		return
+	}
+
+	// $GOPROXY/<module>/@v/list and $GOPROXY/<module>/@v/<version>.{info,mod,zip}
+	enc, file, ok := strings.Cut(p, "/@v/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := module.UnescapePath(enc); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	dir := filepath.Join(s.Dir, filepath.FromSlash(enc), "@v")
+	if file == "list" {
+		s.serveList(w, r, dir)
+		return
+	}
+	encVers, ext, ok := cutLast(file, ".")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := module.UnescapeVersion(encVers); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	var contentType string
+	switch ext {
+	case "info":
+		contentType = "application/json"
+	case "mod":
+		contentType = "text/plain; charset=utf-8"
+	case "zip":
+		contentType = "application/zip"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	s.serveFile(w, r, filepath.Join(dir, file), contentType)
+}
+
+// cutLast is like strings.Cut but cuts around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// serveList serves the list of versions of the module whose cache
+// directory is dir, omitting pseudo-versions.
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, dir string) {
+	if _, err := os.Stat(dir); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "list"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var list bytes.Buffer
+	for v := range strings.FieldsSeq(string(data)) {
+		if !module.IsPseudoVersion(v) {
+			list.WriteString(v + "\n")
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeContent(w, r, "list", time.Time{}, bytes.NewReader(list.Bytes()))
+}
+
+// latest returns the escaped latest version of the module with the
+// escaped path enc that has a .info file in the cache, or "" if there is
+// none. Like the go command in direct mode, it prefers the latest release,
+// then the latest pre-release, then the latest pseudo-version.
+func (s *Server) latest(enc string) string {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, filepath.FromSlash(enc), "@v"))
+	if err != nil {
+		return ""
+	}
+	var latest, latestEnc string
+	rank := func(v string) int {
+		switch {
+		case module.IsPseudoVersion(v):
+			return 0
+		case semver.Prerelease(v) != "":
+			return 1
+		}
+		return 2
+	}
+	for _, e := range entries {
+		encVers, ok := strings.CutSuffix(e.Name(), ".info")
+		if !ok {
+			continue
+		}
+		v, err := module.UnescapeVersion(encVers)
+		if err != nil || module.CanonicalVersion(v) != v {
+			continue
+		}
+		if latest == "" || rank(v) > rank(latest) || rank(v) == rank(latest) && semver.Compare(v, latest) > 0 {
+			latest, latestEnc = v, encVers
+		}
+	}
+	return latestEnc
+}
+
+// serveSumdb serves a request for $GOPROXY/sumdb/<rest>,
+// where rest is <sumdb-name>/<path>, from the cached checksum database
+// lookups and tiles. See https://go.dev/design/25530-sumdb#proxying-a-checksum-database.
+func (s *Server) serveSumdb(w http.ResponseWriter, r *http.Request, rest string) {
+	if s.SumdbDir == "" {
+		http.NotFound(w, r)
+		return
+	}
+	// The name of a checksum database is a host name,
+	// optionally followed by a path.
+	var name, file string
+	for _, op := range []string{"/supported", "/latest", "/lookup/", "/tile/"} {
+		if i := strings.Index(rest, op); i > 0 {
+			name, file = rest[:i], rest[i+1:]
+			break
+		}
+	}
+	if name == "" || path.Clean(name) != name || strings.HasPrefix(name, ".") || strings.Contains(name, "/.") {
+		http.NotFound(w, r)
+		return
+	}
+	dir := filepath.Join(s.Dir, "sumdb", filepath.FromSlash(name))
+
+	switch {
+	case file == "supported":
+		if _, err := os.Stat(dir); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case file == "latest":
+		data, err := os.ReadFile(filepath.Join(s.SumdbDir, filepath.FromSlash(name), "latest"))
+		if err != nil || len(data) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeContent(w, r, "latest", time.Time{}, bytes.NewReader(data))
+
+	case strings.HasPrefix(file, "lookup/"):
+		mod := strings.TrimPrefix(file, "lookup/")
+		enc, encVers, ok := strings.Cut(mod, "@")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := module.UnescapePath(enc); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := module.UnescapeVersion(encVers); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveFile(w, r, filepath.Join(dir, "lookup", filepath.FromSlash(mod)), "text/plain; charset=utf-8")
+
+	default:
+		t, err := tlog.ParseTilePath(file)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		// The go command caches only complete tiles, but a partial tile
+		// is a prefix of the complete tile, if we have it.
+		// (That is not true for data tiles, which hold records, not hashes.)
+		full := t
+		full.W = 1 << t.H
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(t.Path())))
+		if err != nil && t.W != full.W && t.L >= 0 {
+			data, err = os.ReadFile(filepath.Join(dir, filepath.FromSlash(full.Path())))
+			if err == nil && len(data) == full.W*tlog.HashSize {
+				data = data[:t.W*tlog.HashSize]
+			} else {
+				err = fs.ErrNotExist
+			}
+		}
+		if err != nil || len(data) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(w, r, "tile", time.Time{}, bytes.NewReader(data))
+	}
+}
+
+// serveFile serves the named file with the given content type.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, name, contentType string) {
+	f, err := os.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, filepath.Base(name), info.ModTime(), f)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "download")
+	sumdb := filepath.Join(dir, "sumdb")
+	fullTile := bytes.Repeat([]byte("0123456789abcdef0123456789abcdef"), 256)
+	writeFiles(t, cache, map[string]string{
+		"example.com/a/@v/list":                                    "v1.0.0\nv1.1.0-pre\nv0.0.0-20250101000000-0123456789ab\n",
+		"example.com/a/@v/v1.0.0.info":                             `{"Version":"v1.0.0"}`,
+		"example.com/a/@v/v1.0.0.mod":                              "module example.com/a\n",
+		"example.com/a/@v/v1.0.0.zip":                              "zip",
+		"example.com/a/@v/v1.1.0-pre.info":                         `{"Version":"v1.1.0-pre"}`,
+		"example.com/a/@v/v0.0.0-20250101000000-0123456789ab.info": `{"Version":"v0.0.0-20250101000000-0123456789ab"}`,
+		"example.com/b/@v/v1.1.0-pre.info":                         `{"Version":"v1.1.0-pre"}`,
+		"example.com/b/@v/v0.0.0-20250101000000-0123456789ab.info": `{"Version":"v0.0.0-20250101000000-0123456789ab"}`,
+		"example.com/!upper/@v/v1.0.0.mod":                         "module example.com/Upper\n",
+		"example.com/empty/@v/v1.0.0.lock":                         "",
+		"sumdb/sum.example.com/lookup/example.com/a@v1.0.0":        "lookup",
+		"sumdb/sum.example.com/tile/8/0/000":                       string(fullTile),
+	})
+	writeFiles(t, sumdb, map[string]string{
+		"sum.example.com/latest": "go.sum database tree\n1\n",
+	})
+	srv := httptest.NewServer(&Server{Dir: cache, SumdbDir: sumdb})
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/example.com/a/@v/list", 200, "v1.0.0\nv1.1.0-pre\n"},
+		{"/example.com/a/@v/v1.0.0.info", 200, `{"Version":"v1.0.0"}`},
+		{"/example.com/a/@v/v1.0.0.mod", 200, "module example.com/a\n"},
+		{"/example.com/a/@v/v1.0.0.zip", 200, "zip"},
+		{"/example.com/a/@v/v1.0.0.ziphash", 404, ""},
+		{"/example.com/a/@v/v1.2.0.info", 404, ""},
+		{"/example.com/a/@latest", 200, `{"Version":"v1.0.0"}`},
+		{"/example.com/b/@latest", 200, `{"Version":"v1.1.0-pre"}`},
+		{"/example.com/!upper/@v/v1.0.0.mod", 200, "module example.com/Upper\n"},
+		{"/example.com/Upper/@v/v1.0.0.mod", 404, ""},
+		{"/example.com/empty/@v/list", 200, ""},
+		{"/example.com/empty/@latest", 404, ""},
+		{"/example.com/missing/@v/list", 404, ""},
+		{"/example.com/a/@v/../../b/@v/list", 404, ""},
+		{"/sumdb/sum.example.com/supported", 200, ""},
+		{"/sumdb/sum.example.com/latest", 200, "go.sum database tree\n1\n"},
+		{"/sumdb/sum.example.com/lookup/example.com/a@v1.0.0", 200, "lookup"},
+		{"/sumdb/sum.example.com/lookup/example.com/b@v1.0.0", 404, ""},
+		{"/sumdb/sum.example.com/tile/8/0/000", 200, string(fullTile)},
+		{"/sumdb/sum.example.com/tile/8/0/000.p/2", 200, string(fullTile[:64])},
+		{"/sumdb/sum.example.com/tile/8/0/001.p/2", 404, ""},
+		{"/sumdb/sum.example.com/tile/8/data/000.p/2", 404, ""},
+		{"/sumdb/sum.other.com/supported", 404, ""},
+		{"/sumdb/../latest", 404, ""},
+	} {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.code || tt.code == 200 && string(body) != tt.body {
+			t.Errorf("GET %s: %s %q, want %d %q", tt.path, resp.Status, body, tt.code, tt.body)
+		}
+	}
+
+	resp, err := http.Post(srv.URL+"/example.com/a/@v/list", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST: %s, want 405", resp.Status)
+	}
+}
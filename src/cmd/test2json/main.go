@@ -6,7 +6,7 @@
 //
 // Usage:
 //
-//	go tool test2json [-p pkg] [-t] [./pkg.test -test.v=test2json]
+//	go tool test2json [-p pkg] [-t] [-format format] [./pkg.test -test.v=test2json]
 //
 // Test2json runs the given test command and converts its output to JSON;
 // with no command specified, test2json expects test output on standard input.
@@ -18,6 +18,10 @@
 //
 // The -t flag requests that time stamps be added to each test event.
 //
+// The -format flag requests a report of the tests in another format
+// instead of the JSON stream: "junit" for JUnit XML, or "tap" for TAP
+// version 14. See "Other Formats" below.
+//
 // The test should be invoked with -test.v=test2json. Using only -test.v
 // (or -test.v=true) is permissible but produces lower fidelity results.
 //
@@ -42,6 +46,7 @@
 //		Elapsed     float64 // seconds
 //		Output      string
 //		FailedBuild string
+//		Shard       string
 //	}
 //
 // The Time field holds the time the event happened.
@@ -58,6 +63,8 @@
 //	fail   - the test or benchmark failed
 //	output - the test printed output
 //	skip   - the test was skipped or the package contained no tests
+//	retry  - the test failed and is about to be run again
+//	flaky  - the test failed, but passed when run again or is known to be flaky
 //
 // Every JSON stream begins with a "start" event.
 //
@@ -70,8 +77,16 @@
 // function that caused the event. Events for the overall package test
 // do not set Test.
 //
-// The Elapsed field is set for "pass" and "fail" events. It gives the time
-// elapsed for the specific test or the overall package test that passed or failed.
+// The Elapsed field is set for "pass", "fail", "retry", and "flaky" events.
+// It gives the time elapsed for the specific test or the overall package test
+// that passed or failed, or for the single attempt at running a retried test.
+//
+// When a test is retried, as requested by "go test -retry", each failed
+// attempt at running it ends with a "retry" event, and the next attempt
+// starts with a new "run" event. The final attempt ends with a "fail" event
+// if the test never passed, or with a "flaky" event if it did.
+// A test marked as known to be flaky, using testing.T.Flaky, also ends
+// with a "flaky" event when it fails.
 //
 // The Output field is set for Action == "output" and is a portion of the test's output
 // (standard output and standard error merged together). The output is
@@ -85,6 +100,11 @@
 // failed to build. This matches the ImportPath field of the "go list" output,
 // as well as the BuildEvent.ImportPath field as emitted by "go build -json".
 //
+// The Shard field is set for every event when "go test -json" is run
+// with -shard. It holds the value of the -shard flag, such as "1/3",
+// so that the events of several shards can be told apart when their
+// output is merged.
+//
 // When a benchmark runs, it typically produces a single line of output
 // giving timing results. That line is reported in an event with Action == "output"
 // and no Test field. If a benchmark logs output or reports a failure
@@ -92,6 +112,25 @@
 // as a sequence of events with Test set to the benchmark name, terminated
 // by a final event with Action == "bench" or "fail".
 // Benchmarks have no events with Action == "pause".
+//
+// # Other Formats
+//
+// With -format=junit, test2json writes a JUnit XML report once the test
+// binary exits. Each package is a testsuite element, and each test, example,
+// or benchmark a testcase element. Subtests are testcase elements nested in
+// the testcase of their parent test. A failed test has a failure element,
+// and a skipped test a skipped element, whose message is the last line the
+// test logged. Attributes set with testing.T.Attr are property elements,
+// and the artifact directory of a test, if any, is an attachment, written
+// as "[[ATTACHMENT|dir]]" in its system-out element. Retried tests use the
+// flakyFailure and rerunFailure elements of Maven Surefire for their failed
+// attempts.
+//
+// With -format=tap, test2json writes a TAP version 14 report, one package
+// at a time. Each package is a subtest, as is each test with subtests.
+// Skip reasons are given as SKIP directives, and the elapsed time, output,
+// attributes, and artifact directory of each test are given in its YAML
+// diagnostics.
 package main
 
 import (
@@ -107,12 +146,13 @@ import (
 )
 
 var (
-	flagP = flag.String("p", "", "report `pkg` as the package being tested in each event")
-	flagT = flag.Bool("t", false, "include timestamps in events")
+	flagP      = flag.String("p", "", "report `pkg` as the package being tested in each event")
+	flagT      = flag.Bool("t", false, "include timestamps in events")
+	flagFormat = flag.String("format", "json", "write output in `format`: json, junit, or tap")
 )
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: go tool test2json [-p pkg] [-t] [./pkg.test -test.v]\n")
+	fmt.Fprintf(os.Stderr, "usage: go tool test2json [-p pkg] [-t] [-format format] [./pkg.test -test.v]\n")
 	os.Exit(2)
 }
 
@@ -133,9 +173,18 @@ func main() {
 	if *flagT {
 		mode |= test2json.Timestamp
 	}
-	c := test2json.NewConverter(os.Stdout, *flagP, mode)
-	defer c.Close()
+	var out io.WriteCloser = nopCloser{os.Stdout}
+	if *flagFormat != "json" {
+		f, err := test2json.NewFormatter(os.Stdout, *flagFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test2json: %v\n", err)
+			usage()
+		}
+		out = f
+	}
+	c := test2json.NewConverter(out, *flagP, mode)
 
+	exitCode := 0
 	if flag.NArg() == 0 {
 		io.Copy(c, os.Stdin)
 	} else {
@@ -155,12 +204,24 @@ func main() {
 		}
 		c.Exited(err)
 		if err != nil {
-			c.Close()
-			os.Exit(1)
+			exitCode = 1
 		}
 	}
+	c.Close()
+	if err := out.Close(); err != nil {
+		// A JUnit or TAP report is written when out is closed.
+		fmt.Fprintf(os.Stderr, "test2json: writing %s output: %v\n", *flagFormat, err)
+		exitCode = 1
+	}
+	os.Exit(exitCode)
 }
 
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
 type countWriter struct {
 	n int64
 	w io.Writer
@@ -0,0 +1,18 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fips140
+
+import "crypto/internal/fips140deps/godebug"
+
+var enforced = godebug.New("fips140").Value() == "only"
+
+// Enforced reports whether strict FIPS 140-3 enforcement is enabled, in which
+// non-approved cryptography functions return errors or panic.
+//
+// Strict enforcement is enabled when a program is run with
+// GODEBUG=fips140=only. It can't be changed after the program has started.
+func Enforced() bool {
+	return enforced
+}
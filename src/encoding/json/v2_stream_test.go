@@ -9,10 +9,7 @@ package json
 import (
 	"bytes"
 	"io"
-	"log"
 	"net"
-	"net/http"
-	"net/http/httptest"
 	"reflect"
 	"runtime/debug"
 	"strings"
@@ -470,38 +467,6 @@ func TestDecodeInStream(t *testing.T) {
 }
 
 // Test from golang.org/issue/11893
-func TestHTTPDecoding(t *testing.T) {
-	const raw = `{ "foo": "bar" }`
-
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(raw))
-	}))
-	defer ts.Close()
-	res, err := http.Get(ts.URL)
-	if err != nil {
-		log.Fatalf("http.Get error: %v", err)
-	}
-	defer res.Body.Close()
-
-	foo := struct {
-		Foo string
-	}{}
-
-	d := NewDecoder(res.Body)
-	err = d.Decode(&foo)
-	if err != nil {
-		t.Fatalf("Decode error: %v", err)
-	}
-	if foo.Foo != "bar" {
-		t.Errorf(`Decode: got %q, want "bar"`, foo.Foo)
-	}
-
-	// make sure we get the EOF the second time
-	err = d.Decode(&foo)
-	if err != io.EOF {
-		t.Errorf("Decode error:\n\tgot:  %v\n\twant: io.EOF", err)
-	}
-}
 
 func TestTokenTruncation(t *testing.T) {
 	tests := []struct {
@@ -590,7 +590,7 @@ var depsRules = `
 
 	crypto/internal/boring/sig, crypto/tls/internal/fips140tls < crypto/tls/fipsonly;
 
-	CRYPTO, golang.org/x/sys/cpu, encoding/binary, reflect
+	CRYPTO, crypto/fips140, golang.org/x/sys/cpu, encoding/binary, reflect
 	< golang.org/x/crypto/internal/alias
 	< golang.org/x/crypto/internal/subtle
 	< golang.org/x/crypto/chacha20
@@ -638,13 +638,27 @@ var depsRules = `
 	NET, crypto/tls
 	< net/http/httptrace;
 
+	crypto/hkdf, crypto/hmac
+	< golang.org/x/crypto/hkdf;
+
+	encoding/binary
+	< golang.org/x/net/internal/quic/quicwire;
+
+	crypto/tls, log/slog, math/rand/v2,
+	golang.org/x/crypto/chacha20poly1305,
+	golang.org/x/crypto/hkdf,
+	golang.org/x/net/internal/quic/quicwire
+	< golang.org/x/net/quic;
+
 	compress/gzip,
+	compress/zlib,
 	golang.org/x/net/http/httpguts,
 	golang.org/x/net/http/httpproxy,
 	golang.org/x/net/http2/hpack,
 	net/http/internal,
 	net/http/internal/ascii,
 	net/http/internal/testcert,
+	golang.org/x/net/quic,
 	net/http/httptrace,
 	mime/multipart,
 	log
@@ -657,7 +671,13 @@ var depsRules = `
 	< expvar;
 
 	net/http, net/http/internal/ascii
-	< net/http/cookiejar, net/http/httputil;
+	< net/http/httputil;
+
+	encoding/json, net/http, net/http/internal/ascii
+	< net/http/cookiejar;
+
+	net/http
+	< net/http/sse;
 
 	net/http, flag
 	< net/http/httptest;
@@ -693,7 +713,10 @@ var depsRules = `
 	FMT, flag, math/rand
 	< testing/quick;
 
-	FMT, DEBUG, flag, runtime/trace, internal/sysinfo, math/rand
+	FMT, sort
+	< internal/diff;
+
+	FMT, DEBUG, flag, runtime/trace, internal/sysinfo, internal/diff, math/rand
 	< testing;
 
 	log/slog, testing
@@ -719,9 +742,6 @@ var depsRules = `
 	syscall
 	< os/exec/internal/fdtest;
 
-	FMT, sort
-	< internal/diff;
-
 	FMT
 	< internal/txtar;
 
@@ -41,6 +41,7 @@ const KnownEnv = `
 	GOBIN
 	GOCACHE
 	GOCACHEPROG
+	GOCACHEURL
 	GOENV
 	GOEXE
 	GOEXPERIMENT
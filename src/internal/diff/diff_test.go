@@ -2,10 +2,11 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package diff
+package diff_test
 
 import (
 	"bytes"
+	"internal/diff"
 	"internal/txtar"
 	"path/filepath"
 	"testing"
@@ -32,11 +33,11 @@ func Test(t *testing.T) {
 			if len(a.Files) != 3 || a.Files[2].Name != "diff" {
 				t.Fatalf("%s: want three files, third named \"diff\"", file)
 			}
-			diffs := Diff(a.Files[0].Name, clean(a.Files[0].Data), a.Files[1].Name, clean(a.Files[1].Data))
+			diffs := diff.Diff(a.Files[0].Name, clean(a.Files[0].Data), a.Files[1].Name, clean(a.Files[1].Data))
 			want := clean(a.Files[2].Data)
 			if !bytes.Equal(diffs, want) {
 				t.Fatalf("%s: have:\n%s\nwant:\n%s\n%s", file,
-					diffs, want, Diff("have", diffs, "want", want))
+					diffs, want, diff.Diff("have", diffs, "want", want))
 			}
 		})
 	}
@@ -0,0 +1,116 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+)
+
+// When fuzzing, the compiler instruments comparisons in the code being fuzzed
+// with calls to the libfuzzerTraceCmp and libfuzzerHookStrCmp functions (see
+// trace.go). Worker processes record the operands of those comparisons in
+// cmpTable, and report them to the coordinator after each call to the fuzz
+// function, which hands them back to all workers as tokens for the mutator.
+// An input that contains a value the code compares against, such as a
+// keyword or a magic number, is much more likely to reach new code.
+
+const (
+	// cmpTableSize is the number of comparison sites whose operands are
+	// remembered between harvests. Sites are identified by the fake PC
+	// the compiler passes to the hooks, so collisions simply overwrite.
+	cmpTableSize = 256
+
+	// maxTokenLen is the maximum length of a harvested operand.
+	maxTokenLen = 64
+
+	// maxCmpTokens is the maximum number of harvested tokens the coordinator
+	// keeps and sends to workers.
+	maxCmpTokens = 256
+)
+
+// cmpTracing is set in worker processes to enable recording of comparison
+// operands.
+var cmpTracing atomic.Bool
+
+var cmpTable struct {
+	// mu guards entries. Comparisons made while another goroutine holds mu
+	// are not recorded, so the hooks never block.
+	mu      sync.Mutex
+	entries [cmpTableSize]cmpEntry
+}
+
+// cmpEntry holds the operands of one comparison. Operands are stored inline
+// so that recording them does not allocate; n0 or n1 is zero if the
+// corresponding operand is absent or not worth recording.
+type cmpEntry struct {
+	n0, n1 uint8
+	b0, b1 [maxTokenLen]byte
+}
+
+// traceCmpInt records the operands of an integer comparison of the given size
+// in bytes. If isConst is set, arg0 is a constant and arg1 is not recorded,
+// since it likely came from the input.
+func traceCmpInt(arg0, arg1 uint64, size int, isConst bool, fakePC uint) {
+	if !cmpTracing.Load() || arg0 == arg1 {
+		return
+	}
+	if !cmpTable.mu.TryLock() {
+		return
+	}
+	e := &cmpTable.entries[fakePC%cmpTableSize]
+	*e = cmpEntry{}
+	if arg0 != 0 {
+		binary.LittleEndian.PutUint64(e.b0[:], arg0)
+		e.n0 = uint8(size)
+	}
+	if !isConst && arg1 != 0 {
+		binary.LittleEndian.PutUint64(e.b1[:], arg1)
+		e.n1 = uint8(size)
+	}
+	cmpTable.mu.Unlock()
+}
+
+// traceCmpString records the operands of a string comparison.
+func traceCmpString(s0, s1 string, fakePC uint) {
+	if !cmpTracing.Load() || s0 == s1 {
+		return
+	}
+	if !cmpTable.mu.TryLock() {
+		return
+	}
+	e := &cmpTable.entries[fakePC%cmpTableSize]
+	*e = cmpEntry{}
+	if len(s0) <= maxTokenLen {
+		e.n0 = uint8(copy(e.b0[:], s0))
+	}
+	if len(s1) <= maxTokenLen {
+		e.n1 = uint8(copy(e.b1[:], s1))
+	}
+	cmpTable.mu.Unlock()
+}
+
+// harvestCmpTokens returns the distinct operands recorded since the previous
+// call, and clears the table.
+func harvestCmpTokens() [][]byte {
+	cmpTable.mu.Lock()
+	defer cmpTable.mu.Unlock()
+	var tokens [][]byte
+	seen := make(map[string]bool)
+	add := func(b []byte) {
+		if len(b) > 0 && !seen[string(b)] {
+			seen[string(b)] = true
+			tokens = append(tokens, append([]byte(nil), b...))
+		}
+	}
+	for i := range cmpTable.entries {
+		e := &cmpTable.entries[i]
+		add(e.b0[:e.n0])
+		add(e.b1[:e.n1])
+		*e = cmpEntry{}
+	}
+	return tokens
+}
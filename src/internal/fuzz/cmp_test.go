@@ -0,0 +1,63 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHarvestCmpTokens(t *testing.T) {
+	harvestCmpTokens() // clear anything recorded by other tests
+
+	// Nothing is recorded unless tracing is enabled.
+	traceCmpString("GET", "PUT", 1)
+	if got := harvestCmpTokens(); len(got) != 0 {
+		t.Fatalf("harvested %q with tracing disabled", got)
+	}
+
+	cmpTracing.Store(true)
+	defer cmpTracing.Store(false)
+	traceCmpString("GET", "PUT", 1)
+	traceCmpString("same", "same", 2)
+	traceCmpString("HEAD", string(make([]byte, maxTokenLen+1)), 3)
+	traceCmpInt(0x1234, 7, 2, true, 4)
+	traceCmpInt(0xdeadbeef, 0, 4, false, 5)
+	traceCmpString("GET", "POST", 6)
+
+	var got []string
+	for _, tok := range harvestCmpTokens() {
+		got = append(got, string(tok))
+	}
+	slices.Sort(got)
+	want := []string{"GET", "HEAD", "POST", "PUT", "\x34\x12", "\xef\xbe\xad\xde"}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("harvested %q, want %q", got, want)
+	}
+	if got := harvestCmpTokens(); len(got) != 0 {
+		t.Errorf("harvested %q after table was cleared", got)
+	}
+}
+
+func TestMutateIntToken(t *testing.T) {
+	m := newMutator()
+	m.dict = [][]byte{{0xef, 0xbe, 0xad, 0xde}, {0x00, 0x80}}
+	var sawUint, sawInt bool
+	for range 1000 {
+		if m.mutateUInt(0, maxUint) == 0xdeadbeef {
+			sawUint = true
+		}
+		if m.mutateInt(0, maxInt) == -0x8000 {
+			sawInt = true
+		}
+	}
+	if !sawUint {
+		t.Error("mutateUInt never produced a 4-byte token")
+	}
+	if !sawInt {
+		t.Error("mutateInt never produced a sign-extended 2-byte token")
+	}
+}
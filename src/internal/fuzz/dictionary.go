@@ -0,0 +1,100 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+// ReadDictionary reads a fuzzing dictionary from the file at path. If the file
+// does not exist, ReadDictionary returns no tokens and no error.
+//
+// The file uses the format of AFL and libFuzzer dictionaries. Each line holds
+// one token written as a double-quoted string, optionally preceded by a name
+// and '='. Within the quotes, a backslash may only escape a backslash, a
+// double quote, or start a \xHH hexadecimal byte. Blank lines and lines
+// starting with '#' are ignored. For example:
+//
+//	# JSON keywords
+//	kw_true="true"
+//	"null"
+//	magic="\x89PNG"
+func ReadDictionary(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	tokens, err := parseDictionary(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", path, err)
+	}
+	return tokens, nil
+}
+
+// parseDictionary parses the contents of a dictionary file. See ReadDictionary
+// for the format.
+func parseDictionary(data []byte) ([][]byte, error) {
+	var tokens [][]byte
+	lineNum := 0
+	for line := range bytes.Lines(data) {
+		lineNum++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		tok, err := parseDictionaryLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %v", lineNum, err)
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+func parseDictionaryLine(line []byte) ([]byte, error) {
+	q := bytes.IndexByte(line, '"')
+	if q < 0 || line[len(line)-1] != '"' || q == len(line)-1 {
+		return nil, errors.New(`token must be enclosed in double quotes`)
+	}
+	if name := line[:q]; len(name) > 0 {
+		if name[len(name)-1] != '=' || bytes.ContainsAny(name[:len(name)-1], " \t\"=") {
+			return nil, fmt.Errorf("malformed token name %q", name)
+		}
+	}
+	var tok []byte
+	for s := line[q+1 : len(line)-1]; len(s) > 0; {
+		c := s[0]
+		switch {
+		case c == '"':
+			return nil, errors.New(`unescaped double quote in token`)
+		case c != '\\':
+			tok = append(tok, c)
+			s = s[1:]
+		case len(s) >= 2 && (s[1] == '\\' || s[1] == '"'):
+			tok = append(tok, s[1])
+			s = s[2:]
+		case len(s) >= 4 && s[1] == 'x':
+			b, err := strconv.ParseUint(string(s[2:4]), 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid escape %q in token", s[:4])
+			}
+			tok = append(tok, byte(b))
+			s = s[4:]
+		default:
+			return nil, errors.New(`invalid escape in token`)
+		}
+	}
+	if len(tok) == 0 {
+		return nil, errors.New("empty token")
+	}
+	return tok, nil
+}
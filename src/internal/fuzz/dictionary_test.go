@@ -0,0 +1,84 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDictionary(t *testing.T) {
+	tests := []struct {
+		in   string
+		want [][]byte
+		ok   bool
+	}{
+		{
+			in:   "",
+			want: nil,
+			ok:   true,
+		},
+		{
+			in: `# comment
+kw1="true"
+
+  "null"
+magic="\x89PNG\x0d\x0a"
+esc="a\\b\"c"
+`,
+			want: [][]byte{
+				[]byte("true"),
+				[]byte("null"),
+				[]byte("\x89PNG\r\n"),
+				[]byte(`a\b"c`),
+			},
+			ok: true,
+		},
+		{in: `true`},
+		{in: `"true`},
+		{in: `""`},
+		{in: `kw "true"`},
+		{in: `kw="tr"ue"`},
+		{in: `kw="\n"`},
+		{in: `kw="\xZZ"`},
+		{in: `kw="\x1"`},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := parseDictionary([]byte(test.in))
+			if err != nil {
+				if test.ok {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !test.ok {
+				t.Fatalf("unexpected success: %q", got)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadDictionary(t *testing.T) {
+	dir := t.TempDir()
+	tokens, err := ReadDictionary(filepath.Join(dir, "missing.dict"))
+	if err != nil || tokens != nil {
+		t.Fatalf("ReadDictionary of missing file = %q, %v; want nil, nil", tokens, err)
+	}
+
+	path := filepath.Join(dir, "bad.dict")
+	if err := os.WriteFile(path, []byte("\"ok\"\nbad\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadDictionary(path)
+	if want := path + ":2: token must be enclosed in double quotes"; err == nil || err.Error() != want {
+		t.Fatalf("ReadDictionary of malformed file: got error %v, want %q", err, want)
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"internal/fmtsort"
 	"math"
+	"reflect"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -26,80 +28,164 @@ func marshalCorpusFile(vals ...any) []byte {
 		panic("must have at least one value to marshal")
 	}
 	b := bytes.NewBuffer([]byte(encVersion1 + "\n"))
+	for _, val := range vals {
+		marshalValue(b, val)
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// marshalValue writes the encoding of a single value to b, without a
+// trailing newline.
+func marshalValue(b *bytes.Buffer, val any) {
 	// TODO(katiehockman): keep uint8 and int32 encoding where applicable,
 	// instead of changing to byte and rune respectively.
-	for _, val := range vals {
-		switch t := val.(type) {
-		case int, int8, int16, int64, uint, uint16, uint32, uint64, bool:
-			fmt.Fprintf(b, "%T(%v)\n", t, t)
-		case float32:
-			if math.IsNaN(float64(t)) && math.Float32bits(t) != math.Float32bits(float32(math.NaN())) {
-				// We encode unusual NaNs as hex values, because that is how users are
-				// likely to encounter them in literature about floating-point encoding.
-				// This allows us to reproduce fuzz failures that depend on the specific
-				// NaN representation (for float32 there are about 2^24 possibilities!),
-				// not just the fact that the value is *a* NaN.
-				//
-				// Note that the specific value of float32(math.NaN()) can vary based on
-				// whether the architecture represents signaling NaNs using a low bit
-				// (as is common) or a high bit (as commonly implemented on MIPS
-				// hardware before around 2012). We believe that the increase in clarity
-				// from identifying "NaN" with math.NaN() is worth the slight ambiguity
-				// from a platform-dependent value.
-				fmt.Fprintf(b, "math.Float32frombits(0x%x)\n", math.Float32bits(t))
-			} else {
-				// We encode all other values — including the NaN value that is
-				// bitwise-identical to float32(math.Nan()) — using the default
-				// formatting, which is equivalent to strconv.FormatFloat with format
-				// 'g' and can be parsed by strconv.ParseFloat.
-				//
-				// For an ordinary floating-point number this format includes
-				// sufficiently many digits to reconstruct the exact value. For positive
-				// or negative infinity it is the string "+Inf" or "-Inf". For positive
-				// or negative zero it is "0" or "-0". For NaN, it is the string "NaN".
-				fmt.Fprintf(b, "%T(%v)\n", t, t)
-			}
-		case float64:
-			if math.IsNaN(t) && math.Float64bits(t) != math.Float64bits(math.NaN()) {
-				fmt.Fprintf(b, "math.Float64frombits(0x%x)\n", math.Float64bits(t))
-			} else {
-				fmt.Fprintf(b, "%T(%v)\n", t, t)
-			}
-		case string:
-			fmt.Fprintf(b, "string(%q)\n", t)
-		case rune: // int32
-			// Although rune and int32 are represented by the same type, only a subset
-			// of valid int32 values can be expressed as rune literals. Notably,
-			// negative numbers, surrogate halves, and values above unicode.MaxRune
-			// have no quoted representation.
+	switch t := val.(type) {
+	case int, int8, int16, int64, uint, uint16, uint32, uint64, bool:
+		fmt.Fprintf(b, "%T(%v)", t, t)
+	case float32:
+		if math.IsNaN(float64(t)) && math.Float32bits(t) != math.Float32bits(float32(math.NaN())) {
+			// We encode unusual NaNs as hex values, because that is how users are
+			// likely to encounter them in literature about floating-point encoding.
+			// This allows us to reproduce fuzz failures that depend on the specific
+			// NaN representation (for float32 there are about 2^24 possibilities!),
+			// not just the fact that the value is *a* NaN.
 			//
-			// fmt with "%q" (and the corresponding functions in the strconv package)
-			// would quote out-of-range values to the Unicode replacement character
-			// instead of the original value (see https://go.dev/issue/51526), so
-			// they must be treated as int32 instead.
+			// Note that the specific value of float32(math.NaN()) can vary based on
+			// whether the architecture represents signaling NaNs using a low bit
+			// (as is common) or a high bit (as commonly implemented on MIPS
+			// hardware before around 2012). We believe that the increase in clarity
+			// from identifying "NaN" with math.NaN() is worth the slight ambiguity
+			// from a platform-dependent value.
+			fmt.Fprintf(b, "math.Float32frombits(0x%x)", math.Float32bits(t))
+		} else {
+			// We encode all other values — including the NaN value that is
+			// bitwise-identical to float32(math.Nan()) — using the default
+			// formatting, which is equivalent to strconv.FormatFloat with format
+			// 'g' and can be parsed by strconv.ParseFloat.
 			//
-			// We arbitrarily draw the line at UTF-8 validity, which biases toward the
-			// "rune" interpretation. (However, we accept either format as input.)
-			if utf8.ValidRune(t) {
-				fmt.Fprintf(b, "rune(%q)\n", t)
-			} else {
-				fmt.Fprintf(b, "int32(%v)\n", t)
-			}
-		case byte: // uint8
-			// For bytes, we arbitrarily prefer the character interpretation.
-			// (Every byte has a valid character encoding.)
-			fmt.Fprintf(b, "byte(%q)\n", t)
-		case []byte: // []uint8
-			fmt.Fprintf(b, "[]byte(%q)\n", t)
-		default:
+			// For an ordinary floating-point number this format includes
+			// sufficiently many digits to reconstruct the exact value. For positive
+			// or negative infinity it is the string "+Inf" or "-Inf". For positive
+			// or negative zero it is "0" or "-0". For NaN, it is the string "NaN".
+			fmt.Fprintf(b, "%T(%v)", t, t)
+		}
+	case float64:
+		if math.IsNaN(t) && math.Float64bits(t) != math.Float64bits(math.NaN()) {
+			fmt.Fprintf(b, "math.Float64frombits(0x%x)", math.Float64bits(t))
+		} else {
+			fmt.Fprintf(b, "%T(%v)", t, t)
+		}
+	case string:
+		fmt.Fprintf(b, "string(%q)", t)
+	case rune: // int32
+		// Although rune and int32 are represented by the same type, only a subset
+		// of valid int32 values can be expressed as rune literals. Notably,
+		// negative numbers, surrogate halves, and values above unicode.MaxRune
+		// have no quoted representation.
+		//
+		// fmt with "%q" (and the corresponding functions in the strconv package)
+		// would quote out-of-range values to the Unicode replacement character
+		// instead of the original value (see https://go.dev/issue/51526), so
+		// they must be treated as int32 instead.
+		//
+		// We arbitrarily draw the line at UTF-8 validity, which biases toward the
+		// "rune" interpretation. (However, we accept either format as input.)
+		if utf8.ValidRune(t) {
+			fmt.Fprintf(b, "rune(%q)", t)
+		} else {
+			fmt.Fprintf(b, "int32(%v)", t)
+		}
+	case byte: // uint8
+		// For bytes, we arbitrarily prefer the character interpretation.
+		// (Every byte has a valid character encoding.)
+		fmt.Fprintf(b, "byte(%q)", t)
+	case []byte: // []uint8
+		fmt.Fprintf(b, "[]byte(%q)", t)
+	default:
+		if typ := reflect.TypeOf(val); typ == nil || !isComposite(typ) {
 			panic(fmt.Sprintf("unsupported type: %T", t))
 		}
+		marshalComposite(b, reflect.ValueOf(val))
 	}
-	return b.Bytes()
+}
+
+// marshalComposite writes v, a struct, slice, array, map or pointer value, to
+// b as a Go expression. Pointers are written as new(x) or nil, and the other
+// kinds as composite literals of their type. Primitive values nested within v
+// are written in the same form as top-level values, according to their kind
+// rather than their (possibly defined) type.
+func marshalComposite(b *bytes.Buffer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString("new(")
+		marshalElem(b, v.Elem())
+		b.WriteString(")")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		fmt.Fprintf(b, "%v{", v.Type())
+		for i := range v.Len() {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			marshalElem(b, v.Index(i))
+		}
+		b.WriteString("}")
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		// Sort the keys so that equal maps always have the same encoding.
+		fmt.Fprintf(b, "%v{", v.Type())
+		for i, kv := range fmtsort.Sort(v) {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			marshalElem(b, kv.Key)
+			b.WriteString(": ")
+			marshalElem(b, kv.Value)
+		}
+		b.WriteString("}")
+	case reflect.Struct:
+		fmt.Fprintf(b, "%v{", v.Type())
+		for i := range v.NumField() {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(b, "%s: ", v.Type().Field(i).Name)
+			marshalElem(b, v.Field(i))
+		}
+		b.WriteString("}")
+	default:
+		panic(fmt.Sprintf("unsupported type: %v", v.Type()))
+	}
+}
+
+// marshalElem writes v, a value nested within a composite value, to b.
+func marshalElem(b *bytes.Buffer, v reflect.Value) {
+	if isComposite(v.Type()) {
+		marshalComposite(b, v)
+		return
+	}
+	marshalValue(b, basicValue(v))
 }
 
 // unmarshalCorpusFile decodes corpus bytes into their respective values.
-func unmarshalCorpusFile(b []byte) ([]any, error) {
+//
+// If types is non-nil, each value is decoded as the corresponding type. This
+// is required for struct, slice, array, map and pointer values, which do not
+// record their full type in the encoding. If types is nil, or the file holds
+// more values than types, the type of each remaining value is inferred from
+// its encoding, which is only possible for primitive types, string and []byte.
+func unmarshalCorpusFile(b []byte, types []reflect.Type) ([]any, error) {
 	if len(b) == 0 {
 		return nil, fmt.Errorf("cannot unmarshal empty string")
 	}
@@ -117,7 +203,11 @@ func unmarshalCorpusFile(b []byte) ([]any, error) {
 		if len(line) == 0 {
 			continue
 		}
-		v, err := parseCorpusValue(line)
+		var typ reflect.Type
+		if len(vals) < len(types) {
+			typ = types[len(vals)]
+		}
+		v, err := parseCorpusValue(line, typ)
 		if err != nil {
 			return nil, fmt.Errorf("malformed line %q: %v", line, err)
 		}
@@ -126,12 +216,28 @@ func unmarshalCorpusFile(b []byte) ([]any, error) {
 	return vals, nil
 }
 
-func parseCorpusValue(line []byte) (any, error) {
+// parseCorpusValue decodes a single line of a corpus file. If typ is non-nil
+// and is a composite type, the value is decoded as typ. Otherwise, its type
+// is inferred from the encoding.
+func parseCorpusValue(line []byte, typ reflect.Type) (any, error) {
 	fs := token.NewFileSet()
 	expr, err := parser.ParseExprFrom(fs, "(test)", line, 0)
 	if err != nil {
 		return nil, err
 	}
+	if typ != nil && isComposite(typ) {
+		v, err := parseComposite(expr, typ)
+		if err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+	return parsePrimitive(expr)
+}
+
+// parsePrimitive decodes expr as a value of a primitive type, string or
+// []byte, inferring the type from expr.
+func parsePrimitive(expr ast.Expr) (any, error) {
 	call, ok := expr.(*ast.CallExpr)
 	if !ok {
 		return nil, fmt.Errorf("expected call expression")
@@ -311,6 +417,191 @@ func parseCorpusValue(line []byte) (any, error) {
 	}
 }
 
+// parseComposite decodes expr, as written by marshalComposite, as a value of
+// type typ, which must be a composite type. The type named in a composite
+// literal is not checked, since values are decoded according to typ.
+func parseComposite(expr ast.Expr, typ reflect.Type) (reflect.Value, error) {
+	if id, ok := expr.(*ast.Ident); ok && id.Name == "nil" {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			return reflect.Zero(typ), nil
+		}
+		return reflect.Value{}, fmt.Errorf("nil is not a valid value of type %v", typ)
+	}
+	if typ.Kind() == reflect.Pointer {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return reflect.Value{}, fmt.Errorf("nil or new(value) required for type %v", typ)
+		}
+		if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "new" {
+			return reflect.Value{}, fmt.Errorf("nil or new(value) required for type %v", typ)
+		}
+		elem, err := parseElem(call.Args[0], typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(typ.Elem())
+		p.Elem().Set(elem)
+		return p, nil
+	}
+
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("composite literal required for type %v", typ)
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		var v reflect.Value
+		if typ.Kind() == reflect.Slice {
+			v = reflect.MakeSlice(typ, len(lit.Elts), len(lit.Elts))
+		} else {
+			if len(lit.Elts) != typ.Len() {
+				return reflect.Value{}, fmt.Errorf("%d elements required for type %v; got %d", typ.Len(), typ, len(lit.Elts))
+			}
+			v = reflect.New(typ).Elem()
+		}
+		for i, e := range lit.Elts {
+			if _, ok := e.(*ast.KeyValueExpr); ok {
+				return reflect.Value{}, fmt.Errorf("unexpected key in literal of type %v", typ)
+			}
+			elem, err := parseElem(e, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(elem)
+		}
+		return v, nil
+	case reflect.Map:
+		v := reflect.MakeMapWithSize(typ, len(lit.Elts))
+		for _, e := range lit.Elts {
+			kv, ok := e.(*ast.KeyValueExpr)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("key required in literal of type %v", typ)
+			}
+			key, err := parseElem(kv.Key, typ.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			elem, err := parseElem(kv.Value, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return v, nil
+	case reflect.Struct:
+		v := reflect.New(typ).Elem()
+		for _, e := range lit.Elts {
+			kv, ok := e.(*ast.KeyValueExpr)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("field name required in literal of type %v", typ)
+			}
+			name, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("field name required in literal of type %v", typ)
+			}
+			f, ok := typ.FieldByName(name.Name)
+			if !ok || len(f.Index) != 1 || !f.IsExported() {
+				return reflect.Value{}, fmt.Errorf("unknown field %s in literal of type %v", name.Name, typ)
+			}
+			elem, err := parseElem(kv.Value, f.Type)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Field(f.Index[0]).Set(elem)
+		}
+		return v, nil
+	default:
+		panic("unreachable")
+	}
+}
+
+// parseElem decodes expr as a value of type typ nested within a composite
+// value.
+func parseElem(expr ast.Expr, typ reflect.Type) (reflect.Value, error) {
+	if isComposite(typ) {
+		return parseComposite(expr, typ)
+	}
+	val, err := parsePrimitive(expr)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.New(typ).Elem()
+	if err := setBasicValue(v, val); err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
+
+// isComposite reports whether t is a struct, array, map or pointer type, or a
+// slice type other than a slice of bytes. Values of these types are built
+// from other values, and are encoded, mutated and minimized element-wise.
+func isComposite(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Map, reflect.Pointer:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// basicValue returns v, a primitive value or a slice of bytes nested within a
+// composite value, converted to the predeclared type of its kind, so that it
+// can be encoded and mutated like a top-level value.
+func basicValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int:
+		return int(v.Int())
+	case reflect.Int8:
+		return int8(v.Int())
+	case reflect.Int16:
+		return int16(v.Int())
+	case reflect.Int32:
+		return int32(v.Int())
+	case reflect.Int64:
+		return v.Int()
+	case reflect.Uint:
+		return uint(v.Uint())
+	case reflect.Uint8:
+		return uint8(v.Uint())
+	case reflect.Uint16:
+		return uint16(v.Uint())
+	case reflect.Uint32:
+		return uint32(v.Uint())
+	case reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32:
+		return float32(v.Float())
+	case reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		return v.Bytes()
+	}
+	panic(fmt.Sprintf("unsupported type: %v", v.Type()))
+}
+
+// setBasicValue sets v to val, which must be a value of the predeclared type
+// of v's kind, as returned by basicValue.
+func setBasicValue(v reflect.Value, val any) error {
+	x := reflect.ValueOf(val)
+	if x.Kind() != v.Kind() {
+		return fmt.Errorf("mismatched types: %v, want %v", x.Type(), v.Type())
+	}
+	if v.Kind() == reflect.Slice {
+		// The element type of v may be a defined type, which prevents a
+		// conversion from []byte, so copy the bytes instead.
+		v.SetBytes(bytes.Clone(x.Bytes()))
+		return nil
+	}
+	v.Set(x.Convert(v.Type()))
+	return nil
+}
+
 // parseInt returns an integer of value val and type typ.
 func parseInt(val, typ string) (any, error) {
 	switch typ {
@@ -6,6 +6,7 @@ package fuzz
 
 import (
 	"math"
+	"reflect"
 	"strconv"
 	"testing"
 	"unicode"
@@ -222,7 +223,7 @@ uint(18446744073709551615)`
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
-			vals, err := unmarshalCorpusFile([]byte(test.in))
+			vals, err := unmarshalCorpusFile([]byte(test.in), nil)
 			if test.reject {
 				if err == nil {
 					t.Fatalf("unmarshal unexpected success")
@@ -283,7 +284,162 @@ func BenchmarkUnmarshalCorpusFile(b *testing.B) {
 		b.Run(strconv.Itoa(sz), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				b.SetBytes(int64(sz))
-				unmarshalCorpusFile(data)
+				unmarshalCorpusFile(data, nil)
+			}
+		})
+	}
+}
+
+type compositePoint struct {
+	X, Y  int
+	Label string
+}
+
+type compositeList struct {
+	Val  uint8
+	Next *compositeList
+}
+
+type compositeBytes []byte
+
+type compositeMode int16
+
+func TestUnmarshalMarshalComposite(t *testing.T) {
+	var tests = []struct {
+		desc   string
+		typ    reflect.Type
+		in     string
+		reject bool
+		want   string // if different from in
+	}{
+		{
+			desc: "struct",
+			typ:  reflect.TypeFor[compositePoint](),
+			in:   `fuzz.compositePoint{X: int(1), Y: int(-2), Label: string("a\n")}`,
+		},
+		{
+			desc: "struct with missing fields",
+			typ:  reflect.TypeFor[compositePoint](),
+			in:   `fuzz.compositePoint{Y: int(3)}`,
+			want: `fuzz.compositePoint{X: int(0), Y: int(3), Label: string("")}`,
+		},
+		{
+			desc: "anonymous struct",
+			typ:  reflect.TypeFor[struct{ A, B bool }](),
+			in:   `struct { A bool; B bool }{A: bool(true), B: bool(false)}`,
+		},
+		{
+			desc:   "unknown field",
+			typ:    reflect.TypeFor[compositePoint](),
+			in:     `fuzz.compositePoint{Z: int(1)}`,
+			reject: true,
+		},
+		{
+			desc:   "mismatched field type",
+			typ:    reflect.TypeFor[compositePoint](),
+			in:     `fuzz.compositePoint{X: string("1")}`,
+			reject: true,
+		},
+		{
+			desc: "slice",
+			typ:  reflect.TypeFor[[]float64](),
+			in:   `[]float64{float64(1.5), float64(NaN), math.Float64frombits(0x7ff0000000000001)}`,
+		},
+		{
+			desc: "nil slice",
+			typ:  reflect.TypeFor[[]string](),
+			in:   `nil`,
+		},
+		{
+			desc: "empty slice",
+			typ:  reflect.TypeFor[[]string](),
+			in:   `[]string{}`,
+		},
+		{
+			desc: "slice of byte slices",
+			typ:  reflect.TypeFor[[][]byte](),
+			in:   `[][]uint8{[]byte("a"), []byte("\x00")}`,
+		},
+		{
+			desc: "array",
+			typ:  reflect.TypeFor[[3]rune](),
+			in:   `[3]int32{rune('a'), int32(-1), rune('\x00')}`,
+		},
+		{
+			desc:   "array with wrong length",
+			typ:    reflect.TypeFor[[3]rune](),
+			in:     `[3]int32{rune('a')}`,
+			reject: true,
+		},
+		{
+			desc: "map",
+			typ:  reflect.TypeFor[map[string]int](),
+			in:   `map[string]int{string("b"): int(2), string("a"): int(1)}`,
+			want: `map[string]int{string("a"): int(1), string("b"): int(2)}`,
+		},
+		{
+			desc:   "map without keys",
+			typ:    reflect.TypeFor[map[string]int](),
+			in:     `map[string]int{int(1)}`,
+			reject: true,
+		},
+		{
+			desc: "pointer",
+			typ:  reflect.TypeFor[*compositePoint](),
+			in:   `new(fuzz.compositePoint{X: int(1), Y: int(2), Label: string("")})`,
+		},
+		{
+			desc: "pointer to primitive",
+			typ:  reflect.TypeFor[*uint](),
+			in:   `new(uint(7))`,
+		},
+		{
+			desc:   "pointer literal",
+			typ:    reflect.TypeFor[*uint](),
+			in:     `&uint(7)`,
+			reject: true,
+		},
+		{
+			desc: "recursive",
+			typ:  reflect.TypeFor[compositeList](),
+			in:   `fuzz.compositeList{Val: byte('a'), Next: new(fuzz.compositeList{Val: byte('b'), Next: nil})}`,
+		},
+		{
+			desc: "defined element types",
+			typ:  reflect.TypeFor[map[compositeMode]compositeBytes](),
+			in:   `map[fuzz.compositeMode]fuzz.compositeBytes{int16(-1): []byte("x"), int16(4): []byte("")}`,
+		},
+		{
+			desc:   "nil struct",
+			typ:    reflect.TypeFor[compositePoint](),
+			in:     `nil`,
+			reject: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			in := "go test fuzz v1\n" + test.in
+			types := []reflect.Type{test.typ}
+			vals, err := unmarshalCorpusFile([]byte(in), types)
+			if test.reject {
+				if err == nil {
+					t.Fatalf("unmarshal unexpected success: %#v", vals)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unmarshal unexpected error: %v", err)
+			}
+			if err := CheckCorpus(vals, types); err != nil {
+				t.Fatal(err)
+			}
+			want := test.want
+			if want == "" {
+				want = test.in
+			}
+			want = "go test fuzz v1\n" + want + "\n"
+			if got := string(marshalCorpusFile(vals...)); got != want {
+				t.Errorf("unexpected marshaled value\ngot:\n%s\nwant:\n%s", got, want)
 			}
 		})
 	}
@@ -293,7 +449,7 @@ func TestByteRoundTrip(t *testing.T) {
 	for x := 0; x < 256; x++ {
 		b1 := byte(x)
 		buf := marshalCorpusFile(b1)
-		vs, err := unmarshalCorpusFile(buf)
+		vs, err := unmarshalCorpusFile(buf, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -308,7 +464,7 @@ func TestInt8RoundTrip(t *testing.T) {
 	for x := -128; x < 128; x++ {
 		i1 := int8(x)
 		buf := marshalCorpusFile(i1)
-		vs, err := unmarshalCorpusFile(buf)
+		vs, err := unmarshalCorpusFile(buf, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -335,7 +491,7 @@ func FuzzFloat64RoundTrip(f *testing.F) {
 		b := marshalCorpusFile(x1)
 		t.Logf("marshaled math.Float64frombits(0x%x):\n%s", u1, b)
 
-		xs, err := unmarshalCorpusFile(b)
+		xs, err := unmarshalCorpusFile(b, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -366,7 +522,7 @@ func FuzzRuneRoundTrip(f *testing.F) {
 		b := marshalCorpusFile(r1)
 		t.Logf("marshaled rune(0x%x):\n%s", r1, b)
 
-		rs, err := unmarshalCorpusFile(b)
+		rs, err := unmarshalCorpusFile(b, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -389,7 +545,7 @@ func FuzzStringRoundTrip(f *testing.F) {
 		b := marshalCorpusFile(s1)
 		t.Logf("marshaled %q:\n%s", s1, b)
 
-		rs, err := unmarshalCorpusFile(b)
+		rs, err := unmarshalCorpusFile(b, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 )
@@ -27,6 +28,11 @@ import (
 // CoordinateFuzzingOpts is a set of arguments for CoordinateFuzzing.
 // The zero value is valid for each field unless specified otherwise.
 type CoordinateFuzzingOpts struct {
+	// Name is the name of the fuzz test. If set, worker processes are told to
+	// run only that fuzz test, which allows one test binary to fuzz several
+	// fuzz tests at once.
+	Name string
+
 	// Log is a writer for logging progress messages and warnings.
 	// If nil, io.Discard will be used instead.
 	Log io.Writer
@@ -64,6 +70,11 @@ type CoordinateFuzzingOpts struct {
 	// Types must be set and must match values in Seed.
 	Types []reflect.Type
 
+	// Dictionary is a list of tokens, such as keywords and magic numbers,
+	// which the mutator inserts into inputs. Worker processes must be given
+	// the same dictionary.
+	Dictionary [][]byte
+
 	// CorpusDir is a directory where files containing values that crash the
 	// code being tested may be written. CorpusDir must be set.
 	CorpusDir string
@@ -168,6 +179,9 @@ func CoordinateFuzzing(ctx context.Context, opts CoordinateFuzzingOpts) (err err
 	dir := "" // same as self
 	binPath := os.Args[0]
 	args := append([]string{"-test.fuzzworker"}, os.Args[1:]...)
+	if opts.Name != "" {
+		args = workerFuzzArgs(args, opts.Name)
+	}
 	env := os.Environ() // same as self
 
 	errC := make(chan error)
@@ -241,6 +255,7 @@ func CoordinateFuzzing(ctx context.Context, opts CoordinateFuzzingOpts) (err err
 				break
 			}
 			c.updateStats(result)
+			c.addCmpTokens(result.cmpTokens)
 
 			if result.crasherMsg != "" {
 				if c.warmupRun() && result.entry.IsSeed {
@@ -513,6 +528,9 @@ type fuzzInput struct {
 
 	// coverageData reflects the coordinator's current coverageMask.
 	coverageData []byte
+
+	// cmpTokens reflects the coordinator's current cmpTokens.
+	cmpTokens [][]byte
 }
 
 type fuzzResult struct {
@@ -541,6 +559,9 @@ type fuzzResult struct {
 
 	// entryDuration is the time the worker spent execution an interesting result
 	entryDuration time.Duration
+
+	// cmpTokens are operands of comparisons made by the code being fuzzed.
+	cmpTokens [][]byte
 }
 
 type fuzzMinimizeInput struct {
@@ -650,6 +671,14 @@ type coordinator struct {
 	// value of 12 indicates that separate inputs have triggered this block
 	// between 4-7 times and 8-15 times.
 	coverageMask []byte
+
+	// cmpTokens holds operands of comparisons reported by workers, which are
+	// sent to workers with each input. cmpTokenSet records the same tokens,
+	// and cmpTokenNext is the index of the next token to replace once
+	// cmpTokens is full.
+	cmpTokens    [][]byte
+	cmpTokenSet  map[string]bool
+	cmpTokenNext int
 }
 
 func newCoordinator(opts CoordinateFuzzingOpts) (*coordinator, error) {
@@ -667,6 +696,7 @@ func newCoordinator(opts CoordinateFuzzingOpts) (*coordinator, error) {
 		resultC:     make(chan fuzzResult),
 		timeLastLog: time.Now(),
 		corpus:      corpus{hashes: make(map[[sha256.Size]byte]bool)},
+		cmpTokenSet: make(map[string]bool),
 	}
 	if err := c.readCache(); err != nil {
 		return nil, err
@@ -721,6 +751,38 @@ func (c *coordinator) updateStats(result fuzzResult) {
 	c.duration += result.totalDuration
 }
 
+// addCmpTokens adds operands of comparisons reported by a worker to the
+// tokens sent to all workers. Once there are maxCmpTokens tokens, new tokens
+// replace the oldest ones, so that the tokens keep up with the code reached
+// by the fuzzer.
+//
+// Inputs already sent to workers share the slice, so it is copied rather
+// than modified in place.
+func (c *coordinator) addCmpTokens(tokens [][]byte) {
+	var added [][]byte
+	for _, tok := range tokens {
+		if !c.cmpTokenSet[string(tok)] {
+			added = append(added, tok)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	cmpTokens := slices.Clone(c.cmpTokens)
+	for _, tok := range added {
+		if len(cmpTokens) < maxCmpTokens {
+			cmpTokens = append(cmpTokens, tok)
+		} else {
+			i := c.cmpTokenNext
+			c.cmpTokenNext = (i + 1) % maxCmpTokens
+			delete(c.cmpTokenSet, string(cmpTokens[i]))
+			cmpTokens[i] = tok
+		}
+		c.cmpTokenSet[string(tok)] = true
+	}
+	c.cmpTokens = cmpTokens
+}
+
 func (c *coordinator) logStats() {
 	now := time.Now()
 	if c.warmupRun() {
@@ -782,6 +844,7 @@ func (c *coordinator) peekInput() (fuzzInput, bool) {
 	if c.coverageMask != nil {
 		input.coverageData = bytes.Clone(c.coverageMask)
 	}
+	input.cmpTokens = c.cmpTokens
 	if input.warmup {
 		// No fuzzing will occur, but it should count toward the limit set by
 		// -fuzztime.
@@ -929,6 +992,39 @@ func (c *coordinator) elapsed() time.Duration {
 	return time.Since(c.startTime).Round(1 * time.Second)
 }
 
+// workerFuzzArgs returns args with the -test.fuzz flag replaced by one that
+// matches only the fuzz test with the given name. As with the flag package,
+// the flag may be given with one or two dashes, and its value may follow an
+// equals sign or be the next argument.
+func workerFuzzArgs(args []string, name string) []string {
+	arg := "-test.fuzz=^" + name + "$"
+	var out []string
+	found := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			// The remaining arguments are not flags.
+			out = append(out, args[i:]...)
+			break
+		}
+		f, _, hasValue := strings.Cut(a, "=")
+		if f != "-test.fuzz" && f != "--test.fuzz" {
+			out = append(out, a)
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++ // Skip the value.
+		}
+		out = append(out, arg)
+		found = true
+	}
+	if !found {
+		// Add the flag before any arguments that are not flags.
+		out = append([]string{arg}, out...)
+	}
+	return out
+}
+
 // readCache creates a combined corpus from seed values and values in the cache
 // (in GOCACHE/fuzz).
 //
@@ -1012,7 +1108,7 @@ func ReadCorpus(dir string, types []reflect.Type) ([]CorpusEntry, error) {
 }
 
 func readCorpusData(data []byte, types []reflect.Type) ([]any, error) {
-	vals, err := unmarshalCorpusFile(data)
+	vals, err := unmarshalCorpusFile(data, types)
 	if err != nil {
 		return nil, fmt.Errorf("unmarshal: %v", err)
 	}
@@ -1062,6 +1158,9 @@ func testName(path string) string {
 }
 
 func zeroValue(t reflect.Type) any {
+	if isComposite(t) {
+		return reflect.Zero(t).Interface()
+	}
 	for _, v := range zeroVals {
 		if reflect.TypeOf(v) == t {
 			return v
@@ -50,7 +50,9 @@ type sharedMemHeader struct {
 	// rawInMem is true if the region holds raw bytes, which occurs during
 	// minimization. If true after the worker fails during minimization, this
 	// indicates that an unrecoverable error occurred, and the region can be
-	// used to retrieve the raw bytes that caused the error.
+	// used to retrieve the raw bytes that caused the error. When a composite
+	// value is being minimized, the region instead holds a corpus file
+	// containing only that value.
 	rawInMem bool
 }
 
@@ -0,0 +1,460 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// MergeCorpusOpts is a set of arguments for MergeCorpus.
+// The zero value is valid for each field unless specified otherwise.
+type MergeCorpusOpts struct {
+	// Name is the name of the fuzz test. If set, worker processes are told to
+	// run only that fuzz test.
+	Name string
+
+	// Log is a writer for logging progress messages and warnings.
+	// If nil, io.Discard will be used instead.
+	Log io.Writer
+
+	// MinimizeTimeout is the amount of wall clock time to spend minimizing
+	// each entry added to the seed corpus. If zero, there will be no time
+	// limit. If MinimizeTimeout and MinimizeLimit are both zero, then
+	// minimization will be disabled.
+	MinimizeTimeout time.Duration
+
+	// MinimizeLimit is the maximum number of calls to the fuzz function to be
+	// made while minimizing each entry added to the seed corpus. If zero,
+	// there will be no limit. If MinimizeTimeout and MinimizeLimit are both
+	// zero, then minimization will be disabled.
+	MinimizeLimit int64
+
+	// Parallel is the number of worker processes to run in parallel. If zero,
+	// MergeCorpus will run GOMAXPROCS workers.
+	Parallel int
+
+	// Seed is a list of seed values added by the fuzz target with testing.F.Add
+	// and in testdata. Seed values are always kept.
+	Seed []CorpusEntry
+
+	// Types is the list of types which make up a corpus entry.
+	// Types must be set and must match values in Seed.
+	Types []reflect.Type
+
+	// Dirs is a list of directories containing corpus entries to merge into
+	// the seed corpus. Files that are not valid corpus entries are skipped.
+	Dirs []string
+
+	// CorpusDir is the directory holding the seed corpus in testdata, where
+	// merged entries are written. CorpusDir must be set.
+	CorpusDir string
+}
+
+// MergeCorpus merges the corpus entries in several directories into the seed
+// corpus, keeping only the entries that expand coverage.
+//
+// Like CoordinateFuzzing, MergeCorpus starts worker processes, which run
+// each seed value and each entry in opts.Dirs once to gather its coverage.
+// Entries are then considered from smallest to largest, and an entry is kept
+// only if it covers code that is not covered by the seed corpus or by an
+// entry kept before it. Kept entries are minimized, preserving the new
+// coverage they found, and written to opts.CorpusDir. The seed corpus itself
+// is not changed.
+//
+// If an entry causes an error, it is written to opts.CorpusDir, and
+// MergeCorpus returns an error containing information about the crash.
+func MergeCorpus(ctx context.Context, opts MergeCorpusOpts) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if opts.Log == nil {
+		opts.Log = io.Discard
+	}
+	if opts.Parallel == 0 {
+		opts.Parallel = runtime.GOMAXPROCS(0)
+	}
+	if !coverageEnabled {
+		return errors.New("merging a fuzzing corpus requires coverage instrumentation, which is not supported on this platform")
+	}
+
+	m, err := newMerger(opts)
+	if err != nil {
+		return err
+	}
+	if len(m.candidates) == 0 {
+		fmt.Fprintf(opts.Log, "fuzz: no new corpus entries to merge\n")
+		return nil
+	}
+	if len(opts.Seed)+len(m.candidates) < opts.Parallel {
+		// Don't start more workers than we need.
+		opts.Parallel = len(opts.Seed) + len(m.candidates)
+	}
+	c := m.c
+
+	// fuzzCtx is used to stop workers once the merge is complete or fails.
+	fuzzCtx, cancelWorkers := context.WithCancel(ctx)
+	defer cancelWorkers()
+	doneC := ctx.Done()
+
+	var mergeErr error
+	stopping := false
+	stop := func(err error) {
+		if err == fuzzCtx.Err() || isInterruptError(err) {
+			err = nil
+		}
+		if err != nil && (mergeErr == nil || mergeErr == ctx.Err()) {
+			mergeErr = err
+		}
+		if stopping {
+			return
+		}
+		stopping = true
+		cancelWorkers()
+		doneC = nil
+	}
+
+	// Start workers.
+	dir := "" // same as self
+	binPath := os.Args[0]
+	args := append([]string{"-test.fuzzworker"}, os.Args[1:]...)
+	if opts.Name != "" {
+		args = workerFuzzArgs(args, opts.Name)
+	}
+	env := os.Environ() // same as self
+
+	errC := make(chan error)
+	workers := make([]*worker, opts.Parallel)
+	for i := range workers {
+		var err error
+		workers[i], err = newWorker(c, dir, binPath, args, env)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range workers {
+		w := workers[i]
+		go func() {
+			err := w.coordinate(fuzzCtx)
+			if fuzzCtx.Err() != nil || isInterruptError(err) {
+				err = nil
+			}
+			cleanErr := w.cleanup()
+			if err == nil {
+				err = cleanErr
+			}
+			errC <- err
+		}()
+	}
+
+	// Main event loop. As in CoordinateFuzzing, do not return until all
+	// workers have terminated.
+	activeWorkers := len(workers)
+	statTicker := time.NewTicker(3 * time.Second)
+	defer statTicker.Stop()
+
+	m.logStats()
+	for {
+		var inputC chan fuzzInput
+		var input fuzzInput
+		if len(m.inputs) > 0 && !stopping {
+			inputC = c.inputC
+			input = m.inputs[0]
+		}
+
+		var minimizeC chan fuzzMinimizeInput
+		var minimizeInput fuzzMinimizeInput
+		if len(m.minimizeInputs) > 0 && !stopping {
+			minimizeC = c.minimizeC
+			minimizeInput = m.minimizeInputs[0]
+		}
+
+		select {
+		case <-doneC:
+			// Interrupted or canceled.
+			stop(ctx.Err())
+
+		case err := <-errC:
+			// A worker terminated, possibly after encountering a fatal error.
+			stop(err)
+			activeWorkers--
+			if activeWorkers == 0 {
+				if mergeErr == nil && m.left == 0 {
+					mergeErr = m.write()
+				}
+				return mergeErr
+			}
+
+		case result := <-c.resultC:
+			// Received response from worker.
+			if stopping {
+				break
+			}
+			if result.crasherMsg != "" {
+				stop(m.crash(result))
+				break
+			}
+			m.left--
+			if m.minimizing {
+				m.minimized(result)
+			} else {
+				m.covered(result)
+			}
+			if m.left == 0 {
+				// Done.
+				stop(nil)
+			}
+
+		case inputC <- input:
+			// Sent the next input to a worker.
+			m.inputs = m.inputs[1:]
+
+		case minimizeC <- minimizeInput:
+			// Sent the next input for minimization to a worker.
+			m.minimizeInputs = m.minimizeInputs[1:]
+
+		case <-statTicker.C:
+			m.logStats()
+		}
+	}
+}
+
+// merger holds the state of MergeCorpus. Workers communicate with it through
+// the channels of a coordinator, which is otherwise unused.
+type merger struct {
+	c *coordinator
+
+	// seeds is the number of entries at the start of c.corpus.entries that
+	// belong to the seed corpus. The remaining entries are candidates.
+	seeds      int
+	candidates []CorpusEntry
+
+	// coverage holds the coverage reported for each entry, keyed by path.
+	coverage map[string][]byte
+
+	// kept holds the candidates that expand coverage, in the order they
+	// were considered.
+	kept []CorpusEntry
+
+	// inputs and minimizeInputs are the inputs not yet sent to workers.
+	inputs         []fuzzInput
+	minimizeInputs []fuzzMinimizeInput
+
+	// minimizing is true once coverage has been gathered for every entry.
+	minimizing bool
+
+	// total is the number of inputs in the current phase, and left is the
+	// number of those inputs whose results have not been received yet.
+	total, left int
+}
+
+func newMerger(opts MergeCorpusOpts) (*merger, error) {
+	c := &coordinator{
+		opts: CoordinateFuzzingOpts{
+			Name:            opts.Name,
+			Log:             opts.Log,
+			MinimizeTimeout: opts.MinimizeTimeout,
+			MinimizeLimit:   opts.MinimizeLimit,
+			Parallel:        opts.Parallel,
+			Types:           opts.Types,
+			CorpusDir:       opts.CorpusDir,
+		},
+		startTime: time.Now(),
+		inputC:    make(chan fuzzInput),
+		minimizeC: make(chan fuzzMinimizeInput),
+		resultC:   make(chan fuzzResult),
+		corpus:    corpus{hashes: make(map[[sha256.Size]byte]bool)},
+	}
+	if opts.MinimizeLimit > 0 || opts.MinimizeTimeout > 0 {
+		for _, t := range opts.Types {
+			if isMinimizable(t) {
+				c.minimizationAllowed = true
+				break
+			}
+		}
+	}
+
+	// Make sure all the seed corpus has marshaled data.
+	seed := slices.Clone(opts.Seed)
+	for i := range seed {
+		if seed[i].Data == nil && seed[i].Values != nil {
+			seed[i].Data = marshalCorpusFile(seed[i].Values...)
+		}
+	}
+	if _, err := c.addCorpusEntries(false, seed...); err != nil {
+		return nil, err
+	}
+	m := &merger{
+		c:        c,
+		seeds:    len(c.corpus.entries),
+		coverage: make(map[string][]byte),
+	}
+
+	// Read the entries to merge. Entries that duplicate a seed value or an
+	// entry in another directory are skipped by addCorpusEntries.
+	malformed := 0
+	for _, dir := range opts.Dirs {
+		if filepath.Clean(dir) == filepath.Clean(opts.CorpusDir) {
+			continue
+		}
+		entries, err := ReadCorpus(dir, opts.Types)
+		if err != nil {
+			merr, ok := err.(*MalformedCorpusError)
+			if !ok {
+				return nil, err
+			}
+			malformed += len(merr.errs)
+		}
+		if _, err := c.addCorpusEntries(false, entries...); err != nil {
+			return nil, err
+		}
+	}
+	if malformed > 0 {
+		fmt.Fprintf(opts.Log, "fuzz: skipped %d malformed corpus files\n", malformed)
+	}
+
+	// Load the candidates' data, which is needed to order them by size and
+	// to minimize them.
+	for _, e := range c.corpus.entries[m.seeds:] {
+		data, err := corpusEntryData(e)
+		if err != nil {
+			return nil, err
+		}
+		e.Data = data
+		e.Values = nil
+		m.candidates = append(m.candidates, e)
+	}
+	slices.SortStableFunc(m.candidates, func(a, b CorpusEntry) int {
+		return len(a.Data) - len(b.Data)
+	})
+
+	for _, e := range c.corpus.entries[:m.seeds] {
+		m.inputs = append(m.inputs, fuzzInput{entry: e, limit: 1, warmup: true})
+	}
+	for _, e := range m.candidates {
+		m.inputs = append(m.inputs, fuzzInput{entry: e, limit: 1, warmup: true})
+	}
+	m.total = len(m.inputs)
+	m.left = m.total
+	return m, nil
+}
+
+// covered records the coverage of an entry. Once coverage has been gathered
+// for every entry, covered selects the candidates to keep and queues them
+// for minimization.
+func (m *merger) covered(result fuzzResult) {
+	m.coverage[result.entry.Parent] = result.coverageData
+	if m.left > 0 {
+		return
+	}
+
+	mask := make([]byte, len(coverage()))
+	for _, e := range m.c.corpus.entries[:m.seeds] {
+		if cov := m.coverage[e.Path]; cov != nil {
+			m.updateMask(mask, cov)
+		}
+	}
+	var keepCoverage [][]byte
+	for _, e := range m.candidates {
+		cov := m.coverage[e.Path]
+		if cov == nil {
+			continue
+		}
+		keep := diffCoverage(mask, cov)
+		if keep == nil {
+			continue
+		}
+		m.updateMask(mask, cov)
+		m.kept = append(m.kept, e)
+		keepCoverage = append(keepCoverage, keep)
+	}
+	fmt.Fprintf(m.c.opts.Log, "fuzz: elapsed: %s, gathering coverage: %d/%d completed, %d of %d new corpus entries expand coverage\n", m.c.elapsed(), m.total, m.total, len(m.kept), len(m.candidates))
+	if !m.c.minimizationAllowed || len(m.kept) == 0 {
+		return
+	}
+
+	m.minimizing = true
+	for i, e := range m.kept {
+		// Workers report the minimized entry under a new path, so record the
+		// original path as its parent to match up the result.
+		e.Parent = e.Path
+		m.minimizeInputs = append(m.minimizeInputs, fuzzMinimizeInput{
+			entry:        e,
+			limit:        m.c.opts.MinimizeLimit,
+			timeout:      m.c.opts.MinimizeTimeout,
+			keepCoverage: keepCoverage[i],
+		})
+	}
+	m.total = len(m.minimizeInputs)
+	m.left = m.total
+}
+
+func (m *merger) updateMask(mask, cov []byte) {
+	if len(cov) != len(mask) {
+		panic(fmt.Sprintf("number of coverage counters changed at runtime: %d, expected %d", len(cov), len(mask)))
+	}
+	for i := range cov {
+		mask[i] |= cov[i]
+	}
+}
+
+// minimized replaces a kept entry with its minimized form.
+func (m *merger) minimized(result fuzzResult) {
+	for i, e := range m.kept {
+		if e.Path == result.entry.Parent {
+			m.kept[i].Data = result.entry.Data
+			break
+		}
+	}
+	if m.left == 0 {
+		m.logStats()
+	}
+}
+
+// crash handles a result for an entry that caused an error. A failing seed
+// value is reported as in CoordinateFuzzing. Any other failing entry is
+// written to the seed corpus, so that it can be reproduced.
+func (m *merger) crash(result fuzzResult) error {
+	if result.entry.IsSeed {
+		target := filepath.Base(m.c.opts.CorpusDir)
+		fmt.Fprintf(m.c.opts.Log, "failure while testing seed corpus entry: %s/%s\n", target, testName(result.entry.Parent))
+		return errors.New(result.crasherMsg)
+	}
+	if err := writeToCorpus(&result.entry, m.c.opts.CorpusDir); err != nil {
+		return fmt.Errorf("%s\n%v", result.crasherMsg, err)
+	}
+	return &crashError{
+		path: result.entry.Path,
+		err:  errors.New(result.crasherMsg),
+	}
+}
+
+// write writes the kept entries to the seed corpus.
+func (m *merger) write() error {
+	for i := range m.kept {
+		if err := writeToCorpus(&m.kept[i], m.c.opts.CorpusDir); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(m.c.opts.Log, "fuzz: elapsed: %s, added %d of %d new corpus entries to %s\n", m.c.elapsed(), len(m.kept), len(m.candidates), m.c.opts.CorpusDir)
+	return nil
+}
+
+func (m *merger) logStats() {
+	phase := "gathering coverage"
+	if m.minimizing {
+		phase = "minimizing"
+	}
+	fmt.Fprintf(m.c.opts.Log, "fuzz: elapsed: %s, %s: %d/%d completed\n", m.c.elapsed(), phase, m.total-m.left, m.total)
+}
@@ -5,11 +5,13 @@
 package fuzz
 
 import (
+	"bytes"
+	"internal/fmtsort"
 	"reflect"
 )
 
 func isMinimizable(t reflect.Type) bool {
-	return t == reflect.TypeOf("") || t == reflect.TypeOf([]byte(nil))
+	return t == reflect.TypeOf("") || t == reflect.TypeOf([]byte(nil)) || isComposite(t)
 }
 
 func minimizeBytes(v []byte, try func([]byte) bool, shouldStop func() bool) {
@@ -93,3 +95,145 @@ func minimizeBytes(v []byte, try func([]byte) bool, shouldStop func() bool) {
 		}
 	}
 }
+
+// minimizeComposite minimizes v, a composite value (see isComposite). It
+// removes slice elements and map entries, sets pointers to nil, sets
+// primitive values to zero, and minimizes nested strings and byte slices.
+// try is called with each candidate value and reports whether the candidate
+// should be kept.
+//
+// v is not modified. Candidates passed to try may share memory with each
+// other, so try must not retain them unless it returns true.
+func minimizeComposite(v reflect.Value, try func(any) bool, shouldStop func() bool) {
+	root := copyValue(v)
+	minimizeElem(root, func() bool { return try(root.Interface()) }, shouldStop)
+}
+
+// minimizeElem minimizes v, a settable value within the root value being
+// minimized by minimizeComposite. try tests the root value with the current
+// contents of v. When try reports false, v is restored to its previous value.
+func minimizeElem(v reflect.Value, try func() bool, shouldStop func() bool) {
+	if shouldStop() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		prev := reflect.ValueOf(v.Interface())
+		v.SetZero()
+		if try() {
+			return
+		}
+		v.Set(prev)
+		minimizeElem(v.Elem(), try, shouldStop)
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			minimizeElem(v.Field(i), try, shouldStop)
+		}
+
+	case reflect.Array:
+		for i := range v.Len() {
+			minimizeElem(v.Index(i), try, shouldStop)
+		}
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			minimizeBytesElem(v, try, shouldStop)
+			return
+		}
+		// First, try to remove each element, starting from the end.
+		for i := v.Len() - 1; i >= 0; i-- {
+			if shouldStop() {
+				return
+			}
+			prev := reflect.ValueOf(v.Interface())
+			s := reflect.MakeSlice(v.Type(), 0, prev.Len()-1)
+			s = reflect.AppendSlice(s, prev.Slice(0, i))
+			s = reflect.AppendSlice(s, prev.Slice(i+1, prev.Len()))
+			v.Set(s)
+			if !try() {
+				v.Set(prev)
+			}
+		}
+		// Then, minimize the remaining elements.
+		for i := range v.Len() {
+			minimizeElem(v.Index(i), try, shouldStop)
+		}
+
+	case reflect.Map:
+		// First, try to remove each entry.
+		for _, e := range fmtsort.Sort(v) {
+			if shouldStop() {
+				return
+			}
+			v.SetMapIndex(e.Key, reflect.Value{})
+			if !try() {
+				v.SetMapIndex(e.Key, e.Value)
+			}
+		}
+		// Then, minimize the remaining elements. Map elements are not
+		// addressable, so minimize a copy of each element and store it back
+		// before each try.
+		for _, e := range fmtsort.Sort(v) {
+			elem := copyValue(e.Value)
+			minimizeElem(elem, func() bool {
+				v.SetMapIndex(e.Key, elem)
+				return try()
+			}, shouldStop)
+			v.SetMapIndex(e.Key, elem)
+		}
+
+	case reflect.String:
+		minimizeBytesElem(v, try, shouldStop)
+
+	default:
+		if v.IsZero() {
+			return
+		}
+		prev := reflect.ValueOf(v.Interface())
+		v.SetZero()
+		if !try() {
+			v.Set(prev)
+		}
+	}
+}
+
+// minimizeBytesElem minimizes v, a string or byte slice within a composite
+// value, using minimizeBytes.
+func minimizeBytesElem(v reflect.Value, try func() bool, shouldStop func() bool) {
+	if v.Len() == 0 {
+		return
+	}
+	// minimizeBytes never removes the last byte, so first try the empty value.
+	prev := reflect.ValueOf(v.Interface())
+	v.SetZero()
+	if try() {
+		return
+	}
+	v.Set(prev)
+
+	var b []byte
+	if v.Kind() == reflect.String {
+		b = []byte(v.String())
+	} else {
+		b = bytes.Clone(v.Bytes())
+	}
+	minimizeBytes(b, func(candidate []byte) bool {
+		prev := reflect.ValueOf(v.Interface())
+		if v.Kind() == reflect.String {
+			v.SetString(string(candidate))
+		} else {
+			// candidate is modified by minimizeBytes after try returns,
+			// so it must be copied.
+			v.SetBytes(bytes.Clone(candidate))
+		}
+		if try() {
+			return true
+		}
+		v.Set(prev)
+		return false
+	}, shouldStop)
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 	"unicode"
@@ -158,6 +159,80 @@ func TestMinimizeInput(t *testing.T) {
 	}
 }
 
+func TestMinimizeInputComposite(t *testing.T) {
+	type item struct {
+		Name string
+		Size int
+		Next *item
+	}
+	cases := []struct {
+		name     string
+		fn       func(CorpusEntry) error
+		input    []any
+		expected []any
+	}{
+		{
+			name: "slice_with_value",
+			fn: func(e CorpusEntry) error {
+				for _, v := range e.Values[0].([]int) {
+					if v == 42 {
+						return fmt.Errorf("bad %v", e.Values[0])
+					}
+				}
+				return nil
+			},
+			input:    []any{[]int{1, 2, 42, 3, 42}},
+			expected: []any{[]int{42}},
+		},
+		{
+			name: "struct_with_name",
+			fn: func(e CorpusEntry) error {
+				it := e.Values[0].(item)
+				if it.Next != nil && strings.Contains(it.Next.Name, "x") {
+					return fmt.Errorf("bad %v", e.Values[0])
+				}
+				return nil
+			},
+			input:    []any{item{Name: "first", Size: 10, Next: &item{Name: "axb", Size: 3}}},
+			expected: []any{item{Next: &item{Name: "x"}}},
+		},
+		{
+			name: "map_with_key",
+			fn: func(e CorpusEntry) error {
+				if _, ok := e.Values[0].(map[string]bool)["k"]; ok {
+					return fmt.Errorf("bad %v", e.Values[0])
+				}
+				return nil
+			},
+			input:    []any{map[string]bool{"a": true, "k": true, "z": false}},
+			expected: []any{map[string]bool{"k": false}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ws := &workerServer{
+				fuzzFn: func(e CorpusEntry) (time.Duration, error) {
+					return time.Second, tc.fn(e)
+				},
+			}
+			mem := &sharedMem{region: make([]byte, 1024)} // big enough to hold value and header
+			vals := tc.input
+			success, err := ws.minimizeInput(context.Background(), vals, mem, minimizeArgs{})
+			if !success {
+				t.Errorf("minimizeInput did not succeed")
+			}
+			if err == nil {
+				t.Fatal("minimizeInput didn't provide an error")
+			}
+			if !reflect.DeepEqual(vals, tc.expected) {
+				t.Errorf("unexpected results: got %v, want %v", vals, tc.expected)
+			}
+		})
+	}
+}
+
 // TestMinimizeFlaky checks that if we're minimizing an interesting
 // input and a flaky failure occurs, that minimization was not indicated
 // to be successful, and the error isn't returned (since it's flaky).
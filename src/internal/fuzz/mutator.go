@@ -8,12 +8,21 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"reflect"
 	"unsafe"
 )
 
 type mutator struct {
 	r       mutatorRand
 	scratch []byte // scratch slice to avoid additional allocations
+
+	// dict holds the tokens of the fuzz test's dictionary, and cmpTokens
+	// holds operands of comparisons made by the code being fuzzed. Both are
+	// used by the token mutators. They must be the same in a worker and in
+	// the coordinator's client for that worker, so that the client can
+	// replay the worker's mutations.
+	dict      [][]byte
+	cmpTokens [][]byte
 }
 
 func newMutator() *mutator {
@@ -31,6 +40,48 @@ func (m *mutator) randByteOrder() binary.ByteOrder {
 	return binary.BigEndian
 }
 
+// randToken returns a random token from the dictionary or from the
+// comparison operands, or nil if there are none.
+func (m *mutator) randToken() []byte {
+	n := len(m.dict) + len(m.cmpTokens)
+	if n == 0 {
+		return nil
+	}
+	i := m.rand(n)
+	if i < len(m.dict) {
+		return m.dict[i]
+	}
+	return m.cmpTokens[i-len(m.dict)]
+}
+
+// randIntToken occasionally returns an integer decoded from a random token
+// of 1, 2, 4 or 8 bytes in little-endian order, sign-extended if signed is
+// set. Such tokens are often operands of integer comparisons.
+func (m *mutator) randIntToken(signed bool) (uint64, bool) {
+	if len(m.dict)+len(m.cmpTokens) == 0 || m.rand(4) != 0 {
+		return 0, false
+	}
+	tok := m.randToken()
+	var v uint64
+	switch len(tok) {
+	case 1:
+		v = uint64(tok[0])
+	case 2:
+		v = uint64(binary.LittleEndian.Uint16(tok))
+	case 4:
+		v = uint64(binary.LittleEndian.Uint32(tok))
+	case 8:
+		v = binary.LittleEndian.Uint64(tok)
+	default:
+		return 0, false
+	}
+	if signed {
+		shift := 64 - 8*len(tok)
+		v = uint64(int64(v<<shift) >> shift)
+	}
+	return v, true
+}
+
 // chooseLen chooses length of range mutation in range [1,n]. It gives
 // preference to shorter ranges.
 func (m *mutator) chooseLen(n int) int {
@@ -58,35 +109,42 @@ func (m *mutator) mutate(vals []any, maxBytes int) {
 	// Pick a random value to mutate.
 	// TODO: consider mutating more than one value at a time.
 	i := m.rand(len(vals))
-	switch v := vals[i].(type) {
+	vals[i] = m.mutateValue(vals[i], maxPerVal)
+}
+
+// mutateValue returns a mutation of v, whose encoding should not exceed
+// maxPerVal bytes.
+func (m *mutator) mutateValue(v any, maxPerVal int) any {
+	switch v := v.(type) {
 	case int:
-		vals[i] = int(m.mutateInt(int64(v), maxInt))
+		return int(m.mutateInt(int64(v), maxInt))
 	case int8:
-		vals[i] = int8(m.mutateInt(int64(v), math.MaxInt8))
+		return int8(m.mutateInt(int64(v), math.MaxInt8))
 	case int16:
-		vals[i] = int16(m.mutateInt(int64(v), math.MaxInt16))
+		return int16(m.mutateInt(int64(v), math.MaxInt16))
 	case int64:
-		vals[i] = m.mutateInt(v, maxInt)
+		return m.mutateInt(v, maxInt)
 	case uint:
-		vals[i] = uint(m.mutateUInt(uint64(v), maxUint))
+		return uint(m.mutateUInt(uint64(v), maxUint))
 	case uint16:
-		vals[i] = uint16(m.mutateUInt(uint64(v), math.MaxUint16))
+		return uint16(m.mutateUInt(uint64(v), math.MaxUint16))
 	case uint32:
-		vals[i] = uint32(m.mutateUInt(uint64(v), math.MaxUint32))
+		return uint32(m.mutateUInt(uint64(v), math.MaxUint32))
 	case uint64:
-		vals[i] = m.mutateUInt(v, maxUint)
+		return m.mutateUInt(v, maxUint)
 	case float32:
-		vals[i] = float32(m.mutateFloat(float64(v), math.MaxFloat32))
+		return float32(m.mutateFloat(float64(v), math.MaxFloat32))
 	case float64:
-		vals[i] = m.mutateFloat(v, math.MaxFloat64)
+		return m.mutateFloat(v, math.MaxFloat64)
 	case bool:
 		if m.rand(2) == 1 {
-			vals[i] = !v // 50% chance of flipping the bool
+			return !v // 50% chance of flipping the bool
 		}
+		return v
 	case rune: // int32
-		vals[i] = rune(m.mutateInt(int64(v), math.MaxInt32))
+		return rune(m.mutateInt(int64(v), math.MaxInt32))
 	case byte: // uint8
-		vals[i] = byte(m.mutateUInt(uint64(v), math.MaxUint8))
+		return byte(m.mutateUInt(uint64(v), math.MaxUint8))
 	case string:
 		if len(v) > maxPerVal {
 			panic(fmt.Sprintf("cannot mutate bytes of length %d", len(v)))
@@ -98,7 +156,7 @@ func (m *mutator) mutate(vals []any, maxBytes int) {
 			copy(m.scratch, v)
 		}
 		m.mutateBytes(&m.scratch)
-		vals[i] = string(m.scratch)
+		return string(m.scratch)
 	case []byte:
 		if len(v) > maxPerVal {
 			panic(fmt.Sprintf("cannot mutate bytes of length %d", len(v)))
@@ -110,13 +168,21 @@ func (m *mutator) mutate(vals []any, maxBytes int) {
 			copy(m.scratch, v)
 		}
 		m.mutateBytes(&m.scratch)
-		vals[i] = m.scratch
+		return m.scratch
 	default:
-		panic(fmt.Sprintf("type not supported for mutating: %T", vals[i]))
+		if t := reflect.TypeOf(v); t == nil || !isComposite(t) {
+			panic(fmt.Sprintf("type not supported for mutating: %T", v))
+		}
+		return m.mutateComposite(reflect.ValueOf(v), maxPerVal).Interface()
 	}
 }
 
 func (m *mutator) mutateInt(v, maxValue int64) int64 {
+	if t, ok := m.randIntToken(true); ok {
+		if t := int64(t); t != v && t >= -maxValue && t <= maxValue {
+			return t
+		}
+	}
 	var max int64
 	for {
 		max = 100
@@ -148,6 +214,9 @@ func (m *mutator) mutateInt(v, maxValue int64) int64 {
 }
 
 func (m *mutator) mutateUInt(v, maxValue uint64) uint64 {
+	if t, ok := m.randIntToken(false); ok && t != v && t <= maxValue {
+		return t
+	}
 	var max uint64
 	for {
 		max = 100
@@ -252,6 +321,9 @@ var byteSliceMutators = []byteSliceMutator{
 	byteSliceOverwriteConstantBytes,
 	byteSliceShuffleBytes,
 	byteSliceSwapBytes,
+	byteSliceInsertToken,
+	byteSliceOverwriteToken,
+	byteSliceSpliceToken,
 }
 
 func (m *mutator) mutateBytes(ptrB *[]byte) {
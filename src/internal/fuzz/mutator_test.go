@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"reflect"
 	"strconv"
 	"testing"
 )
@@ -115,3 +116,74 @@ func TestStringImmutability(t *testing.T) {
 		t.Fatalf("string was mutated: got %x, want %x", []byte(original), originalCopy)
 	}
 }
+
+func TestMutateComposite(t *testing.T) {
+	type inner struct {
+		Name  string
+		Count uint16
+	}
+	type outer struct {
+		Items []inner
+		Index map[string]*inner
+		Flags [2]bool
+		Data  []byte
+	}
+	orig := outer{
+		Items: []inner{{Name: "a", Count: 1}},
+		Index: map[string]*inner{"a": {Name: "a", Count: 1}},
+		Data:  []byte("data"),
+	}
+	origData := marshalCorpusFile(orig)
+
+	m := newMutator()
+	vals := []any{orig}
+	for i := 0; i < 1000; i++ {
+		m.mutate(vals, 1<<20)
+		v, ok := vals[0].(outer)
+		if !ok {
+			t.Fatalf("mutated value has type %T, want %T", vals[0], orig)
+		}
+		// The mutated value must round-trip through the corpus encoding.
+		data := marshalCorpusFile(v)
+		got, err := unmarshalCorpusFile(data, []reflect.Type{reflect.TypeFor[outer]()})
+		if err != nil {
+			t.Fatalf("unmarshaling mutated value: %v\n%s", err, data)
+		}
+		if gotData := marshalCorpusFile(got...); !bytes.Equal(gotData, data) {
+			t.Fatalf("mutated value did not round-trip:\ngot:\n%s\nwant:\n%s", gotData, data)
+		}
+	}
+	if data := marshalCorpusFile(orig); !bytes.Equal(data, origData) {
+		t.Errorf("original value was mutated:\ngot:\n%s\nwant:\n%s", data, origData)
+	}
+}
+
+func TestMutateCompositeTooLarge(t *testing.T) {
+	// The only mutation of a nil pointer allocates a value,
+	// whose encoding here is always larger than maxBytes.
+	var p *[64]int
+	m := newMutator()
+	vals := []any{p}
+	for i := 0; i < 10; i++ {
+		m.mutate(vals, 16)
+		if vals[0].(*[64]int) != nil {
+			t.Fatalf("mutated value %v exceeds the size limit", vals[0])
+		}
+	}
+}
+
+func TestMutateCompositeDeterministic(t *testing.T) {
+	v := []any{map[int][]string{1: {"a"}, 2: {"b", "c"}, 3: nil}}
+	m1, m2 := newMutator(), newMutator()
+	var state, inc uint64
+	m1.r.save(&state, &inc)
+	m2.r.restore(state, inc)
+	v1, v2 := []any{v[0]}, []any{v[0]}
+	for i := 0; i < 100; i++ {
+		m1.mutate(v1, 1<<20)
+		m2.mutate(v2, 1<<20)
+		if b1, b2 := marshalCorpusFile(v1...), marshalCorpusFile(v2...); !bytes.Equal(b1, b2) {
+			t.Fatalf("mutation %d differs:\n%s\n%s", i, b1, b2)
+		}
+	}
+}
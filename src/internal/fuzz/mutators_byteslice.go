@@ -311,3 +311,46 @@ func byteSliceSwapBytes(m *mutator, b []byte) []byte {
 	b = b[:end]
 	return b
 }
+
+// byteSliceInsertToken inserts a dictionary token into b at a random position.
+func byteSliceInsertToken(m *mutator, b []byte) []byte {
+	tok := m.randToken()
+	if tok == nil || len(b)+len(tok) >= cap(b) {
+		return nil
+	}
+	pos := m.rand(len(b) + 1)
+	b = b[:len(b)+len(tok)]
+	copy(b[pos+len(tok):], b[pos:])
+	copy(b[pos:], tok)
+	return b
+}
+
+// byteSliceOverwriteToken overwrites a chunk of b with a dictionary token.
+func byteSliceOverwriteToken(m *mutator, b []byte) []byte {
+	tok := m.randToken()
+	if tok == nil || len(tok) > len(b) {
+		return nil
+	}
+	pos := m.rand(len(b) - len(tok) + 1)
+	copy(b[pos:], tok)
+	return b
+}
+
+// byteSliceSpliceToken replaces a random chunk of b with a dictionary token.
+func byteSliceSpliceToken(m *mutator, b []byte) []byte {
+	tok := m.randToken()
+	if tok == nil || len(b) == 0 {
+		return nil
+	}
+	pos0 := m.rand(len(b))
+	pos1 := pos0 + m.chooseLen(len(b)-pos0)
+	n := len(b) - (pos1 - pos0) + len(tok)
+	if n >= cap(b) {
+		return nil
+	}
+	tail := b[pos1:]
+	b = b[:n]
+	copy(b[pos0+len(tok):], tail)
+	copy(b[pos0:], tok)
+	return b
+}
@@ -53,6 +53,7 @@ func TestByteSliceMutators(t *testing.T) {
 		name     string
 		mutator  func(*mutator, []byte) []byte
 		randVals []int
+		dict     [][]byte
 		input    []byte
 		expected []byte
 	}{
@@ -165,13 +166,40 @@ func TestByteSliceMutators(t *testing.T) {
 			input:    append(make([]byte, 0, 9), []byte{1, 2, 3, 4}...),
 			expected: []byte{3, 2, 1, 4},
 		},
+		{
+			name:     "byteSliceInsertToken",
+			mutator:  byteSliceInsertToken,
+			dict:     [][]byte{{8, 9}},
+			input:    append(make([]byte, 0, 8), []byte{1, 2, 3, 4}...),
+			expected: []byte{1, 8, 9, 2, 3, 4},
+		},
+		{
+			name:     "byteSliceInsertToken with no tokens",
+			mutator:  byteSliceInsertToken,
+			input:    append(make([]byte, 0, 8), []byte{1, 2, 3, 4}...),
+			expected: nil,
+		},
+		{
+			name:     "byteSliceOverwriteToken",
+			mutator:  byteSliceOverwriteToken,
+			dict:     [][]byte{{8, 9}},
+			input:    []byte{1, 2, 3, 4},
+			expected: []byte{1, 8, 9, 4},
+		},
+		{
+			name:     "byteSliceSpliceToken",
+			mutator:  byteSliceSpliceToken,
+			dict:     [][]byte{{8, 9}},
+			input:    append(make([]byte, 0, 8), []byte{1, 2, 3, 4}...),
+			expected: []byte{1, 8, 9, 3, 4},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			r := &mockRand{values: []int{0, 1, 2, 3, 4, 5}}
 			if tc.randVals != nil {
 				r.values = tc.randVals
 			}
-			m := &mutator{r: r}
+			m := &mutator{r: r, dict: tc.dict}
 			b := tc.mutator(m, tc.input)
 			if !bytes.Equal(b, tc.expected) {
 				t.Errorf("got %x, want %x", b, tc.expected)
@@ -203,13 +231,16 @@ func BenchmarkByteSliceMutators(b *testing.B) {
 		{"OverwriteConstantBytes", byteSliceOverwriteConstantBytes},
 		{"ShuffleBytes", byteSliceShuffleBytes},
 		{"SwapBytes", byteSliceSwapBytes},
+		{"InsertToken", byteSliceInsertToken},
+		{"OverwriteToken", byteSliceOverwriteToken},
+		{"SpliceToken", byteSliceSpliceToken},
 	}
 
 	for _, tc := range tests {
 		b.Run(tc.name, func(b *testing.B) {
 			for size := 64; size <= 1024; size *= 2 {
 				b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
-					m := &mutator{r: newPcgRand()}
+					m := &mutator{r: newPcgRand(), dict: [][]byte{[]byte("token")}}
 					input := make([]byte, size)
 					for i := 0; i < b.N; i++ {
 						tc.mutator(m, input)
@@ -0,0 +1,189 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"internal/fmtsort"
+	"reflect"
+)
+
+// mutateComposite returns a mutated copy of v, which must be a composite
+// value (see isComposite). v itself is left unchanged: the worker restores
+// the original input between chains of mutations, and the coordinator must
+// be able to replay the same mutations on the same input.
+//
+// Mutations that grow the encoding of v beyond maxBytes are discarded, and
+// another mutation is tried instead. If none of maxCompositeAttempts
+// mutations fits, as when every mutation of v must grow it, v is returned
+// unchanged.
+func (m *mutator) mutateComposite(v reflect.Value, maxBytes int) reflect.Value {
+	size := encodedLen(v)
+	for range maxCompositeAttempts {
+		c := copyValue(v)
+		m.mutateElem(c, maxBytes)
+		if n := encodedLen(c); n <= maxBytes || n <= size {
+			return c
+		}
+	}
+	return v
+}
+
+// maxCompositeAttempts is the number of mutations of a composite value
+// mutateComposite tries before giving up.
+const maxCompositeAttempts = 100
+
+// mutateElem applies a single mutation to v, which must be settable, or to
+// one of the values nested within it.
+func (m *mutator) mutateElem(v reflect.Value, maxBytes int) {
+	if !isComposite(v.Type()) {
+		if err := setBasicValue(v, m.mutateValue(basicValue(v), maxBytes)); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		switch {
+		case v.IsNil():
+			v.Set(reflect.New(v.Type().Elem()))
+		case m.rand(10) == 0:
+			v.SetZero()
+		default:
+			m.mutateElem(v.Elem(), maxBytes)
+		}
+
+	case reflect.Struct:
+		if v.NumField() > 0 {
+			m.mutateElem(v.Field(m.rand(v.NumField())), maxBytes)
+		}
+
+	case reflect.Array:
+		if v.Len() > 0 {
+			m.mutateElem(v.Index(m.rand(v.Len())), maxBytes)
+		}
+
+	case reflect.Slice:
+		n := v.Len()
+		switch x := m.rand(10); {
+		case n == 0 || x == 0:
+			// Insert a new element at a random position, and mutate it.
+			i := m.rand(n + 1)
+			v.Set(sliceInsert(v, i, reflect.New(v.Type().Elem()).Elem()))
+			m.mutateElem(v.Index(i), maxBytes)
+		case x == 1:
+			// Duplicate an element into a random position.
+			e := copyValue(v.Index(m.rand(n)))
+			v.Set(sliceInsert(v, m.rand(n+1), e))
+		case x == 2:
+			// Remove an element.
+			i := m.rand(n)
+			s := reflect.MakeSlice(v.Type(), 0, n-1)
+			s = reflect.AppendSlice(s, v.Slice(0, i))
+			s = reflect.AppendSlice(s, v.Slice(i+1, n))
+			v.Set(s)
+		default:
+			m.mutateElem(v.Index(m.rand(n)), maxBytes)
+		}
+
+	case reflect.Map:
+		// Map iteration order is random, so use the sorted order of the
+		// keys to keep mutations reproducible.
+		entries := fmtsort.Sort(v)
+		n := len(entries)
+		switch x := m.rand(10); {
+		case n == 0 || x == 0:
+			// Insert an entry with a new key and a zero value.
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			m.mutateElem(key, maxBytes)
+			if !v.MapIndex(key).IsValid() {
+				v.SetMapIndex(key, reflect.New(v.Type().Elem()).Elem())
+			}
+		case x == 1:
+			// Remove an entry.
+			v.SetMapIndex(entries[m.rand(n)].Key, reflect.Value{})
+		case x == 2:
+			// Move an entry to a mutated key.
+			e := entries[m.rand(n)]
+			key := copyValue(e.Key)
+			m.mutateElem(key, maxBytes)
+			v.SetMapIndex(e.Key, reflect.Value{})
+			v.SetMapIndex(key, e.Value)
+		default:
+			// Map elements are not addressable, so mutate a copy of the
+			// element and store it back.
+			e := entries[m.rand(n)]
+			elem := copyValue(e.Value)
+			m.mutateElem(elem, maxBytes)
+			v.SetMapIndex(e.Key, elem)
+		}
+	}
+}
+
+// sliceInsert returns a new slice with the elements of s and e inserted at
+// index i.
+func sliceInsert(s reflect.Value, i int, e reflect.Value) reflect.Value {
+	n := s.Len()
+	r := reflect.MakeSlice(s.Type(), 0, n+1)
+	r = reflect.AppendSlice(r, s.Slice(0, i))
+	r = reflect.Append(r, e)
+	return reflect.AppendSlice(r, s.Slice(i, n))
+}
+
+// copyValue returns a settable deep copy of v.
+func copyValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			p := reflect.New(v.Type().Elem())
+			p.Elem().Set(copyValue(v.Elem()))
+			c.Set(p)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(c, v)
+			break
+		}
+		for i := range v.Len() {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(copyValue(iter.Key()), copyValue(iter.Value()))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			c.Field(i).Set(copyValue(v.Field(i)))
+		}
+	default:
+		c.Set(v)
+	}
+	return c
+}
+
+// encodedLen returns the length of the corpus encoding of v, a composite
+// value.
+func encodedLen(v reflect.Value) int {
+	var b bytes.Buffer
+	marshalComposite(&b, v)
+	return b.Len()
+}
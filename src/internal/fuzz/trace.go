@@ -21,15 +21,36 @@ import _ "unsafe" // for go:linkname
 //go:linkname libfuzzerHookStrCmp runtime.libfuzzerHookStrCmp
 //go:linkname libfuzzerHookEqualFold runtime.libfuzzerHookEqualFold
 
-func libfuzzerTraceCmp1(arg0, arg1 uint8, fakePC uint)  {}
-func libfuzzerTraceCmp2(arg0, arg1 uint16, fakePC uint) {}
-func libfuzzerTraceCmp4(arg0, arg1 uint32, fakePC uint) {}
-func libfuzzerTraceCmp8(arg0, arg1 uint64, fakePC uint) {}
-
-func libfuzzerTraceConstCmp1(arg0, arg1 uint8, fakePC uint)  {}
-func libfuzzerTraceConstCmp2(arg0, arg1 uint16, fakePC uint) {}
-func libfuzzerTraceConstCmp4(arg0, arg1 uint32, fakePC uint) {}
-func libfuzzerTraceConstCmp8(arg0, arg1 uint64, fakePC uint) {}
-
-func libfuzzerHookStrCmp(arg0, arg1 string, fakePC uint)    {}
-func libfuzzerHookEqualFold(arg0, arg1 string, fakePC uint) {}
+func libfuzzerTraceCmp1(arg0, arg1 uint8, fakePC uint) {
+	// Single-byte comparisons are only worth recording against constants,
+	// such as delimiters.
+}
+func libfuzzerTraceCmp2(arg0, arg1 uint16, fakePC uint) {
+	traceCmpInt(uint64(arg0), uint64(arg1), 2, false, fakePC)
+}
+func libfuzzerTraceCmp4(arg0, arg1 uint32, fakePC uint) {
+	traceCmpInt(uint64(arg0), uint64(arg1), 4, false, fakePC)
+}
+func libfuzzerTraceCmp8(arg0, arg1 uint64, fakePC uint) {
+	traceCmpInt(arg0, arg1, 8, false, fakePC)
+}
+
+func libfuzzerTraceConstCmp1(arg0, arg1 uint8, fakePC uint) {
+	traceCmpInt(uint64(arg0), uint64(arg1), 1, true, fakePC)
+}
+func libfuzzerTraceConstCmp2(arg0, arg1 uint16, fakePC uint) {
+	traceCmpInt(uint64(arg0), uint64(arg1), 2, true, fakePC)
+}
+func libfuzzerTraceConstCmp4(arg0, arg1 uint32, fakePC uint) {
+	traceCmpInt(uint64(arg0), uint64(arg1), 4, true, fakePC)
+}
+func libfuzzerTraceConstCmp8(arg0, arg1 uint64, fakePC uint) {
+	traceCmpInt(arg0, arg1, 8, true, fakePC)
+}
+
+func libfuzzerHookStrCmp(arg0, arg1 string, fakePC uint) {
+	traceCmpString(arg0, arg1, fakePC)
+}
+func libfuzzerHookEqualFold(arg0, arg1 string, fakePC uint) {
+	traceCmpString(arg0, arg1, fakePC)
+}
@@ -152,6 +152,7 @@ func (w *worker) coordinate(ctx context.Context) error {
 				Timeout:      input.timeout,
 				Warmup:       input.warmup,
 				CoverageData: input.coverageData,
+				CmpTokens:    input.cmpTokens,
 			}
 			entry, resp, isInternalError, err := w.client.fuzz(ctx, input.entry, args)
 			canMinimize := true
@@ -195,6 +196,7 @@ func (w *worker) coordinate(ctx context.Context) error {
 				crasherMsg:    resp.Err,
 				coverageData:  resp.CoverageData,
 				canMinimize:   canMinimize,
+				cmpTokens:     resp.CmpTokens,
 			}
 			w.coordinator.resultC <- result
 
@@ -381,7 +383,8 @@ func (w *worker) start() (err error) {
 	w.termC = make(chan struct{})
 	comm := workerComm{fuzzIn: fuzzInW, fuzzOut: fuzzOutR, memMu: w.memMu}
 	m := newMutator()
-	w.client = newWorkerClient(comm, m)
+	m.dict = w.coordinator.opts.Dictionary
+	w.client = newWorkerClient(comm, m, w.coordinator.opts.Types)
 
 	go func() {
 		w.waitErr = w.cmd.Wait()
@@ -475,13 +478,17 @@ func (w *worker) stop() error {
 // coordinator process in order to fuzz random inputs. RunFuzzWorker loops
 // until the coordinator tells it to stop.
 //
+// types is the list of types of the fuzz function's arguments, which must
+// match the types the coordinator was given, and dict is the fuzz test's
+// dictionary, which must also match.
+//
 // fn is a wrapper on the fuzz function. It may return an error to indicate
 // a given input "crashed". The coordinator will also record a crasher if
 // the function times out or terminates the process.
 //
 // RunFuzzWorker returns an error if it could not communicate with the
 // coordinator process.
-func RunFuzzWorker(ctx context.Context, fn func(CorpusEntry) error) error {
+func RunFuzzWorker(ctx context.Context, types []reflect.Type, dict [][]byte, fn func(CorpusEntry) error) error {
 	comm, err := getWorkerComm()
 	if err != nil {
 		return err
@@ -494,11 +501,15 @@ func RunFuzzWorker(ctx context.Context, fn func(CorpusEntry) error) error {
 			})
 			defer timer.Stop()
 			start := time.Now()
+			cmpTracing.Store(true)
 			err := fn(e)
+			cmpTracing.Store(false)
 			return time.Since(start), err
 		},
-		m: newMutator(),
+		m:     newMutator(),
+		types: types,
 	}
+	srv.m.dict = dict
 	return srv.serve(ctx)
 }
 
@@ -574,6 +585,10 @@ type fuzzArgs struct {
 	// CoverageData is the coverage data. If set, the worker should update its
 	// local coverage data prior to fuzzing.
 	CoverageData []byte
+
+	// CmpTokens are operands of comparisons gathered by all workers, which
+	// the worker's mutator should use while fuzzing.
+	CmpTokens [][]byte
 }
 
 // fuzzResponse contains results from workerServer.fuzz.
@@ -596,6 +611,10 @@ type fuzzResponse struct {
 	// InternalErr is the error string caused by an internal error in the
 	// worker. This shouldn't be considered a crasher.
 	InternalErr string
+
+	// CmpTokens are operands of comparisons made by the code being fuzzed
+	// during this call.
+	CmpTokens [][]byte
 }
 
 // pingArgs contains arguments to workerServer.ping.
@@ -627,6 +646,10 @@ type workerServer struct {
 	workerComm
 	m *mutator
 
+	// types is the list of types of the fuzz target's arguments, used to
+	// decode inputs from shared memory.
+	types []reflect.Type
+
 	// coverageMask is the local coverage data for the worker. It is
 	// periodically updated to reflect the data in the coordinator when new
 	// coverage is found.
@@ -721,8 +744,10 @@ func (ws *workerServer) fuzz(ctx context.Context, args fuzzArgs) (resp fuzzRespo
 	}
 	mem := <-ws.memMu
 	ws.m.r.save(&mem.header().randState, &mem.header().randInc)
+	ws.m.cmpTokens = args.CmpTokens
 	defer func() {
 		resp.Count = mem.header().count
+		resp.CmpTokens = harvestCmpTokens()
 		ws.memMu <- mem
 	}()
 	if args.Limit > 0 && mem.header().count >= args.Limit {
@@ -730,7 +755,7 @@ func (ws *workerServer) fuzz(ctx context.Context, args fuzzArgs) (resp fuzzRespo
 		return resp
 	}
 
-	originalVals, err := unmarshalCorpusFile(mem.valueCopy())
+	originalVals, err := unmarshalCorpusFile(mem.valueCopy(), ws.types)
 	if err != nil {
 		resp.InternalErr = err.Error()
 		return resp
@@ -805,7 +830,7 @@ func (ws *workerServer) minimize(ctx context.Context, args minimizeArgs) (resp m
 	defer func() { resp.Duration = time.Since(start) }()
 	mem := <-ws.memMu
 	defer func() { ws.memMu <- mem }()
-	vals, err := unmarshalCorpusFile(mem.valueCopy())
+	vals, err := unmarshalCorpusFile(mem.valueCopy(), ws.types)
 	if err != nil {
 		panic(err)
 	}
@@ -874,23 +899,12 @@ func (ws *workerServer) minimizeInput(ctx context.Context, vals []any, mem *shar
 	}
 	mem.header().rawInMem = true
 
-	// tryMinimized runs the fuzz function with candidate replacing the value
-	// at index valI. tryMinimized returns whether the input with candidate is
-	// interesting for the same reason as the original input: it returns
-	// an error if one was expected, or it preserves coverage.
-	tryMinimized := func(candidate []byte) bool {
-		prev := vals[args.Index]
-		switch prev.(type) {
-		case []byte:
-			vals[args.Index] = candidate
-		case string:
-			vals[args.Index] = string(candidate)
-		default:
-			panic("impossible")
-		}
-		copy(*bPtr, candidate)
-		*bPtr = (*bPtr)[:len(candidate)]
-		mem.setValueLen(len(candidate))
+	// tryVals runs the fuzz function with vals, where the value at
+	// args.Index has been replaced by a candidate. tryVals returns whether
+	// the input is interesting for the same reason as the original input: it
+	// returns an error if one was expected, or it preserves coverage. If not,
+	// tryVals restores the value at args.Index to prev.
+	tryVals := func(prev any) bool {
 		*count++
 		_, err := ws.fuzzFn(CorpusEntry{Values: vals})
 		if err != nil {
@@ -910,13 +924,42 @@ func (ws *workerServer) minimizeInput(ctx context.Context, vals []any, mem *shar
 		vals[args.Index] = prev
 		return false
 	}
+
+	// tryMinimized runs the fuzz function with candidate replacing the value
+	// at index valI. tryMinimized returns whether the input with candidate is
+	// interesting for the same reason as the original input.
+	tryMinimized := func(candidate []byte) bool {
+		prev := vals[args.Index]
+		switch prev.(type) {
+		case []byte:
+			vals[args.Index] = candidate
+		case string:
+			vals[args.Index] = string(candidate)
+		default:
+			panic("impossible")
+		}
+		copy(*bPtr, candidate)
+		*bPtr = (*bPtr)[:len(candidate)]
+		mem.setValueLen(len(candidate))
+		return tryVals(prev)
+	}
+
+	// tryMinimizedValue is like tryMinimized, but for composite values.
+	// Composite values have no raw form, so the candidate is stored in
+	// shared memory as a corpus file containing only that value.
+	tryMinimizedValue := func(candidate any) bool {
+		prev := vals[args.Index]
+		vals[args.Index] = candidate
+		mem.setValue(marshalCorpusFile(candidate))
+		return tryVals(prev)
+	}
 	switch v := vals[args.Index].(type) {
 	case string:
 		minimizeBytes([]byte(v), tryMinimized, shouldStop)
 	case []byte:
 		minimizeBytes(v, tryMinimized, shouldStop)
 	default:
-		panic("impossible")
+		minimizeComposite(reflect.ValueOf(v), tryMinimizedValue, shouldStop)
 	}
 	return true, retErr
 }
@@ -939,6 +982,10 @@ type workerClient struct {
 	workerComm
 	m *mutator
 
+	// types is the list of types of the fuzz target's arguments, used to
+	// decode inputs from shared memory.
+	types []reflect.Type
+
 	// mu is the mutex protecting the workerComm.fuzzIn pipe. This must be
 	// locked before making calls to the workerServer. It prevents
 	// workerClient.Close from closing fuzzIn while workerClient methods are
@@ -947,8 +994,8 @@ type workerClient struct {
 	mu sync.Mutex
 }
 
-func newWorkerClient(comm workerComm, m *mutator) *workerClient {
-	return &workerClient{workerComm: comm, m: m}
+func newWorkerClient(comm workerComm, m *mutator, types []reflect.Type) *workerClient {
+	return &workerClient{workerComm: comm, m: m, types: types}
 }
 
 // Close shuts down the connection to the RPC server (the worker process) by
@@ -1001,7 +1048,7 @@ func (wc *workerClient) minimize(ctx context.Context, entryIn CorpusEntry, args
 	}
 	mem.setValue(inp)
 	entryOut = entryIn
-	entryOut.Values, err = unmarshalCorpusFile(inp)
+	entryOut.Values, err = unmarshalCorpusFile(inp, wc.types)
 	if err != nil {
 		return CorpusEntry{}, minimizeResponse{}, fmt.Errorf("workerClient.minimize unmarshaling provided value: %v", err)
 	}
@@ -1027,14 +1074,19 @@ func (wc *workerClient) minimize(ctx context.Context, entryIn CorpusEntry, args
 			}
 			// An unrecoverable error occurred during minimization. mem now
 			// holds the raw, unmarshaled bytes of entryIn.Values[i] that
-			// caused the error.
+			// caused the error, or for a composite value, a corpus file
+			// containing only that value.
 			switch entryOut.Values[i].(type) {
 			case string:
 				entryOut.Values[i] = string(mem.valueCopy())
 			case []byte:
 				entryOut.Values[i] = mem.valueCopy()
 			default:
-				panic("impossible")
+				vals, err := unmarshalCorpusFile(mem.valueCopy(), wc.types[i:i+1])
+				if err != nil {
+					return entryIn, minimizeResponse{}, retErr
+				}
+				entryOut.Values[i] = vals[0]
 			}
 			entryOut.Data = marshalCorpusFile(entryOut.Values...)
 			// Stop minimizing; another unrecoverable error is likely to occur.
@@ -1044,7 +1096,7 @@ func (wc *workerClient) minimize(ctx context.Context, entryIn CorpusEntry, args
 		if resp.WroteToMem {
 			// Minimization succeeded, and mem holds the marshaled data.
 			entryOut.Data = mem.valueCopy()
-			entryOut.Values, err = unmarshalCorpusFile(entryOut.Data)
+			entryOut.Values, err = unmarshalCorpusFile(entryOut.Data, wc.types)
 			if err != nil {
 				return CorpusEntry{}, minimizeResponse{}, fmt.Errorf("workerClient.minimize unmarshaling minimized value: %v", err)
 			}
@@ -1106,11 +1158,12 @@ func (wc *workerClient) fuzz(ctx context.Context, entryIn CorpusEntry, args fuzz
 	needEntryOut := callErr != nil || resp.Err != "" ||
 		(!args.Warmup && resp.CoverageData != nil)
 	if needEntryOut {
-		valuesOut, err := unmarshalCorpusFile(inp)
+		valuesOut, err := unmarshalCorpusFile(inp, wc.types)
 		if err != nil {
 			return CorpusEntry{}, fuzzResponse{}, true, fmt.Errorf("unmarshaling fuzz input value after call: %v", err)
 		}
 		wc.m.r.restore(mem.header().randState, mem.header().randInc)
+		wc.m.cmpTokens = args.CmpTokens
 		if !args.Warmup {
 			// Only mutate the valuesOut if fuzzing actually occurred.
 			numMutations := ((resp.Count - 1) % chainedMutations) + 1
@@ -1133,6 +1186,10 @@ func (wc *workerClient) fuzz(ctx context.Context, entryIn CorpusEntry, args fuzz
 			// then entryOut is too.
 			entryOut.IsSeed = entryIn.IsSeed
 		}
+	} else if args.Warmup {
+		// Identify the input, so the coverage reported for it can be
+		// attributed to it.
+		entryOut = CorpusEntry{Parent: entryIn.Path, IsSeed: entryIn.IsSeed}
 	}
 
 	return entryOut, resp, false, callErr
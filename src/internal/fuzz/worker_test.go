@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	"reflect"
+	"slices"
 	"strconv"
 	"testing"
 	"time"
@@ -154,7 +155,7 @@ func runBenchmarkWorker() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 	fn := func(CorpusEntry) error { return nil }
-	if err := RunFuzzWorker(ctx, fn); err != nil && err != ctx.Err() {
+	if err := RunFuzzWorker(ctx, nil, nil, fn); err != nil && err != ctx.Err() {
 		panic(err)
 	}
 }
@@ -203,3 +204,54 @@ func BenchmarkWorkerMinimize(b *testing.B) {
 		})
 	}
 }
+
+func TestWorkerFuzzArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "equals",
+			args: []string{"-test.fuzzworker", "-test.fuzz=Fuzz", "-test.v"},
+			want: []string{"-test.fuzzworker", "-test.fuzz=^FuzzA$", "-test.v"},
+		},
+		{
+			name: "separate value",
+			args: []string{"-test.fuzzworker", "-test.fuzz", "Fuzz", "-test.v"},
+			want: []string{"-test.fuzzworker", "-test.fuzz=^FuzzA$", "-test.v"},
+		},
+		{
+			name: "double dash equals",
+			args: []string{"-test.fuzzworker", "--test.fuzz=Fuzz", "-test.v"},
+			want: []string{"-test.fuzzworker", "-test.fuzz=^FuzzA$", "-test.v"},
+		},
+		{
+			name: "double dash separate value",
+			args: []string{"-test.fuzzworker", "--test.fuzz", "Fuzz", "-test.v"},
+			want: []string{"-test.fuzzworker", "-test.fuzz=^FuzzA$", "-test.v"},
+		},
+		{
+			name: "repeated",
+			args: []string{"-test.fuzzworker", "-test.fuzz", "Fuzz", "--test.fuzz=FuzzB"},
+			want: []string{"-test.fuzzworker", "-test.fuzz=^FuzzA$", "-test.fuzz=^FuzzA$"},
+		},
+		{
+			name: "missing",
+			args: []string{"-test.fuzzworker", "-test.v", "arg"},
+			want: []string{"-test.fuzz=^FuzzA$", "-test.fuzzworker", "-test.v", "arg"},
+		},
+		{
+			name: "after terminator",
+			args: []string{"-test.fuzzworker", "-test.fuzz=Fuzz", "--", "-test.fuzz=x"},
+			want: []string{"-test.fuzzworker", "-test.fuzz=^FuzzA$", "--", "-test.fuzz=x"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := workerFuzzArgs(slices.Clone(tc.args), "FuzzA")
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("workerFuzzArgs(%q) = %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
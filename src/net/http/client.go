@@ -103,6 +103,13 @@ type Client struct {
 	// RoundTripper implementations should use the Request's Context
 	// for cancellation instead of implementing CancelRequest.
 	Timeout time.Duration
+
+	// Retry specifies the policy for retrying requests which fail
+	// with a transient error. Each redirect the client follows is
+	// retried independently. Retries count against the Timeout.
+	//
+	// If Retry is nil, requests are not retried.
+	Retry *RetryPolicy
 }
 
 // DefaultClient is the default [Client] and is used by [Get], [Head], and [Post].
@@ -182,7 +189,11 @@ func (c *Client) send(req *Request, deadline time.Time) (resp *Response, didTime
 			req.AddCookie(cookie)
 		}
 	}
-	resp, didTimeout, err = send(req, c.transport(), deadline)
+	if c.Retry != nil {
+		resp, didTimeout, err = c.Retry.send(req, c.transport(), deadline)
+	} else {
+		resp, didTimeout, err = send(req, c.transport(), deadline)
+	}
 	if err != nil {
 		return nil, didTimeout, err
 	}
@@ -0,0 +1,452 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http/internal/ascii"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// ResponseCompression compresses response bodies using a content coding
+// negotiated with the client's Accept-Encoding header.
+//
+// The gzip and deflate codings are always available. Other codings,
+// such as zstd or br, may be added with [ResponseCompression.AddEncoding].
+// When the client accepts several codings equally, codings added with
+// AddEncoding are preferred, in the order they were added, followed by
+// gzip and then deflate.
+//
+// Responses are sent uncompressed when they are smaller than 1 KiB,
+// when the request is a HEAD request or has a Range header, when the
+// handler sets a Content-Encoding header or a Cache-Control header with
+// the no-transform directive, when the status code is not 200, 201, 202,
+// 203, or 404, or when the Content-Type is one that is usually already
+// compressed, such as most image, audio, and video types.
+// Otherwise, the response includes a "Vary: Accept-Encoding" header,
+// whether or not it is compressed.
+//
+// When a response is compressed, its Content-Length header is removed
+// and a strong ETag header is made weak.
+//
+// Calling Flush on the [ResponseWriter], directly or through a
+// [ResponseController], flushes any buffered compressed data to the client.
+//
+// The zero value of ResponseCompression is valid and supports
+// the gzip and deflate codings.
+type ResponseCompression struct {
+	mu        sync.RWMutex
+	encodings []compressionEncoding // added with AddEncoding
+}
+
+type compressionEncoding struct {
+	name      string
+	newWriter func(io.Writer) io.WriteCloser
+}
+
+// compressionMinSize is the smallest response body ResponseCompression compresses.
+const compressionMinSize = 1024
+
+// AddEncoding adds support for the named content coding, such as "zstd" or "br".
+// The newWriter function returns a writer that compresses data written to it
+// and writes the result to w. Closing the writer must write any remaining data,
+// but must not close w. If the writer has a Flush() error method, it is called
+// when the response is flushed.
+//
+// Adding a coding that has already been added replaces it.
+// Adding gzip or deflate replaces the built-in implementation.
+//
+// AddEncoding can be called concurrently with other methods
+// or request handling, and applies to future requests.
+func (c *ResponseCompression) AddEncoding(coding string, newWriter func(w io.Writer) io.WriteCloser) error {
+	if !httpguts.ValidHeaderFieldName(coding) {
+		return fmt.Errorf("invalid content coding %q", coding)
+	}
+	if newWriter == nil {
+		return errors.New("nil newWriter function")
+	}
+	coding, _ = ascii.ToLower(coding)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	encodings := make([]compressionEncoding, 0, len(c.encodings)+1)
+	for _, e := range c.encodings {
+		if e.name != coding {
+			encodings = append(encodings, e)
+		}
+	}
+	c.encodings = append(encodings, compressionEncoding{coding, newWriter})
+	return nil
+}
+
+var (
+	gzipWriterPool sync.Pool // of *gzip.Writer
+	zlibWriterPool sync.Pool // of *zlib.Writer
+)
+
+// builtinCompressionEncodings are the codings supported by every ResponseCompression.
+var builtinCompressionEncodings = []compressionEncoding{
+	{"gzip", func(w io.Writer) io.WriteCloser {
+		if zw, ok := gzipWriterPool.Get().(*gzip.Writer); ok {
+			zw.Reset(w)
+			return &pooledCompressor{zw, &gzipWriterPool}
+		}
+		return &pooledCompressor{gzip.NewWriter(w), &gzipWriterPool}
+	}},
+	{"deflate", func(w io.Writer) io.WriteCloser {
+		// The "deflate" coding is the zlib format (RFC 9110, Section 8.4.1.2).
+		if zw, ok := zlibWriterPool.Get().(*zlib.Writer); ok {
+			zw.Reset(w)
+			return &pooledCompressor{zw, &zlibWriterPool}
+		}
+		return &pooledCompressor{zlib.NewWriter(w), &zlibWriterPool}
+	}},
+}
+
+// A pooledCompressor is a compressing writer which returns itself
+// to a pool when closed.
+type pooledCompressor struct {
+	w interface {
+		io.WriteCloser
+		Flush() error
+	}
+	pool *sync.Pool
+}
+
+func (p *pooledCompressor) Write(b []byte) (int, error) { return p.w.Write(b) }
+func (p *pooledCompressor) Flush() error                { return p.w.Flush() }
+
+func (p *pooledCompressor) Close() error {
+	err := p.w.Close()
+	p.pool.Put(p.w)
+	p.w = nil
+	return err
+}
+
+// negotiate returns the preferred coding accepted by the Accept-Encoding
+// header values accept, or nil if none is acceptable.
+func (c *ResponseCompression) negotiate(accept []string) *compressionEncoding {
+	var (
+		qvalues  map[string]float64
+		wildcard = -1.0
+	)
+	for _, v := range accept {
+		foreachHeaderElement(v, func(elem string) {
+			name, params, _ := strings.Cut(elem, ";")
+			name, _ = ascii.ToLower(textproto.TrimString(name))
+			q := 1.0
+			for p := range strings.SplitSeq(params, ";") {
+				k, v, _ := strings.Cut(p, "=")
+				if textproto.TrimString(k) == "q" {
+					f, err := strconv.ParseFloat(textproto.TrimString(v), 64)
+					if err != nil {
+						f = 0
+					}
+					q = f
+				}
+			}
+			if name == "*" {
+				wildcard = q
+				return
+			}
+			if name == "x-gzip" {
+				name = "gzip"
+			}
+			if qvalues == nil {
+				qvalues = make(map[string]float64)
+			}
+			qvalues[name] = q
+		})
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var (
+		best  *compressionEncoding
+		bestQ float64
+	)
+	try := func(e *compressionEncoding) {
+		q, ok := qvalues[e.name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	for i := range c.encodings {
+		try(&c.encodings[i])
+	}
+	for i := range builtinCompressionEncodings {
+		e := &builtinCompressionEncodings[i]
+		if !c.hasEncodingLocked(e.name) {
+			try(e)
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	e := *best
+	return &e
+}
+
+func (c *ResponseCompression) hasEncodingLocked(name string) bool {
+	for _, e := range c.encodings {
+		if e.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns a handler that invokes h, compressing its responses
+// when possible.
+func (c *ResponseCompression) Handler(h Handler) Handler {
+	return HandlerFunc(func(w ResponseWriter, r *Request) {
+		if r.Method == "HEAD" || r.Header.Get("Range") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressionWriter{
+			rw:     w,
+			c:      c,
+			accept: r.Header["Accept-Encoding"],
+		}
+		// Close the encoder even if h panics,
+		// so that it is returned to its pool.
+		defer cw.closeEncoder()
+		h.ServeHTTP(cw, r)
+		cw.decide(true)
+	})
+}
+
+// A compressionWriter is the ResponseWriter used by ResponseCompression.
+//
+// It buffers the start of the response until it has enough data to decide
+// whether to compress it, the handler flushes, or the handler returns.
+type compressionWriter struct {
+	rw     ResponseWriter
+	c      *ResponseCompression
+	accept []string
+
+	status  int            // status code passed to WriteHeader
+	buf     []byte         // response data buffered before deciding
+	decided bool           // whether the response will be compressed has been decided
+	enc     io.WriteCloser // compressing writer, or nil if not compressing
+	err     error          // sticky write error
+}
+
+func (cw *compressionWriter) Header() Header {
+	return cw.rw.Header()
+}
+
+func (cw *compressionWriter) WriteHeader(code int) {
+	if cw.decided {
+		// Let the underlying ResponseWriter report superfluous calls.
+		cw.rw.WriteHeader(code)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	if code >= 100 && code <= 199 && code != StatusSwitchingProtocols {
+		cw.rw.WriteHeader(code)
+		return
+	}
+	checkWriteHeaderCode(code)
+	cw.status = code
+}
+
+func (cw *compressionWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < compressionMinSize {
+			return len(p), nil
+		}
+		if err := cw.decide(false); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	var (
+		n   int
+		err error
+	)
+	if cw.enc != nil {
+		n, err = cw.enc.Write(p)
+	} else {
+		n, err = cw.rw.Write(p)
+	}
+	if err != nil {
+		cw.err = err
+	}
+	return n, err
+}
+
+func (cw *compressionWriter) Flush() {
+	cw.FlushError()
+}
+
+func (cw *compressionWriter) FlushError() error {
+	if err := cw.decide(false); err != nil {
+		return err
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			cw.err = err
+			return err
+		}
+	}
+	return NewResponseController(cw.rw).Flush()
+}
+
+func (cw *compressionWriter) Unwrap() ResponseWriter {
+	return cw.rw
+}
+
+// closeEncoder closes the compressing writer, if there is one.
+func (cw *compressionWriter) closeEncoder() {
+	if cw.enc != nil {
+		cw.enc.Close()
+		cw.enc = nil
+	}
+}
+
+// decide decides whether to compress the response, sends the response
+// header, and writes any buffered data. The done parameter reports
+// whether the handler has returned, in which case the buffer holds
+// the complete response body.
+func (cw *compressionWriter) decide(done bool) error {
+	if cw.decided {
+		return cw.err
+	}
+	cw.decided = true
+	status := cw.status
+	if status == 0 {
+		status = StatusOK
+	}
+	if cw.compressible(status) {
+		h := cw.rw.Header()
+		if _, haveType := h["Content-Type"]; !haveType && len(cw.buf) > 0 {
+			// Sniff the type of the uncompressed data,
+			// as the server would have done.
+			h.Set("Content-Type", DetectContentType(cw.buf))
+		}
+		if !isCompressedContentType(h.Get("Content-Type")) {
+			addVary(h, "Accept-Encoding")
+			cw.enc = cw.newEncoder(done)
+		}
+	}
+	if cw.status != 0 {
+		cw.rw.WriteHeader(cw.status)
+	}
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := cw.Write(buf)
+	return err
+}
+
+// compressible reports whether a response with the given status
+// and the handler's header may be compressed.
+func (cw *compressionWriter) compressible(status int) bool {
+	switch status {
+	case StatusOK, StatusCreated, StatusAccepted, StatusNonAuthoritativeInfo, StatusNotFound:
+	default:
+		return false
+	}
+	h := cw.rw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	for _, v := range h["Cache-Control"] {
+		for d := range strings.SplitSeq(v, ",") {
+			if textproto.TrimString(d) == "no-transform" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// newEncoder returns the compressing writer for the response,
+// or nil if it should not be compressed.
+func (cw *compressionWriter) newEncoder(done bool) io.WriteCloser {
+	h := cw.rw.Header()
+	if done && len(cw.buf) < compressionMinSize {
+		return nil
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil && n < compressionMinSize {
+			return nil
+		}
+	}
+	enc := cw.c.negotiate(cw.accept)
+	if enc == nil {
+		return nil
+	}
+	h.Set("Content-Encoding", enc.name)
+	h.Del("Content-Length")
+	h.Del("Accept-Ranges")
+	if etag := h.Get("Etag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("Etag", "W/"+etag)
+	}
+	return enc.newWriter(writerOnly{cw.rw})
+}
+
+// addVary adds name to the Vary header in h, if it is not already present.
+func addVary(h Header, name string) {
+	for _, v := range h["Vary"] {
+		found := false
+		foreachHeaderElement(v, func(elem string) {
+			if elem == "*" || ascii.EqualFold(elem, name) {
+				found = true
+			}
+		})
+		if found {
+			return
+		}
+	}
+	h.Add("Vary", name)
+}
+
+// isCompressedContentType reports whether responses with the
+// media type ct are usually already compressed.
+func isCompressedContentType(ct string) bool {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	major, minor, _ := strings.Cut(mediaType, "/")
+	switch major {
+	case "image":
+		return minor != "svg+xml" && minor != "bmp" && minor != "x-icon" && minor != "vnd.microsoft.icon"
+	case "audio", "video":
+		return true
+	case "font":
+		return minor == "woff" || minor == "woff2"
+	case "application":
+		switch minor {
+		case "zip", "gzip", "x-gzip", "zstd", "x-bzip2", "x-xz", "x-7z-compressed",
+			"x-rar-compressed", "vnd.rar":
+			return true
+		}
+	}
+	return false
+}
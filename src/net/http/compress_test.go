@@ -0,0 +1,315 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var compressibleBody = strings.Repeat("compressible text ", 100)
+
+// nopCompressor is a content coding for tests which does not compress.
+type nopCompressor struct{ io.Writer }
+
+func (nopCompressor) Close() error { return nil }
+
+func serveCompressed(t *testing.T, c *http.ResponseCompression, req *http.Request, h http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	if c == nil {
+		c = new(http.ResponseCompression)
+	}
+	rec := httptest.NewRecorder()
+	c.Handler(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch ce := rec.Header().Get("Content-Encoding"); ce {
+	case "gzip":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case "deflate":
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case "", "test":
+	default:
+		t.Fatalf("unexpected Content-Encoding %q", ce)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	return string(b)
+}
+
+func TestResponseCompressionNegotiation(t *testing.T) {
+	for _, test := range []struct {
+		accept   string
+		custom   bool // add the "test" coding
+		wantCE   string
+		wantVary bool
+	}{
+		{"", false, "", true},
+		{"gzip", false, "gzip", true},
+		{"x-gzip", false, "gzip", true},
+		{"GZIP", false, "gzip", true},
+		{"deflate", false, "deflate", true},
+		{"deflate, gzip", false, "gzip", true},
+		{"gzip;q=0.5, deflate", false, "deflate", true},
+		{"gzip;q=0, deflate;q=0", false, "", true},
+		{"*", false, "gzip", true},
+		{"*;q=0.1, deflate;q=0.5", false, "deflate", true},
+		{"br, identity", false, "", true},
+		{"gzip, test", true, "test", true},
+		{"gzip, test;q=0.9", true, "gzip", true},
+		{"deflate", true, "deflate", true},
+	} {
+		c := new(http.ResponseCompression)
+		if test.custom {
+			if err := c.AddEncoding("test", func(w io.Writer) io.WriteCloser { return nopCompressor{w} }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept-Encoding", test.accept)
+		}
+		rec := serveCompressed(t, c, req, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, compressibleBody)
+		})
+		if got := rec.Header().Get("Content-Encoding"); got != test.wantCE {
+			t.Errorf("Accept-Encoding %q: Content-Encoding = %q, want %q", test.accept, got, test.wantCE)
+		}
+		if got := rec.Header().Get("Vary") == "Accept-Encoding"; got != test.wantVary {
+			t.Errorf("Accept-Encoding %q: Vary = %q", test.accept, rec.Header().Get("Vary"))
+		}
+		if got := decodeBody(t, rec); got != compressibleBody {
+			t.Errorf("Accept-Encoding %q: body mismatch", test.accept)
+		}
+	}
+}
+
+func TestResponseCompressionSkipped(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		method   string
+		reqh     []string
+		h        http.HandlerFunc
+		wantVary bool
+	}{{
+		name: "small body",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "small")
+		},
+		wantVary: true,
+	}, {
+		name: "small content length",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "10")
+			w.(http.Flusher).Flush()
+			io.WriteString(w, "0123456789")
+		},
+		wantVary: true,
+	}, {
+		name: "compressed content type",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, compressibleBody)
+		},
+	}, {
+		name: "content encoding set",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "identity")
+			io.WriteString(w, compressibleBody)
+		},
+	}, {
+		name: "no-transform",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "public, no-transform")
+			io.WriteString(w, compressibleBody)
+		},
+	}, {
+		name: "status",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, compressibleBody)
+		},
+	}, {
+		name: "range",
+		reqh: []string{"Range", "bytes=0-"},
+		h: func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, compressibleBody)
+		},
+	}, {
+		name:   "HEAD",
+		method: "HEAD",
+		h: func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, compressibleBody)
+		},
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			for i := 0; i < len(test.reqh); i += 2 {
+				req.Header.Set(test.reqh[i], test.reqh[i+1])
+			}
+			rec := serveCompressed(t, nil, req, test.h)
+			if ce := rec.Header().Get("Content-Encoding"); ce == "gzip" {
+				t.Errorf("response was compressed")
+			}
+			if got := rec.Header().Get("Vary") != ""; got != test.wantVary {
+				t.Errorf("Vary = %q, want set: %v", rec.Header().Get("Vary"), test.wantVary)
+			}
+		})
+	}
+}
+
+func TestResponseCompressionHeaders(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := serveCompressed(t, nil, req, func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Length", "1800")
+		h.Set("Etag", `"abc"`)
+		h.Set("Accept-Ranges", "bytes")
+		h.Set("Vary", "Cookie")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "<!DOCTYPE html>"+compressibleBody[:1800-15])
+	})
+	h := rec.Header()
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %v, want %v", rec.Code, http.StatusCreated)
+	}
+	for _, test := range []struct {
+		name, want string
+	}{
+		{"Content-Encoding", "gzip"},
+		{"Content-Length", ""},
+		{"Accept-Ranges", ""},
+		{"Etag", `W/"abc"`},
+		{"Content-Type", "text/html; charset=utf-8"},
+	} {
+		if got := h.Get(test.name); got != test.want {
+			t.Errorf("%v = %q, want %q", test.name, got, test.want)
+		}
+	}
+	if got, want := h.Values("Vary"), []string{"Cookie", "Accept-Encoding"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Vary = %q, want %q", got, want)
+	}
+	if got := decodeBody(t, rec); len(got) != 1800 {
+		t.Errorf("decoded body has length %v, want 1800", len(got))
+	}
+}
+
+// closeRecorder is a content coding for tests which records
+// whether it was closed.
+type closeRecorder struct {
+	io.Writer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestResponseCompressionPanic(t *testing.T) {
+	var enc *closeRecorder
+	c := new(http.ResponseCompression)
+	c.AddEncoding("test", func(w io.Writer) io.WriteCloser {
+		enc = &closeRecorder{Writer: w}
+		return enc
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "test")
+	func() {
+		defer func() {
+			if e := recover(); e != http.ErrAbortHandler {
+				t.Errorf("recovered %v, want ErrAbortHandler", e)
+			}
+		}()
+		serveCompressed(t, c, req, func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, compressibleBody)
+			panic(http.ErrAbortHandler)
+		})
+	}()
+	if enc == nil {
+		t.Fatal("encoder was not created")
+	}
+	if !enc.closed {
+		t.Errorf("encoder was not closed after the handler panicked")
+	}
+}
+
+func TestResponseCompressionFlush(t *testing.T) {
+	run(t, testResponseCompressionFlush, []testMode{http1Mode, https1Mode, http2Mode})
+}
+func testResponseCompressionFlush(t *testing.T, mode testMode) {
+	const chunks = 3
+	next := make(chan struct{})
+	cst := newClientServerTest(t, mode, new(http.ResponseCompression).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		rc := http.NewResponseController(w)
+		for i := range chunks {
+			if i > 0 {
+				<-next
+			}
+			io.WriteString(w, "data: event\n\n")
+			if err := rc.Flush(); err != nil {
+				t.Errorf("Flush: %v", err)
+				return
+			}
+		}
+	})))
+	req, _ := http.NewRequest("GET", cst.ts.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := cst.c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(zr)
+	for i := range chunks {
+		// Each event is readable before the handler writes the next.
+		for range 2 {
+			if _, err := br.ReadString('\n'); err != nil {
+				t.Fatalf("reading event %v: %v", i, err)
+			}
+		}
+		if i < chunks-1 {
+			next <- struct{}{}
+		}
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		t.Errorf("after last event: %v, want EOF", err)
+	}
+}
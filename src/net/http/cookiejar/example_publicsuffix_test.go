@@ -0,0 +1,28 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cookiejar_test
+
+import (
+	"fmt"
+	"log"
+	"net/http/cookiejar"
+)
+
+func ExampleDefaultPublicSuffixList() {
+	// A jar using the bundled list does not accept cookies
+	// for a domain such as "co.uk" or "github.io".
+	_, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: cookiejar.DefaultPublicSuffixList})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, domain := range []string{"www.example.com", "www.example.co.uk", "user.github.io"} {
+		fmt.Println(domain, cookiejar.DefaultPublicSuffixList.PublicSuffix(domain))
+	}
+	// Output:
+	// www.example.com com
+	// www.example.co.uk co.uk
+	// user.github.io github.io
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+
+// This program generates table.go, the public suffix list bundled with
+// the cookiejar package, from https://publicsuffix.org/list/public_suffix_list.dat.
+//
+// Usage:
+//
+//	go run gen.go [-version YYYYMMDD] [public_suffix_list.dat]
+//
+// If no file is given, the list is downloaded from publicsuffix.org.
+// The version defaults to the current date.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const listURL = "https://publicsuffix.org/list/public_suffix_list.dat"
+
+var (
+	output  = flag.String("output", "table.go", "output file")
+	version = flag.String("version", time.Now().UTC().Format("20060102"), "version of the list, such as the date it was retrieved")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gen: ")
+	flag.Parse()
+	var r io.Reader
+	switch flag.NArg() {
+	case 0:
+		resp, err := http.Get(listURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Fatalf("fetching %v: %v", listURL, resp.Status)
+		}
+		r = resp.Body
+	case 1:
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	default:
+		log.Fatal("usage: go run gen.go [-version YYYYMMDD] [public_suffix_list.dat]")
+	}
+
+	// Keep only the rules; the list is parsed at run time by
+	// parsePublicSuffixList, which also ignores comments.
+	var rules []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		rule := sc.Text()
+		if i := strings.IndexAny(rule, " \t\r"); i >= 0 {
+			rule = rule[:i]
+		}
+		if rule == "" || strings.HasPrefix(rule, "//") {
+			continue
+		}
+		if strings.Contains(rule, "`") {
+			log.Fatalf("rule %q cannot be stored in a raw string literal", rule)
+		}
+		rules = append(rules, rule)
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if len(rules) == 0 {
+		log.Fatal("no rules found")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `// Code generated by gen.go; DO NOT EDIT.
+
+// The public suffix list below is from https://publicsuffix.org/list/.
+// It is subject to the terms of the Mozilla Public License, v. 2.0.
+// A copy of the MPL is available at https://mozilla.org/MPL/2.0/.
+
+package cookiejar
+
+const publicSuffixListVersion = %q
+
+const publicSuffixListData = `+"`", "publicsuffix.org's public_suffix_list.dat, version "+*version)
+	for _, r := range rules {
+		buf.WriteString(r)
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("`\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, src, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
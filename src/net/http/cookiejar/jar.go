@@ -31,8 +31,9 @@ import (
 // testing but it is not secure: it means that the HTTP server for foo.com can
 // set a cookie for bar.com.
 //
-// A public suffix list implementation is in the package
-// golang.org/x/net/publicsuffix.
+// [DefaultPublicSuffixList] is an implementation bundled with this package,
+// which a [Jar] uses unless [Options] sets another.
+// Another is in the package golang.org/x/net/publicsuffix.
 type PublicSuffixList interface {
 	// PublicSuffix returns the public suffix of domain.
 	//
@@ -50,11 +51,10 @@ type PublicSuffixList interface {
 // Options are the options for creating a new Jar.
 type Options struct {
 	// PublicSuffixList is the public suffix list that determines whether
-	// an HTTP server can set a cookie for a domain.
+	// an HTTP server can set a cookie for a domain, so that, for example,
+	// the HTTP server for foo.co.uk cannot set a cookie for bar.co.uk.
 	//
-	// A nil value is valid and may be useful for testing but it is not
-	// secure: it means that the HTTP server for foo.co.uk can set a cookie
-	// for bar.co.uk.
+	// If PublicSuffixList is nil, the jar uses [DefaultPublicSuffixList].
 	PublicSuffixList PublicSuffixList
 }
 
@@ -78,9 +78,10 @@ type Jar struct {
 // Options.
 func New(o *Options) (*Jar, error) {
 	jar := &Jar{
+		psList:  DefaultPublicSuffixList,
 		entries: make(map[string]map[string]entry),
 	}
-	if o != nil {
+	if o != nil && o.PublicSuffixList != nil {
 		jar.psList = o.PublicSuffixList
 	}
 	return jar, nil
@@ -1361,6 +1361,28 @@ func TestDomainHandling(t *testing.T) {
 	}
 }
 
+// TestNewDefaultPublicSuffixList checks that a jar created without a public
+// suffix list uses DefaultPublicSuffixList, so that a server cannot set a
+// cookie for a public suffix such as co.uk.
+func TestNewDefaultPublicSuffixList(t *testing.T) {
+	for _, o := range []*Options{nil, {}} {
+		jar, err := New(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jar.SetCookies(mustParseURL("http://www.example.co.uk"), []*http.Cookie{
+			{Name: "super", Value: "1", Domain: "co.uk"},
+			{Name: "ok", Value: "2", Domain: "example.co.uk"},
+		})
+		if got := jar.Cookies(mustParseURL("http://www.other.co.uk")); len(got) != 0 {
+			t.Errorf("New(%v): cookie for co.uk accepted, sent to www.other.co.uk: %v", o, got)
+		}
+		if got := jar.Cookies(mustParseURL("http://www.example.co.uk")); len(got) != 1 || got[0].Name != "ok" {
+			t.Errorf("New(%v): cookies for www.example.co.uk = %v, want only ok", o, got)
+		}
+	}
+}
+
 func TestIssue19384(t *testing.T) {
 	cookies := []*http.Cookie{{Name: "name", Value: "value"}}
 	for _, host := range []string{"", ".", "..", "..."} {
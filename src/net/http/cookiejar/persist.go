@@ -0,0 +1,301 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cookiejar
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/internal/ascii"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteJSON writes the persistent cookies in the jar to w as a JSON array.
+// Session cookies and expired cookies are not written.
+// The cookies can be restored with [Jar.ReadJSON].
+//
+// Each cookie is a JSON object with the string fields "name", "value",
+// "domain", "path" and "sameSite", the boolean fields "quoted", "hostOnly",
+// "secure" and "httpOnly", and the RFC 3339 times "expires", "creation"
+// and "lastAccess". Fields with their zero value may be omitted.
+func (j *Jar) WriteJSON(w io.Writer) error {
+	return j.writeJSON(w, time.Now())
+}
+
+func (j *Jar) writeJSON(w io.Writer, now time.Time) error {
+	cookies := []jsonCookie{}
+	for _, e := range j.persistentEntries(now) {
+		cookies = append(cookies, jsonCookie{
+			Name:       e.Name,
+			Value:      e.Value,
+			Quoted:     e.Quoted,
+			Domain:     e.Domain,
+			HostOnly:   e.HostOnly,
+			Path:       e.Path,
+			Secure:     e.Secure,
+			HttpOnly:   e.HttpOnly,
+			SameSite:   e.SameSite,
+			Expires:    e.Expires,
+			Creation:   e.Creation,
+			LastAccess: e.LastAccess,
+		})
+	}
+	return json.NewEncoder(w).Encode(cookies)
+}
+
+// ReadJSON adds the cookies in r, in the format written by [Jar.WriteJSON],
+// to the jar. A cookie without an expiry time is added as a session cookie.
+// A cookie replaces any cookie in the jar with the same name, domain and
+// path. Expired cookies are ignored, as are cookies for a domain which the
+// jar's [PublicSuffixList] reports is a public suffix.
+//
+// If r is malformed, ReadJSON returns an error and does not modify the jar.
+func (j *Jar) ReadJSON(r io.Reader) error {
+	return j.readJSON(r, time.Now())
+}
+
+func (j *Jar) readJSON(r io.Reader, now time.Time) error {
+	var cookies []jsonCookie
+	if err := json.NewDecoder(r).Decode(&cookies); err != nil {
+		return fmt.Errorf("cookiejar: reading JSON: %w", err)
+	}
+	entries := make([]entry, 0, len(cookies))
+	for _, c := range cookies {
+		entries = append(entries, entry{
+			Name:       c.Name,
+			Value:      c.Value,
+			Quoted:     c.Quoted,
+			Domain:     c.Domain,
+			HostOnly:   c.HostOnly,
+			Path:       c.Path,
+			Secure:     c.Secure,
+			HttpOnly:   c.HttpOnly,
+			SameSite:   c.SameSite,
+			Persistent: !c.Expires.IsZero(),
+			Expires:    c.Expires,
+			Creation:   c.Creation,
+			LastAccess: c.LastAccess,
+		})
+	}
+	return j.addEntries(entries, now)
+}
+
+// jsonCookie is a cookie in the JSON format of WriteJSON and ReadJSON.
+// It is kept separate from entry so that the format does not change
+// with the jar's internal representation of cookies.
+type jsonCookie struct {
+	Name       string    `json:"name"`
+	Value      string    `json:"value"`
+	Quoted     bool      `json:"quoted,omitempty"`
+	Domain     string    `json:"domain"`
+	HostOnly   bool      `json:"hostOnly,omitempty"`
+	Path       string    `json:"path"`
+	Secure     bool      `json:"secure,omitempty"`
+	HttpOnly   bool      `json:"httpOnly,omitempty"`
+	SameSite   string    `json:"sameSite,omitempty"`
+	Expires    time.Time `json:"expires,omitzero"`
+	Creation   time.Time `json:"creation,omitzero"`
+	LastAccess time.Time `json:"lastAccess,omitzero"`
+}
+
+// netscapeHeader is the first line of a Netscape cookies.txt file.
+const netscapeHeader = "# Netscape HTTP Cookie File"
+
+// httpOnlyPrefix marks a line in a cookies.txt file as describing an
+// HttpOnly cookie. This is the convention used by curl.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// WriteNetscape writes the persistent cookies in the jar to w in the
+// Netscape cookies.txt format understood by curl, wget and other tools.
+// Session cookies and expired cookies are not written.
+// The cookies can be restored with [Jar.ReadNetscape].
+//
+// The format has no room for the SameSite attribute or the cookie's
+// creation time; these are lost.
+func (j *Jar) WriteNetscape(w io.Writer) error {
+	return j.writeNetscape(w, time.Now())
+}
+
+func (j *Jar) writeNetscape(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(netscapeHeader + "\n\n")
+	for _, e := range j.persistentEntries(now) {
+		domain, subdomains := e.Domain, "FALSE"
+		if !e.HostOnly {
+			domain, subdomains = "."+e.Domain, "TRUE"
+		}
+		if e.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		secure := "FALSE"
+		if e.Secure {
+			secure = "TRUE"
+		}
+		value := e.Value
+		if e.Quoted {
+			value = `"` + value + `"`
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, subdomains, e.Path, secure, e.Expires.Unix(), e.Name, value)
+	}
+	return bw.Flush()
+}
+
+// ReadNetscape adds the cookies in r, in the Netscape cookies.txt format,
+// to the jar. A cookie with an expiry time of zero is added as a session
+// cookie. Otherwise ReadNetscape behaves like [Jar.ReadJSON].
+func (j *Jar) ReadNetscape(r io.Reader) error {
+	return j.readNetscape(r, time.Now())
+}
+
+func (j *Jar) readNetscape(r io.Reader, now time.Time) error {
+	var entries []entry
+	sc := bufio.NewScanner(r)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		httpOnly := false
+		if rest, ok := strings.CutPrefix(line, httpOnlyPrefix); ok {
+			line, httpOnly = rest, true
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		e, ok := parseNetscapeLine(line)
+		if !ok {
+			return fmt.Errorf("cookiejar: line %d: malformed cookie", lineNum)
+		}
+		e.HttpOnly = httpOnly
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return j.addEntries(entries, now)
+}
+
+// parseNetscapeLine parses a cookie in the cookies.txt format:
+//
+//	domain	include-subdomains	path	secure	expires	name	value
+func parseNetscapeLine(line string) (e entry, ok bool) {
+	f := strings.Split(line, "\t")
+	if len(f) != 7 {
+		return e, false
+	}
+	parseBool := func(s string) (bool, bool) {
+		switch s {
+		case "TRUE":
+			return true, true
+		case "FALSE":
+			return false, true
+		}
+		return false, false
+	}
+	subdomains, ok1 := parseBool(f[1])
+	secure, ok2 := parseBool(f[3])
+	expires, err := strconv.ParseInt(f[4], 10, 64)
+	if !ok1 || !ok2 || err != nil {
+		return e, false
+	}
+	e.Domain = strings.TrimPrefix(f[0], ".")
+	e.HostOnly = !subdomains
+	e.Path = f[2]
+	e.Secure = secure
+	if expires != 0 {
+		e.Persistent = true
+		e.Expires = time.Unix(expires, 0)
+	}
+	e.Name = f[5]
+	e.Value = f[6]
+	if len(e.Value) >= 2 && e.Value[0] == '"' && e.Value[len(e.Value)-1] == '"' {
+		e.Value = e.Value[1 : len(e.Value)-1]
+		e.Quoted = true
+	}
+	return e, true
+}
+
+// persistentEntries returns the unexpired persistent cookies in the jar,
+// oldest first.
+func (j *Jar) persistentEntries(now time.Time) []entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var entries []entry
+	for _, submap := range j.entries {
+		for _, e := range submap {
+			if e.Persistent && e.Expires.After(now) {
+				entries = append(entries, e)
+			}
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		if r := a.Creation.Compare(b.Creation); r != 0 {
+			return r
+		}
+		return cmp.Compare(a.seqNum, b.seqNum)
+	})
+	return entries
+}
+
+// addEntries adds entries read by ReadJSON or ReadNetscape to the jar.
+// It returns an error without modifying the jar if any entry is invalid.
+func (j *Jar) addEntries(entries []entry, now time.Time) error {
+	for i := range entries {
+		e := &entries[i]
+		domain, isASCII := ascii.ToLower(e.Domain)
+		if !isASCII || domain == "" || domain[0] == '.' || domain[len(domain)-1] == '.' {
+			return fmt.Errorf("cookiejar: invalid cookie domain %q", e.Domain)
+		}
+		e.Domain = domain
+		if e.Path == "" || e.Path[0] != '/' {
+			return fmt.Errorf("cookiejar: invalid cookie path %q", e.Path)
+		}
+		if e.Name == "" {
+			return fmt.Errorf("cookiejar: cookie with empty name for domain %q", e.Domain)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, e := range entries {
+		if !e.Persistent {
+			e.Expires = endOfTime
+		} else if !e.Expires.After(now) {
+			continue
+		}
+		if !e.HostOnly && !isIP(e.Domain) {
+			// See domainAndType.
+			if ps := j.psList.PublicSuffix(e.Domain); ps != "" && !hasDotSuffix(e.Domain, ps) {
+				continue
+			}
+		}
+		if e.Creation.IsZero() {
+			e.Creation = now
+		}
+		if e.LastAccess.IsZero() {
+			e.LastAccess = now
+		}
+		key := jarKey(e.Domain, j.psList)
+		submap := j.entries[key]
+		if submap == nil {
+			submap = make(map[string]entry)
+			j.entries[key] = submap
+		}
+		id := e.id()
+		if old, ok := submap[id]; ok {
+			e.seqNum = old.seqNum
+		} else {
+			e.seqNum = j.nextSeqNum
+			j.nextSeqNum++
+		}
+		submap[id] = e
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cookiejar
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// persistCookies are set from http://www.host.test by the tests below.
+var persistCookies = []string{
+	"session=1",
+	"host=2; max-age=3600",
+	"domain=3; domain=host.test; path=/foo; max-age=3600; secure; httponly",
+	`quoted="4 5"; max-age=3600; samesite=strict`,
+	"expired=6; max-age=1",
+}
+
+func newPersistTestJar(t *testing.T) *Jar {
+	jar := newTestJar()
+	var cookies []*http.Cookie
+	for _, cs := range persistCookies {
+		c, err := http.ParseSetCookie(cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cookies = append(cookies, c)
+	}
+	jar.setCookies(mustParseURL("http://www.host.test"), cookies, tNow)
+	return jar
+}
+
+// jarContent returns the cookies in jar, including session cookies,
+// in a form suitable for comparison.
+func jarContent(jar *Jar) []entry {
+	var entries []entry
+	for _, submap := range jar.entries {
+		for _, e := range submap {
+			e.seqNum = 0
+			entries = append(entries, e)
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.id(), b.id())
+	})
+	return entries
+}
+
+func TestPersistJSON(t *testing.T) {
+	jar := newPersistTestJar(t)
+	now := tNow.Add(2 * time.Second)
+	var buf bytes.Buffer
+	if err := jar.writeJSON(&buf, now); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); strings.Contains(s, "session") || strings.Contains(s, "expired") {
+		t.Errorf("JSON contains session or expired cookies:\n%s", s)
+	}
+
+	for _, key := range []string{`"name":`, `"domain":`, `"hostOnly":`, `"sameSite":`, `"expires":`} {
+		if !strings.Contains(buf.String(), key) {
+			t.Errorf("JSON does not contain %s:\n%s", key, buf.String())
+		}
+	}
+
+	jar2 := newTestJar()
+	if err := jar2.readJSON(&buf, now); err != nil {
+		t.Fatal(err)
+	}
+	want := jarContent(jar)
+	want = slices.DeleteFunc(want, func(e entry) bool {
+		return !e.Persistent || !e.Expires.After(now)
+	})
+	got := jarContent(jar2)
+	if len(got) != 3 || len(got) != len(want) {
+		t.Fatalf("restored %v cookies, want 3", len(got))
+	}
+	for i := range got {
+		if !got[i].Expires.Equal(want[i].Expires) || !got[i].Creation.Equal(want[i].Creation) {
+			t.Errorf("restored cookie %v has wrong times:\ngot  %+v\nwant %+v", got[i].id(), got[i], want[i])
+		}
+		got[i].Expires, got[i].Creation, got[i].LastAccess = want[i].Expires, want[i].Creation, want[i].LastAccess
+		if got[i] != want[i] {
+			t.Errorf("restored cookie:\ngot  %+v\nwant %+v", got[i], want[i])
+		}
+	}
+
+	cookies := jar2.cookies(mustParseURL("https://www.host.test/foo"), now)
+	var s []string
+	for _, c := range cookies {
+		s = append(s, c.String())
+	}
+	if got, want := strings.Join(s, " "), `domain=3 host=2 quoted="4 5"`; got != want {
+		t.Errorf("Cookies after ReadJSON = %q, want %q", got, want)
+	}
+
+	// A cookie without an expiry time is a session cookie.
+	jar3 := newTestJar()
+	in := `[{"name":"a","value":"1","domain":"host.test","hostOnly":true,"path":"/"}]`
+	if err := jar3.readJSON(strings.NewReader(in), now); err != nil {
+		t.Fatal(err)
+	}
+	if got := jarContent(jar3); len(got) != 1 || got[0].Persistent || !got[0].Expires.Equal(endOfTime) {
+		t.Errorf("ReadJSON(%q) = %+v, want one session cookie", in, got)
+	}
+}
+
+func TestPersistNetscape(t *testing.T) {
+	jar := newPersistTestJar(t)
+	now := tNow.Add(2 * time.Second)
+	var buf bytes.Buffer
+	if err := jar.writeNetscape(&buf, now); err != nil {
+		t.Fatal(err)
+	}
+	exp := tNow.Add(time.Hour).Unix()
+	want := netscapeHeader + "\n\n" + strings.ReplaceAll(
+		"www.host.test\tFALSE\t/\tFALSE\tEXP\thost\t2\n"+
+			"#HttpOnly_.host.test\tTRUE\t/foo\tTRUE\tEXP\tdomain\t3\n"+
+			"www.host.test\tFALSE\t/\tFALSE\tEXP\tquoted\t\"4 5\"\n",
+		"EXP", strconv.FormatInt(exp, 10))
+	if got := buf.String(); got != want {
+		t.Errorf("WriteNetscape:\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	jar2 := newTestJar()
+	in := buf.String() + "# A comment.\r\n" +
+		"other.test\tFALSE\t/\tFALSE\t0\tsession\t7\r\n"
+	if err := jar2.readNetscape(strings.NewReader(in), now); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		url, want string
+	}{
+		{"https://www.host.test/foo", `domain=3 host=2 quoted="4 5"`},
+		{"http://www.host.test/foo", `host=2 quoted="4 5"`},
+		{"https://sub.host.test/foo", "domain=3"},
+		{"http://other.test", "session=7"},
+	} {
+		var s []string
+		for _, c := range jar2.cookies(mustParseURL(test.url), now) {
+			s = append(s, c.String())
+		}
+		if got := strings.Join(s, " "); got != test.want {
+			t.Errorf("Cookies(%q) after ReadNetscape = %q, want %q", test.url, got, test.want)
+		}
+	}
+	for _, e := range jarContent(jar2) {
+		if e.Name == "domain" && !e.HttpOnly {
+			t.Errorf("cookie %q is not HttpOnly", e.Name)
+		}
+		if e.Name == "session" && e.Persistent {
+			t.Errorf("cookie %q is persistent", e.Name)
+		}
+	}
+}
+
+func TestPersistReadErrors(t *testing.T) {
+	for _, in := range []string{
+		"host.test\tFALSE\t/\tFALSE\t0\tname\n",
+		"host.test\tMAYBE\t/\tFALSE\t0\tname\tvalue\n",
+		"host.test\tFALSE\t/\tFALSE\tnever\tname\tvalue\n",
+		"host.test\tFALSE\tpath\tFALSE\t0\tname\tvalue\n",
+		"host.test.\tFALSE\t/\tFALSE\t0\tname\tvalue\n",
+		"héllo.test\tFALSE\t/\tFALSE\t0\tname\tvalue\n",
+		"host.test\tFALSE\t/\tFALSE\t0\t\tvalue\n",
+	} {
+		jar := newTestJar()
+		in = "good.test\tFALSE\t/\tFALSE\t0\tname\tvalue\n" + in
+		if err := jar.readNetscape(strings.NewReader(in), tNow); err == nil {
+			t.Errorf("ReadNetscape(%q) succeeded, want error", in)
+		}
+		if len(jar.entries) != 0 {
+			t.Errorf("ReadNetscape(%q) modified the jar", in)
+		}
+	}
+
+	jar := newTestJar()
+	if err := jar.readJSON(strings.NewReader(`[{"name":"a"`), tNow); err == nil {
+		t.Errorf("ReadJSON of truncated input succeeded, want error")
+	}
+}
+
+// TestPersistPublicSuffix checks that cookies read from a file for a
+// public suffix are ignored.
+func TestPersistPublicSuffix(t *testing.T) {
+	jar := newTestJar()
+	in := ".co.uk\tTRUE\t/\tFALSE\t0\tsuper\tcookie\n" +
+		"co.uk\tFALSE\t/\tFALSE\t0\thost\tcookie\n"
+	if err := jar.readNetscape(strings.NewReader(in), tNow); err != nil {
+		t.Fatal(err)
+	}
+	got := jarContent(jar)
+	if len(got) != 1 || got[0].Name != "host" {
+		t.Errorf("jar contains %+v, want only the host cookie", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cookiejar
+
+//go:generate go run gen.go
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http/internal/ascii"
+	"strings"
+	"sync"
+)
+
+// DefaultPublicSuffixList is a [PublicSuffixList] built from a copy of the
+// public suffix list maintained at https://publicsuffix.org, bundled with
+// this package. It includes both the ICANN and the private sections of
+// the list.
+//
+// The bundled copy is updated from time to time. Programs which need a
+// more recent list can load one with [ParsePublicSuffixList].
+var DefaultPublicSuffixList PublicSuffixList = bundledSuffixList{}
+
+type bundledSuffixList struct{}
+
+var bundledSuffixes = sync.OnceValue(func() *suffixList {
+	l, err := parsePublicSuffixList(strings.NewReader(publicSuffixListData), publicSuffixListVersion)
+	if err != nil {
+		panic("cookiejar: bundled public suffix list: " + err.Error())
+	}
+	return l
+})
+
+func (bundledSuffixList) PublicSuffix(domain string) string {
+	return bundledSuffixes().PublicSuffix(domain)
+}
+
+func (bundledSuffixList) String() string {
+	return publicSuffixListVersion
+}
+
+// ParsePublicSuffixList parses a public suffix list in the format of
+// https://publicsuffix.org/list/public_suffix_list.dat.
+// Internationalized rules are converted to Punycode.
+//
+// The source describes the origin of the list, such as its URL and the
+// time it was retrieved, and is returned by the String method of the
+// result.
+func ParsePublicSuffixList(r io.Reader, source string) (PublicSuffixList, error) {
+	l, err := parsePublicSuffixList(r, source)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// A suffixList is a parsed public suffix list.
+type suffixList struct {
+	source string
+
+	// rules maps a domain to the kinds of rule which mention it.
+	rules map[string]ruleKind
+}
+
+type ruleKind uint8
+
+const (
+	ruleNormal    ruleKind = 1 << iota // "example.com": example.com is a public suffix
+	ruleWildcard                       // "*.example.com": every child of example.com is a public suffix
+	ruleException                      // "!www.example.com": www.example.com is not a public suffix
+)
+
+func parsePublicSuffixList(r io.Reader, source string) (*suffixList, error) {
+	l := &suffixList{
+		source: source,
+		rules:  make(map[string]ruleKind),
+	}
+	sc := bufio.NewScanner(r)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		// Each line is only read up to the first whitespace.
+		line := sc.Text()
+		if i := strings.IndexAny(line, " \t\r"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		rule := line
+		kind := ruleNormal
+		if r, ok := strings.CutPrefix(rule, "!"); ok {
+			rule, kind = r, ruleException
+		} else if r, ok := strings.CutPrefix(rule, "*."); ok {
+			rule, kind = r, ruleWildcard
+		}
+		domain, err := toASCII(rule)
+		if err != nil || !validSuffixRule(domain) {
+			return nil, fmt.Errorf("cookiejar: public suffix list line %d: invalid rule %q", lineNum, line)
+		}
+		domain, _ = ascii.ToLower(domain)
+		l.rules[domain] |= kind
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// validSuffixRule reports whether domain, taken from a rule with any
+// "!" or "*." prefix removed, is a valid domain name.
+func validSuffixRule(domain string) bool {
+	for label := range strings.SplitSeq(domain, ".") {
+		if label == "" || strings.Contains(label, "*") {
+			return false
+		}
+	}
+	return true
+}
+
+// PublicSuffix returns the public suffix of domain, which must be in
+// lower case and contain only ASCII characters.
+//
+// It implements the algorithm described at https://publicsuffix.org/list/:
+// the longest matching rule prevails, exception rules take priority,
+// and a domain which matches no rule has its last label as public suffix.
+func (l *suffixList) PublicSuffix(domain string) string {
+	// The implicit "*" rule.
+	suffix := domain[strings.LastIndexByte(domain, '.')+1:]
+	parent := suffix
+	wildcard := l.rules[parent]&ruleWildcard != 0
+	for len(parent) < len(domain) {
+		i := strings.LastIndexByte(domain[:len(domain)-len(parent)-1], '.')
+		d := domain[i+1:]
+		kind := l.rules[d]
+		if kind&ruleException != 0 {
+			return parent
+		}
+		if kind&ruleNormal != 0 || wildcard {
+			suffix = d
+		}
+		parent = d
+		wildcard = kind&ruleWildcard != 0
+	}
+	return suffix
+}
+
+func (l *suffixList) String() string {
+	return l.source
+}
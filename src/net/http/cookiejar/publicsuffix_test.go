@@ -0,0 +1,139 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cookiejar
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test cases from https://github.com/publicsuffix/list/blob/main/tests/test_psl.txt,
+// given as domain and public suffix.
+var defaultPublicSuffixTests = map[string]string{
+	"com":                   "com",
+	"example.com":           "com",
+	"www.example.com":       "com",
+	"uk.com":                "uk.com",
+	"example.uk.com":        "uk.com",
+	"c.com.ac":              "com.ac",
+	"example":               "example",
+	"b.example.example":     "example",
+	"mm":                    "mm",
+	"c.mm":                  "c.mm",
+	"b.c.mm":                "c.mm",
+	"jp":                    "jp",
+	"test.jp":               "jp",
+	"ac.jp":                 "ac.jp",
+	"www.test.ac.jp":        "ac.jp",
+	"kyoto.jp":              "kyoto.jp",
+	"ide.kyoto.jp":          "ide.kyoto.jp",
+	"b.ide.kyoto.jp":        "ide.kyoto.jp",
+	"c.kobe.jp":             "c.kobe.jp",
+	"b.c.kobe.jp":           "c.kobe.jp",
+	"city.kobe.jp":          "kobe.jp",
+	"www.city.kobe.jp":      "kobe.jp",
+	"ck":                    "ck",
+	"test.ck":               "test.ck",
+	"b.test.ck":             "test.ck",
+	"www.ck":                "ck",
+	"www.www.ck":            "ck",
+	"us":                    "us",
+	"test.us":               "us",
+	"ak.us":                 "ak.us",
+	"www.test.ak.us":        "ak.us",
+	"k12.ak.us":             "k12.ak.us",
+	"www.test.k12.ak.us":    "k12.ak.us",
+	"www.example.co.uk":     "co.uk",
+	"foo.blogspot.com":      "blogspot.com",
+	"xn--85x722f.com.cn":    "com.cn",
+	"xn--55qx5d.cn":         "xn--55qx5d.cn",
+	"www.xn--55qx5d.cn":     "xn--55qx5d.cn",
+	"shishi.xn--fiqs8s":     "xn--fiqs8s",
+	"www.shishi.xn--fiqs8s": "xn--fiqs8s",
+}
+
+func TestDefaultPublicSuffixList(t *testing.T) {
+	for domain, want := range defaultPublicSuffixTests {
+		if got := DefaultPublicSuffixList.PublicSuffix(domain); got != want {
+			t.Errorf("PublicSuffix(%q) = %q, want %q", domain, got, want)
+		}
+	}
+	if got := DefaultPublicSuffixList.String(); !strings.HasPrefix(got, "publicsuffix.org") {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParsePublicSuffixList(t *testing.T) {
+	const list = `// A comment.
+// ===BEGIN ICANN DOMAINS===
+
+test
+Co.Test ignored text
+*.wild.test
+!www.wild.test
+*.deep.test
+bücher.test
+`
+	psl, err := ParsePublicSuffixList(strings.NewReader(list), "test list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := psl.String(); got != "test list" {
+		t.Errorf("String() = %q, want %q", got, "test list")
+	}
+	for domain, want := range map[string]string{
+		"test":                       "test",
+		"a.test":                     "test",
+		"a.co.test":                  "co.test",
+		"wild.test":                  "test",
+		"a.wild.test":                "a.wild.test",
+		"b.a.wild.test":              "a.wild.test",
+		"www.wild.test":              "wild.test",
+		"a.www.wild.test":            "wild.test",
+		"a.b.deep.test":              "b.deep.test",
+		"a.xn--bcher-kva.test":       "xn--bcher-kva.test",
+		"a.other":                    "other",
+		"nodots":                     "nodots",
+		"a.b.c.xn--bcher-kva.test.x": "x",
+	} {
+		if got := psl.PublicSuffix(domain); got != want {
+			t.Errorf("PublicSuffix(%q) = %q, want %q", domain, got, want)
+		}
+	}
+
+	for _, bad := range []string{
+		"a..test",
+		".test",
+		"a.*.test",
+		"*",
+	} {
+		if _, err := ParsePublicSuffixList(strings.NewReader(bad), ""); err == nil {
+			t.Errorf("ParsePublicSuffixList(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+// TestJarDefaultPublicSuffixList checks that a Jar using the bundled list
+// rejects cookies for public suffixes.
+func TestJarDefaultPublicSuffixList(t *testing.T) {
+	jar, err := New(&Options{PublicSuffixList: DefaultPublicSuffixList})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jarTest{
+		"Supercookies are rejected.",
+		"http://www.foo.github.io",
+		[]string{
+			"a=1; domain=github.io",
+			"b=2; domain=foo.github.io",
+			"c=3; domain=co.uk",
+		},
+		"b=2",
+		[]query{
+			{"http://bar.github.io", ""},
+			{"http://foo.github.io", "b=2"},
+		},
+	}.run(t, jar)
+}
@@ -164,7 +164,7 @@ func (t *Transport) IdleConnStrsForTesting_h2() []string {
 func (t *Transport) IdleConnCountForTesting(scheme, addr string) int {
 	t.idleMu.Lock()
 	defer t.idleMu.Unlock()
-	key := connectMethodKey{"", scheme, addr, false}
+	key := connectMethodKey{"", scheme, addr, false, false}
 	cacheKey := key.String()
 	for k, conns := range t.idleConn {
 		if k.String() == cacheKey {
@@ -194,7 +194,7 @@ func (t *Transport) QueueForIdleConnForTesting() {
 // persistConn for scheme, addr into the idle connection pool.
 func (t *Transport) PutIdleTestConn(scheme, addr string) bool {
 	c, _ := net.Pipe()
-	key := connectMethodKey{"", scheme, addr, false}
+	key := connectMethodKey{"", scheme, addr, false, false}
 
 	if t.MaxConnsPerHost > 0 {
 		// Transport is tracking conns-per-host.
@@ -219,7 +219,7 @@ func (t *Transport) PutIdleTestConn(scheme, addr string) bool {
 // PutIdleTestConnH2 reports whether it was able to insert a fresh
 // HTTP/2 persistConn for scheme, addr into the idle connection pool.
 func (t *Transport) PutIdleTestConnH2(scheme, addr string, alt RoundTripper) bool {
-	key := connectMethodKey{"", scheme, addr, false}
+	key := connectMethodKey{"", scheme, addr, false, false}
 
 	if t.MaxConnsPerHost > 0 {
 		// Transport is tracking conns-per-host.
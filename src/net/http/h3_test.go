@@ -0,0 +1,251 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	. "net/http"
+	"net/http/internal/testcert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHTTP3TestServer starts a server for HTTP/1 and HTTP/3
+// on the same TCP and UDP port.
+func newHTTP3TestServer(t *testing.T, h Handler) (srv *Server, addr string) {
+	t.Helper()
+	cert, err := tls.X509KeyPair(testcert.LocalhostCert, testcert.LocalhostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	protocols := new(Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP3(true)
+	srv = &Server{
+		Handler:   h,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		Protocols: protocols,
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ServeTLS(ln, "", "")
+	}()
+	t.Cleanup(func() {
+		srv.Close()
+		if err := <-errc; err != ErrServerClosed {
+			t.Errorf("ServeTLS = %v, want ErrServerClosed", err)
+		}
+	})
+	return srv, ln.Addr().String()
+}
+
+func newHTTP3TestTransport(t *testing.T, protocols ...func(*Protocols, bool)) *Transport {
+	t.Helper()
+	p := new(Protocols)
+	p.SetHTTP3(true)
+	for _, set := range protocols {
+		set(p, true)
+	}
+	tr := &Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		Protocols:       p,
+	}
+	t.Cleanup(tr.CloseIdleConnections)
+	return tr
+}
+
+func TestHTTP3RoundTrip(t *testing.T) {
+	t.Cleanup(func() { afterTest(t) })
+	var addr string
+	_, addr = newHTTP3TestServer(t, HandlerFunc(func(w ResponseWriter, r *Request) {
+		if r.ProtoMajor != 3 || r.Proto != "HTTP/3.0" {
+			t.Errorf("request Proto = %q (%v), want HTTP/3.0", r.Proto, r.ProtoMajor)
+		}
+		if got, want := r.Host, addr; got != want {
+			t.Errorf("request Host = %q, want %q", got, want)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body: %v", err)
+		}
+		if got, want := r.Trailer.Get("Client-Trailer"), "ct"; got != want {
+			t.Errorf("request trailer = %q, want %q", got, want)
+		}
+		w.Header().Set("Trailer", "Server-Trailer")
+		w.Header().Set("X-Method", r.Method)
+		w.Header()["X-Cookie"] = []string{r.Header.Get("Cookie")}
+		w.Write(body)
+		w.Header().Set("Server-Trailer", "st")
+	}))
+	tr := newHTTP3TestTransport(t)
+
+	req, _ := NewRequest("POST", "https://"+addr+"/path?q=1", io.MultiReader(strings.NewReader("hello, "), strings.NewReader("world")))
+	req.AddCookie(&Cookie{Name: "a", Value: "1"})
+	req.AddCookie(&Cookie{Name: "b", Value: "2"})
+	req.Trailer = Header{"Client-Trailer": {"ct"}}
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(body), "hello, world"; got != want {
+		t.Errorf("response body = %q, want %q", got, want)
+	}
+	if resp.StatusCode != 200 || resp.ProtoMajor != 3 {
+		t.Errorf("response %v %v, want 200 HTTP/3.0", resp.Status, resp.Proto)
+	}
+	if got, want := resp.Header.Get("X-Method"), "POST"; got != want {
+		t.Errorf("X-Method = %q, want %q", got, want)
+	}
+	if got, want := resp.Header.Get("X-Cookie"), "a=1; b=2"; got != want {
+		t.Errorf("X-Cookie = %q, want %q", got, want)
+	}
+	if got, want := resp.Trailer.Get("Server-Trailer"), "st"; got != want {
+		t.Errorf("response trailer = %q, want %q", got, want)
+	}
+}
+
+func TestHTTP3ContentLength(t *testing.T) {
+	t.Cleanup(func() { afterTest(t) })
+	_, addr := newHTTP3TestServer(t, HandlerFunc(func(w ResponseWriter, r *Request) {
+		switch r.URL.Path {
+		case "/empty":
+			w.WriteHeader(204)
+		default:
+			w.Header().Set("Content-Length", "12")
+			io.WriteString(w, "some content")
+		}
+	}))
+	tr := newHTTP3TestTransport(t)
+	c := &Client{Transport: tr}
+	for _, test := range []struct {
+		method, path string
+		wantLen      int64
+		wantBody     string
+	}{
+		{"GET", "/", 12, "some content"},
+		{"HEAD", "/", 12, ""},
+		{"GET", "/empty", -1, ""},
+	} {
+		req, _ := NewRequest(test.method, "https://"+addr+test.path, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("%v %v: %v", test.method, test.path, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%v %v: reading body: %v", test.method, test.path, err)
+		}
+		if resp.ContentLength != test.wantLen || string(body) != test.wantBody {
+			t.Errorf("%v %v: ContentLength=%v, body=%q; want %v, %q", test.method, test.path, resp.ContentLength, body, test.wantLen, test.wantBody)
+		}
+	}
+}
+
+func TestHTTP3CancelRequest(t *testing.T) {
+	t.Cleanup(func() { afterTest(t) })
+	release := make(chan struct{})
+	defer close(release)
+	_, addr := newHTTP3TestServer(t, HandlerFunc(func(w ResponseWriter, r *Request) {
+		w.WriteHeader(200)
+		w.(Flusher).Flush()
+		<-release
+	}))
+	tr := newHTTP3TestTransport(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := NewRequestWithContext(ctx, "GET", "https://"+addr+"/", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, context.Canceled) {
+		t.Errorf("reading body after cancel: %v, want context.Canceled", err)
+	}
+	resp.Body.Close()
+}
+
+func TestHTTP3AltSvcUpgrade(t *testing.T) {
+	t.Cleanup(func() { afterTest(t) })
+	_, addr := newHTTP3TestServer(t, HandlerFunc(func(w ResponseWriter, r *Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	tr := newHTTP3TestTransport(t, (*Protocols).SetHTTP1)
+	c := &Client{Transport: tr}
+	url := "https://" + addr + "/"
+	for i, want := range []string{"HTTP/1.1", "HTTP/3.0", "HTTP/3.0"} {
+		resp, err := c.Get(url)
+		if err != nil {
+			t.Fatalf("request %v: %v", i, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("request %v: reading body: %v", i, err)
+		}
+		if string(body) != want {
+			t.Errorf("request %v served using %v, want %v", i, string(body), want)
+		}
+		if i == 0 && !strings.HasPrefix(resp.Header.Get("Alt-Svc"), `h3=":`) {
+			t.Errorf("Alt-Svc = %q, want h3 alternative", resp.Header.Get("Alt-Svc"))
+		}
+	}
+}
+
+func TestHTTP3Shutdown(t *testing.T) {
+	t.Cleanup(func() { afterTest(t) })
+	inHandler := make(chan struct{})
+	release := make(chan struct{})
+	srv, addr := newHTTP3TestServer(t, HandlerFunc(func(w ResponseWriter, r *Request) {
+		close(inHandler)
+		<-release
+		io.WriteString(w, "done")
+	}))
+	tr := newHTTP3TestTransport(t)
+	respc := make(chan error, 1)
+	go func() {
+		resp, err := (&Client{Transport: tr}).Get("https://" + addr + "/")
+		if err == nil {
+			var body []byte
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err == nil && string(body) != "done" {
+				err = errors.New("unexpected body " + string(body))
+			}
+		}
+		respc <- err
+	}()
+	<-inHandler
+	shutdownc := make(chan error, 1)
+	go func() {
+		shutdownc <- srv.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-shutdownc:
+		t.Fatalf("Shutdown returned %v with a request in progress", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-respc; err != nil {
+		t.Errorf("request in progress during Shutdown: %v", err)
+	}
+	if err := <-shutdownc; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
@@ -3,6 +3,7 @@
 // license that can be found in the LICENSE file.
 
 //go:generate bundle -o=h2_bundle.go -prefix=http2 -tags=!nethttpomithttp2 -import=golang.org/x/net/internal/httpcommon=net/http/internal/httpcommon golang.org/x/net/http2
+//go:generate bundle -o=h3_bundle.go -prefix=http3 -import=golang.org/x/net/internal/httpcommon=net/http/internal/httpcommon golang.org/x/net/internal/http3
 
 package http
 
@@ -27,6 +28,8 @@ import (
 //   - HTTP2 is the HTTP/2 protcol over a TLS connection.
 //
 //   - UnencryptedHTTP2 is the HTTP/2 protocol over an unsecured TCP connection.
+//
+//   - HTTP3 is the HTTP/3 protocol over QUIC.
 type Protocols struct {
 	bits uint8
 }
@@ -35,6 +38,7 @@ const (
 	protoHTTP1 = 1 << iota
 	protoHTTP2
 	protoUnencryptedHTTP2
+	protoHTTP3
 )
 
 // HTTP1 reports whether p includes HTTP/1.
@@ -55,6 +59,12 @@ func (p Protocols) UnencryptedHTTP2() bool { return p.bits&protoUnencryptedHTTP2
 // SetUnencryptedHTTP2 adds or removes unencrypted HTTP/2 from p.
 func (p *Protocols) SetUnencryptedHTTP2(ok bool) { p.setBit(protoUnencryptedHTTP2, ok) }
 
+// HTTP3 reports whether p includes HTTP/3.
+func (p Protocols) HTTP3() bool { return p.bits&protoHTTP3 != 0 }
+
+// SetHTTP3 adds or removes HTTP/3 from p.
+func (p *Protocols) SetHTTP3(ok bool) { p.setBit(protoHTTP3, ok) }
+
 func (p *Protocols) setBit(bit uint8, ok bool) {
 	if ok {
 		p.bits |= bit
@@ -74,6 +84,9 @@ func (p Protocols) String() string {
 	if p.UnencryptedHTTP2() {
 		s = append(s, "UnencryptedHTTP2")
 	}
+	if p.HTTP3() {
+		s = append(s, "HTTP3")
+	}
 	return "{" + strings.Join(s, ",") + "}"
 }
 
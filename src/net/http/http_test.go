@@ -209,6 +209,13 @@ func TestProtocols(t *testing.T) {
 	if !p.HTTP2() {
 		t.Errorf("after unsetting HTTP1: p.HTTP2() = false, want true")
 	}
+	p.SetHTTP3(true)
+	if !p.HTTP3() {
+		t.Errorf("after setting HTTP3: p.HTTP3() = false, want true")
+	}
+	if got, want := p.String(), "{HTTP2,HTTP3}"; got != want {
+		t.Errorf("p.String() = %q, want %q", got, want)
+	}
 }
 
 const redirectURL = "/thisaredirect细雪withasciilettersのけぶabcdefghijk.html"
@@ -161,6 +161,11 @@ type ClientTrace struct {
 	// request and any body. It may be called multiple times
 	// in the case of retried requests.
 	WroteRequest func(WroteRequestInfo)
+
+	// Retry is called when an http.Client with a retry policy
+	// decides to retry a request, before waiting for the
+	// backoff delay.
+	Retry func(RetryInfo)
 }
 
 // WroteRequestInfo contains information provided to the WroteRequest
@@ -170,6 +175,23 @@ type WroteRequestInfo struct {
 	Err error
 }
 
+// RetryInfo contains information provided to the Retry hook.
+type RetryInfo struct {
+	// Attempt is the number of the attempt about to be made.
+	// The first retry is attempt 2.
+	Attempt int
+
+	// Delay is how long the client waits before making the attempt.
+	Delay time.Duration
+
+	// Err is the error returned by the previous attempt, if any.
+	Err error
+
+	// StatusCode is the status code of the previous attempt's
+	// response, or zero if the previous attempt returned an error.
+	StatusCode int
+}
+
 // compose modifies t such that it respects the previously-registered hooks in old,
 // subject to the composition policy requested in t.Compose.
 func (t *ClientTrace) compose(old *ClientTrace) {
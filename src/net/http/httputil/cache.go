@@ -0,0 +1,877 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// HTTP caching, as defined in RFC 9111.
+
+package httputil
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/internal/ascii"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Cache stores the responses cached by a [CachingTransport].
+//
+// Entries are opaque byte slices holding a response and the metadata
+// needed to reuse it. A Cache may discard entries at any time.
+// Its methods may be called concurrently.
+type Cache interface {
+	// Get returns the entry stored for key, reporting whether it was found.
+	Get(key string) (entry []byte, ok bool)
+
+	// Set stores an entry for key, replacing any existing entry.
+	// Set must not modify or retain entry after it returns.
+	Set(key string, entry []byte)
+
+	// Delete removes the entry for key, if there is one.
+	Delete(key string)
+}
+
+// A MemoryCache is a [Cache] that keeps entries in memory.
+// When the total size of its keys and entries exceeds its limit,
+// it discards the least recently used entries.
+type MemoryCache struct {
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     list.List // of *memoryCacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key   string
+	entry []byte
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxSize bytes.
+func NewMemoryCache(maxSize int64) *MemoryCache {
+	return &MemoryCache{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get implements [Cache].
+// It returns a copy of the stored entry.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return bytes.Clone(el.Value.(*memoryCacheEntry).entry), true
+}
+
+// Set implements [Cache].
+// Entries larger than the cache's maximum size are not stored.
+func (c *MemoryCache) Set(key string, entry []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleteLocked(key)
+	size := int64(len(key) + len(entry))
+	if size > c.maxSize {
+		return
+	}
+	e := &memoryCacheEntry{key: key, entry: bytes.Clone(entry)}
+	c.entries[key] = c.lru.PushFront(e)
+	c.size += size
+	for c.size > c.maxSize {
+		c.deleteLocked(c.lru.Back().Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete implements [Cache].
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleteLocked(key)
+}
+
+func (c *MemoryCache) deleteLocked(key string) {
+	el, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	e := c.lru.Remove(el).(*memoryCacheEntry)
+	delete(c.entries, key)
+	c.size -= int64(len(e.key) + len(e.entry))
+}
+
+// defaultCacheSize is the size of the MemoryCache used by a
+// CachingTransport with no Cache.
+const defaultCacheSize = 64 << 20
+
+// maxCachedBodySize is the largest response body a CachingTransport stores.
+const maxCachedBodySize = 32 << 20
+
+// maxVariants is the number of responses with different Vary
+// field values a CachingTransport stores for a URL.
+const maxVariants = 8
+
+// A CachingTransport is an [http.RoundTripper] that caches responses
+// as specified by RFC 9111.
+//
+// CachingTransport stores responses to GET requests. It answers a request
+// from the cache without contacting the server while the stored response
+// is fresh, as determined by the Cache-Control and Expires header fields
+// of the response (or heuristically, from its Last-Modified field) and
+// the Cache-Control field of the request. It revalidates stale responses
+// with a conditional request using their ETag and Last-Modified validators.
+// If a stale response carries a stale-while-revalidate directive (RFC 5861),
+// CachingTransport returns it during the permitted period and revalidates
+// it in the background.
+//
+// Responses are stored per URL. A response with a Vary field is only
+// used for requests with the same values of the fields it names;
+// up to 8 such responses with different values are stored for a URL.
+//
+// A successful response to a request with an unsafe method such as POST
+// invalidates the stored response for the request URL, and for the URLs
+// in its Location and Content-Location fields that have the same host.
+//
+// CachingTransport does not use the cache for requests with a Range
+// field or with conditional fields such as If-None-Match, and does
+// not store response bodies larger than 32 MiB.
+type CachingTransport struct {
+	// Transport is the RoundTripper used to send requests
+	// that cannot be answered from the cache.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// Cache stores responses.
+	// If nil, the CachingTransport uses a MemoryCache
+	// holding up to 64 MiB, created on first use.
+	Cache Cache
+
+	// Shared reports whether the cache is shared between users.
+	// A private cache, the default, stores responses for a single user.
+	// A shared cache does not store responses marked private,
+	// stores responses to requests with an Authorization field only
+	// when the response explicitly allows it, and honors the s-maxage
+	// directive.
+	Shared bool
+
+	defaultCacheOnce sync.Once
+	defaultCache     *MemoryCache
+
+	mu           sync.Mutex
+	revalidating map[string]bool // keys being revalidated in the background
+
+	now func() time.Time // for testing
+}
+
+func (t *CachingTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (t *CachingTransport) cache() Cache {
+	if t.Cache != nil {
+		return t.Cache
+	}
+	t.defaultCacheOnce.Do(func() {
+		t.defaultCache = NewMemoryCache(defaultCacheSize)
+	})
+	return t.defaultCache
+}
+
+func (t *CachingTransport) timeNow() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "" && req.Method != "GET" {
+		resp, err := t.transport().RoundTrip(req)
+		if err == nil && isUnsafeMethod(req.Method) && resp.StatusCode < 400 {
+			t.invalidate(req.URL, resp)
+		}
+		return resp, err
+	}
+	if req.Header.Get("Range") != "" || hasConditionalFields(req.Header) {
+		return t.transport().RoundTrip(req)
+	}
+
+	key := cacheKey(req.URL)
+	reqCC := parseCacheControl(req.Header)
+	if !reqCC.has("no-cache") && len(req.Header["Cache-Control"]) == 0 {
+		// Pragma: no-cache is only used when Cache-Control is absent
+		// (RFC 9111, Section 5.4).
+		for _, v := range req.Header["Pragma"] {
+			if ascii.EqualFold(textproto.TrimString(v), "no-cache") {
+				reqCC["no-cache"] = ""
+			}
+		}
+	}
+
+	e := t.lookup(key, req)
+	if e != nil {
+		now := t.timeNow()
+		switch e.usability(reqCC, now, t.Shared) {
+		case cacheFresh:
+			closeRequestBody(req)
+			return e.response(req, now), nil
+		case cacheStaleWhileRevalidate:
+			closeRequestBody(req)
+			resp := e.response(req, now)
+			t.revalidateInBackground(key, req, e)
+			return resp, nil
+		}
+	}
+	if reqCC.has("only-if-cached") {
+		closeRequestBody(req)
+		return &http.Response{
+			Status:     "504 Gateway Timeout",
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	return t.fetch(key, req, reqCC, e)
+}
+
+// fetch sends req to the server, revalidating the stored entry e if it
+// is not nil, and arranges to store the response if it is storable.
+func (t *CachingTransport) fetch(key string, req *http.Request, reqCC cacheControl, e *cacheEntry) (*http.Response, error) {
+	outreq := req
+	if e != nil {
+		etag := e.resp.Header.Get("Etag")
+		lastModified := e.resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			outreq = req.Clone(req.Context())
+			if etag != "" {
+				outreq.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				outreq.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+	reqTime := t.timeNow()
+	resp, err := t.transport().RoundTrip(outreq)
+	if err != nil {
+		return nil, err
+	}
+	respTime := t.timeNow()
+	resp.Request = req
+
+	if e != nil && outreq != req && resp.StatusCode == http.StatusNotModified {
+		// The stored response is still valid (RFC 9111, Section 4.3.4).
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+		resp.Body.Close()
+		e.update(resp.Header, reqTime, respTime)
+		if !reqCC.has("no-store") {
+			t.store(key, e)
+		}
+		return e.response(req, respTime), nil
+	}
+	if !isStorable(req, reqCC, resp, t.Shared) {
+		if e != nil && resp.StatusCode < 500 && !reqCC.has("no-store") {
+			// The stored response has been replaced by one
+			// that cannot be stored.
+			t.remove(key, e)
+		}
+		return resp, nil
+	}
+	stored := &cacheEntry{
+		reqTime:  reqTime,
+		respTime: respTime,
+		vary:     varyFields(req, resp.Header),
+		resp: &http.Response{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+		},
+	}
+	resp.Body = &cachingBody{
+		ReadCloser: resp.Body,
+		done: func(body []byte) {
+			stored.body = body
+			t.store(key, stored)
+		},
+	}
+	return resp, nil
+}
+
+// revalidateInBackground revalidates the stale entry e for req,
+// unless a revalidation of the key is already in progress.
+func (t *CachingTransport) revalidateInBackground(key string, req *http.Request, e *cacheEntry) {
+	t.mu.Lock()
+	if t.revalidating[key] {
+		t.mu.Unlock()
+		return
+	}
+	if t.revalidating == nil {
+		t.revalidating = make(map[string]bool)
+	}
+	t.revalidating[key] = true
+	t.mu.Unlock()
+
+	outreq := req.Clone(context.WithoutCancel(req.Context()))
+	outreq.Body = nil
+	outreq.GetBody = nil
+	outreq.ContentLength = 0
+	go func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.revalidating, key)
+			t.mu.Unlock()
+		}()
+		resp, err := t.fetch(key, outreq, cacheControl{}, e)
+		if err != nil {
+			return
+		}
+		// Reading the body stores the response.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+}
+
+// lookup returns the stored entry for key that can be used for req,
+// or nil if there is none.
+func (t *CachingTransport) lookup(key string, req *http.Request) *cacheEntry {
+	for _, e := range t.variants(key) {
+		if e.matchesVary(req) {
+			return e
+		}
+	}
+	return nil
+}
+
+// variants returns the entries stored for key, most recent first.
+func (t *CachingTransport) variants(key string) []*cacheEntry {
+	b, ok := t.cache().Get(key)
+	if !ok {
+		return nil
+	}
+	variants, err := parseCacheEntries(b)
+	if err != nil {
+		t.cache().Delete(key)
+		return nil
+	}
+	return variants
+}
+
+// store stores e for key, replacing any entry with the same Vary values.
+func (t *CachingTransport) store(key string, e *cacheEntry) {
+	variants := []*cacheEntry{e}
+	for _, v := range t.variants(key) {
+		if len(variants) == maxVariants {
+			break
+		}
+		if !v.sameVary(e) {
+			variants = append(variants, v)
+		}
+	}
+	t.storeVariants(key, variants)
+}
+
+// remove removes the entry with the same Vary values as e from key.
+func (t *CachingTransport) remove(key string, e *cacheEntry) {
+	var variants []*cacheEntry
+	for _, v := range t.variants(key) {
+		if !v.sameVary(e) {
+			variants = append(variants, v)
+		}
+	}
+	t.storeVariants(key, variants)
+}
+
+func (t *CachingTransport) storeVariants(key string, variants []*cacheEntry) {
+	if len(variants) == 0 {
+		t.cache().Delete(key)
+		return
+	}
+	b, err := marshalCacheEntries(variants)
+	if err != nil {
+		return
+	}
+	t.cache().Set(key, b)
+}
+
+// invalidate removes stored responses after a successful request
+// to u with an unsafe method (RFC 9111, Section 4.4).
+func (t *CachingTransport) invalidate(u *url.URL, resp *http.Response) {
+	t.cache().Delete(cacheKey(u))
+	for _, name := range []string{"Location", "Content-Location"} {
+		v := resp.Header.Get(name)
+		if v == "" {
+			continue
+		}
+		ref, err := u.Parse(v)
+		if err != nil || ref.Host != u.Host {
+			continue
+		}
+		t.cache().Delete(cacheKey(ref))
+	}
+}
+
+// cacheKey returns the cache key for a GET request to u.
+func cacheKey(u *url.URL) string {
+	if u.Fragment == "" && u.RawFragment == "" {
+		return u.String()
+	}
+	u2 := *u
+	u2.Fragment = ""
+	u2.RawFragment = ""
+	return u2.String()
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return false
+	}
+	return true
+}
+
+func hasConditionalFields(h http.Header) bool {
+	for _, k := range []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range"} {
+		if _, ok := h[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// A cacheControl holds the directives of a Cache-Control field.
+// Directives without an argument have an empty value.
+type cacheControl map[string]string
+
+func parseCacheControl(h http.Header) cacheControl {
+	cc := make(cacheControl)
+	for _, v := range h["Cache-Control"] {
+		for d := range strings.SplitSeq(v, ",") {
+			name, value, _ := strings.Cut(d, "=")
+			name, ok := ascii.ToLower(textproto.TrimString(name))
+			if !ok || name == "" {
+				continue
+			}
+			value = textproto.TrimString(value)
+			if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+				value = value[1 : len(value)-1]
+			}
+			if _, dup := cc[name]; !dup {
+				cc[name] = value
+			}
+		}
+	}
+	return cc
+}
+
+// seconds returns the value of a delta-seconds directive.
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		// Invalid values are treated as zero, which is the most
+		// conservative choice for freshness.
+		return 0, true
+	}
+	if n > int64(1<<62/time.Second) {
+		n = int64(1 << 62 / time.Second)
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// heuristicallyCacheable reports whether a response with the status code
+// may be stored without explicit freshness information
+// (RFC 9110, Section 15.1).
+func heuristicallyCacheable(code int) bool {
+	switch code {
+	case 200, 203, 204, 300, 301, 308, 404, 405, 410, 414, 501:
+		return true
+	}
+	return false
+}
+
+// isStorable reports whether resp, received in response to req,
+// may be stored (RFC 9111, Section 3).
+func isStorable(req *http.Request, reqCC cacheControl, resp *http.Response, shared bool) bool {
+	if resp.StatusCode < 200 || resp.StatusCode == http.StatusPartialContent || resp.StatusCode == http.StatusNotModified {
+		return false
+	}
+	if reqCC.has("no-store") {
+		return false
+	}
+	respCC := parseCacheControl(resp.Header)
+	if respCC.has("no-store") {
+		return false
+	}
+	if shared && respCC.has("private") {
+		return false
+	}
+	if shared && req.Header.Get("Authorization") != "" &&
+		!respCC.has("must-revalidate") && !respCC.has("public") && !respCC.has("s-maxage") {
+		return false
+	}
+	for _, v := range resp.Header.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			if textproto.TrimString(name) == "*" {
+				return false
+			}
+		}
+	}
+	return resp.Header.Get("Expires") != "" ||
+		respCC.has("max-age") ||
+		(shared && respCC.has("s-maxage")) ||
+		respCC.has("public") ||
+		(!shared && respCC.has("private")) ||
+		heuristicallyCacheable(resp.StatusCode)
+}
+
+// varyFields returns the request fields named by the Vary field of h.
+func varyFields(req *http.Request, h http.Header) http.Header {
+	vary := make(http.Header)
+	for _, v := range h.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			name = textproto.TrimString(name)
+			if name == "" {
+				continue
+			}
+			vary.Set(name, strings.Join(req.Header.Values(name), ", "))
+		}
+	}
+	return vary
+}
+
+// A cacheEntry is a stored response.
+type cacheEntry struct {
+	reqTime  time.Time      // when the request was sent
+	respTime time.Time      // when the response was received
+	vary     http.Header    // values of the request fields named by Vary
+	resp     *http.Response // the response, with Body unused
+	body     []byte
+}
+
+// cacheEntryMagic begins the encoded entries stored for a key.
+const cacheEntryMagic = "go-http-cache-2\n"
+
+// marshalCacheEntries encodes the entries stored for a key:
+// a version line, and then each entry preceded by a line
+// holding its length.
+func marshalCacheEntries(entries []*cacheEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(cacheEntryMagic)
+	for _, e := range entries {
+		b, err := e.marshal()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(strconv.Itoa(len(b)))
+		buf.WriteByte('\n')
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+var errBadCacheEntry = errors.New("httputil: invalid cache entry")
+
+func parseCacheEntries(b []byte) ([]*cacheEntry, error) {
+	rest, ok := bytes.CutPrefix(b, []byte(cacheEntryMagic))
+	if !ok {
+		return nil, errBadCacheEntry
+	}
+	var entries []*cacheEntry
+	for len(rest) > 0 {
+		line, after, ok := bytes.Cut(rest, []byte("\n"))
+		if !ok {
+			return nil, errBadCacheEntry
+		}
+		n, err := strconv.Atoi(string(line))
+		if err != nil || n < 0 || n > len(after) {
+			return nil, errBadCacheEntry
+		}
+		e, err := parseCacheEntry(after[:n])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+		rest = after[n:]
+	}
+	return entries, nil
+}
+
+// marshal encodes e: the request and response times, the Vary fields,
+// and then the response in HTTP/1.1 wire format.
+func (e *cacheEntry) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(strconv.FormatInt(e.reqTime.UnixNano(), 10))
+	buf.WriteByte('\n')
+	buf.WriteString(strconv.FormatInt(e.respTime.UnixNano(), 10))
+	buf.WriteByte('\n')
+	if err := e.vary.Write(&buf); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	resp := &http.Response{
+		StatusCode:    e.resp.StatusCode,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.resp.Header.Clone(),
+		ContentLength: int64(len(e.body)),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+	}
+	resp.Header.Del("Transfer-Encoding")
+	if len(e.body) == 0 {
+		resp.Body = nil
+	}
+	if err := resp.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func parseCacheEntry(rest []byte) (*cacheEntry, error) {
+	var times [2]time.Time
+	for i := range times {
+		line, after, ok := bytes.Cut(rest, []byte("\n"))
+		if !ok {
+			return nil, errBadCacheEntry
+		}
+		n, err := strconv.ParseInt(string(line), 10, 64)
+		if err != nil {
+			return nil, errBadCacheEntry
+		}
+		times[i] = time.Unix(0, n)
+		rest = after
+	}
+	br := bufio.NewReader(bytes.NewReader(rest))
+	vary, err := textproto.NewReader(br).ReadMIMEHeader()
+	if err != nil {
+		return nil, errBadCacheEntry
+	}
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		return nil, errBadCacheEntry
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errBadCacheEntry
+	}
+	resp.Body = nil
+	return &cacheEntry{
+		reqTime:  times[0],
+		respTime: times[1],
+		vary:     http.Header(vary),
+		resp:     resp,
+		body:     body,
+	}, nil
+}
+
+// matchesVary reports whether e may be used for req.
+func (e *cacheEntry) matchesVary(req *http.Request) bool {
+	for name, vv := range e.vary {
+		if name == "*" || strings.Join(req.Header.Values(name), ", ") != vv[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameVary reports whether e and e2 were stored for requests with
+// the same values of the fields named by Vary.
+func (e *cacheEntry) sameVary(e2 *cacheEntry) bool {
+	if len(e.vary) != len(e2.vary) {
+		return false
+	}
+	for name, vv := range e.vary {
+		vv2, ok := e2.vary[name]
+		if !ok || vv[0] != vv2[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// age returns the current age of e (RFC 9111, Section 4.2.3).
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	date, err := http.ParseTime(e.resp.Header.Get("Date"))
+	if err != nil {
+		date = e.respTime
+	}
+	apparentAge := max(0, e.respTime.Sub(date))
+	var ageValue time.Duration
+	if n, err := strconv.ParseInt(e.resp.Header.Get("Age"), 10, 64); err == nil && n > 0 {
+		ageValue = time.Duration(min(n, int64(1<<62/time.Second))) * time.Second
+	}
+	responseDelay := e.respTime.Sub(e.reqTime)
+	correctedAgeValue := ageValue + responseDelay
+	correctedInitialAge := max(apparentAge, correctedAgeValue)
+	residentTime := now.Sub(e.respTime)
+	return correctedInitialAge + residentTime
+}
+
+// freshnessLifetime returns the freshness lifetime of e
+// (RFC 9111, Section 4.2.1).
+func (e *cacheEntry) freshnessLifetime(cc cacheControl, shared bool) time.Duration {
+	if shared {
+		if d, ok := cc.seconds("s-maxage"); ok {
+			return d
+		}
+	}
+	if d, ok := cc.seconds("max-age"); ok {
+		return d
+	}
+	h := e.resp.Header
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		date = e.respTime
+	}
+	if v := h.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			// An invalid Expires value represents a time in the past.
+			return 0
+		}
+		return max(0, expires.Sub(date))
+	}
+	if heuristicallyCacheable(e.resp.StatusCode) || cc.has("public") {
+		// Use a tenth of the time since the response was last modified
+		// (RFC 9111, Section 4.2.2).
+		if lastModified, err := http.ParseTime(h.Get("Last-Modified")); err == nil && lastModified.Before(date) {
+			return date.Sub(lastModified) / 10
+		}
+	}
+	return 0
+}
+
+// A cacheUsability describes how a stored response may be used.
+type cacheUsability int
+
+const (
+	cacheMustValidate         cacheUsability = iota // revalidate before use
+	cacheFresh                                      // use without revalidating
+	cacheStaleWhileRevalidate                       // use, and revalidate in the background
+)
+
+// usability reports how e may be used for a request
+// with the Cache-Control directives reqCC.
+func (e *cacheEntry) usability(reqCC cacheControl, now time.Time, shared bool) cacheUsability {
+	respCC := parseCacheControl(e.resp.Header)
+	if reqCC.has("no-cache") || respCC.has("no-cache") {
+		return cacheMustValidate
+	}
+	age := e.age(now)
+	lifetime := e.freshnessLifetime(respCC, shared)
+	if maxAge, ok := reqCC.seconds("max-age"); ok && age > maxAge {
+		return cacheMustValidate
+	}
+	if minFresh, ok := reqCC.seconds("min-fresh"); ok {
+		lifetime -= minFresh
+	}
+	if age < lifetime {
+		return cacheFresh
+	}
+	if respCC.has("must-revalidate") || (shared && respCC.has("proxy-revalidate")) {
+		return cacheMustValidate
+	}
+	if reqCC.has("max-stale") {
+		if maxStale, _ := reqCC.seconds("max-stale"); reqCC["max-stale"] == "" || age-lifetime <= maxStale {
+			return cacheFresh
+		}
+	}
+	if swr, ok := respCC.seconds("stale-while-revalidate"); ok && age < lifetime+swr {
+		return cacheStaleWhileRevalidate
+	}
+	return cacheMustValidate
+}
+
+// update updates e after a 304 Not Modified response with header h
+// (RFC 9111, Section 3.2).
+func (e *cacheEntry) update(h http.Header, reqTime, respTime time.Time) {
+	for k, vv := range h {
+		switch k {
+		case "Content-Length", "Content-Encoding", "Content-Range", "Transfer-Encoding":
+			continue
+		}
+		e.resp.Header[k] = vv
+	}
+	e.reqTime = reqTime
+	e.respTime = respTime
+}
+
+// response returns a response for req from e.
+func (e *cacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	resp := &http.Response{
+		Status:        e.resp.Status,
+		StatusCode:    e.resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.resp.Header.Clone(),
+		ContentLength: int64(len(e.body)),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		Request:       req,
+	}
+	if len(e.body) == 0 {
+		resp.Body = http.NoBody
+	}
+	resp.Header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	return resp
+}
+
+// A cachingBody is a response body which is stored when it is
+// read to the end.
+type cachingBody struct {
+	io.ReadCloser
+	buf      bytes.Buffer
+	tooLarge bool
+	done     func(body []byte) // called with the complete body
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.tooLarge {
+		if b.buf.Len()+n > maxCachedBodySize {
+			b.tooLarge = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.tooLarge && b.done != nil {
+		b.done(b.buf.Bytes())
+		b.done = nil
+	}
+	return n, err
+}
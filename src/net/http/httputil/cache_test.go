@@ -0,0 +1,402 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httputil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A cacheTest is a CachingTransport in front of a test server,
+// with a fake clock.
+type cacheTest struct {
+	t        *testing.T
+	ts       *httptest.Server
+	tr       *CachingTransport
+	requests atomic.Int32 // requests received by the server
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newCacheTest(t *testing.T, h http.HandlerFunc) *cacheTest {
+	ct := &cacheTest{
+		t:   t,
+		now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	ct.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ct.requests.Add(1)
+		w.Header().Set("Date", ct.timeNow().Format(http.TimeFormat))
+		h(w, r)
+	}))
+	t.Cleanup(ct.ts.Close)
+	ct.tr = &CachingTransport{
+		Transport: ct.ts.Client().Transport,
+		now:       ct.timeNow,
+	}
+	return ct
+}
+
+func (ct *cacheTest) timeNow() time.Time {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.now
+}
+
+func (ct *cacheTest) advance(d time.Duration) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.now = ct.now.Add(d)
+}
+
+// get sends a GET request for path with the given header fields,
+// and returns the response body.
+func (ct *cacheTest) get(path string, header ...string) (*http.Response, string) {
+	ct.t.Helper()
+	return ct.do("GET", path, header...)
+}
+
+func (ct *cacheTest) do(method, path string, header ...string) (*http.Response, string) {
+	ct.t.Helper()
+	req, err := http.NewRequest(method, ct.ts.URL+path, nil)
+	if err != nil {
+		ct.t.Fatal(err)
+	}
+	for i := 0; i < len(header); i += 2 {
+		req.Header.Add(header[i], header[i+1])
+	}
+	resp, err := ct.tr.RoundTrip(req)
+	if err != nil {
+		ct.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ct.t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func (ct *cacheTest) wantRequests(want int32) {
+	ct.t.Helper()
+	if got := ct.requests.Load(); got != want {
+		ct.t.Errorf("server received %v requests, want %v", got, want)
+	}
+}
+
+func TestCachingTransportMaxAge(t *testing.T) {
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Etag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "content")
+	})
+
+	_, body := ct.get("/")
+	ct.wantRequests(1)
+	if body != "content" {
+		t.Errorf("body = %q, want %q", body, "content")
+	}
+
+	ct.advance(30 * time.Second)
+	resp, body := ct.get("/")
+	ct.wantRequests(1)
+	if body != "content" {
+		t.Errorf("cached body = %q, want %q", body, "content")
+	}
+	if got, want := resp.Header.Get("Age"), "30"; got != want {
+		t.Errorf("Age = %q, want %q", got, want)
+	}
+
+	// The stored response is stale, and is revalidated.
+	ct.advance(60 * time.Second)
+	resp, body = ct.get("/")
+	ct.wantRequests(2)
+	if resp.StatusCode != 200 || body != "content" {
+		t.Errorf("revalidated response: %v %q, want 200 %q", resp.StatusCode, body, "content")
+	}
+
+	// Revalidation refreshed the stored response.
+	ct.advance(30 * time.Second)
+	ct.get("/")
+	ct.wantRequests(2)
+
+	// A request with no-cache always revalidates.
+	ct.get("/", "Cache-Control", "no-cache")
+	ct.wantRequests(3)
+}
+
+func TestCachingTransportLastModified(t *testing.T) {
+	lastModified := time.Date(2024, 12, 22, 0, 0, 0, 0, time.UTC)
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "content")
+	})
+	ct.get("/")
+	ct.wantRequests(1)
+
+	// The heuristic freshness lifetime is a tenth of the time since
+	// the last modification: 10 days / 10 = 1 day.
+	ct.advance(23 * time.Hour)
+	ct.get("/")
+	ct.wantRequests(1)
+
+	ct.advance(2 * time.Hour)
+	if _, body := ct.get("/"); body != "content" {
+		t.Errorf("revalidated body = %q, want %q", body, "content")
+	}
+	ct.wantRequests(2)
+}
+
+func TestCachingTransportNotStored(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		header []string
+		status int
+	}{
+		{"no-store", []string{"Cache-Control", "no-store, max-age=60"}, 200},
+		{"no freshness", nil, 200},
+		{"uncacheable status", []string{"Cache-Control", "max-age=60"}, 206},
+		{"vary star", []string{"Cache-Control", "max-age=60", "Vary", "*"}, 200},
+		{"invalid expires", []string{"Expires", "0"}, 200},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < len(test.header); i += 2 {
+					w.Header().Set(test.header[i], test.header[i+1])
+				}
+				w.WriteHeader(test.status)
+				io.WriteString(w, "content")
+			})
+			ct.get("/")
+			ct.get("/")
+			ct.wantRequests(2)
+		})
+	}
+}
+
+func TestCachingTransportSharedCache(t *testing.T) {
+	for _, test := range []struct {
+		cacheControl string
+		shared       bool
+		wantRequests int32
+	}{
+		{"private, max-age=60", false, 1},
+		{"private, max-age=60", true, 2},
+		{"max-age=60", true, 2}, // request has Authorization
+		{"public, max-age=60", true, 1},
+		{"s-maxage=60, max-age=0", true, 1},
+		{"s-maxage=60, max-age=0", false, 2},
+	} {
+		t.Run(fmt.Sprintf("%v/shared=%v", test.cacheControl, test.shared), func(t *testing.T) {
+			ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", test.cacheControl)
+				io.WriteString(w, "content")
+			})
+			ct.tr.Shared = test.shared
+			ct.get("/", "Authorization", "Bearer x")
+			ct.get("/", "Authorization", "Bearer x")
+			ct.wantRequests(test.wantRequests)
+		})
+	}
+}
+
+func TestCachingTransportVary(t *testing.T) {
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		io.WriteString(w, "lang="+r.Header.Get("Accept-Language"))
+	})
+	ct.get("/", "Accept-Language", "en")
+	if _, body := ct.get("/", "Accept-Language", "en"); body != "lang=en" {
+		t.Errorf("body = %q, want %q", body, "lang=en")
+	}
+	ct.wantRequests(1)
+	if _, body := ct.get("/", "Accept-Language", "fr"); body != "lang=fr" {
+		t.Errorf("body = %q, want %q", body, "lang=fr")
+	}
+	ct.wantRequests(2)
+
+	// Both variants are stored.
+	for _, lang := range []string{"en", "fr"} {
+		if _, body := ct.get("/", "Accept-Language", lang); body != "lang="+lang {
+			t.Errorf("body = %q, want %q", body, "lang="+lang)
+		}
+	}
+	ct.wantRequests(2)
+
+	// Only the most recent variants are kept.
+	for i := range maxVariants {
+		ct.get("/", "Accept-Language", fmt.Sprint(i))
+	}
+	ct.wantRequests(2 + maxVariants)
+	ct.get("/", "Accept-Language", "en")
+	ct.wantRequests(3 + maxVariants)
+	ct.get("/", "Accept-Language", fmt.Sprint(maxVariants-1))
+	ct.wantRequests(3 + maxVariants)
+}
+
+func TestCachingTransportStaleWhileRevalidate(t *testing.T) {
+	var version atomic.Int32
+	revalidated := make(chan struct{}, 1)
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+		fmt.Fprintf(w, "v%v", version.Add(1))
+		if version.Load() > 1 {
+			revalidated <- struct{}{}
+		}
+	})
+	ct.get("/")
+
+	// Within the stale-while-revalidate period, the stale response
+	// is returned and revalidated in the background.
+	ct.advance(30 * time.Second)
+	if _, body := ct.get("/"); body != "v1" {
+		t.Errorf("stale body = %q, want %q", body, "v1")
+	}
+	<-revalidated
+	var body string
+	for {
+		// Wait for the background revalidation to store the response.
+		_, body = ct.get("/")
+		if body == "v2" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	ct.wantRequests(2)
+
+	// After the stale-while-revalidate period, the request waits
+	// for revalidation.
+	ct.advance(2 * time.Minute)
+	if _, body := ct.get("/"); body != "v3" {
+		t.Errorf("body = %q, want %q", body, "v3")
+	}
+	<-revalidated
+}
+
+func TestCachingTransportRequestDirectives(t *testing.T) {
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "content")
+	})
+	resp, _ := ct.get("/", "Cache-Control", "only-if-cached")
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("only-if-cached with no stored response: status %v, want 504", resp.StatusCode)
+	}
+	ct.wantRequests(0)
+
+	ct.get("/")
+	ct.advance(30 * time.Second)
+	ct.get("/", "Cache-Control", "max-age=10")
+	ct.wantRequests(2)
+	ct.advance(30 * time.Second)
+	ct.get("/", "Cache-Control", "min-fresh=40")
+	ct.wantRequests(3)
+
+	ct.advance(90 * time.Second)
+	resp, _ = ct.get("/", "Cache-Control", "max-stale=60")
+	ct.wantRequests(3)
+	if got, want := resp.Header.Get("Age"), "90"; got != want {
+		t.Errorf("Age = %q, want %q", got, want)
+	}
+	ct.get("/", "Pragma", "no-cache")
+	ct.wantRequests(4)
+}
+
+func TestCachingTransportInvalidation(t *testing.T) {
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.Header().Set("Location", "/other")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "content")
+	})
+	ct.get("/")
+	ct.get("/other")
+	ct.get("/unrelated")
+	ct.wantRequests(3)
+
+	ct.do("POST", "/")
+	ct.wantRequests(4)
+
+	ct.get("/")
+	ct.get("/other")
+	ct.get("/unrelated")
+	ct.wantRequests(6)
+}
+
+func TestCachingTransportBodyNotRead(t *testing.T) {
+	ct := newCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "content")
+	})
+	req, _ := http.NewRequest("GET", ct.ts.URL, nil)
+	resp, err := ct.tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	// A response is only stored when its body is read completely.
+	ct.get("/")
+	ct.get("/")
+	ct.wantRequests(2)
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(20)
+	c.Set("a", []byte("aaaa")) // size 5
+	c.Set("b", []byte("bbbb"))
+	c.Set("c", []byte("cccc"))
+	if _, ok := c.Get("a"); !ok { // a is now the most recently used
+		t.Fatalf("Get(a) not found")
+	}
+	c.Set("d", []byte("dddddddd")) // size 9, evicts b
+	for _, test := range []struct {
+		key  string
+		want string
+	}{
+		{"a", "aaaa"},
+		{"b", ""},
+		{"c", "cccc"},
+		{"d", "dddddddd"},
+	} {
+		got, ok := c.Get(test.key)
+		if string(got) != test.want || ok != (test.want != "") {
+			t.Errorf("Get(%q) = %q, %v; want %q", test.key, got, ok, test.want)
+		}
+	}
+
+	c.Set("e", []byte(strings.Repeat("e", 20)))
+	if _, ok := c.Get("e"); ok {
+		t.Errorf("entry larger than the cache was stored")
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) found after Delete")
+	}
+
+	got, _ := c.Get("c")
+	got[0] = 'x'
+	if got, _ := c.Get("c"); string(got) != "cccc" {
+		t.Errorf("after modifying the result of Get(c), Get(c) = %q, want %q", got, "cccc")
+	}
+}
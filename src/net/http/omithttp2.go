@@ -22,6 +22,12 @@ var http2errRequestCanceled = errors.New("net/http: request canceled")
 
 var http2goAwayTimeout = 1 * time.Second
 
+var http2errClientConnGotGoAway = errors.New(noHTTP2)
+
+type http2GoAwayError struct{}
+
+func (http2GoAwayError) Error() string { panic(noHTTP2) }
+
 const http2NextProtoTLS = "h2"
 
 type http2Transport struct {
@@ -0,0 +1,216 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy configures how a [Client] retries requests that
+// fail with a transient error.
+//
+// Only requests which may be safely replayed are retried: the
+// request must use the GET, HEAD, OPTIONS, or TRACE method or carry
+// an Idempotency-Key header, and its Body must be nil, [NoBody], or
+// rewindable with [Request.GetBody].
+//
+// Between attempts the client waits for an exponentially increasing,
+// randomly jittered delay. If a response includes a Retry-After
+// header, the client waits for the duration it specifies instead.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent,
+	// including the first attempt.
+	// If zero, a default of 3 is used.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry.
+	// The delay doubles for each subsequent retry, up to MaxDelay.
+	// The client waits for a random duration between half the
+	// delay and the full delay.
+	// If zero, a default of 100ms is used.
+	BaseDelay time.Duration
+
+	// MaxDelay is the maximum delay between attempts.
+	// If a response's Retry-After header asks for a longer delay,
+	// the response is returned without retrying.
+	// If zero, a default of 30s is used.
+	MaxDelay time.Duration
+
+	// ShouldRetry reports whether a request should be retried
+	// after an attempt returned resp and err. Exactly one of resp
+	// and err is non-nil. ShouldRetry must not read or close
+	// resp.Body.
+	//
+	// If ShouldRetry is nil, a request is retried when it fails
+	// with a network error other than a timeout or cancellation,
+	// when the server closes the connection before responding,
+	// or when the response status is 429 (Too Many Requests),
+	// 502 (Bad Gateway), 503 (Service Unavailable), or
+	// 504 (Gateway Timeout).
+	//
+	// ShouldRetry is not called for requests which cannot be
+	// safely replayed.
+	ShouldRetry func(req *Request, resp *Response, err error) bool
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return 3
+}
+
+func (p *RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay > 0 {
+		return p.MaxDelay
+	}
+	return 30 * time.Second
+}
+
+// backoff returns the delay before the given attempt,
+// where attempt 2 is the first retry.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	if d <= 0 {
+		d = 100 * time.Millisecond
+	}
+	maxDelay := p.maxDelay()
+	for i := 2; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+func (p *RetryPolicy) shouldRetry(req *Request, resp *Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(req, resp, err)
+	}
+	if err != nil {
+		return isRetryableError(err)
+	}
+	switch resp.StatusCode {
+	case StatusTooManyRequests, StatusBadGateway, StatusServiceUnavailable, StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError reports whether err, returned by a RoundTripper,
+// is likely to be transient.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, errServerClosedIdle) || errors.Is(err, http2errClientConnGotGoAway) {
+		return true
+	}
+	if errors.As(err, new(http2GoAwayError)) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && !ne.Timeout()
+}
+
+// send sends req using rt, retrying according to the policy.
+// It has the same contract as the package-level send.
+func (p *RetryPolicy) send(req *Request, rt RoundTripper, deadline time.Time) (resp *Response, didTimeout func() bool, err error) {
+	if !req.isReplayable() {
+		return send(req, rt, deadline)
+	}
+	ctx := req.Context()
+	trace := httptrace.ContextClientTrace(ctx)
+	areq := req
+	for attempt := 1; ; attempt++ {
+		resp, didTimeout, err = send(areq, rt, deadline)
+		if attempt >= p.maxAttempts() || (err != nil && didTimeout()) || !p.shouldRetry(areq, resp, err) {
+			return resp, didTimeout, err
+		}
+		delay := p.backoff(attempt + 1)
+		if resp != nil {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if d > p.maxDelay() {
+					return resp, didTimeout, err
+				}
+				delay = d
+			}
+		}
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return resp, didTimeout, err
+		}
+		next := req
+		if req.Body != nil && req.Body != NoBody {
+			body, gerr := req.GetBody()
+			if gerr != nil {
+				return resp, didTimeout, err
+			}
+			next = new(Request)
+			*next = *req
+			next.Body = body
+		}
+		if resp != nil {
+			// Drain a small amount of the body so the
+			// connection can be reused, as when following
+			// a redirect.
+			const maxBodySlurpSize = 2 << 10
+			if resp.ContentLength == -1 || resp.ContentLength <= maxBodySlurpSize {
+				io.CopyN(io.Discard, resp.Body, maxBodySlurpSize)
+			}
+			resp.Body.Close()
+		}
+		if trace != nil && trace.Retry != nil {
+			info := httptrace.RetryInfo{
+				Attempt: attempt + 1,
+				Delay:   delay,
+				Err:     err,
+			}
+			if resp != nil {
+				info.StatusCode = resp.StatusCode
+			}
+			trace.Retry(info)
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				next.closeBody()
+				return nil, alwaysFalse, context.Cause(ctx)
+			}
+		}
+		areq = next
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header,
+// which is either a number of seconds or an HTTP-date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		if secs > int64(1<<63-1)/int64(time.Second) {
+			return 1<<63 - 1, true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
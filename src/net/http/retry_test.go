@@ -0,0 +1,291 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	. "net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetryStatus(t *testing.T) {
+	run(t, testClientRetryStatus, []testMode{http1Mode, http2Mode})
+}
+func testClientRetryStatus(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(StatusTooManyRequests)
+		case 3:
+			w.Header().Set("Retry-After", time.Now().Add(-time.Hour).UTC().Format(TimeFormat))
+			w.WriteHeader(StatusServiceUnavailable)
+		default:
+			io.WriteString(w, "ok")
+		}
+	}))
+	cst.c.Retry = &RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}
+
+	var infos []httptrace.RetryInfo
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		Retry: func(info httptrace.RetryInfo) {
+			infos = append(infos, info)
+		},
+	})
+	req, _ := NewRequestWithContext(ctx, "GET", cst.ts.URL, nil)
+	resp, err := cst.c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, _ := io.ReadAll(resp.Body); string(b) != "ok" {
+		t.Errorf("body = %q, want %q", b, "ok")
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("server saw %v requests, want 4", got)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("Retry hook called %v times, want 3", len(infos))
+	}
+	for i, want := range []struct {
+		attempt, status int
+	}{{2, StatusServiceUnavailable}, {3, StatusTooManyRequests}, {4, StatusServiceUnavailable}} {
+		if got := infos[i]; got.Attempt != want.attempt || got.StatusCode != want.status || got.Err != nil {
+			t.Errorf("Retry hook call %v: got %+v, want attempt %v, status %v", i, got, want.attempt, want.status)
+		}
+	}
+	if d := infos[0].Delay; d < time.Millisecond/2 || d > time.Millisecond {
+		t.Errorf("first retry delay = %v, want between 0.5ms and 1ms", d)
+	}
+	if d := infos[1].Delay; d != 0 {
+		t.Errorf("Retry-After: 0 delay = %v, want 0", d)
+	}
+	if d := infos[2].Delay; d != 0 {
+		t.Errorf("Retry-After in the past: delay = %v, want 0", d)
+	}
+}
+
+func TestClientRetryMaxAttempts(t *testing.T) {
+	run(t, testClientRetryMaxAttempts, []testMode{http1Mode})
+}
+func testClientRetryMaxAttempts(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		calls.Add(1)
+		w.WriteHeader(StatusBadGateway)
+		io.WriteString(w, "bad gateway")
+	}))
+	cst.c.Retry = &RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}
+	resp, err := cst.c.Get(cst.ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != StatusBadGateway {
+		t.Errorf("status = %v, want %v", resp.StatusCode, StatusBadGateway)
+	}
+	if b, _ := io.ReadAll(resp.Body); string(b) != "bad gateway" {
+		t.Errorf("body = %q, want %q", b, "bad gateway")
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("server saw %v requests, want 4", got)
+	}
+}
+
+func TestClientRetryAfterTooLong(t *testing.T) {
+	run(t, testClientRetryAfterTooLong, []testMode{http1Mode})
+}
+func testClientRetryAfterTooLong(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(StatusServiceUnavailable)
+	}))
+	cst.c.Retry = &RetryPolicy{MaxDelay: time.Minute}
+	resp, err := cst.c.Get(cst.ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != StatusServiceUnavailable {
+		t.Errorf("status = %v, want %v", resp.StatusCode, StatusServiceUnavailable)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %v requests, want 1", got)
+	}
+}
+
+func TestClientRetryBody(t *testing.T) {
+	run(t, testClientRetryBody, []testMode{http1Mode, http2Mode})
+}
+func testClientRetryBody(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	var bodies []string
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(StatusServiceUnavailable)
+		}
+	}))
+	cst.c.Retry = &RetryPolicy{BaseDelay: time.Millisecond}
+
+	// A POST is not retried without an Idempotency-Key.
+	resp, err := cst.c.Post(cst.ts.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != StatusServiceUnavailable {
+		t.Errorf("POST: status = %v, want %v", resp.StatusCode, StatusServiceUnavailable)
+	}
+
+	calls.Store(0)
+	bodies = nil
+	req, _ := NewRequest("POST", cst.ts.URL, strings.NewReader("body"))
+	req.Header.Set("Idempotency-Key", "1")
+	resp, err = cst.c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != StatusOK {
+		t.Errorf("POST with Idempotency-Key: status = %v, want %v", resp.StatusCode, StatusOK)
+	}
+	if got, want := strings.Join(bodies, ","), "body,body"; got != want {
+		t.Errorf("server saw bodies %q, want %q", got, want)
+	}
+
+	// A body without GetBody cannot be rewound.
+	calls.Store(0)
+	req, _ = NewRequest("PUT", cst.ts.URL, io.NopCloser(strings.NewReader("body")))
+	req.Header.Set("Idempotency-Key", "2")
+	resp, err = cst.c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("PUT without GetBody: server saw %v requests, want 1", got)
+	}
+}
+
+func TestClientRetryConnectionError(t *testing.T) {
+	run(t, testClientRetryConnectionError, []testMode{http1Mode})
+}
+func testClientRetryConnectionError(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		if calls.Add(1) == 1 {
+			conn, _, err := w.(Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	cst.c.Retry = &RetryPolicy{BaseDelay: time.Millisecond}
+	var retryErr error
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		Retry: func(info httptrace.RetryInfo) {
+			retryErr = info.Err
+		},
+	})
+	req, _ := NewRequestWithContext(ctx, "GET", cst.ts.URL, nil)
+	resp, err := cst.c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server saw %v requests, want 2", got)
+	}
+	if retryErr == nil {
+		t.Errorf("Retry hook saw no error from the first attempt")
+	}
+}
+
+func TestClientRetryShouldRetry(t *testing.T) {
+	run(t, testClientRetryShouldRetry, []testMode{http1Mode})
+}
+func testClientRetryShouldRetry(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(StatusInternalServerError)
+		}
+	}))
+	cst.c.Retry = &RetryPolicy{
+		BaseDelay: time.Millisecond,
+		ShouldRetry: func(req *Request, resp *Response, err error) bool {
+			return err == nil && resp.StatusCode >= 500
+		},
+	}
+	resp, err := cst.c.Get(cst.ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != StatusOK {
+		t.Errorf("status = %v, want %v", resp.StatusCode, StatusOK)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server saw %v requests, want 2", got)
+	}
+}
+
+func TestClientRetryCancel(t *testing.T) {
+	run(t, testClientRetryCancel, []testMode{http1Mode})
+}
+func testClientRetryCancel(t *testing.T, mode testMode) {
+	var calls atomic.Int32
+	cst := newClientServerTest(t, mode, HandlerFunc(func(w ResponseWriter, r *Request) {
+		calls.Add(1)
+		w.WriteHeader(StatusServiceUnavailable)
+	}))
+	cst.c.Retry = &RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		Retry: func(httptrace.RetryInfo) {
+			cancel()
+		},
+	})
+	req, _ := NewRequestWithContext(ctx, "GET", cst.ts.URL, nil)
+	_, err := cst.c.Do(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do: %v, want context.Canceled", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %v requests, want 1", got)
+	}
+
+	// A retry which would exceed the Client's Timeout is not made.
+	calls.Store(0)
+	cst.c.Timeout = time.Minute
+	resp, err := cst.c.Get(cst.ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != StatusServiceUnavailable {
+		t.Errorf("status = %v, want %v", resp.StatusCode, StatusServiceUnavailable)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %v requests, want 1", got)
+	}
+}
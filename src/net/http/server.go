@@ -33,6 +33,7 @@ import (
 	_ "unsafe" // for linkname
 
 	"golang.org/x/net/http/httpguts"
+	"golang.org/x/net/quic"
 )
 
 // Errors used by the HTTP server.
@@ -3116,6 +3117,10 @@ type Server struct {
 	// unencrypted HTTP/2 connections. The server can serve both
 	// HTTP/1 and unencrypted HTTP/2 on the same address and port.
 	//
+	// If Protocols includes HTTP3, [Server.ServeTLS] also serves HTTP/3
+	// on the UDP port with the same address as its listener, and responses
+	// sent over HTTP/1 and HTTP/2 advertise it in an Alt-Svc header field.
+	//
 	// If Protocols is nil, the default is usually HTTP/1 and HTTP/2.
 	// If TLSNextProto is non-nil and does not contain an "h2" entry,
 	// the default is HTTP/1 only.
@@ -3127,10 +3132,13 @@ type Server struct {
 	nextProtoOnce     sync.Once // guards setupHTTP2_* init
 	nextProtoErr      error     // result of http2.ConfigureServer if used
 
-	mu         sync.Mutex
-	listeners  map[*net.Listener]struct{}
-	activeConn map[*conn]struct{}
-	onShutdown []func()
+	mu           sync.Mutex
+	listeners    map[*net.Listener]struct{}
+	activeConn   map[*conn]struct{}
+	http3Servers map[*http3server]*http3Handler // started by ServeTLS
+	onShutdown   []func()
+
+	http3AltSvc atomic.Pointer[string] // Alt-Svc advertising HTTP/3, or nil
 
 	listenerGroup sync.WaitGroup
 }
@@ -3162,6 +3170,11 @@ func (s *Server) Close() error {
 		c.rwc.Close()
 		delete(s.activeConn, c)
 	}
+	for s3 := range s.http3Servers {
+		s3.shutdown(http3canceledCtx)
+		delete(s.http3Servers, s3)
+	}
+	s.updateHTTP3AltSvcLocked()
 	return err
 }
 
@@ -3202,6 +3215,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	for _, f := range s.onShutdown {
 		go f()
 	}
+	for s3 := range s.http3Servers {
+		// Send GOAWAY on HTTP/3 connections.
+		// closeIdleConns closes them once their requests are done.
+		go s3.shutdown(ctx)
+	}
 	s.mu.Unlock()
 	s.listenerGroup.Wait()
 
@@ -3266,6 +3284,16 @@ func (s *Server) closeIdleConns() bool {
 		c.rwc.Close()
 		delete(s.activeConn, c)
 	}
+	for s3, h := range s.http3Servers {
+		// HTTP/3 connections may be closed once they have no requests
+		// in progress, since GOAWAY stops them from starting new ones.
+		if h.active.Load() > 0 {
+			quiescent = false
+			continue
+		}
+		s3.shutdown(http3canceledCtx)
+		delete(s.http3Servers, s3)
+	}
 	return quiescent
 }
 
@@ -3355,6 +3383,15 @@ func (sh serverHandler) ServeHTTP(rw ResponseWriter, req *Request) {
 		handler = globalOptionsHandler{}
 	}
 
+	if req.TLS != nil && req.ProtoMajor < 3 {
+		// Advertise HTTP/3 to clients connecting over TLS.
+		if altSvc := sh.srv.http3AltSvc.Load(); altSvc != nil {
+			if h := rw.Header(); !h.has("Alt-Svc") {
+				h.Set("Alt-Svc", *altSvc)
+			}
+		}
+	}
+
 	handler.ServeHTTP(rw, req)
 }
 
@@ -3524,6 +3561,9 @@ func (s *Server) Serve(l net.Listener) error {
 // certFile should be the concatenation of the server's certificate,
 // any intermediates, and the CA's certificate.
 //
+// If s.Protocols includes HTTP3, ServeTLS also serves HTTP/3 on the
+// UDP address matching l's address.
+//
 // ServeTLS always returns a non-nil error. After [Server.Shutdown] or [Server.Close], the
 // returned error is [ErrServerClosed].
 func (s *Server) ServeTLS(l net.Listener, certFile, keyFile string) error {
@@ -3546,10 +3586,106 @@ func (s *Server) ServeTLS(l net.Listener, certFile, keyFile string) error {
 		}
 	}
 
+	if s.protocols().HTTP3() {
+		s3, err := s.listenHTTP3(l.Addr().String(), config)
+		if err != nil {
+			return err
+		}
+		defer s.closeHTTP3(s3)
+	}
+
 	tlsListener := tls.NewListener(l, config)
 	return s.Serve(tlsListener)
 }
 
+// listenHTTP3 starts serving HTTP/3 on the UDP address addr,
+// using the certificates in config.
+func (s *Server) listenHTTP3(addr string, config *tls.Config) (*http3server, error) {
+	config = config.Clone()
+	config.NextProtos = []string{"h3"}
+	h := &http3Handler{srv: s}
+	s3 := &http3server{
+		handler: h,
+		config:  &quic.Config{TLSConfig: config},
+	}
+	if d := s.idleTimeout(); d > 0 {
+		s3.config.MaxIdleTimeout = d
+	}
+	s3.init()
+	e, err := quic.Listen("udp", addr, s3.config)
+	if err != nil {
+		return nil, err
+	}
+	h.port = int(e.LocalAddr().Port())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shuttingDown() {
+		e.Close(http3canceledCtx)
+		return nil, ErrServerClosed
+	}
+	if s.http3Servers == nil {
+		s.http3Servers = make(map[*http3server]*http3Handler)
+	}
+	s.http3Servers[s3] = h
+	s.updateHTTP3AltSvcLocked()
+	go s3.serve(e)
+	return s3, nil
+}
+
+// closeHTTP3 stops an HTTP/3 server started by listenHTTP3,
+// unless Close or Shutdown already did.
+func (s *Server) closeHTTP3(s3 *http3server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.http3Servers[s3]; !ok || s.shuttingDown() {
+		// Shutdown stops the server once it is idle.
+		return
+	}
+	s3.shutdown(http3canceledCtx)
+	delete(s.http3Servers, s3)
+	s.updateHTTP3AltSvcLocked()
+}
+
+// updateHTTP3AltSvcLocked sets the Alt-Svc field value
+// advertising the server's HTTP/3 endpoints.
+func (s *Server) updateHTTP3AltSvcLocked() {
+	var alts []string
+	for _, h := range s.http3Servers {
+		if h.port != 0 {
+			alts = append(alts, fmt.Sprintf(`h3=":%d"; ma=86400`, h.port))
+		}
+	}
+	if len(alts) == 0 {
+		s.http3AltSvc.Store(nil)
+		return
+	}
+	altSvc := strings.Join(alts, ", ")
+	s.http3AltSvc.Store(&altSvc)
+}
+
+// An http3Handler is the handler of an HTTP/3 server started by ServeTLS.
+type http3Handler struct {
+	srv    *Server
+	port   int          // UDP port, for Alt-Svc
+	active atomic.Int64 // requests in progress
+}
+
+func (h *http3Handler) ServeHTTP(rw ResponseWriter, req *Request) {
+	h.active.Add(1)
+	defer h.active.Add(-1)
+	defer func() {
+		if err := recover(); err != nil && err != ErrAbortHandler {
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			h.srv.logf("http: panic serving %v: %v\n%s", req.RemoteAddr, err, buf)
+		}
+	}()
+	ctx := context.WithValue(req.Context(), ServerContextKey, h.srv)
+	serverHandler{h.srv}.ServeHTTP(rw, req.WithContext(ctx))
+}
+
 func (s *Server) protocols() Protocols {
 	if s.Protocols != nil {
 		return *s.Protocols // user-configured set
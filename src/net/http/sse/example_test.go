@@ -0,0 +1,57 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sse_test
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/sse"
+	"strconv"
+	"time"
+)
+
+func Example() {
+	// A server streaming the time, resuming after the last event a
+	// reconnecting client received.
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := 0
+		if id, err := strconv.Atoi(sse.LastEventID(r)); err == nil {
+			n = id + 1
+		}
+		w := sse.NewWriter(rw)
+		defer w.Close()
+		w.SetHeartbeat(15 * time.Second)
+		for ; n < 3; n++ {
+			err := w.Send(sse.Event{
+				ID:   strconv.Itoa(n),
+				Type: "tick",
+				Data: fmt.Sprintf("tick %d", n),
+			})
+			if err != nil {
+				return // The client has gone away.
+			}
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := sse.NewReader(resp.Body)
+	for e := range r.Events() {
+		fmt.Printf("%s %s: %s\n", e.Type, e.ID, e.Data)
+	}
+	if err := r.Err(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// tick 0: tick 0
+	// tick 1: tick 1
+	// tick 2: tick 2
+}
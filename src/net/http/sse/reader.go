@@ -0,0 +1,224 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// A Reader reads server-sent events from an event stream,
+// such as the Body of an [http.Response].
+//
+// To resume a stream after the connection is lost, a client should
+// wait for [Reader.Retry], if set, and then repeat the request with
+// the Last-Event-ID header set to [Reader.LastEventID].
+type Reader struct {
+	// MaxEventSize is the maximum size in bytes of a line of the
+	// stream and of the data of an event. If it is zero, the maximum
+	// is 1 MiB. Reading a longer line or event stops the iteration
+	// with the error ErrEventTooLong.
+	MaxEventSize int
+
+	br  *bufio.Reader
+	err error
+
+	started bool // the byte order mark has been checked for
+	skipLF  bool // the previous line ended in "\r"
+	line    []byte
+
+	lastID string
+	retry  time.Duration
+
+	// The event being read.
+	typ  string
+	data []byte
+}
+
+// ErrEventTooLong is reported by [Reader.Err] when a line or the data
+// of an event is longer than [Reader.MaxEventSize].
+var ErrEventTooLong = errors.New("sse: event too long")
+
+// defaultMaxEventSize is the maximum size of a line or event
+// if Reader.MaxEventSize is zero.
+const defaultMaxEventSize = 1 << 20
+
+// NewReader returns a Reader which reads events from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+// Events returns an iterator over the events in the stream.
+// The iteration stops at the end of the stream or at the first error,
+// which is reported by [Reader.Err]. An incomplete event at the end of
+// the stream is discarded.
+func (r *Reader) Events() iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		for {
+			e, ok := r.next()
+			if !ok || !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the first error encountered while reading events,
+// or nil if the stream ended normally.
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// LastEventID returns the most recent event ID sent by the server.
+func (r *Reader) LastEventID() string {
+	return r.lastID
+}
+
+// Retry returns the most recent reconnection delay sent by the server,
+// or zero if the server has not sent one.
+func (r *Reader) Retry() time.Duration {
+	return r.retry
+}
+
+// maxEventSize returns the maximum size of a line or event.
+func (r *Reader) maxEventSize() int {
+	if r.MaxEventSize > 0 {
+		return r.MaxEventSize
+	}
+	return defaultMaxEventSize
+}
+
+// next reads the next event. It reports false at the end of the
+// stream or on error.
+func (r *Reader) next() (Event, bool) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			r.err = err
+			return Event{}, false
+		}
+		if len(line) == 0 {
+			// A blank line dispatches the event.
+			if e, ok := r.dispatch(); ok {
+				return e, true
+			}
+			continue
+		}
+		if line[0] == ':' {
+			continue // comment
+		}
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			if len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+		switch string(field) {
+		case "event":
+			r.typ = string(value)
+		case "data":
+			if len(r.data)+len(value) > r.maxEventSize() {
+				r.err = ErrEventTooLong
+				return Event{}, false
+			}
+			r.data = append(r.data, value...)
+			r.data = append(r.data, '\n')
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				r.lastID = string(value)
+			}
+		case "retry":
+			if ms, ok := parseRetry(value); ok {
+				r.retry = ms
+			}
+		}
+	}
+}
+
+// dispatch completes the event being read. It reports false if the
+// event has no data, in which case it is not delivered.
+func (r *Reader) dispatch() (Event, bool) {
+	data, typ := r.data, r.typ
+	r.data, r.typ = r.data[:0], ""
+	if len(data) == 0 {
+		return Event{}, false
+	}
+	if typ == "" {
+		typ = "message"
+	}
+	return Event{
+		ID:   r.lastID,
+		Type: typ,
+		Data: string(data[:len(data)-1]),
+	}, true
+}
+
+// parseRetry parses the value of a retry field, which must consist
+// only of ASCII digits giving a number of milliseconds.
+func parseRetry(v []byte) (time.Duration, bool) {
+	if len(v) == 0 {
+		return 0, false
+	}
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	ms, err := strconv.ParseInt(string(v), 10, 64)
+	if err != nil || ms > int64(1<<63-1)/int64(time.Millisecond) {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// readLine reads a line terminated by "\r\n", "\n", or "\r".
+// The returned slice is valid until the next call.
+func (r *Reader) readLine() ([]byte, error) {
+	if !r.started {
+		r.started = true
+		// Skip a UTF-8 byte order mark.
+		if b, err := r.br.Peek(3); err == nil && string(b) == "\xef\xbb\xbf" {
+			r.br.Discard(3)
+		}
+	}
+	r.line = r.line[:0]
+	limit := r.maxEventSize()
+	for {
+		c, err := r.br.ReadByte()
+		if err != nil {
+			// An unterminated line at the end of the stream
+			// can't complete an event, so it is dropped.
+			return nil, err
+		}
+		if r.skipLF {
+			r.skipLF = false
+			if c == '\n' {
+				continue
+			}
+		}
+		switch c {
+		case '\r':
+			// Don't wait for a following "\n", which may not
+			// arrive until the server sends the next event.
+			r.skipLF = true
+			return r.line, nil
+		case '\n':
+			return r.line, nil
+		}
+		if len(r.line) >= limit {
+			return nil, ErrEventTooLong
+		}
+		r.line = append(r.line, c)
+	}
+}
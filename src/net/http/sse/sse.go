@@ -0,0 +1,247 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sse implements server-sent events, a format for streaming
+// events from an HTTP server to a client, as specified in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html.
+//
+// A server sends events with a [Writer]. A client reads them from a
+// response body with a [Reader].
+package sse
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Event is a single server-sent event.
+type Event struct {
+	// ID is the event ID. A client which reconnects after losing the
+	// connection sends the ID of the last event it received in the
+	// Last-Event-ID request header.
+	//
+	// When writing an event, an empty ID sends no ID and the client
+	// keeps the previous one. When reading an event, ID is the most
+	// recent ID sent by the server.
+	ID string
+
+	// Type is the event type. When reading an event without a type,
+	// Type is "message".
+	Type string
+
+	// Data is the event payload. It may contain multiple lines.
+	Data string
+}
+
+// LastEventID returns the ID of the last event received by a client
+// reconnecting to an event stream, or "" if the client does not
+// provide one. A server can use it to resume the stream after that
+// event.
+func LastEventID(r *http.Request) string {
+	return r.Header.Get("Last-Event-ID")
+}
+
+var (
+	errClosed      = errors.New("sse: write after Close")
+	errInvalidID   = errors.New("sse: event ID contains a newline or NUL")
+	errInvalidType = errors.New("sse: event type contains a newline")
+)
+
+// A Writer sends server-sent events on an HTTP response.
+//
+// The methods of a Writer may be called concurrently, but a Writer must
+// not be used after the handler which created it returns.
+type Writer struct {
+	rw http.ResponseWriter
+	rc *http.ResponseController
+
+	mu        sync.Mutex
+	buf       []byte
+	err       error // sticky write or flush error
+	closed    bool
+	heartbeat time.Duration
+	timer     *time.Timer
+}
+
+// NewWriter starts an event stream on w. It sets the Content-Type
+// header to "text/event-stream" and the Cache-Control header to
+// "no-cache", writes a 200 (OK) status, and flushes the response
+// headers to the client. Handlers should set any other headers before
+// calling NewWriter.
+//
+// Each event is flushed to the client as it is sent, using an
+// [http.ResponseController]. Event streams are long-lived, so handlers
+// served by an [http.Server] with a WriteTimeout should extend or clear
+// the write deadline with [http.ResponseController.SetWriteDeadline].
+func NewWriter(w http.ResponseWriter) *Writer {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Del("Content-Length")
+	w.WriteHeader(http.StatusOK)
+	sw := &Writer{
+		rw: w,
+		rc: http.NewResponseController(w),
+	}
+	sw.err = sw.rc.Flush()
+	return sw
+}
+
+// Send sends an event to the client and flushes it.
+//
+// It returns an error if the event's ID or Type contain a line break,
+// or if writing to the response fails. After a write fails, every
+// subsequent call returns the same error.
+func (w *Writer) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n\x00") {
+		return errInvalidID
+	}
+	if strings.ContainsAny(e.Type, "\r\n") {
+		return errInvalidType
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := w.buf[:0]
+	if e.ID != "" {
+		b = appendField(b, "id", e.ID)
+	}
+	if e.Type != "" {
+		b = appendField(b, "event", e.Type)
+	}
+	for line := range lines(e.Data) {
+		b = appendField(b, "data", line)
+	}
+	b = append(b, '\n')
+	w.buf = b
+	return w.writeLocked(b)
+}
+
+// Comment sends a comment, which clients ignore.
+// Comments may be used to keep a connection alive; see [Writer.SetHeartbeat].
+func (w *Writer) Comment(text string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := w.buf[:0]
+	for line := range lines(text) {
+		b = append(b, ':')
+		if line != "" {
+			b = append(b, ' ')
+			b = append(b, line...)
+		}
+		b = append(b, '\n')
+	}
+	w.buf = b
+	return w.writeLocked(b)
+}
+
+// SetRetry tells the client to wait for d before reconnecting if the
+// connection is lost. The duration is sent with millisecond precision.
+func (w *Writer) SetRetry(d time.Duration) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := appendField(w.buf[:0], "retry", strconv.FormatInt(max(d.Milliseconds(), 0), 10))
+	b = append(b, '\n')
+	w.buf = b
+	return w.writeLocked(b)
+}
+
+// SetHeartbeat arranges for an empty comment to be sent whenever
+// no other data has been sent for d. Heartbeats keep proxies and
+// clients from closing an idle connection, and let the server detect
+// a client which has gone away. A d of zero disables heartbeats.
+//
+// A handler which enables heartbeats must call [Writer.Close] before
+// returning.
+func (w *Writer) SetHeartbeat(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.heartbeat = d
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if d > 0 {
+		w.timer = time.AfterFunc(d, w.sendHeartbeat)
+	}
+}
+
+func (w *Writer) sendHeartbeat() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || w.timer == nil || w.err != nil {
+		return
+	}
+	w.writeLocked([]byte(":\n"))
+}
+
+// Close stops sending heartbeats. Calls to other methods after Close
+// return an error. Close does not end the response, which ends when
+// the handler returns.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	return nil
+}
+
+func (w *Writer) writeLocked(b []byte) error {
+	if w.closed {
+		return errClosed
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := w.rw.Write(b); err != nil {
+		w.err = err
+		return err
+	}
+	if err := w.rc.Flush(); err != nil {
+		w.err = err
+		return err
+	}
+	if w.timer != nil {
+		w.timer.Reset(w.heartbeat)
+	}
+	return nil
+}
+
+func appendField(b []byte, name, value string) []byte {
+	b = append(b, name...)
+	b = append(b, ": "...)
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// lines returns an iterator over the lines of s, which may be separated
+// by "\r\n", "\n" or "\r". An empty s has a single empty line.
+func lines(s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for {
+			i := strings.IndexAny(s, "\r\n")
+			if i < 0 {
+				yield(s)
+				return
+			}
+			if !yield(s[:i]) {
+				return
+			}
+			if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+			s = s[i+1:]
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sse
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Length", "10")
+	w := NewWriter(rec)
+	if !rec.Flushed {
+		t.Errorf("NewWriter did not flush the response headers")
+	}
+	for _, e := range []Event{
+		{Data: "hello"},
+		{ID: "1", Type: "update", Data: "line 1\nline 2\r\nline 3\rline 4"},
+		{Data: ""},
+	} {
+		if err := w.Send(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Comment("a comment\nin two lines"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetRetry(1500 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Send(Event{ID: "bad\nid"}); err == nil {
+		t.Errorf("Send with a newline in the ID succeeded")
+	}
+	if err := w.Send(Event{Type: "bad\rtype"}); err == nil {
+		t.Errorf("Send with a newline in the type succeeded")
+	}
+	w.Close()
+	if err := w.Send(Event{Data: "closed"}); err == nil {
+		t.Errorf("Send after Close succeeded")
+	}
+
+	h := rec.Result().Header
+	for _, test := range []struct {
+		name, want string
+	}{
+		{"Content-Type", "text/event-stream"},
+		{"Cache-Control", "no-cache"},
+		{"Content-Length", ""},
+	} {
+		if got := h.Get(test.name); got != test.want {
+			t.Errorf("%v = %q, want %q", test.name, got, test.want)
+		}
+	}
+	const want = "data: hello\n\n" +
+		"id: 1\nevent: update\ndata: line 1\ndata: line 2\ndata: line 3\ndata: line 4\n\n" +
+		"data: \n\n" +
+		": a comment\n: in two lines\n" +
+		"retry: 1500\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func readAll(t *testing.T, r *Reader) []Event {
+	t.Helper()
+	events := slices.Collect(r.Events())
+	if err := r.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	return events
+}
+
+func TestReader(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		stream string
+		want   []Event
+	}{{
+		name:   "empty",
+		stream: "",
+	}, {
+		name:   "data",
+		stream: "data: hello\n\n",
+		want:   []Event{{Type: "message", Data: "hello"}},
+	}, {
+		name:   "multiline",
+		stream: "data: a\ndata:b\ndata:  c\ndata\n\n",
+		want:   []Event{{Type: "message", Data: "a\nb\n c\n"}},
+	}, {
+		name:   "line endings",
+		stream: "\xef\xbb\xbfdata: a\r\ndata: b\rdata: c\n\r\n\rdata: d\r\r",
+		want: []Event{
+			{Type: "message", Data: "a\nb\nc"},
+			{Type: "message", Data: "d"},
+		},
+	}, {
+		name:   "type and id",
+		stream: "event: add\nid: 1\ndata: x\n\ndata: y\n\nid\ndata: z\n\n",
+		want: []Event{
+			{ID: "1", Type: "add", Data: "x"},
+			{ID: "1", Type: "message", Data: "y"},
+			{ID: "", Type: "message", Data: "z"},
+		},
+	}, {
+		name:   "id with NUL",
+		stream: "id: 1\ndata: x\n\nid: 2\x00\ndata: y\n\n",
+		want: []Event{
+			{ID: "1", Type: "message", Data: "x"},
+			{ID: "1", Type: "message", Data: "y"},
+		},
+	}, {
+		name:   "no data",
+		stream: "event: empty\n\n: comment\n\nunknown: field\n\ndata: x\n\n",
+		want:   []Event{{Type: "message", Data: "x"}},
+	}, {
+		name:   "incomplete",
+		stream: "data: x\n\ndata: y\n",
+		want:   []Event{{Type: "message", Data: "x"}},
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []io.Reader{
+				strings.NewReader(test.stream),
+				iotest.OneByteReader(strings.NewReader(test.stream)),
+			} {
+				got := readAll(t, NewReader(r))
+				if !slices.Equal(got, test.want) {
+					t.Errorf("events:\n%q\nwant:\n%q", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderRetry(t *testing.T) {
+	r := NewReader(strings.NewReader("retry: 1000\n\nretry: x\n\nretry: -5\n\nretry\n\nid: 7\nretry: 2500\ndata: x\n\n"))
+	if got := len(readAll(t, r)); got != 1 {
+		t.Errorf("read %v events, want 1", got)
+	}
+	if got, want := r.Retry(), 2500*time.Millisecond; got != want {
+		t.Errorf("Retry() = %v, want %v", got, want)
+	}
+	if got, want := r.LastEventID(), "7"; got != want {
+		t.Errorf("LastEventID() = %q, want %q", got, want)
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(io.MultiReader(strings.NewReader("data: x\n\n"), iotest.ErrReader(iotest.ErrTimeout)))
+	got := slices.Collect(r.Events())
+	if len(got) != 1 {
+		t.Errorf("read %v events, want 1", len(got))
+	}
+	if err := r.Err(); err != iotest.ErrTimeout {
+		t.Errorf("Err() = %v, want %v", err, iotest.ErrTimeout)
+	}
+}
+
+func TestReaderTooLong(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		stream string
+		want   int // number of events read before the error
+		err    error
+	}{
+		{"short", "data: 12345678\n\n", 1, nil},
+		{"line", "data: x\n\ndata: 123456789\n\n", 1, ErrEventTooLong},
+		{"comment", ":" + strings.Repeat("x", 20) + "\ndata: x\n\n", 0, ErrEventTooLong},
+		{"multiline", "data: 12345678\ndata: 12345\n\n", 1, nil},
+		{"event", "data: 12345678\ndata: 123456\n\n", 0, ErrEventTooLong},
+		{"separate", "data: 12345678\n\ndata: 12345678\n\n", 2, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(test.stream))
+			r.MaxEventSize = 14
+			got := slices.Collect(r.Events())
+			if len(got) != test.want {
+				t.Errorf("read %v events, want %v", len(got), test.want)
+			}
+			if err := r.Err(); err != test.err {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestReaderDefaultMaxEventSize(t *testing.T) {
+	data := strings.Repeat("x", 1<<20)
+	r := NewReader(strings.NewReader("data: " + data[:1000] + "\n\ndata: " + data + "\n\n"))
+	if got := len(slices.Collect(r.Events())); got != 1 {
+		t.Errorf("read %v events, want 1", got)
+	}
+	if err := r.Err(); err != ErrEventTooLong {
+		t.Errorf("Err() = %v, want %v", err, ErrEventTooLong)
+	}
+}
+
+// TestServer streams events over HTTP and resumes the stream using
+// the Last-Event-ID header.
+func TestServer(t *testing.T) {
+	const total = 5
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := 0
+		if id := LastEventID(r); id != "" {
+			n, err := strconv.Atoi(id)
+			if err != nil {
+				http.Error(rw, "bad Last-Event-ID", http.StatusBadRequest)
+				return
+			}
+			start = n + 1
+		}
+		w := NewWriter(rw)
+		defer w.Close()
+		w.SetRetry(10 * time.Millisecond)
+		// Disconnect after every three events.
+		for i := start; i < total && i < start+3; i++ {
+			if err := w.Send(Event{ID: strconv.Itoa(i), Data: "event " + strconv.Itoa(i)}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+
+	var got []string
+	lastID := ""
+	for range 3 {
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewReader(resp.Body)
+		for e := range r.Events() {
+			got = append(got, e.ID+":"+e.Data)
+		}
+		resp.Body.Close()
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Retry(), 10*time.Millisecond; got != want {
+			t.Errorf("Retry() = %v, want %v", got, want)
+		}
+		lastID = r.LastEventID()
+	}
+	want := []string{"0:event 0", "1:event 1", "2:event 2", "3:event 3", "4:event 4"}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := NewWriter(rw)
+		defer w.Close()
+		w.SetHeartbeat(time.Millisecond)
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	resp, err := ts.Client().Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	br := bufio.NewReader(resp.Body)
+	for range 3 {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != ":\n" {
+			t.Fatalf("read %q, want heartbeat", line)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"net/textproto"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -36,6 +37,7 @@ import (
 
 	"golang.org/x/net/http/httpguts"
 	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/quic"
 )
 
 // DefaultTransport is the default implementation of [Transport] and is
@@ -107,6 +109,9 @@ type Transport struct {
 	altMu    sync.Mutex   // guards changing altProto only
 	altProto atomic.Value // of nil or map[string]RoundTripper, key is URI scheme
 
+	h3AltMu sync.Mutex
+	h3Alt   map[string]http3Alt // HTTP/3 alternatives learned from Alt-Svc, key is origin host:port
+
 	connsPerHostMu   sync.Mutex
 	connsPerHost     map[connectMethodKey]int
 	connsPerHostWait map[connectMethodKey]wantConnQueue // waiting getConns
@@ -288,8 +293,9 @@ type Transport struct {
 	// nextProtoOnce guards initialization of TLSNextProto and
 	// h2transport (via onceSetNextProtoDefaults)
 	nextProtoOnce      sync.Once
-	h2transport        h2Transport // non-nil if http2 wired up
-	tlsNextProtoWasNil bool        // whether TLSNextProto was nil when the Once fired
+	h2transport        h2Transport     // non-nil if http2 wired up
+	h3transport        *http3transport // non-nil if HTTP/3 is enabled
+	tlsNextProtoWasNil bool            // whether TLSNextProto was nil when the Once fired
 
 	// ForceAttemptHTTP2 controls whether HTTP/2 is enabled when a non-zero
 	// Dial, DialTLS, or DialContext func or TLSClientConfig is provided.
@@ -306,6 +312,13 @@ type Transport struct {
 	// If Protocols includes UnencryptedHTTP2 and does not include HTTP1,
 	// the transport will use unencrypted HTTP/2 for requests for http:// URLs.
 	//
+	// If Protocols includes HTTP3, the transport will send requests for
+	// https:// URLs using HTTP/3 to servers that have advertised HTTP/3
+	// support in an Alt-Svc response header field. If HTTP/3 fails, it
+	// falls back to HTTP/1 or HTTP/2. If Protocols includes HTTP3 and
+	// neither HTTP1 nor HTTP2, the transport sends all requests for
+	// https:// URLs using HTTP/3, and does not use proxies for them.
+	//
 	// If Protocols is nil, the default is usually HTTP/1 only.
 	// If ForceAttemptHTTP2 is true, or if TLSNextProto contains an "h2" entry,
 	// the default is HTTP/1 and HTTP/2.
@@ -400,6 +413,17 @@ var http2client = godebug.New("http2client")
 // It must be called via t.nextProtoOnce.Do.
 func (t *Transport) onceSetNextProtoDefaults() {
 	t.tlsNextProtoWasNil = (t.TLSNextProto == nil)
+	if t.protocols().HTTP3() {
+		config := cloneTLSConfig(t.TLSClientConfig)
+		config.NextProtos = []string{"h3"}
+		t.h3transport = &http3transport{
+			config: http3initConfig(&quic.Config{TLSConfig: config}),
+			listenQUIC: func(addr string, config *quic.Config) (*quic.Endpoint, error) {
+				return quic.Listen("udp", addr, config)
+			},
+			activeConns: make(map[*http3clientConn]struct{}),
+		}
+	}
 	if http2client.Value() == "0" {
 		http2client.IncNonDefault()
 		return
@@ -692,13 +716,19 @@ func (t *Transport) roundTrip(req *Request) (_ *Response, err error) {
 		// to send it requests.
 		pconn, err := t.getConn(treq, cm)
 		if err != nil {
+			if cm.h3 && !t.onlyHTTP3() && ctx.Err() == nil {
+				// Consider the HTTP/3 alternative broken,
+				// and fall back to HTTP/1 or HTTP/2.
+				t.forgetHTTP3Alt(canonicalAddr(req.URL))
+				continue
+			}
 			req.closeBody()
 			return nil, err
 		}
 
 		var resp *Response
 		if pconn.alt != nil {
-			// HTTP/2 path.
+			// HTTP/2 and HTTP/3 path.
 			resp, err = pconn.alt.RoundTrip(req)
 		} else {
 			resp, err = pconn.roundTrip(treq)
@@ -712,6 +742,9 @@ func (t *Transport) roundTrip(req *Request) (_ *Response, err error) {
 				// canceling the context after the response body is read.
 				cancel(errRequestDone)
 			}
+			if t.h3transport != nil && scheme == "https" {
+				t.learnHTTP3Alt(req.URL, resp.Header)
+			}
 			resp.Request = origReq
 			return resp, nil
 		}
@@ -917,6 +950,9 @@ func (t *Transport) CloseIdleConnections() {
 	if t2 := t.h2transport; t2 != nil {
 		t2.CloseIdleConnections()
 	}
+	if t3 := t.h3transport; t3 != nil {
+		t3.CloseIdleConnections()
+	}
 }
 
 // prepareTransportCancel sets up state to convert Transport.CancelRequest into context cancelation.
@@ -988,9 +1024,146 @@ func (t *Transport) connectMethodForRequest(treq *transportRequest) (cm connectM
 		cm.proxyURL, err = t.Proxy(treq.Request)
 	}
 	cm.onlyH1 = treq.requiresHTTP1()
+	if err == nil && t.h3transport != nil && cm.targetScheme == "https" {
+		err = t.setHTTP3ConnectMethod(&cm)
+	}
 	return cm, err
 }
 
+// onlyHTTP3 reports whether the transport is configured to
+// use HTTP/3 for all https requests.
+func (t *Transport) onlyHTTP3() bool {
+	p := t.protocols()
+	return p.HTTP3() && !p.HTTP1() && !p.HTTP2()
+}
+
+// setHTTP3ConnectMethod sets cm to use HTTP/3 when the transport
+// supports only HTTP/3, or when the origin has advertised an HTTP/3
+// alternative. HTTP/3 is not used through proxies.
+func (t *Transport) setHTTP3ConnectMethod(cm *connectMethod) error {
+	if t.onlyHTTP3() {
+		if cm.onlyH1 {
+			return errors.New("http: request requires HTTP/1, but Transport.Protocols is HTTP3 only")
+		}
+		if cm.proxyURL != nil {
+			return errors.New("http: HTTP/3 requests cannot be sent through a proxy")
+		}
+		cm.h3 = true
+		return nil
+	}
+	if cm.onlyH1 || cm.proxyURL != nil {
+		return nil
+	}
+	if port, ok := t.lookupHTTP3Alt(cm.targetAddr); ok {
+		cm.h3 = true
+		cm.targetAddr = net.JoinHostPort(cm.tlsHost(), port)
+	}
+	return nil
+}
+
+// maxHTTP3AltEntries limits the number of origins for which
+// a Transport remembers HTTP/3 alternatives.
+const maxHTTP3AltEntries = 1000
+
+// An http3Alt is an HTTP/3 alternative service for an origin,
+// learned from an Alt-Svc header field (RFC 7838).
+// Only alternatives on the origin's host are used.
+type http3Alt struct {
+	port    string
+	expires time.Time
+}
+
+// lookupHTTP3Alt returns the UDP port of the HTTP/3 alternative for origin.
+func (t *Transport) lookupHTTP3Alt(origin string) (port string, ok bool) {
+	t.h3AltMu.Lock()
+	defer t.h3AltMu.Unlock()
+	alt, ok := t.h3Alt[origin]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(alt.expires) {
+		delete(t.h3Alt, origin)
+		return "", false
+	}
+	return alt.port, true
+}
+
+func (t *Transport) forgetHTTP3Alt(origin string) {
+	t.h3AltMu.Lock()
+	defer t.h3AltMu.Unlock()
+	delete(t.h3Alt, origin)
+}
+
+// learnHTTP3Alt records any HTTP/3 alternative advertised in the
+// Alt-Svc field of a response to a request for u.
+func (t *Transport) learnHTTP3Alt(u *url.URL, h Header) {
+	v := h.Get("Alt-Svc")
+	if v == "" {
+		return
+	}
+	origin := canonicalAddr(u)
+	authority, maxAge, clear, ok := parseHTTP3AltSvc(v)
+	if !ok && !clear {
+		return
+	}
+	t.h3AltMu.Lock()
+	defer t.h3AltMu.Unlock()
+	if clear || maxAge <= 0 {
+		delete(t.h3Alt, origin)
+		return
+	}
+	host, port, err := net.SplitHostPort(authority)
+	if err != nil || host != "" && host != idnaASCIIFromURL(u) {
+		return
+	}
+	if t.h3Alt == nil {
+		t.h3Alt = make(map[string]http3Alt)
+	}
+	if _, ok := t.h3Alt[origin]; !ok && len(t.h3Alt) >= maxHTTP3AltEntries {
+		for k := range t.h3Alt {
+			delete(t.h3Alt, k)
+			break
+		}
+	}
+	t.h3Alt[origin] = http3Alt{
+		port:    port,
+		expires: time.Now().Add(maxAge),
+	}
+}
+
+// parseHTTP3AltSvc parses an Alt-Svc field value (RFC 7838, Section 3),
+// returning the authority and freshness lifetime of the first HTTP/3
+// alternative. It reports clear if the value is "clear".
+func parseHTTP3AltSvc(v string) (authority string, maxAge time.Duration, clear, ok bool) {
+	if textproto.TrimString(v) == "clear" {
+		return "", 0, true, false
+	}
+	for alt := range strings.SplitSeq(v, ",") {
+		params := strings.Split(alt, ";")
+		proto, value, found := strings.Cut(textproto.TrimString(params[0]), "=")
+		if !found || proto != "h3" {
+			continue
+		}
+		authority, err := strconv.Unquote(value)
+		if err != nil {
+			continue
+		}
+		maxAge = 24 * time.Hour
+		for _, p := range params[1:] {
+			name, value, _ := strings.Cut(textproto.TrimString(p), "=")
+			if ascii.EqualFold(name, "ma") {
+				secs, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+				if err != nil || secs < 0 {
+					secs = 0
+				}
+				maxAge = time.Duration(min(secs, int64(1<<62/time.Second))) * time.Second
+			}
+		}
+		return authority, maxAge, false, true
+	}
+	return "", 0, false, false
+}
+
 // proxyAuth returns the Proxy-Authorization header to set
 // on requests, if applicable.
 func (cm *connectMethod) proxyAuth() string {
@@ -1747,6 +1920,9 @@ type erringRoundTripper interface {
 var testHookProxyConnectTimeout = context.WithTimeout
 
 func (t *Transport) dialConn(ctx context.Context, cm connectMethod) (pconn *persistConn, err error) {
+	if cm.h3 {
+		return t.dialHTTP3Conn(ctx, cm)
+	}
 	pconn = &persistConn{
 		t:             t,
 		cacheKey:      cm.key(),
@@ -1959,6 +2135,100 @@ func (t *Transport) dialConn(ctx context.Context, cm connectMethod) (pconn *pers
 	return pconn, nil
 }
 
+// dialHTTP3Conn creates a new HTTP/3 connection for cm.
+func (t *Transport) dialHTTP3Conn(ctx context.Context, cm connectMethod) (*persistConn, error) {
+	cc, err := t.h3transport.dial(ctx, cm.addr())
+	if err != nil {
+		return nil, err
+	}
+	pconn := &persistConn{
+		t:        t,
+		cacheKey: cm.key(),
+		alt:      &http3ClientConn{cc: cc},
+	}
+	go func() {
+		// Like HTTP/2 connections, HTTP/3 connections stay in the idle pool
+		// while in use. Remove them when the QUIC connection closes.
+		cc.qconn.Wait(context.Background())
+		if t.removeIdleConn(pconn) {
+			t.decConnsPerHost(pconn.cacheKey)
+		}
+	}()
+	return pconn, nil
+}
+
+// An http3ClientConn is an HTTP/3 connection in a Transport's
+// connection pool. It counts the requests in progress, so that
+// closing it from the pool does not interrupt them.
+type http3ClientConn struct {
+	cc *http3clientConn
+
+	mu       sync.Mutex
+	inFlight int  // requests whose response body is not closed
+	used     bool // whether any request has been sent
+	closing  bool // close when no requests are in flight
+	closed   bool
+}
+
+func (c *http3ClientConn) RoundTrip(req *Request) (*Response, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		req.closeBody()
+		return nil, errors.New("http: HTTP/3 connection closed")
+	}
+	c.inFlight++
+	c.used = true
+	c.mu.Unlock()
+
+	resp, err := c.cc.RoundTrip(req)
+	if err != nil {
+		c.requestDone()
+		return nil, err
+	}
+	resp.Body = &http3ResponseBody{ReadCloser: resp.Body, c: c}
+	return resp, nil
+}
+
+// closeWhenIdle closes the connection once it has no requests in progress.
+// A connection that was never used stays open for its first request.
+func (c *http3ClientConn) closeWhenIdle() {
+	c.mu.Lock()
+	c.closing = true
+	closeNow := c.used && c.inFlight == 0 && !c.closed
+	c.closed = c.closed || closeNow
+	c.mu.Unlock()
+	if closeNow {
+		c.cc.Close()
+	}
+}
+
+func (c *http3ClientConn) requestDone() {
+	c.mu.Lock()
+	c.inFlight--
+	closeNow := c.closing && c.inFlight == 0 && !c.closed
+	c.closed = c.closed || closeNow
+	c.mu.Unlock()
+	if closeNow {
+		// Don't make Response.Body.Close wait for the peer.
+		go c.cc.Close()
+	}
+}
+
+// An http3ResponseBody is the body of a response received
+// on an http3ClientConn.
+type http3ResponseBody struct {
+	io.ReadCloser
+	c    *http3ClientConn
+	once sync.Once
+}
+
+func (b *http3ResponseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.c.requestDone)
+	return err
+}
+
 // persistConnWriter is the io.Writer written to by pc.bw.
 // It accumulates the number of bytes written to the underlying conn,
 // so the retry logic can determine whether any bytes made it across
@@ -2011,6 +2281,7 @@ type connectMethod struct {
 	// be reused for different targetAddr values.
 	targetAddr string
 	onlyH1     bool // whether to disable HTTP/2 and force HTTP/1
+	h3         bool // whether to use HTTP/3
 }
 
 func (cm *connectMethod) key() connectMethodKey {
@@ -2027,6 +2298,7 @@ func (cm *connectMethod) key() connectMethodKey {
 		scheme: cm.targetScheme,
 		addr:   targetAddr,
 		onlyH1: cm.onlyH1,
+		h3:     cm.h3,
 	}
 }
 
@@ -2062,6 +2334,7 @@ func (cm *connectMethod) tlsHost() string {
 type connectMethodKey struct {
 	proxy, scheme, addr string
 	onlyH1              bool
+	h3                  bool
 }
 
 func (k connectMethodKey) String() string {
@@ -2070,6 +2343,9 @@ func (k connectMethodKey) String() string {
 	if k.onlyH1 {
 		h1 = ",h1"
 	}
+	if k.h3 {
+		h1 = ",h3"
+	}
 	return fmt.Sprintf("%s|%s%s|%s", k.proxy, k.scheme, h1, k.addr)
 }
 
@@ -2931,11 +3207,14 @@ func (pc *persistConn) closeLocked(err error) {
 		pc.t.decConnsPerHost(pc.cacheKey)
 		// Close HTTP/1 (pc.alt == nil) connection.
 		// HTTP/2 closes its connection itself.
+		// HTTP/3 connections close once their requests are done.
 		if pc.alt == nil {
 			if err != errCallerOwnsConn {
 				pc.conn.Close()
 			}
 			close(pc.closech)
+		} else if cc, ok := pc.alt.(*http3ClientConn); ok {
+			cc.closeWhenIdle()
 		}
 	}
 	pc.mutateHeaderFunc = nil
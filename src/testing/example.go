@@ -29,7 +29,7 @@ func RunExamples(matchString func(pat, str string) (bool, error), examples []Int
 func runExamples(matchString func(pat, str string) (bool, error), examples []InternalExample) (ran, ok bool) {
 	ok = true
 
-	m := newMatcher(matchString, *match, "-test.run", *skip)
+	m := newRunMatcher(matchString)
 
 	var eg InternalExample
 	for _, eg = range examples {
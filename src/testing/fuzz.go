@@ -5,6 +5,7 @@
 package testing
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"flag"
@@ -15,6 +16,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +24,7 @@ func initFuzzFlags() {
 	matchFuzz = flag.String("test.fuzz", "", "run the fuzz test matching `regexp`")
 	flag.Var(&fuzzDuration, "test.fuzztime", "time to spend fuzzing; default is to run indefinitely")
 	flag.Var(&minimizeDuration, "test.fuzzminimizetime", "time to spend minimizing a value after finding a failing input")
+	fuzzMerge = flag.String("test.fuzzmerge", "", "instead of fuzzing, add entries from the corpora in the comma-separated `list` of directories to the seed corpus")
 
 	fuzzCacheDir = flag.String("test.fuzzcachedir", "", "directory where interesting fuzzing inputs are stored (for use only by cmd/go)")
 	isFuzzWorker = flag.Bool("test.fuzzworker", false, "coordinate with the parent process to fuzz random values (for use only by cmd/go)")
@@ -31,6 +34,7 @@ var (
 	matchFuzz        *string
 	fuzzDuration     durationOrCountFlag
 	minimizeDuration = durationOrCountFlag{d: 60 * time.Second, allowZero: true}
+	fuzzMerge        *string
 	fuzzCacheDir     *string
 	isFuzzWorker     *bool
 
@@ -79,8 +83,25 @@ type F struct {
 	// from testdata.
 	corpus []corpusEntry
 
+	// dict is the set of dictionary tokens added with F.AddDictionary.
+	dict [][]byte
+
 	result     fuzzResult
 	fuzzCalled bool
+
+	// parallel and fuzzTime are the number of worker processes and the
+	// amount of fuzzing to do when coordinating. They are set from
+	// -test.parallel and -test.fuzztime, and are divided between fuzz tests
+	// when more than one is fuzzed at a time.
+	parallel int
+	fuzzTime durationOrCountFlag
+
+	// log receives progress messages from the fuzzing engine.
+	log io.Writer
+
+	// fuzzCtx, if not nil, is canceled to stop fuzzing early, when another
+	// fuzz test fuzzed at the same time fails.
+	fuzzCtx context.Context
 }
 
 var _ TB = (*F)(nil)
@@ -153,7 +174,7 @@ func (f *F) Skipped() bool {
 func (f *F) Add(args ...any) {
 	var values []any
 	for i := range args {
-		if t := reflect.TypeOf(args[i]); !supportedTypes[t] {
+		if t := reflect.TypeOf(args[i]); t == nil || !isSupportedType(t) {
 			panic(fmt.Sprintf("testing: unsupported type to Add %v", t))
 		}
 		values = append(values, args[i])
@@ -161,6 +182,41 @@ func (f *F) Add(args ...any) {
 	f.corpus = append(f.corpus, corpusEntry{Values: values, IsSeed: true, Path: fmt.Sprintf("seed#%d", len(f.corpus))})
 }
 
+// AddDictionary adds tokens to the dictionary of the fuzz test. While fuzzing,
+// the fuzzing engine inserts dictionary tokens into string and []byte inputs,
+// and overwrites parts of those inputs with them. Integer inputs may also be
+// replaced by tokens of 1, 2, 4 or 8 bytes, read in little-endian order. A
+// dictionary helps the fuzzing engine produce the keywords, magic numbers and
+// delimiters that a parser looks for.
+//
+// The dictionary also includes the tokens in the file
+// testdata/fuzz/<Name>.dict (where <Name> is the name of the fuzz test), if it
+// exists. That file uses the format of AFL and libFuzzer dictionaries: each
+// line holds one token in double quotes, optionally preceded by a name and
+// '=', and blank lines and lines starting with '#' are ignored. Within the
+// quotes, \\, \" and \xHH escapes are allowed. For example:
+//
+//	# JSON keywords
+//	kw_true="true"
+//	"null"
+//
+// AddDictionary must be called before [F.Fuzz], and has no effect unless
+// fuzzing is enabled.
+func (f *F) AddDictionary(tokens ...string) {
+	if f.inFuzzFn {
+		panic("testing: f.AddDictionary was called inside the fuzz target")
+	}
+	if f.fuzzCalled {
+		panic("testing: f.AddDictionary was called after f.Fuzz")
+	}
+	for _, tok := range tokens {
+		if tok == "" {
+			panic("testing: f.AddDictionary was called with an empty token")
+		}
+		f.dict = append(f.dict, []byte(tok))
+	}
+}
+
 // supportedTypes represents all of the supported types which can be fuzzed.
 var supportedTypes = map[reflect.Type]bool{
 	reflect.TypeFor[[]byte]():  true,
@@ -182,6 +238,79 @@ var supportedTypes = map[reflect.Type]bool{
 	reflect.TypeFor[uint64]():  true,
 }
 
+// isSupportedType reports whether values of type t can be fuzzed: either t is
+// one of supportedTypes, or t is a struct, slice, array, map or pointer type
+// built from supported types. Within such composite types, any type whose
+// underlying type is one of supportedTypes is allowed, structs must have
+// only exported fields, and map keys must not hold pointers.
+func isSupportedType(t reflect.Type) bool {
+	if supportedTypes[t] {
+		return true
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		// Slices of bytes are only supported as []byte.
+		return false
+	}
+	return isSupportedComposite(t, make(map[reflect.Type]bool))
+}
+
+// isSupportedComposite reports whether t is a composite type built from
+// supported types. seen records the composite types already being checked,
+// which allows recursive types.
+func isSupportedComposite(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return isSupportedElem(t.Elem(), seen)
+	case reflect.Map:
+		// Map entries are encoded and mutated in key order. Keys holding
+		// pointers are ordered by address, so mutations of such a map
+		// could not be replayed.
+		return !hasPointer(t.Key()) && isSupportedElem(t.Key(), seen) && isSupportedElem(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() || !isSupportedElem(f.Type, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// hasPointer reports whether values of the comparable type t hold pointers.
+func hasPointer(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return true
+	case reflect.Array:
+		return hasPointer(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointer(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSupportedElem reports whether t may be used within a composite type.
+func isSupportedElem(t reflect.Type, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return isSupportedComposite(t, seen)
+}
+
 // Fuzz runs the fuzz function, ff, for fuzz testing. If ff fails for a set of
 // arguments, those arguments will be added to the seed corpus.
 //
@@ -193,6 +322,14 @@ var supportedTypes = map[reflect.Type]bool{
 //
 // The following types are allowed: []byte, string, bool, byte, rune, float32,
 // float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64.
+// Struct, slice, array, map and pointer types built from these types are
+// also allowed, for example:
+//
+//	f.Fuzz(func(t *testing.T, p struct{ X, Y int }, names []string) { ... })
+//
+// Within such types, any type whose underlying type is one of the types listed
+// above may be used, and recursive types are allowed. Structs must not have
+// unexported fields.
 // More types may be supported in the future.
 //
 // ff must not call any [*F] methods, e.g. [F.Log], [F.Error], [F.Skip]. Use
@@ -235,7 +372,7 @@ func (f *F) Fuzz(ff any) {
 	var types []reflect.Type
 	for i := 1; i < fnType.NumIn(); i++ {
 		t := fnType.In(i)
-		if !supportedTypes[t] {
+		if !isSupportedType(t) {
 			panic(fmt.Sprintf("testing: unsupported type for fuzzing %v", t))
 		}
 		types = append(types, t)
@@ -270,6 +407,17 @@ func (f *F) Fuzz(ff any) {
 		f.corpus = append(f.corpus, c...)
 	}
 
+	// Load the dictionary if fuzzing. The coordinator and its workers must
+	// use the same dictionary.
+	var dict [][]byte
+	if f.fstate.mode != seedCorpusOnly {
+		d, err := f.fstate.deps.ReadDictionary(filepath.Join(corpusDir, f.name+".dict"))
+		if err != nil {
+			f.Fatal(err)
+		}
+		dict = append(d, f.dict...)
+	}
+
 	// run calls fn on a given input, as a subtest with its own T.
 	// run is analogous to T.Run. The test filtering and cleanup works similarly.
 	// fn is called in its own goroutine.
@@ -355,16 +503,50 @@ func (f *F) Fuzz(ff any) {
 		// actual fuzzing.
 		corpusTargetDir := filepath.Join(corpusDir, f.name)
 		cacheTargetDir := filepath.Join(*fuzzCacheDir, f.name)
-		err := f.fstate.deps.CoordinateFuzzing(
-			fuzzDuration.d,
-			int64(fuzzDuration.n),
-			minimizeDuration.d,
-			int64(minimizeDuration.n),
-			*parallel,
-			f.corpus,
-			types,
-			corpusTargetDir,
-			cacheTargetDir)
+		var err error
+		if *fuzzMerge != "" {
+			// Instead of fuzzing, add the entries from other corpora that
+			// expand coverage to the seed corpus. Each directory in the list
+			// holds a corpus for each fuzz test, like testdata/fuzz, and
+			// "cache" names the generated corpus.
+			var dirs []string
+			for _, dir := range strings.Split(*fuzzMerge, ",") {
+				if dir == "cache" {
+					dirs = append(dirs, cacheTargetDir)
+				} else {
+					dirs = append(dirs, filepath.Join(dir, f.name))
+				}
+			}
+			err = f.fstate.deps.MergeCorpus(
+				f.name,
+				f.log,
+				minimizeDuration.d,
+				int64(minimizeDuration.n),
+				f.parallel,
+				f.corpus,
+				types,
+				dirs,
+				corpusTargetDir)
+		} else {
+			ctx := f.fuzzCtx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			err = f.fstate.deps.CoordinateFuzzing(
+				ctx,
+				f.name,
+				f.log,
+				f.fuzzTime.d,
+				int64(f.fuzzTime.n),
+				minimizeDuration.d,
+				int64(minimizeDuration.n),
+				f.parallel,
+				f.corpus,
+				types,
+				dict,
+				corpusTargetDir,
+				cacheTargetDir)
+		}
 		if err != nil {
 			f.result = fuzzResult{Error: err}
 			f.Fail()
@@ -382,7 +564,7 @@ func (f *F) Fuzz(ff any) {
 	case fuzzWorker:
 		// Fuzzing is enabled, and this is a worker process. Follow instructions
 		// from the coordinator.
-		if err := f.fstate.deps.RunFuzzWorker(func(e corpusEntry) error {
+		if err := f.fstate.deps.RunFuzzWorker(types, dict, func(e corpusEntry) error {
 			// Don't write to f.w (which points to Stdout) if running from a
 			// fuzz worker. This would become very verbose, particularly during
 			// minimization. Return the error instead, and let the caller deal
@@ -479,7 +661,7 @@ func runFuzzTests(deps testDeps, fuzzTests []InternalFuzzTarget, deadline time.T
 	if len(fuzzTests) == 0 || *isFuzzWorker {
 		return ran, ok
 	}
-	m := newMatcher(deps.MatchString, *match, "-test.run", *skip)
+	m := newRunMatcher(deps.MatchString)
 	var mFuzz *matcher
 	if *matchFuzz != "" {
 		mFuzz = newMatcher(deps.MatchString, *matchFuzz, "-test.fuzz", *skip)
@@ -553,9 +735,15 @@ func runFuzzTests(deps testDeps, fuzzTests []InternalFuzzTarget, deadline time.T
 	return ran, ok
 }
 
-// runFuzzing runs the fuzz test matching the pattern for -fuzz. Only one such
-// fuzz test must match. This will run the fuzzing engine to generate and
-// mutate new inputs against the fuzz target.
+// runFuzzing runs the fuzz tests matching the pattern for -fuzz. This will
+// run the fuzzing engine to generate and mutate new inputs against each fuzz
+// target.
+//
+// When more than one fuzz test matches, the fuzz tests are fuzzed
+// concurrently, and the -parallel worker processes and the -fuzztime
+// duration are divided between them; see scheduleFuzzing. A fuzz worker
+// process only ever runs the one fuzz test named by its coordinator. With
+// -fuzzmerge, the corpus of each fuzz test is merged instead, one at a time.
 //
 // If fuzzing is disabled (-test.fuzz is not set), runFuzzing
 // returns immediately.
@@ -579,8 +767,7 @@ func runFuzzing(deps testDeps, fuzzTests []InternalFuzzTarget) (ok bool) {
 	if Verbose() && !*isFuzzWorker {
 		root.chatty = newChattyPrinter(root.w)
 	}
-	var fuzzTest *InternalFuzzTarget
-	var testName string
+	var targets []*InternalFuzzTarget
 	var matched []string
 	for i := range fuzzTests {
 		name, ok, _ := tstate.match.fullName(nil, fuzzTests[i].Name)
@@ -588,39 +775,64 @@ func runFuzzing(deps testDeps, fuzzTests []InternalFuzzTarget) (ok bool) {
 			continue
 		}
 		matched = append(matched, name)
-		fuzzTest = &fuzzTests[i]
-		testName = name
+		targets = append(targets, &fuzzTests[i])
 	}
 	if len(matched) == 0 {
 		fmt.Fprintln(os.Stderr, "testing: warning: no fuzz tests to fuzz")
 		return true
 	}
-	if len(matched) > 1 {
+	if len(matched) > 1 && *isFuzzWorker {
 		fmt.Fprintf(os.Stderr, "testing: will not fuzz, -fuzz matches more than one fuzz test: %v\n", matched)
 		return false
 	}
 
-	ctx, cancelCtx := context.WithCancel(context.Background())
-	f := &F{
-		common: common{
-			signal:    make(chan bool),
-			barrier:   nil, // T.Parallel has no effect when fuzzing.
-			name:      testName,
-			parent:    &root,
-			level:     root.level + 1,
-			chatty:    root.chatty,
-			ctx:       ctx,
-			cancelCtx: cancelCtx,
-		},
-		fstate: fstate,
-		tstate: tstate,
-	}
-	f.w = indenter{&f.common}
-	f.setOutputWriter()
+	fs := make([]*F, len(matched))
+	for i, name := range matched {
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		f := &F{
+			common: common{
+				signal:    make(chan bool),
+				barrier:   nil, // T.Parallel has no effect when fuzzing.
+				name:      name,
+				parent:    &root,
+				level:     root.level + 1,
+				chatty:    root.chatty,
+				ctx:       ctx,
+				cancelCtx: cancelCtx,
+			},
+			fstate:   fstate,
+			tstate:   tstate,
+			parallel: *parallel,
+			fuzzTime: fuzzDuration,
+			log:      os.Stderr,
+		}
+		f.w = indenter{&f.common}
+		f.setOutputWriter()
+		fs[i] = f
+	}
+	if len(fs) == 1 {
+		return runFuzzTest(fs[0], targets[0].Fn)
+	}
+	if *fuzzMerge != "" {
+		// Merging a corpus takes a bounded amount of time, so merge the
+		// corpus of each fuzz test in turn, using all of the workers.
+		ok = true
+		for i, f := range fs {
+			if !runFuzzTest(f, targets[i].Fn) {
+				ok = false
+			}
+		}
+		return ok
+	}
+	return scheduleFuzzing(fs, targets)
+}
+
+// runFuzzTest runs the fuzz test f with body fn and reports whether it passed.
+func runFuzzTest(f *F, fn func(*F)) bool {
 	if f.chatty != nil {
 		f.chatty.Updatef(f.name, "=== RUN   %s\n", f.name)
 	}
-	go fRunner(f, fuzzTest.Fn)
+	go fRunner(f, fn)
 	<-f.signal
 	if f.chatty != nil {
 		f.chatty.Updatef(f.parent.name, "=== NAME  %s\n", f.parent.name)
@@ -628,6 +840,139 @@ func runFuzzing(deps testDeps, fuzzTests []InternalFuzzTarget) (ok bool) {
 	return !f.failed
 }
 
+// scheduleFuzzing fuzzes several fuzz tests concurrently.
+//
+// At most -parallel fuzz tests are fuzzed at a time, and each running fuzz
+// test gets an equal share of the -parallel worker processes, but at least
+// one. A -fuzztime duration is the total time to spend fuzzing: it is divided
+// by the number of rounds needed to fuzz every test, so that each fuzz test
+// is fuzzed for the same amount of time. A -fuzztime count applies to each
+// fuzz test. Without -fuzztime, fuzzing continues until a failure or
+// interruption, so all of the fuzz tests are fuzzed at once.
+//
+// As when fuzzing a single fuzz test, fuzzing stops at the first failure:
+// the other fuzz tests stop fuzzing, and those not yet started are skipped.
+//
+// Progress messages from the fuzzing engine are prefixed with the name of
+// the fuzz test, and a combined report is printed periodically and once all
+// fuzz tests are done.
+func scheduleFuzzing(fs []*F, targets []*InternalFuzzTarget) (ok bool) {
+	n := len(fs)
+	concurrency := min(n, *parallel)
+	if fuzzDuration.d == 0 && fuzzDuration.n == 0 {
+		concurrency = n
+	}
+	rounds := (n + concurrency - 1) / concurrency
+	workers := max(1, *parallel/concurrency)
+
+	p := &fuzzProgress{
+		start: time.Now(),
+		total: n,
+		w:     os.Stderr,
+	}
+	fmt.Fprintf(p.w, "fuzz: fuzzing %d fuzz tests, %d at a time with %d workers each\n", n, concurrency, workers)
+
+	stop := make(chan struct{})
+	go p.report(stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, f := range fs {
+		f.parallel = workers
+		f.fuzzTime.d = fuzzDuration.d / time.Duration(rounds)
+		f.log = &prefixWriter{prefix: f.name + ": ", w: p.w}
+		f.fuzzCtx = ctx
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		p.update(1, 0, false)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failed := !runFuzzTest(f, targets[i].Fn)
+			if failed {
+				cancel()
+			}
+			p.update(-1, 1, failed)
+			<-sem
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	return p.log()
+}
+
+// fuzzProgress tracks the fuzz tests being fuzzed by scheduleFuzzing.
+type fuzzProgress struct {
+	start time.Time
+	total int
+	w     io.Writer
+
+	mu      sync.Mutex
+	running int
+	done    int
+	failed  int
+}
+
+func (p *fuzzProgress) update(running, done int, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running += running
+	p.done += done
+	if failed {
+		p.failed++
+	}
+}
+
+// report logs the combined progress every few seconds until stop is closed.
+func (p *fuzzProgress) report(stop <-chan struct{}) {
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.log()
+		}
+	}
+}
+
+// log logs the combined progress and reports whether no fuzz test has failed
+// so far.
+func (p *fuzzProgress) log() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	elapsed := time.Since(p.start).Round(time.Second)
+	fmt.Fprintf(p.w, "fuzz: elapsed: %s, fuzz tests: %d running, %d/%d completed, %d failed\n", elapsed, p.running, p.done, p.total, p.failed)
+	return p.failed == 0
+}
+
+// prefixWriter writes to w, adding prefix to the start of each line.
+// Each call to Write is expected to contain whole lines, as the messages
+// of the fuzzing engine do, so that lines from different fuzz tests are not
+// interleaved.
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+}
+
+func (pw *prefixWriter) Write(b []byte) (int, error) {
+	var buf bytes.Buffer
+	for line := range bytes.Lines(b) {
+		buf.WriteString(pw.prefix)
+		buf.Write(line)
+	}
+	if _, err := pw.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
 // fRunner wraps a call to a fuzz test and ensures that cleanup functions are
 // called and status flags are set. fRunner should be called in its own
 // goroutine. To wait for its completion, receive from f.signal.
@@ -0,0 +1,83 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"internal/diff"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Golden compares got with the contents of the test's golden file,
+// testdata/<test name>.golden, and reports an error showing the
+// difference between them if they differ. For a subtest, each element
+// of the test name is a directory: the golden file of TestX/sub is
+// testdata/TestX/sub.golden.
+//
+// A test that checks more than one output calls Golden once for each.
+// The second call compares with testdata/<test name>.2.golden, the third
+// with testdata/<test name>.3.golden, and so on.
+//
+// When the -update-golden flag is provided, Golden instead writes got to
+// the golden file, creating it and its directory if needed.
+//
+// Because the golden file is read with the os package, it is one of the
+// files the go command records as an input of a test when caching test
+// results; running tests with -update-golden disables caching.
+func (c *common) Golden(got []byte) {
+	c.checkFuzzFn("Golden")
+	c.Helper()
+
+	c.mu.Lock()
+	c.goldenCalls++
+	n := c.goldenCalls
+	c.mu.Unlock()
+	name := c.name
+	if n > 1 {
+		name += "." + strconv.Itoa(n)
+	}
+
+	file, err := filepath.Localize(name + ".golden")
+	if err != nil {
+		c.Fatalf("Golden: test name %q cannot be used as a file name", c.name)
+	}
+	file = filepath.Join("testdata", file)
+
+	want, err := os.ReadFile(file)
+	if *updateGolden {
+		if err == nil && bytes.Equal(want, got) {
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o777); err != nil {
+			c.Fatalf("Golden: %v", err)
+		}
+		if err := os.WriteFile(file, got, 0o666); err != nil {
+			c.Fatalf("Golden: %v", err)
+		}
+		c.Logf("Golden: updated %s", file)
+		return
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		c.Errorf("Golden: %s does not exist; run with -update-golden to create it", file)
+		return
+	}
+	if err != nil {
+		c.Fatalf("Golden: %v", err)
+	}
+	if d := diff.Diff(file, want, "got", got); d != nil {
+		c.Errorf("Golden: got does not match %s; run with -update-golden to update it:\n%s", file, d)
+	}
+}
+
+// GoldenString is like Golden, but for a string.
+func (c *common) GoldenString(got string) {
+	c.checkFuzzFn("GoldenString")
+	c.Helper()
+	c.Golden([]byte(got))
+}
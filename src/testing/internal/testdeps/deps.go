@@ -143,6 +143,9 @@ func (TestDeps) SetPanicOnExit0(v bool) {
 }
 
 func (TestDeps) CoordinateFuzzing(
+	ctx context.Context,
+	name string,
+	log io.Writer,
 	timeout time.Duration,
 	limit int64,
 	minimizeTimeout time.Duration,
@@ -150,15 +153,17 @@ func (TestDeps) CoordinateFuzzing(
 	parallel int,
 	seed []fuzz.CorpusEntry,
 	types []reflect.Type,
+	dict [][]byte,
 	corpusDir,
 	cacheDir string) (err error) {
-	// Fuzzing may be interrupted with a timeout or if the user presses ^C.
-	// In either case, we'll stop worker processes gracefully and save
-	// crashers and interesting values.
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	// Fuzzing may be interrupted with a timeout, if the user presses ^C,
+	// or by canceling ctx. In each case, we'll stop worker processes
+	// gracefully and save crashers and interesting values.
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
 	defer cancel()
 	err = fuzz.CoordinateFuzzing(ctx, fuzz.CoordinateFuzzingOpts{
-		Log:             os.Stderr,
+		Name:            name,
+		Log:             log,
 		Timeout:         timeout,
 		Limit:           limit,
 		MinimizeTimeout: minimizeTimeout,
@@ -166,6 +171,7 @@ func (TestDeps) CoordinateFuzzing(
 		Parallel:        parallel,
 		Seed:            seed,
 		Types:           types,
+		Dictionary:      dict,
 		CorpusDir:       corpusDir,
 		CacheDir:        cacheDir,
 	})
@@ -175,7 +181,38 @@ func (TestDeps) CoordinateFuzzing(
 	return err
 }
 
-func (TestDeps) RunFuzzWorker(fn func(fuzz.CorpusEntry) error) error {
+func (TestDeps) MergeCorpus(
+	name string,
+	log io.Writer,
+	minimizeTimeout time.Duration,
+	minimizeLimit int64,
+	parallel int,
+	seed []fuzz.CorpusEntry,
+	types []reflect.Type,
+	dirs []string,
+	corpusDir string) (err error) {
+	// Merging may be interrupted if the user presses ^C. Stop worker
+	// processes gracefully, leaving the seed corpus unchanged.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	err = fuzz.MergeCorpus(ctx, fuzz.MergeCorpusOpts{
+		Name:            name,
+		Log:             log,
+		MinimizeTimeout: minimizeTimeout,
+		MinimizeLimit:   minimizeLimit,
+		Parallel:        parallel,
+		Seed:            seed,
+		Types:           types,
+		Dirs:            dirs,
+		CorpusDir:       corpusDir,
+	})
+	if err == ctx.Err() {
+		return nil
+	}
+	return err
+}
+
+func (TestDeps) RunFuzzWorker(types []reflect.Type, dict [][]byte, fn func(fuzz.CorpusEntry) error) error {
 	// Worker processes may or may not receive a signal when the user presses ^C
 	// On POSIX operating systems, a signal sent to a process group is delivered
 	// to all processes in that group. This is not the case on Windows.
@@ -184,7 +221,7 @@ func (TestDeps) RunFuzzWorker(fn func(fuzz.CorpusEntry) error) error {
 	// process to stop by closing its "fuzz_in" pipe.
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
-	err := fuzz.RunFuzzWorker(ctx, fn)
+	err := fuzz.RunFuzzWorker(ctx, types, dict, fn)
 	if err == ctx.Err() {
 		return nil
 	}
@@ -199,6 +236,10 @@ func (TestDeps) CheckCorpus(vals []any, types []reflect.Type) error {
 	return fuzz.CheckCorpus(vals, types)
 }
 
+func (TestDeps) ReadDictionary(path string) ([][]byte, error) {
+	return fuzz.ReadDictionary(path)
+}
+
 func (TestDeps) ResetCoverage() {
 	fuzz.ResetCoverage()
 }
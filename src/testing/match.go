@@ -16,6 +16,7 @@ import (
 type matcher struct {
 	filter    filterMatch
 	skip      filterMatch
+	shard     shardFlag // selects top-level names; the zero value selects all
 	matchFunc func(pat, str string) (bool, error)
 
 	mu sync.Mutex
@@ -80,6 +81,14 @@ func newMatcher(matchString func(pat, str string) (bool, error), patterns, name,
 	}
 }
 
+// newRunMatcher returns the matcher for the tests, examples, and fuzz tests
+// selected by the -test.run, -test.skip, and -test.shard flags.
+func newRunMatcher(matchString func(pat, str string) (bool, error)) *matcher {
+	m := newMatcher(matchString, *match, "-test.run", *skip)
+	m.shard = shard
+	return m
+}
+
 func (m *matcher) fullName(c *common, subname string) (name string, ok, partial bool) {
 	name = subname
 
@@ -110,9 +119,56 @@ func (m *matcher) fullName(c *common, subname string) (name string, ok, partial
 		return name, false, false
 	}
 
+	// The top-level test must belong to the shard.
+	// Subtests are in the shard of their top-level test.
+	if (c == nil || c.level == 0) && !m.shard.contains(elem[0]) {
+		return name, false, false
+	}
+
 	return name, ok, partial
 }
 
+// shardFlag implements the -test.shard flag, which splits the top-level
+// tests into n shards, numbered from 1, and selects shard i of them.
+// A test's shard is determined by a hash of its name, so that it does not
+// depend on the other tests in the package or on -test.run and -test.skip.
+type shardFlag struct {
+	i, n int
+}
+
+func (f *shardFlag) String() string {
+	if f.n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", f.i, f.n)
+}
+
+func (f *shardFlag) Set(value string) error {
+	is, ns, ok := strings.Cut(value, "/")
+	i, err1 := strconv.Atoi(is)
+	n, err2 := strconv.Atoi(ns)
+	if !ok || err1 != nil || err2 != nil || i < 1 || i > n {
+		return fmt.Errorf("shard must be of the form i/n, with 1 <= i <= n")
+	}
+	*f = shardFlag{i: i, n: n}
+	return nil
+}
+
+// contains reports whether the top-level test with the given name is in the
+// shard.
+func (f *shardFlag) contains(name string) bool {
+	if f.n <= 1 {
+		return true
+	}
+	// 32-bit FNV-1a.
+	h := uint32(2166136261)
+	for i := 0; i < len(name); i++ {
+		h ^= uint32(name[i])
+		h *= 16777619
+	}
+	return int(h%uint32(f.n)) == f.i-1
+}
+
 // clearSubNames clears the matcher's internal state, potentially freeing
 // memory. After this is called, T.Name may return the same strings as it did
 // for earlier subtests.
@@ -122,6 +178,20 @@ func (m *matcher) clearSubNames() {
 	clear(m.subNames)
 }
 
+// clearSubNamesOf forgets the names of the subtests of the named test, so
+// that T.Name returns the same strings for the subtests of a new attempt at
+// running that test as it did for those of earlier attempts.
+func (m *matcher) clearSubNamesOf(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := name + "/"
+	for sub := range m.subNames {
+		if strings.HasPrefix(sub, prefix) {
+			delete(m.subNames, sub)
+		}
+	}
+}
+
 func (m simpleMatch) matches(name []string, matchString func(pat, str string) (bool, error)) (ok, partial bool) {
 	for i, s := range name {
 		if i >= len(m) {
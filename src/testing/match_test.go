@@ -174,6 +174,71 @@ func TestMatcher(t *T) {
 	}
 }
 
+func TestShard(t *T) {
+	for _, s := range []string{"", "1", "0/3", "4/3", "1/0", "a/3", "1/3/5", "-1/3"} {
+		var f shardFlag
+		if err := f.Set(s); err == nil {
+			t.Errorf("shardFlag.Set(%q) succeeded, want error", s)
+		}
+	}
+
+	const n = 3
+	counts := make(map[string]int)
+	for i := 1; i <= n; i++ {
+		m := newMatcher(regexp.MatchString, "", "-test.run", "")
+		if err := m.shard.Set(fmt.Sprintf("%d/%d", i, n)); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.shard.String(); got != fmt.Sprintf("%d/%d", i, n) {
+			t.Errorf("shardFlag.String() = %q", got)
+		}
+		ran := 0
+		for j := range 30 {
+			name := fmt.Sprintf("Test%d", j)
+			if _, ok, _ := m.fullName(nil, name); !ok {
+				continue
+			}
+			counts[name]++
+			ran++
+
+			// Subtests and seed corpus entries are in the shard of their
+			// top-level test.
+			parent := &common{name: name, level: 1}
+			if _, ok, _ := m.fullName(parent, "sub"); !ok {
+				t.Errorf("shard %d/%d: subtest of %s does not match", i, n, name)
+			}
+			if _, ok, _ := m.fullName(nil, name+"/seed#0"); !ok {
+				t.Errorf("shard %d/%d: %s/seed#0 does not match", i, n, name)
+			}
+		}
+		if ran == 0 {
+			t.Errorf("shard %d/%d: no tests matched", i, n)
+		}
+	}
+	for j := range 30 {
+		name := fmt.Sprintf("Test%d", j)
+		if counts[name] != 1 {
+			t.Errorf("%s matched in %d shards, want 1", name, counts[name])
+		}
+	}
+
+	// -test.run and -test.skip still apply within a shard.
+	m := newMatcher(regexp.MatchString, "Test1", "-test.run", "Test12")
+	m.shard = shardFlag{i: 1, n: 1}
+	for _, tc := range []struct {
+		name string
+		ok   bool
+	}{
+		{"Test1", true},
+		{"Test12", false},
+		{"Test2", false},
+	} {
+		if _, ok, _ := m.fullName(nil, tc.name); ok != tc.ok {
+			t.Errorf("fullName(%q) = %v, want %v", tc.name, ok, tc.ok)
+		}
+	}
+}
+
 var namingTestCases = []struct{ name, want string }{
 	// Uniqueness
 	{"", "x/#00"},
@@ -256,7 +256,10 @@
 // supported platforms, 'go test' compiles the test executable with fuzzing
 // coverage instrumentation. The fuzzing engine uses that instrumentation to
 // find and cache inputs that expand coverage, increasing the likelihood of
-// finding bugs. If the fuzz target fails for a given input, the fuzzing engine
+// finding bugs. It also records the operands of comparisons made by the code
+// being fuzzed, and uses them, along with any tokens in the fuzz test's
+// dictionary (see [F.AddDictionary]), to build new inputs. If the fuzz target
+// fails for a given input, the fuzzing engine
 // writes the inputs that caused the failure to a file in the directory
 // testdata/fuzz/<Name> within the package directory. This file later serves as
 // a seed input. If the file can't be written at that location (for example,
@@ -297,6 +300,21 @@
 //	    })
 //	}
 //
+// # Golden Files
+//
+// A golden file holds the expected output of a test. The [T.Golden] and
+// [T.GoldenString] methods compare output with the golden file of the test,
+// testdata/<Name>.golden, and report any difference. A test that checks
+// several outputs calls them once for each:
+//
+//	func TestFormat(t *testing.T) {
+//	    t.GoldenString(Format(input))      // testdata/TestFormat.golden
+//	    t.GoldenString(Format(otherInput)) // testdata/TestFormat.2.golden
+//	}
+//
+// Running "go test -update-golden" writes the golden files instead,
+// after which the difference can be reviewed with version control.
+//
 // # Subtests and Sub-benchmarks
 //
 // The [T.Run] and [B.Run] methods allow defining subtests and sub-benchmarks,
@@ -459,6 +477,7 @@ func Init() {
 	// Report as tests are run; default is silent for success.
 	flag.Var(&chatty, "test.v", "verbose: print additional output")
 	count = flag.Uint("test.count", 1, "run tests and benchmarks `n` times")
+	retry = flag.Uint("test.retry", 0, "retry failing tests up to `n` times")
 	coverProfile = flag.String("test.coverprofile", "", "write a coverage profile to `file`")
 	gocoverdir = flag.String("test.gocoverdir", "", "write coverage intermediate files to this directory")
 	matchList = flag.String("test.list", "", "list tests, examples, and benchmarks matching `regexp` then exit")
@@ -478,7 +497,10 @@ func Init() {
 	parallel = flag.Int("test.parallel", runtime.GOMAXPROCS(0), "run at most `n` tests in parallel")
 	testlog = flag.String("test.testlogfile", "", "write test action log to `file` (for use only by cmd/go)")
 	shuffle = flag.String("test.shuffle", "off", "randomize the execution order of tests and benchmarks")
+	flag.Var(&shard, "test.shard", "run only the top-level tests in shard `i/n`, one of n shards")
 	fullPath = flag.Bool("test.fullpath", false, "show full file names in error messages")
+	testTimeout = flag.Duration("test.testtimeout", 0, "fail each test that runs longer than `d` (default 0, timeout disabled)")
+	updateGolden = flag.Bool("test.update-golden", false, "write golden files instead of comparing with them")
 
 	initBenchmarkFlags()
 	initFuzzFlags()
@@ -492,6 +514,7 @@ var (
 	artifacts            *bool
 	chatty               chattyFlag
 	count                *uint
+	retry                *uint
 	coverProfile         *string
 	gocoverdir           *string
 	matchList            *string
@@ -507,11 +530,14 @@ var (
 	panicOnExit0         *bool
 	traceFile            *string
 	timeout              *time.Duration
+	testTimeout          *time.Duration
 	cpuListStr           *string
 	parallel             *int
 	shuffle              *string
+	shard                shardFlag
 	testlog              *string
 	fullPath             *bool
+	updateGolden         *bool
 
 	haveExamples bool // are there examples?
 
@@ -646,6 +672,8 @@ type common struct {
 	cleanupPc   []uintptr            // The stack trace at the point where Cleanup was called.
 	finished    bool                 // Test function has completed.
 	inFuzzFn    bool                 // Whether the fuzz target, if this is one, is running.
+	flaky       bool                 // Test is known to be flaky; see T.Flaky.
+	goldenCalls int                  // Number of calls to Golden.
 	isSynctest  bool
 
 	chatty         *chattyPrinter // A copy of chattyPrinter, if the chatty flag is set.
@@ -654,6 +682,7 @@ type common struct {
 	cleanupStarted atomic.Bool    // Registered cleanup callbacks have started to execute
 	runner         string         // Function name of tRunner running the test.
 	isParallel     bool           // Whether the test is parallel.
+	topLevel       bool           // Whether the test is a top-level test, which may be retried.
 
 	parent     *common
 	level      int       // Nesting depth of test or benchmark.
@@ -679,6 +708,17 @@ type common struct {
 	artifactDir     string
 	artifactDirErr  error
 
+	timeoutMu    sync.Mutex    // guards this group of fields
+	timeout      time.Duration // Per-test timeout; see T.SetTimeout.
+	timeoutTimer *time.Timer
+	timeoutSeq   int       // Incremented whenever the timer changes, to ignore stale timers.
+	timeoutGoid  uint64    // ID of the test goroutine.
+	testDeadline time.Time // When the timer will fire.
+	exited       bool      // Test function has exited.
+	abandoned    bool      // Test timed out and was abandoned.
+
+	hasAbandoned atomic.Bool // Top-level test or one of its subtests was abandoned.
+
 	ctx       context.Context
 	cancelCtx context.CancelFunc
 }
@@ -934,6 +974,8 @@ var (
 type T struct {
 	common
 	denyParallel bool
+	attempt      int        // Number of earlier attempts at running a retried test.
+	serialRetry  bool       // Retry of an attempt which did not call Parallel.
 	tstate       *testState // For running tests and subtests.
 }
 
@@ -959,7 +1001,9 @@ func (c *common) setRan() {
 
 // Fail marks the function as having failed but continues execution.
 func (c *common) Fail() {
-	if c.parent != nil {
+	// The failure of a top-level test is passed on to its parent only once
+	// the test has finished, as the test may still pass when retried.
+	if c.parent != nil && !c.topLevel {
 		c.parent.Fail()
 	}
 	c.mu.Lock()
@@ -1776,8 +1820,27 @@ func (t *T) Parallel() {
 
 	// We don't want to include the time we spend waiting for serial tests
 	// in the test duration. Record the elapsed time thus far and reset the
-	// timer afterwards.
+	// timer afterwards. The same goes for the per-test timeout.
 	t.duration += highPrecisionTimeSince(t.start)
+	t.pauseTimeout()
+	defer t.restartTimeout()
+
+	if t.attempt > 0 {
+		// A test is retried once its previous attempt has finished, after
+		// the parent has already released its parallel subtests.
+		// There is nothing to wait for but a free slot.
+		if t.serialRetry {
+			// The previous attempt ran sequentially, and this attempt
+			// still holds the count for sequential tests. Release it,
+			// as a parent does before running its parallel subtests;
+			// retry reacquires it once this attempt has finished.
+			t.tstate.release()
+		}
+		t.tstate.waitParallel()
+		parallelStart.Add(1)
+		t.start = highPrecisionTimeNow()
+		return
+	}
 
 	// Add to the list of tests to be released by the parent.
 	t.parent.sub = append(t.parent.sub, t)
@@ -1857,6 +1920,27 @@ func (t *T) Chdir(dir string) {
 	t.common.Chdir(dir)
 }
 
+// Flaky marks the test as known to be flaky. If a flaky test fails, it is
+// retried as though the -test.retry flag were at least 1. As with -test.retry,
+// a test that passes when retried is reported as flaky and does not cause the
+// test binary to fail, while a test that fails every attempt is reported as
+// failing.
+//
+// Calling Flaky from a subtest marks the top-level test that contains it.
+// Flaky has no effect in tests run by a fuzz test.
+func (t *T) Flaky() {
+	c := &t.common
+	for !c.topLevel {
+		if c.parent == nil {
+			return
+		}
+		c = c.parent
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flaky = true
+}
+
 // InternalTest is an internal type but exported because it is cross-package;
 // it is part of the implementation of the "go test" command.
 type InternalTest struct {
@@ -1874,10 +1958,19 @@ func tRunner(t *T, fn func(t *T)) {
 	// a call to runtime.Goexit, record the duration and send
 	// a signal saying that the test is done.
 	defer func() {
+		if t.stopTimeout() {
+			// The test timed out, and its result was reported
+			// when it was abandoned. It can no longer fail,
+			// so discard any panic rather than crash the binary.
+			recover()
+			return
+		}
 		t.checkRaces()
 
 		// TODO(#61034): This is the wrong place for this check.
-		if t.Failed() {
+		// The failure of a top-level test is only counted once
+		// it can no longer be retried.
+		if t.Failed() && !t.topLevel {
 			numFailed.Add(1)
 		}
 
@@ -2009,7 +2102,13 @@ func tRunner(t *T, fn func(t *T)) {
 		for root := &t.common; root.parent != nil; root = root.parent {
 			root.flushPartial()
 		}
-		t.report() // Report after all subtests have finished.
+		if !t.retry(fn) {
+			if t.topLevel && t.Failed() {
+				numFailed.Add(1)
+				t.parent.Fail()
+			}
+			t.report() // Report after all subtests have finished.
+		}
 
 		// Do not lock t.done to allow race detector to detect race in case
 		// the user does not appropriately synchronize a goroutine.
@@ -2026,6 +2125,9 @@ func tRunner(t *T, fn func(t *T)) {
 
 	t.start = highPrecisionTimeNow()
 	t.resetRaces()
+	if t.topLevel && *testTimeout > 0 {
+		t.SetTimeout(*testTimeout)
+	}
 	fn(t)
 
 	// code beyond here will not be executed when FailNow is invoked
@@ -2059,9 +2161,14 @@ func (t *T) Run(name string, f func(t *T)) bool {
 	var pc [maxStackLen]uintptr
 	n := runtime.Callers(2, pc[:])
 
-	// There's no reason to inherit this context from parent. The user's code can't observe
-	// the difference between the background context and the one from the parent test.
-	ctx, cancelCtx := context.WithCancel(context.Background())
+	// The context is derived from the parent's, so that it is canceled when
+	// the parent test times out. Otherwise, the user's code can't observe the
+	// difference: the parent's context is canceled only after its subtests finish.
+	parentCtx := t.ctx
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+	ctx, cancelCtx := context.WithCancel(parentCtx)
 	t = &T{
 		common: common{
 			barrier:    make(chan bool),
@@ -2075,6 +2182,7 @@ func (t *T) Run(name string, f func(t *T)) bool {
 			chatty:     t.chatty,
 			ctx:        ctx,
 			cancelCtx:  cancelCtx,
+			topLevel:   t.level == 0,
 		},
 		tstate: t.tstate,
 	}
@@ -2111,6 +2219,71 @@ func (t *T) Run(name string, f func(t *T)) bool {
 	return !t.failed
 }
 
+// retry runs fn again in a fresh T if t is a failed top-level test that may
+// be retried, after reporting the failed attempt. The result of the test is
+// then reported by its last attempt. retry reports whether fn was run again.
+func (t *T) retry(fn func(t *T)) bool {
+	if !t.topLevel || !t.Failed() || t.hasAbandoned.Load() {
+		// An abandoned attempt may still be running,
+		// so the test cannot be run again.
+		return false
+	}
+	retries := *retry
+	if retries == 0 && t.isFlaky() {
+		retries = 1
+	}
+	if uint(t.attempt) >= retries {
+		return false
+	}
+	t.flushToParent(t.name, "--- RETRY: %s (%s)\n", t.name, fmtDuration(t.duration))
+
+	// Subtests of the new attempt get the same names as those of this one.
+	t.tstate.match.clearSubNamesOf(t.name)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	t2 := &T{
+		common: common{
+			barrier:    make(chan bool),
+			signal:     make(chan bool, 1),
+			name:       t.name,
+			modulePath: t.modulePath,
+			importPath: t.importPath,
+			parent:     t.parent,
+			level:      t.level,
+			creator:    t.creator,
+			chatty:     t.chatty,
+			ctx:        ctx,
+			cancelCtx:  cancelCtx,
+			flaky:      t.isFlaky(),
+			topLevel:   true,
+		},
+		attempt:     t.attempt + 1,
+		serialRetry: !t.isParallel,
+		tstate:      t.tstate,
+	}
+	t2.w = indenter{&t2.common}
+	t2.setOutputWriter()
+
+	if t2.chatty != nil {
+		t2.chatty.Updatef(t2.name, "=== RUN   %s\n", t2.name)
+	}
+	running.Store(t2.name, highPrecisionTimeNow())
+	go tRunner(t2, fn)
+	<-t2.signal
+	if t2.serialRetry && t2.isParallel {
+		// Reacquire the count for sequential tests released by Parallel.
+		t.tstate.waitParallel()
+	}
+	return true
+}
+
+// isFlaky reports whether the test is known to be flaky.
+func (c *common) isFlaky() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.flaky
+}
+
 // testingSynctestTest runs f within a synctest bubble.
 // It is called by synctest.Test, from within an already-created bubble.
 //
@@ -2150,9 +2323,12 @@ func testingSynctestTest(t *T, f func(*T)) (ok bool) {
 }
 
 // Deadline reports the time at which the test binary will have
-// exceeded the timeout specified by the -timeout flag.
+// exceeded the timeout specified by the -timeout flag, or the test
+// or one of its parents will have exceeded its own timeout, set by
+// the -testtimeout flag or [T.SetTimeout], whichever comes first.
 //
-// The ok result is false if the -timeout flag indicates “no timeout” (0).
+// The ok result is false if there is no timeout: the -timeout flag
+// indicates “no timeout” (0) and no per-test timeout is set.
 func (t *T) Deadline() (deadline time.Time, ok bool) {
 	if t.isSynctest {
 		// There's no point in returning a real-clock deadline to
@@ -2161,6 +2337,14 @@ func (t *T) Deadline() (deadline time.Time, ok bool) {
 		panic("testing: t.Deadline called inside synctest bubble")
 	}
 	deadline = t.tstate.deadline
+	for c := &t.common; c != nil; c = c.parent {
+		c.timeoutMu.Lock()
+		d := c.testDeadline
+		c.timeoutMu.Unlock()
+		if !d.IsZero() && (deadline.IsZero() || d.Before(deadline)) {
+			deadline = d
+		}
+	}
 	return deadline, !deadline.IsZero()
 }
 
@@ -2240,16 +2424,24 @@ func (f matchStringOnly) ImportPath() string                          { return "
 func (f matchStringOnly) StartTestLog(io.Writer)                      {}
 func (f matchStringOnly) StopTestLog() error                          { return errMain }
 func (f matchStringOnly) SetPanicOnExit0(bool)                        {}
-func (f matchStringOnly) CoordinateFuzzing(time.Duration, int64, time.Duration, int64, int, []corpusEntry, []reflect.Type, string, string) error {
+func (f matchStringOnly) CoordinateFuzzing(context.Context, string, io.Writer, time.Duration, int64, time.Duration, int64, int, []corpusEntry, []reflect.Type, [][]byte, string, string) error {
+	return errMain
+}
+func (f matchStringOnly) MergeCorpus(string, io.Writer, time.Duration, int64, int, []corpusEntry, []reflect.Type, []string, string) error {
+	return errMain
+}
+func (f matchStringOnly) RunFuzzWorker([]reflect.Type, [][]byte, func(corpusEntry) error) error {
 	return errMain
 }
-func (f matchStringOnly) RunFuzzWorker(func(corpusEntry) error) error { return errMain }
 func (f matchStringOnly) ReadCorpus(string, []reflect.Type) ([]corpusEntry, error) {
 	return nil, errMain
 }
 func (f matchStringOnly) CheckCorpus([]any, []reflect.Type) error { return nil }
-func (f matchStringOnly) ResetCoverage()                          {}
-func (f matchStringOnly) SnapshotCoverage()                       {}
+func (f matchStringOnly) ReadDictionary(string) ([][]byte, error) {
+	return nil, errMain
+}
+func (f matchStringOnly) ResetCoverage()    {}
+func (f matchStringOnly) SnapshotCoverage() {}
 
 func (f matchStringOnly) InitRuntimeCoverage() (mode string, tearDown func(string, string) (string, error), snapcov func() float64) {
 	return
@@ -2297,10 +2489,12 @@ type testDeps interface {
 	StartTestLog(io.Writer)
 	StopTestLog() error
 	WriteProfileTo(string, io.Writer, int) error
-	CoordinateFuzzing(time.Duration, int64, time.Duration, int64, int, []corpusEntry, []reflect.Type, string, string) error
-	RunFuzzWorker(func(corpusEntry) error) error
+	CoordinateFuzzing(context.Context, string, io.Writer, time.Duration, int64, time.Duration, int64, int, []corpusEntry, []reflect.Type, [][]byte, string, string) error
+	MergeCorpus(string, io.Writer, time.Duration, int64, int, []corpusEntry, []reflect.Type, []string, string) error
+	RunFuzzWorker([]reflect.Type, [][]byte, func(corpusEntry) error) error
 	ReadCorpus(string, []reflect.Type) ([]corpusEntry, error)
 	CheckCorpus([]any, []reflect.Type) error
+	ReadDictionary(string) ([][]byte, error)
 	ResetCoverage()
 	SnapshotCoverage()
 	InitRuntimeCoverage() (mode string, tearDown func(coverprofile string, gocoverdir string) (string, error), snapcov func() float64)
@@ -2396,6 +2590,12 @@ func (m *M) Run() (code int) {
 		m.exitCode = 2
 		return
 	}
+	if *fuzzMerge != "" && *matchFuzz == "" {
+		fmt.Fprintln(os.Stderr, "testing: -test.fuzzmerge can only be used with -test.fuzz")
+		flag.Usage()
+		m.exitCode = 2
+		return
+	}
 
 	if *matchList != "" {
 		listTests(m.deps.MatchString, m.tests, m.benchmarks, m.fuzzTargets, m.examples)
@@ -2488,7 +2688,9 @@ func (t *T) report() {
 	}
 	dstr := fmtDuration(t.duration)
 	format := "--- %s: %s (%s)\n"
-	if t.Failed() {
+	if !t.Failed() && t.attempt > 0 {
+		t.flushToParent(t.name, format, "FLAKY", t.name, dstr)
+	} else if t.Failed() {
 		t.flushToParent(t.name, format, "FAIL", t.name, dstr)
 	} else if t.chatty != nil {
 		if t.Skipped() {
@@ -2556,7 +2758,7 @@ func runTests(modulePath, importPath string, matchString func(pat, str string) (
 				break
 			}
 			ctx, cancelCtx := context.WithCancel(context.Background())
-			tstate := newTestState(*parallel, newMatcher(matchString, *match, "-test.run", *skip))
+			tstate := newTestState(*parallel, newRunMatcher(matchString))
 			tstate.deadline = deadline
 			t := &T{
 				common: common{
@@ -983,6 +983,308 @@ func TestParentRun(t1 *testing.T) {
 	})
 }
 
+var retryAttempts int
+
+func TestRetry(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		retryAttempts++
+		t.Parallel()
+		t.Run("sub", func(t *testing.T) {
+			if retryAttempts < 3 {
+				t.Errorf("attempt %d failed", retryAttempts)
+			}
+		})
+		return
+	}
+
+	out := runTest(t, "TestRetry", "-test.retry=2")
+	for _, tt := range []struct {
+		line string
+		want int
+	}{
+		{"--- RETRY: TestRetry ", 2},
+		{"    --- FAIL: TestRetry/sub ", 2},
+		{"--- FLAKY: TestRetry ", 1},
+		{"    --- PASS: TestRetry/sub ", 1},
+		{"attempt 3 failed", 0},
+	} {
+		if c := bytes.Count(out, []byte(tt.line)); c != tt.want {
+			t.Errorf("got %d lines containing %q, want %d", c, tt.line, tt.want)
+		}
+	}
+	if !bytes.HasSuffix(out, []byte("\nPASS\n")) {
+		t.Errorf("flaky test caused the test binary to fail")
+	}
+
+	out = runTest(t, "TestRetry", "-test.retry=1")
+	if !bytes.Contains(out, []byte("--- FAIL: TestRetry ")) {
+		t.Errorf("test that failed every attempt was not reported as failing")
+	}
+}
+
+var retryParallelAttempts int
+
+// TestRetryParallel checks that a retried test which calls Parallel only
+// after its first attempt failed does not wait forever for a free slot.
+func TestRetryParallel(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		retryParallelAttempts++
+		if retryParallelAttempts == 1 {
+			t.Fatal("first attempt failed before calling Parallel")
+		}
+		t.Parallel()
+		return
+	}
+
+	out := runTest(t, "TestRetryParallel", "-test.retry=1", "-test.parallel=1", "-test.timeout=1m")
+	if !bytes.Contains(out, []byte("--- FLAKY: TestRetryParallel ")) {
+		t.Errorf("retried test not reported as flaky")
+	}
+	if !bytes.HasSuffix(out, []byte("\nPASS\n")) {
+		t.Errorf("retried test caused the test binary to fail")
+	}
+}
+
+var flakyAttempts int
+
+func TestFlaky(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		flakyAttempts++
+		t.Run("sub", func(t *testing.T) {
+			t.Flaky()
+			if flakyAttempts == 1 {
+				t.Error("known to be flaky")
+			}
+		})
+		return
+	}
+
+	out := runTest(t, "TestFlaky")
+	for _, tt := range []struct {
+		line string
+		want int
+	}{
+		{"--- RETRY: TestFlaky ", 1},
+		{"--- FLAKY: TestFlaky ", 1},
+		{"known to be flaky", 1},
+	} {
+		if c := bytes.Count(out, []byte(tt.line)); c != tt.want {
+			t.Errorf("got %d lines containing %q, want %d", c, tt.line, tt.want)
+		}
+	}
+	if !bytes.HasSuffix(out, []byte("\nPASS\n")) {
+		t.Errorf("test marked as flaky that passed when retried caused the test binary to fail")
+	}
+}
+
+// TestFlakyFail checks that a test marked as flaky that fails
+// every attempt is reported as failing.
+func TestFlakyFail(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		t.Flaky()
+		t.Error("always fails")
+		return
+	}
+
+	out := runTest(t, "TestFlakyFail")
+	if c := bytes.Count(out, []byte("--- RETRY: TestFlakyFail ")); c != 1 {
+		t.Errorf("got %d retries, want 1", c)
+	}
+	if !bytes.Contains(out, []byte("--- FAIL: TestFlakyFail ")) {
+		t.Errorf("test marked as flaky that failed every attempt not reported as failing")
+	}
+	if bytes.Contains(out, []byte("--- FLAKY: TestFlakyFail ")) {
+		t.Errorf("test marked as flaky that failed every attempt reported as flaky")
+	}
+	if !bytes.HasSuffix(out, []byte("\nFAIL\n")) {
+		t.Errorf("test marked as flaky that failed every attempt did not fail the test binary")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		t.Chdir(os.Getenv("GOLDEN_DIR"))
+		t.Run("match", func(t *testing.T) {
+			t.GoldenString("hello\n")
+		})
+		t.Run("mismatch", func(t *testing.T) {
+			t.Golden([]byte("hello\ngoodbye\n"))
+		})
+		t.Run("missing", func(t *testing.T) {
+			t.GoldenString("new\n")
+		})
+		t.Run("multiple", func(t *testing.T) {
+			t.GoldenString("one\n")
+			t.GoldenString("two\n")
+		})
+		return
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GOLDEN_DIR", dir)
+	testdata := filepath.Join(dir, "testdata", "TestGolden")
+	if err := os.MkdirAll(testdata, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"match", "mismatch"} {
+		if err := os.WriteFile(filepath.Join(testdata, name+".golden"), []byte("hello\n"), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := runTest(t, "TestGolden")
+	for _, want := range []string{
+		"--- PASS: TestGolden/match ",
+		"--- FAIL: TestGolden/mismatch ",
+		"run with -update-golden to update it",
+		"\n        +goodbye\n",
+		"--- FAIL: TestGolden/missing ",
+		"does not exist; run with -update-golden to create it",
+		"multiple.2.golden does not exist",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+
+	out = runTest(t, "TestGolden", "-test.update-golden")
+	if !bytes.HasSuffix(out, []byte("\nPASS\n")) {
+		t.Errorf("tests failed with -test.update-golden")
+	}
+	for name, want := range map[string]string{
+		"match":      "hello\n",
+		"mismatch":   "hello\ngoodbye\n",
+		"missing":    "new\n",
+		"multiple":   "one\n",
+		"multiple.2": "two\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(testdata, name+".golden"))
+		if err != nil {
+			t.Error(err)
+		} else if string(got) != want {
+			t.Errorf("%s.golden = %q after -test.update-golden, want %q", name, got, want)
+		}
+	}
+
+	out = runTest(t, "TestGolden")
+	if !bytes.HasSuffix(out, []byte("\nPASS\n")) {
+		t.Errorf("tests failed after -test.update-golden")
+	}
+}
+
+func TestSetTimeout(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		t.Run("canceled", func(t *testing.T) {
+			t.SetTimeout(10 * time.Millisecond)
+			if _, ok := t.Deadline(); !ok {
+				t.Errorf("Deadline reports no deadline after SetTimeout")
+			}
+			<-t.Context().Done()
+		})
+		t.Run("hung", func(t *testing.T) {
+			t.SetTimeout(10 * time.Millisecond)
+			select {}
+		})
+		t.Run("parallel", func(t *testing.T) {
+			// The time spent waiting for "removed" does not count.
+			t.SetTimeout(100 * time.Millisecond)
+			t.Parallel()
+		})
+		t.Run("removed", func(t *testing.T) {
+			t.SetTimeout(10 * time.Millisecond)
+			t.SetTimeout(0)
+			time.Sleep(200 * time.Millisecond)
+		})
+		return
+	}
+
+	out := runTest(t, "TestSetTimeout")
+	for _, want := range []string{
+		"--- FAIL: TestSetTimeout/canceled ",
+		"--- FAIL: TestSetTimeout/hung ",
+		"--- PASS: TestSetTimeout/parallel ",
+		"--- PASS: TestSetTimeout/removed ",
+		"test timed out after 10ms",
+		"testing_test.TestSetTimeout.func2",
+		"test did not return after timing out; abandoning it",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	if c := bytes.Count(out, []byte("abandoning it")); c != 1 {
+		t.Errorf("%d tests abandoned, want 1", c)
+	}
+	if bytes.Contains(out, []byte("testing_test.TestSetTimeout.func4")) {
+		t.Errorf("output contains goroutines of other tests")
+	}
+}
+
+// TestSetTimeoutAbandoned checks that an abandoned subtest counts as a
+// failure, is not retried, and does not crash the test binary if it
+// panics later.
+func TestSetTimeoutAbandoned(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		t.Run("hung", func(t *testing.T) {
+			t.SetTimeout(10 * time.Millisecond)
+			time.Sleep(100 * time.Millisecond)
+			panic("abandoned test panicked")
+		})
+		t.Run("later", func(t *testing.T) {
+			time.Sleep(200 * time.Millisecond)
+		})
+		return
+	}
+
+	out := runTest(t, "TestSetTimeoutAbandoned", "-test.retry=1")
+	for _, tt := range []struct {
+		line string
+		want int
+	}{
+		{"abandoning it", 1},
+		{"--- RETRY: ", 0},
+		{"--- FAIL: TestSetTimeoutAbandoned/hung ", 1},
+		{"--- PASS: TestSetTimeoutAbandoned/later ", 1},
+		{"--- FAIL: TestSetTimeoutAbandoned ", 1},
+		{"abandoned test panicked", 0},
+	} {
+		if c := bytes.Count(out, []byte(tt.line)); c != tt.want {
+			t.Errorf("got %d lines containing %q, want %d", c, tt.line, tt.want)
+		}
+	}
+	if !bytes.HasSuffix(out, []byte("\nFAIL\n")) {
+		t.Errorf("abandoned test did not fail the test binary")
+	}
+
+	out = runTest(t, "TestSetTimeoutAbandoned", "-test.failfast")
+	if bytes.Contains(out, []byte("TestSetTimeoutAbandoned/later")) {
+		t.Errorf("test run after an abandoned test with -failfast")
+	}
+}
+
+func TestTestTimeoutFlag(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		t.Run("sub", func(t *testing.T) {
+			<-t.Context().Done()
+		})
+		return
+	}
+
+	out := runTest(t, "TestTestTimeoutFlag", "-test.testtimeout=10ms")
+	for _, want := range []string{
+		"--- FAIL: TestTestTimeoutFlag ",
+		"test timed out after 10ms",
+		"testing_test.TestTestTimeoutFlag.func1",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	if bytes.Contains(out, []byte("abandoning it")) {
+		t.Errorf("test abandoned after the context of its subtest was canceled")
+	}
+}
+
 func TestContext(t *testing.T) {
 	ctx := t.Context()
 	if err := ctx.Err(); err != nil {
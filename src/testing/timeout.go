@@ -0,0 +1,255 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTimeoutGrace is the longest time a test that timed out is given
+// to return before it is abandoned.
+const maxTimeoutGrace = 5 * time.Second
+
+// SetTimeout sets a timeout for the test: if the test has not finished
+// d after the call to SetTimeout, it fails. SetTimeout replaces any
+// earlier timeout of the test, including the one set by the -testtimeout
+// flag, and SetTimeout(0) removes it. If the test calls [T.Parallel],
+// its timeout starts again when it resumes, so that the time spent
+// waiting to run in parallel does not count.
+//
+// When a test times out, it fails with an error showing the stack traces
+// of the test goroutine and of the goroutines it started, including those
+// of its subtests, and the contexts returned by [T.Context] for the test
+// and its subtests are canceled. If the test then returns, the remaining
+// tests run as usual. If it does not return within d or 5 seconds,
+// whichever is shorter, the test is abandoned: it is reported as failed,
+// and the remaining tests run while its goroutines are left running.
+// An abandoned test, and the top-level test that contains it, are not
+// retried because of the -test.retry flag or [T.Flaky], as the abandoned
+// goroutines may still be running. The cleanup functions of an abandoned
+// test run only if it returns after all, and a panic in it is then ignored.
+//
+// SetTimeout must be called from the goroutine running the test function.
+func (t *T) SetTimeout(d time.Duration) {
+	if t.isSynctest {
+		panic("testing: t.SetTimeout called inside synctest bubble")
+	}
+	t.timeoutMu.Lock()
+	defer t.timeoutMu.Unlock()
+	if t.timeoutGoid == 0 {
+		t.timeoutGoid = curGoroutineID()
+	}
+	t.timeout = d
+	t.startTimeout()
+}
+
+// pauseTimeout stops the timer of the test while it waits to run in parallel.
+func (t *T) pauseTimeout() {
+	t.timeoutMu.Lock()
+	defer t.timeoutMu.Unlock()
+	if t.timeoutTimer != nil {
+		t.timeoutTimer.Stop()
+		t.timeoutTimer = nil
+	}
+	t.timeoutSeq++
+	t.testDeadline = time.Time{}
+}
+
+// restartTimeout starts the timeout of the test again, if it has one.
+// It is called when a parallel test resumes.
+func (t *T) restartTimeout() {
+	t.timeoutMu.Lock()
+	defer t.timeoutMu.Unlock()
+	if t.timeout > 0 {
+		t.startTimeout()
+	}
+}
+
+// startTimeout starts the timer for t.timeout, stopping any earlier one.
+// t.timeoutMu must be held.
+func (t *T) startTimeout() {
+	if t.timeoutTimer != nil {
+		t.timeoutTimer.Stop()
+		t.timeoutTimer = nil
+	}
+	t.timeoutSeq++
+	t.testDeadline = time.Time{}
+	if t.timeout <= 0 || t.exited {
+		return
+	}
+	d, seq := t.timeout, t.timeoutSeq
+	t.testDeadline = time.Now().Add(d)
+	t.timeoutTimer = time.AfterFunc(d, func() { t.timedOut(d, seq) })
+}
+
+// stopTimeout stops the timeout of the test when the test function
+// has exited. It reports whether the test was abandoned after timing out,
+// in which case its result has already been reported.
+func (t *T) stopTimeout() (abandoned bool) {
+	t.timeoutMu.Lock()
+	defer t.timeoutMu.Unlock()
+	t.exited = true
+	if t.timeoutTimer != nil {
+		t.timeoutTimer.Stop()
+		t.timeoutTimer = nil
+	}
+	return t.abandoned
+}
+
+// timedOut fails the test when its timeout of d expires,
+// and abandons it if it does not exit soon after.
+func (t *T) timedOut(d time.Duration, seq int) {
+	t.timeoutMu.Lock()
+	if t.exited || seq != t.timeoutSeq {
+		// The test finished, or its timeout changed, just as the timer fired.
+		t.timeoutMu.Unlock()
+		return
+	}
+	// Holding timeoutMu keeps the test from finishing while it is failed.
+	t.Fail()
+	t.logNoSite(fmt.Sprintf("test timed out after %v\n\n%s", d, testGoroutines(t.timeoutGoid)))
+	t.timeoutMu.Unlock()
+
+	t.cancelCtx()
+	time.Sleep(min(d, maxTimeoutGrace))
+
+	t.timeoutMu.Lock()
+	if t.exited || seq != t.timeoutSeq {
+		t.timeoutMu.Unlock()
+		return
+	}
+	t.abandoned = true
+	t.timeoutMu.Unlock()
+	t.abandon()
+}
+
+// abandon reports the result of a test that timed out and did not exit,
+// and lets its parent continue as though it had finished.
+// The test goroutine does nothing more once it exits; see tRunner.
+func (t *T) abandon() {
+	t.logNoSite("test did not return after timing out; abandoning it")
+
+	// The fields written by the test goroutine before the timer
+	// started are safe to read, as the test goroutine will not write
+	// them again. Those it reads while it runs, such as t.done, are
+	// written with t.mu held.
+	t.mu.Lock()
+	t.duration += highPrecisionTimeSince(t.start)
+	t.mu.Unlock()
+	if t.isParallel {
+		t.tstate.release()
+	}
+	for root := &t.common; root.parent != nil; root = root.parent {
+		root.flushPartial()
+	}
+	// Count the failure now, as tRunner does not once the test exits.
+	// The failure of a subtest has already been passed on to its parent.
+	numFailed.Add(1)
+	if t.topLevel {
+		t.parent.Fail()
+	}
+	for c := &t.common; c != nil; c = c.parent {
+		if c.topLevel {
+			c.hasAbandoned.Store(true)
+			break
+		}
+	}
+	t.report()
+
+	t.mu.Lock()
+	t.done = true
+	t.mu.Unlock()
+	if t.parent != nil && !t.hasSub.Load() {
+		t.setRan()
+	}
+	running.Delete(t.name)
+	if t.isParallel {
+		parallelStop.Add(1)
+	}
+	t.signal <- true
+}
+
+// logNoSite is like log, but without the call site,
+// for messages logged by the testing package itself.
+func (c *common) logNoSite(s string) {
+	s = strings.TrimSuffix(s, "\n")
+	s = strings.ReplaceAll(s, "\n", "\n"+indent) + "\n"
+	n := c.destination()
+	if n == nil {
+		return
+	}
+	n.flushPartial()
+	n.o.Write([]byte(s))
+}
+
+// curGoroutineID returns the ID of the calling goroutine,
+// as shown in its stack trace.
+func curGoroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// The stack trace starts with "goroutine 18 [running]:".
+	f := strings.Fields(string(buf[:n]))
+	if len(f) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(f[1], 10, 64)
+	return id
+}
+
+// testGoroutines returns the stack traces of the goroutine with the given
+// ID and of the goroutines it created, directly or indirectly.
+func testGoroutines(goid uint64) string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	type goroutine struct {
+		id, creator uint64
+		trace       string
+	}
+	var all []goroutine
+	for trace := range strings.SplitSeq(strings.TrimSpace(string(buf)), "\n\n") {
+		g := goroutine{trace: trace}
+		fmt.Sscanf(trace, "goroutine %d ", &g.id)
+		// A goroutine's trace ends with
+		// "created by f in goroutine 7" and the location of the go statement.
+		if _, after, ok := strings.Cut(trace, "\ncreated by "); ok {
+			line, _, _ := strings.Cut(after, "\n")
+			if i := strings.LastIndex(line, " in goroutine "); i >= 0 {
+				g.creator, _ = strconv.ParseUint(line[i+len(" in goroutine "):], 10, 64)
+			}
+		}
+		all = append(all, g)
+	}
+
+	ids := []uint64{goid}
+	for added := true; added; {
+		added = false
+		for _, g := range all {
+			if g.creator != 0 && slices.Contains(ids, g.creator) && !slices.Contains(ids, g.id) {
+				ids = append(ids, g.id)
+				added = true
+			}
+		}
+	}
+	var traces []string
+	for _, g := range all {
+		if slices.Contains(ids, g.id) {
+			traces = append(traces, g.trace)
+		}
+	}
+	return strings.Join(traces, "\n\n")
+}